@@ -0,0 +1,242 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// defaultBodyCaptureBytes сколько байт тела запроса или ответа пишется по умолчанию
+const defaultBodyCaptureBytes = 4096
+
+// BodyCaptureRule правило записи тел запроса и ответа для отдельных маршрутов
+type BodyCaptureRule struct {
+	Method   string // метод запроса, пустой - любой
+	Path     string // шаблон пути в формате path.Match, например /api/v1/payments/*
+	Request  bool   // записывать прочитанную обработчиком часть тела запроса
+	Response bool   // записывать тело ответа
+	MaxBytes int    // сколько байт тела записывать (по умолчанию 4096)
+}
+
+// AccessLogOptions настройки журнала HTTP-запросов
+type AccessLogOptions struct {
+	// BodyRules маршруты, для которых записываются тела. Применяется первое
+	// подходящее правило. Значения чувствительных ключей в JSON и формах
+	// скрываются, обрезанные тела и тела других форматов заменяются размером
+	BodyRules []BodyCaptureRule
+}
+
+// HTTPMiddleware пишет журнал HTTP-запросов: метод, путь, статус, размер ответа
+// и длительность. Обработчик получает в контексте логгер запроса
+func (l *Logger) HTTPMiddleware(opts AccessLogOptions) func(http.Handler) http.Handler {
+	l = l.orNop()
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			reqLogger := l.WithContext(r.Context())
+			rule := matchBodyRule(opts.BodyRules, r)
+
+			var reqBody *capture
+			if rule != nil && rule.Request && r.Body != nil && r.Body != http.NoBody {
+				reqBody = &capture{limit: rule.maxBytes()}
+				r.Body = &captureReader{ReadCloser: r.Body, capture: reqBody}
+			}
+
+			rw := &accessResponseWriter{ResponseWriter: w, status: http.StatusOK}
+			if rule != nil && rule.Response {
+				rw.body = &capture{limit: rule.maxBytes()}
+			}
+
+			next.ServeHTTP(rw, r.WithContext(NewContext(r.Context(), reqLogger)))
+
+			fields := map[string]interface{}{
+				"method":     r.Method,
+				"uri":        r.RequestURI,
+				"status":     rw.status,
+				"bytes":      rw.written,
+				"latency_ms": time.Since(start).Milliseconds(),
+			}
+			if reqBody != nil {
+				reqBody.addFields(fields, "request_body", r.Header.Get("Content-Type"), l.core.redactKeySet())
+			}
+			if rw.body != nil {
+				rw.body.addFields(fields, "response_body", rw.Header().Get("Content-Type"), l.core.redactKeySet())
+			}
+			reqLogger.withFields().WithFields(fields).Info("HTTP request processed")
+		})
+	}
+}
+
+// matchBodyRule возвращает первое правило, подходящее запросу
+func matchBodyRule(rules []BodyCaptureRule, r *http.Request) *BodyCaptureRule {
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Method != "" && !strings.EqualFold(rule.Method, r.Method) {
+			continue
+		}
+		if ok, _ := path.Match(rule.Path, r.URL.Path); ok {
+			return rule
+		}
+	}
+	return nil
+}
+
+// maxBytes возвращает ограничение размера записываемого тела
+func (r *BodyCaptureRule) maxBytes() int {
+	if r.MaxBytes > 0 {
+		return r.MaxBytes
+	}
+	return defaultBodyCaptureBytes
+}
+
+// capture первые байты тела запроса или ответа
+type capture struct {
+	buf       bytes.Buffer
+	limit     int
+	size      int
+	truncated bool
+}
+
+// write сохраняет не больше limit байт
+func (c *capture) write(p []byte) {
+	c.size += len(p)
+	if room := c.limit - c.buf.Len(); room < len(p) {
+		c.truncated = true
+		p = p[:max(room, 0)]
+	}
+	c.buf.Write(p)
+}
+
+// addFields добавляет тело со скрытыми чувствительными ключами в поля записи.
+// Обрезанное тело и тело, которое не удалось разобрать, не пишутся: в них
+// нельзя найти чувствительные значения, поэтому записывается только размер
+func (c *capture) addFields(fields map[string]interface{}, key, contentType string, redactKeys map[string]struct{}) {
+	if c.truncated {
+		fields[key+"_bytes"] = c.size
+		fields[key+"_truncated"] = true
+		return
+	}
+	body, ok := redactBody(c.buf.Bytes(), contentType, redactKeys)
+	if !ok {
+		fields[key+"_bytes"] = c.size
+		return
+	}
+	fields[key] = body
+}
+
+// redactBody скрывает значения чувствительных ключей в JSON-теле на любом
+// уровне вложенности и в теле формы application/x-www-form-urlencoded.
+// ok false, если тело не удалось разобрать
+func redactBody(body []byte, contentType string, redactKeys map[string]struct{}) (string, bool) {
+	if len(body) == 0 {
+		return "", true
+	}
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "application/x-www-form-urlencoded" {
+		return redactForm(body, redactKeys)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return "", false
+	}
+	if !redactJSON(doc, redactKeys) {
+		return string(body), true
+	}
+	redacted, err := json.Marshal(doc)
+	if err != nil {
+		return "", false
+	}
+	return string(redacted), true
+}
+
+// redactForm скрывает значения чувствительных ключей формы
+func redactForm(body []byte, redactKeys map[string]struct{}) (string, bool) {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return "", false
+	}
+	changed := false
+	for k, values := range form {
+		if _, ok := redactKeys[strings.ToLower(k)]; !ok {
+			continue
+		}
+		for i := range values {
+			values[i] = redactedValue
+		}
+		changed = true
+	}
+	if !changed {
+		return string(body), true
+	}
+	return form.Encode(), true
+}
+
+// redactJSON заменяет значения чувствительных ключей и сообщает, были ли замены
+func redactJSON(doc interface{}, redactKeys map[string]struct{}) bool {
+	changed := false
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		for k, value := range v {
+			if _, ok := redactKeys[strings.ToLower(k)]; ok {
+				v[k] = redactedValue
+				changed = true
+				continue
+			}
+			changed = redactJSON(value, redactKeys) || changed
+		}
+	case []interface{}:
+		for _, value := range v {
+			changed = redactJSON(value, redactKeys) || changed
+		}
+	}
+	return changed
+}
+
+// captureReader тело запроса, копирующее прочитанные обработчиком байты
+type captureReader struct {
+	io.ReadCloser
+	capture *capture
+}
+
+func (r *captureReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.capture.write(p[:n])
+	return n, err
+}
+
+// accessResponseWriter запоминает статус, размер и начало тела ответа
+type accessResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	written     int64
+	wroteHeader bool
+	body        *capture
+}
+
+func (w *accessResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *accessResponseWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(p)
+	w.written += int64(n)
+	if w.body != nil {
+		w.body.write(p[:n])
+	}
+	return n, err
+}
+
+// Unwrap дает http.ResponseController доступ к исходному ResponseWriter
+func (w *accessResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
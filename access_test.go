@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_HTTPMiddleware(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	var ctxLogger *Logger
+	handler := logger.HTTPMiddleware(AccessLogOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctxLogger = FromContext(r.Context())
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("created"))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/users?x=1", strings.NewReader(`{"name":"a"}`)))
+
+	require.NotNil(t, ctxLogger)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	entry := entries[0]
+	assert.Equal(t, "HTTP request processed", entry["msg"])
+	assert.Equal(t, "POST", entry["method"])
+	assert.Equal(t, "/api/v1/users?x=1", entry["uri"])
+	assert.Equal(t, 201.0, entry["status"])
+	assert.Equal(t, 7.0, entry["bytes"])
+	assert.Contains(t, entry, "latency_ms")
+	assert.NotContains(t, entry, "request_body")
+	assert.NotContains(t, entry, "response_body")
+}
+
+func TestLogger_HTTPMiddleware_BodyRules(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	var received string
+	handler := logger.HTTPMiddleware(AccessLogOptions{BodyRules: []BodyCaptureRule{
+		{Method: http.MethodGet, Path: "/api/v1/payments/*", Response: true},
+		{Path: "/api/v1/payments/*", Request: true, Response: true, MaxBytes: 64},
+	}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		_, _ = w.Write([]byte(`{"id":"p1","card":{"number":"4111","token":"t-1"}}`))
+	}))
+
+	reqBody := `{"amount":100,"password":"hunter2"}`
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/payments/p1", strings.NewReader(reqBody)))
+
+	assert.Equal(t, reqBody, received, "handler must receive the full body")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.JSONEq(t, `{"amount":100,"password":"[REDACTED]"}`, entries[0]["request_body"].(string))
+	assert.JSONEq(t, `{"id":"p1","card":{"number":"4111","token":"[REDACTED]"}}`, entries[0]["response_body"].(string))
+}
+
+func TestLogger_HTTPMiddleware_BodyTruncated(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	handler := logger.HTTPMiddleware(AccessLogOptions{BodyRules: []BodyCaptureRule{
+		{Path: "/upload", Request: true, MaxBytes: 4},
+	}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/upload", strings.NewReader(`{"password":"hunter2"}`)))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/other", strings.NewReader("skipped")))
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	// Обрезанное тело нельзя проверить на чувствительные ключи: пишется только размер
+	assert.NotContains(t, entries[0], "request_body")
+	assert.EqualValues(t, 22, entries[0]["request_body_bytes"])
+	assert.Equal(t, true, entries[0]["request_body_truncated"])
+	assert.NotContains(t, entries[1], "request_body")
+}
+
+func TestLogger_HTTPMiddleware_BodyFormats(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	handler := logger.HTTPMiddleware(AccessLogOptions{BodyRules: []BodyCaptureRule{
+		{Path: "/*", Request: true},
+	}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+	}))
+
+	form := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader("user=alice&password=hunter2"))
+	form.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	handler.ServeHTTP(httptest.NewRecorder(), form)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/note", strings.NewReader("password: hunter2")))
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "password=%5BREDACTED%5D&user=alice", entries[0]["request_body"])
+	assert.NotContains(t, entries[1], "request_body")
+	assert.EqualValues(t, 17, entries[1]["request_body_bytes"])
+	assert.NotContains(t, buf.String(), "hunter2")
+}
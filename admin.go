@@ -0,0 +1,164 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// levelRequest тело запроса на изменение уровня
+type levelRequest struct {
+	Level Level `json:"level"`
+}
+
+// AdminHandler возвращает HTTP-обработчик для управления логгером во время работы:
+//
+//	GET    /level                    текущий уровень
+//	PUT    /level                    изменить уровень: {"level": "debug"}
+//	GET    /targeting                правила таргетинга
+//	PUT    /targeting                заменить правила таргетинга
+//	POST   /targeting/users/{id}     повысить детализацию для пользователя
+//	DELETE /targeting/users/{id}     убрать пользователя из таргетинга
+//	GET    /services/levels          собственные уровни сервисов
+//	PUT    /services/{name}/level    задать уровень сервиса: {"level": "debug"}
+//	DELETE /services/{name}/level    вернуть сервис к общему уровню
+//	GET    /pools/levels             уровни компонентов ForPool
+//	PUT    /pools/{name}/level       задать уровень компонента: {"level": "debug"}
+//	DELETE /pools/{name}/level       вернуть компоненту уровень из ForPool
+//	GET    /snapshot                 записи кольцевого файла-самописца
+//	GET    /bundle                   отладочный архив tar.gz (DebugBundle)
+func (l *Logger) AdminHandler() http.Handler {
+	l = l.orNop()
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /level", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, levelRequest{Level: l.GetLevel()})
+	})
+
+	mux.HandleFunc("PUT /level", func(w http.ResponseWriter, r *http.Request) {
+		level, err := decodeLevel(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		l.SetLevel(level)
+		writeJSON(w, levelRequest{Level: l.GetLevel()})
+	})
+
+	mux.HandleFunc("GET /targeting", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, l.Targeting())
+	})
+
+	mux.HandleFunc("PUT /targeting", func(w http.ResponseWriter, r *http.Request) {
+		var req Targeting
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		l.SetTargeting(req)
+		writeJSON(w, l.Targeting())
+	})
+
+	mux.HandleFunc("POST /targeting/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		l.TargetUser(r.PathValue("id"))
+		writeJSON(w, l.Targeting())
+	})
+
+	mux.HandleFunc("DELETE /targeting/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		l.UntargetUser(r.PathValue("id"))
+		writeJSON(w, l.Targeting())
+	})
+
+	mux.HandleFunc("GET /services/levels", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, l.ServiceLevels())
+	})
+
+	mux.HandleFunc("PUT /services/{name}/level", func(w http.ResponseWriter, r *http.Request) {
+		level, err := decodeLevel(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		l.SetServiceLevel(r.PathValue("name"), level)
+		writeJSON(w, l.ServiceLevels())
+	})
+
+	mux.HandleFunc("DELETE /services/{name}/level", func(w http.ResponseWriter, r *http.Request) {
+		l.ResetServiceLevel(r.PathValue("name"))
+		writeJSON(w, l.ServiceLevels())
+	})
+
+	mux.HandleFunc("GET /pools/levels", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, l.PoolLevels())
+	})
+
+	mux.HandleFunc("PUT /pools/{name}/level", func(w http.ResponseWriter, r *http.Request) {
+		level, err := decodeLevel(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		l.SetPoolLevel(r.PathValue("name"), level)
+		writeJSON(w, l.PoolLevels())
+	})
+
+	mux.HandleFunc("DELETE /pools/{name}/level", func(w http.ResponseWriter, r *http.Request) {
+		l.ResetPoolLevel(r.PathValue("name"))
+		writeJSON(w, l.PoolLevels())
+	})
+
+	mux.HandleFunc("GET /snapshot", func(w http.ResponseWriter, r *http.Request) {
+		// Снимок собирается в памяти, чтобы ошибку можно было вернуть статусом
+		var buf bytes.Buffer
+		if err := l.Snapshot(&buf); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, ErrNoSnapshot) {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", `attachment; filename="log-snapshot.ndjson"`)
+		w.Write(buf.Bytes())
+	})
+
+	mux.HandleFunc("GET /bundle", func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		if err := l.DebugBundle(&buf); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		name := "debug-bundle-" + time.Now().UTC().Format("20060102T150405Z") + ".tar.gz"
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+		w.Write(buf.Bytes())
+	})
+
+	return mux
+}
+
+// decodeLevel читает уровень из тела {"level": "debug"}. Поле обязательно:
+// нулевой Level - это panic, и тело без него выключило бы почти все записи
+func decodeLevel(r *http.Request) (Level, error) {
+	var req struct {
+		Level *Level `json:"level"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return 0, err
+	}
+	if req.Level == nil {
+		return 0, errors.New("level is required")
+	}
+	return *req.Level, nil
+}
+
+// writeJSON записывает ответ в формате JSON
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
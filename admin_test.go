@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_AdminHandler_Level(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+	handler := logger.AdminHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/level", strings.NewReader(`{"level":"warning"}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, WarnLevel, logger.GetLevel())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/level", nil))
+	assert.JSONEq(t, `{"level":"warning"}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/level", strings.NewReader(`{"level":"loud"}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestLogger_AdminHandler_LevelRequired(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+	handler := logger.AdminHandler()
+	level := logger.GetLevel()
+
+	for _, target := range []string{"/level", "/services/orders/level", "/pools/db/level"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, target, strings.NewReader(`{}`)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+		assert.Contains(t, rec.Body.String(), "level is required", target)
+	}
+	assert.Equal(t, level, logger.GetLevel())
+	assert.Empty(t, logger.ServiceLevels())
+	assert.Empty(t, logger.PoolLevels())
+}
+
+func TestLogger_AdminHandler_Targeting(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+	handler := logger.AdminHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/targeting/users/alice", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var targeting Targeting
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &targeting))
+	assert.Equal(t, []string{"alice"}, targeting.UserIDs)
+	assert.Equal(t, DebugLevel, targeting.Level)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/targeting/users/alice", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, logger.Targeting().UserIDs)
+}
+
+func TestLogger_AdminHandler_ServiceLevels(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+	handler := logger.AdminHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/services/orders/level", strings.NewReader(`{"level":"debug"}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"orders":"debug"}`, rec.Body.String())
+	assert.Equal(t, map[string]Level{"orders": DebugLevel}, logger.ServiceLevels())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/services/levels", nil))
+	assert.JSONEq(t, `{"orders":"debug"}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/services/orders/level", nil))
+	assert.JSONEq(t, `{}`, rec.Body.String())
+}
+
+func TestLogger_AdminHandler_Bundle(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+	handler := logger.AdminHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/bundle", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/gzip", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "debug-bundle-")
+	assert.Contains(t, readBundle(t, rec.Body), "runtime.json")
+}
@@ -0,0 +1,226 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultAggregatorMaxLineBytes размер одной записи в потоке агрегатора по умолчанию
+const defaultAggregatorMaxLineBytes = 64 << 10
+
+// proxyHeaderTimeout сколько ждать заголовок PROXY и TLS-рукопожатие
+const proxyHeaderTimeout = 10 * time.Second
+
+// AggregatorOptions настройки приема логов от других хостов по TCP
+type AggregatorOptions struct {
+	Schema EntrySchema
+
+	// ProxyProtocol ожидает в начале соединения заголовок PROXY protocol v1 или v2
+	// от балансировщика: client_ip тогда берется из него, а не из адреса соединения
+	ProxyProtocol bool
+	// TLSConfig включает TLS. При проверке клиентских сертификатов (mTLS)
+	// CN сертификата клиента пишется в поле peer_cn
+	TLSConfig *tls.Config
+
+	MaxLineBytes int // размер одной записи (по умолчанию 64 КБ)
+
+	// MaxClockSkew допустимое расхождение часов хоста и агрегатора,
+	// как в IngestOptions
+	MaxClockSkew time.Duration
+}
+
+// ServeAggregator принимает соединения и читает из каждого записи ClientEntry
+// в формате JSON, по одной в строке. Записи проверяются по схеме, дополняются
+// полями source=aggregator, client_ip и peer_cn и пишутся через обычные
+// назначения. Возвращает ошибку листенера, например после его закрытия
+func (l *Logger) ServeAggregator(ln net.Listener, opts AggregatorOptions) error {
+	if opts.MaxLineBytes <= 0 {
+		opts.MaxLineBytes = defaultAggregatorMaxLineBytes
+	}
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go l.serveAggregatorConn(conn, opts)
+	}
+}
+
+// serveAggregatorConn читает записи одного соединения
+func (l *Logger) serveAggregatorConn(conn net.Conn, opts AggregatorOptions) {
+	defer conn.Close()
+
+	fields := logrus.Fields{"source": "aggregator", "client_ip": hostOf(conn.RemoteAddr())}
+	connLogger := l.with(logrus.Fields{"remote_addr": conn.RemoteAddr().String()})
+
+	_ = conn.SetReadDeadline(time.Now().Add(proxyHeaderTimeout))
+	reader := bufio.NewReader(conn)
+	var stream net.Conn = &bufferedConn{Conn: conn, reader: reader}
+
+	if opts.ProxyProtocol {
+		source, err := readProxyHeader(reader)
+		if err != nil {
+			connLogger.WithError(err).Warn("invalid PROXY protocol header")
+			return
+		}
+		if source != nil {
+			fields["client_ip"] = source.IP.String()
+		}
+	}
+
+	if opts.TLSConfig != nil {
+		tlsConn := tls.Server(stream, opts.TLSConfig)
+		if err := tlsConn.Handshake(); err != nil {
+			connLogger.WithError(err).Warn("TLS handshake failed")
+			return
+		}
+		if certs := tlsConn.ConnectionState().PeerCertificates; len(certs) > 0 {
+			fields["peer_cn"] = certs[0].Subject.CommonName
+		}
+		stream = tlsConn
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+
+	entryLogger := l.with(fields)
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 0, 4096), opts.MaxLineBytes)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var entry ClientEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			connLogger.WithError(err).Debug("invalid aggregated entry")
+			continue
+		}
+		entry, level, err := opts.Schema.Sanitize(entry)
+		if err != nil {
+			connLogger.WithError(err).Debug("rejected aggregated entry")
+			continue
+		}
+		entryLogger.logClientEntry(level, entry, time.Now(), opts.MaxClockSkew)
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, net.ErrClosed) {
+		connLogger.WithError(err).Warn("aggregator connection failed")
+	}
+}
+
+// bufferedConn соединение, чтение которого идет через буфер, уже содержащий
+// прочитанные после заголовка PROXY байты
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+// hostOf возвращает IP из сетевого адреса
+func hostOf(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+// proxyV2Signature сигнатура заголовка PROXY protocol v2
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// readProxyHeader читает заголовок PROXY protocol v1 или v2 и возвращает
+// исходный адрес клиента. Для соединений без адреса (UNKNOWN, LOCAL) возвращает nil
+func readProxyHeader(r *bufio.Reader) (*net.TCPAddr, error) {
+	prefix, err := r.Peek(len(proxyV2Signature))
+	if err == nil && bytes.Equal(prefix, proxyV2Signature) {
+		return readProxyV2(r)
+	}
+	return readProxyV1(r)
+}
+
+// readProxyV1 разбирает текстовый заголовок:
+// PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n
+func readProxyV1(r *bufio.Reader) (*net.TCPAddr, error) {
+	// По спецификации заголовок v1 не длиннее 107 байт
+	var line []byte
+	for len(line) < 107 {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("read PROXY header: %w", err)
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+	}
+	header := string(line)
+	if !strings.HasPrefix(header, "PROXY ") || !strings.HasSuffix(header, "\r\n") {
+		return nil, errors.New("missing PROXY header")
+	}
+
+	parts := strings.Fields(strings.TrimSuffix(header, "\r\n"))
+	if len(parts) >= 2 && parts[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(parts) != 6 || (parts[1] != "TCP4" && parts[1] != "TCP6") {
+		return nil, fmt.Errorf("malformed PROXY header: %q", header)
+	}
+	ip := net.ParseIP(parts[2])
+	if ip == nil {
+		return nil, fmt.Errorf("malformed PROXY source address: %q", parts[2])
+	}
+	var port int
+	if _, err := fmt.Sscanf(parts[4], "%d", &port); err != nil {
+		return nil, fmt.Errorf("malformed PROXY source port: %q", parts[4])
+	}
+	return &net.TCPAddr{IP: ip, Port: port}, nil
+}
+
+// readProxyV2 разбирает двоичный заголовок v2
+func readProxyV2(r *bufio.Reader) (*net.TCPAddr, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("read PROXY v2 header: %w", err)
+	}
+	verCmd, family := header[12], header[13]
+	if verCmd>>4 != 2 {
+		return nil, fmt.Errorf("unsupported PROXY version: %d", verCmd>>4)
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, fmt.Errorf("read PROXY v2 addresses: %w", err)
+	}
+
+	// Команда LOCAL: соединение от самого балансировщика, например health check
+	if verCmd&0x0F == 0 {
+		return nil, nil
+	}
+
+	switch family >> 4 {
+	case 1: // IPv4: src(4) dst(4) sport(2) dport(2)
+		if len(payload) < 12 {
+			return nil, errors.New("short PROXY v2 IPv4 addresses")
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:4]), Port: int(binary.BigEndian.Uint16(payload[8:10]))}, nil
+	case 2: // IPv6: src(16) dst(16) sport(2) dport(2)
+		if len(payload) < 36 {
+			return nil, errors.New("short PROXY v2 IPv6 addresses")
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:16]), Port: int(binary.BigEndian.Uint16(payload[32:34]))}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,202 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startAggregator запускает агрегатор на локальном порту
+func startAggregator(t *testing.T, opts AggregatorOptions) (*lockedBuffer, string) {
+	t.Helper()
+	logger, _ := newBufferLogger(t)
+	buf := &lockedBuffer{}
+	setTestSink(logger, buf, &logrus.JSONFormatter{})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go logger.ServeAggregator(ln, opts)
+
+	return buf, ln.Addr().String()
+}
+
+func TestLogger_ServeAggregator_ProxyProtocol(t *testing.T) {
+	buf, addr := startAggregator(t, AggregatorOptions{ProxyProtocol: true})
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("PROXY TCP4 192.0.2.10 198.51.100.1 56324 443\r\n" +
+		`{"level":"error","message":"disk full","fields":{"host":"db-1","client_ip":"6.6.6.6"}}` + "\n" +
+		"not json\n" +
+		`{"level":"info","message":"recovered"}` + "\n"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	aggregated := func() []map[string]interface{} {
+		var entries []map[string]interface{}
+		for _, e := range decodeEntries(t, buf) {
+			if e["source"] == "aggregator" {
+				entries = append(entries, e)
+			}
+		}
+		return entries
+	}
+	require.Eventually(t, func() bool { return len(aggregated()) >= 2 }, time.Second, 10*time.Millisecond)
+
+	entries := aggregated()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "disk full", entries[0]["msg"])
+	assert.Equal(t, "192.0.2.10", entries[0]["client_ip"])
+	assert.Equal(t, "db-1", entries[0]["host"])
+	assert.Equal(t, "recovered", entries[1]["msg"])
+}
+
+func TestLogger_ServeAggregator_MutualTLS(t *testing.T) {
+	serverCert, clientCert, pool := newTestCertificates(t)
+	buf, addr := startAggregator(t, AggregatorOptions{TLSConfig: &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	}})
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{
+		Certificates: []tls.Certificate{clientCert},
+		RootCAs:      pool,
+		ServerName:   "aggregator",
+	})
+	require.NoError(t, err)
+	_, err = conn.Write([]byte(`{"level":"warn","message":"slow query"}` + "\n"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	require.Eventually(t, func() bool { return len(buf.String()) > 0 }, time.Second, 10*time.Millisecond)
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "slow query", entries[0]["msg"])
+	assert.Equal(t, "billing-worker", entries[0]["peer_cn"])
+	assert.Equal(t, "127.0.0.1", entries[0]["client_ip"])
+}
+
+func TestLogger_ServeAggregator_PipelineFields(t *testing.T) {
+	buf, addr := startAggregator(t, AggregatorOptions{})
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte(`{"level":"info","message":"spoofed","fields":{` +
+		`"retention":"legal_hold","priority":"critical","checksum":"00000000","metric":true,"summary_key":"k","host":"db-1"}}` + "\n"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	var entry map[string]interface{}
+	require.Eventually(t, func() bool {
+		for _, e := range decodeEntries(t, buf) {
+			if e["source"] == "aggregator" {
+				entry = e
+				return true
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, "db-1", entry["host"])
+	for _, key := range []string{RetentionField, PriorityField, ChecksumField, MetricTagField, SummaryKeyField} {
+		assert.NotContains(t, entry, key)
+	}
+	assert.ElementsMatch(t, []interface{}{"checksum", "metric", "priority", "retention", "summary_key"}, entry["dropped_fields"])
+}
+
+func TestReadProxyHeader_V1(t *testing.T) {
+	addr, err := readProxyHeader(bufio.NewReader(bytes.NewBufferString("PROXY TCP6 2001:db8::1 2001:db8::2 4000 443\r\nrest")))
+	require.NoError(t, err)
+	assert.Equal(t, "2001:db8::1", addr.IP.String())
+	assert.Equal(t, 4000, addr.Port)
+
+	addr, err = readProxyHeader(bufio.NewReader(bytes.NewBufferString("PROXY UNKNOWN\r\n")))
+	require.NoError(t, err)
+	assert.Nil(t, addr)
+
+	for _, header := range []string{"GET / HTTP/1.1\r\n", "PROXY TCP4 bad 1.1.1.1 1 2\r\n", "PROXY TCP4 1.1.1.1\r\n"} {
+		_, err := readProxyHeader(bufio.NewReader(bytes.NewBufferString(header)))
+		assert.Error(t, err, header)
+	}
+}
+
+func TestReadProxyHeader_V2(t *testing.T) {
+	header := append([]byte{}, proxyV2Signature...)
+	header = append(header, 0x21, 0x11, 0, 12) // v2 PROXY, TCP over IPv4, 12 байт адресов
+	header = append(header, 192, 0, 2, 7, 10, 0, 0, 1)
+	header = binary.BigEndian.AppendUint16(header, 51000)
+	header = binary.BigEndian.AppendUint16(header, 443)
+
+	r := bufio.NewReader(bytes.NewReader(append(header, "payload"...)))
+	addr, err := readProxyHeader(r)
+	require.NoError(t, err)
+	assert.Equal(t, "192.0.2.7", addr.IP.String())
+	assert.Equal(t, 51000, addr.Port)
+
+	rest, _ := r.ReadString(0)
+	assert.Equal(t, "payload", rest)
+
+	local := append(append([]byte{}, proxyV2Signature...), 0x20, 0x00, 0, 0)
+	addr, err = readProxyHeader(bufio.NewReader(bytes.NewReader(local)))
+	require.NoError(t, err)
+	assert.Nil(t, addr)
+}
+
+// newTestCertificates создает CA и подписанные им сертификаты сервера и клиента
+func newTestCertificates(t *testing.T) (server, client tls.Certificate, pool *x509.CertPool) {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	pool = x509.NewCertPool()
+	pool.AddCert(caCert)
+
+	issue := func(serial int64, cn string, usage x509.ExtKeyUsage) tls.Certificate {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: cn},
+			DNSNames:     []string{cn},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+		require.NoError(t, err)
+		return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	}
+
+	return issue(2, "aggregator", x509.ExtKeyUsageServerAuth), issue(3, "billing-worker", x509.ExtKeyUsageClientAuth), pool
+}
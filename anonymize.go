@@ -0,0 +1,224 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// defaultAnonymizeValueBytes длина строковых значений в обезличенной выгрузке по умолчанию
+const defaultAnonymizeValueBytes = 256
+
+var (
+	// defaultPseudonymKeys поля-идентификаторы, которые заменяются псевдонимами
+	defaultPseudonymKeys = []string{
+		"user_id", "account_id", "session_id", "client_ip", "remote_addr", "ip_address", "ip", "peer_cn",
+	}
+	// defaultStripKeys поля с персональными данными, которые удаляются
+	defaultStripKeys = []string{
+		"email", "phone", "name", "full_name", "address", "card_number", "user_agent",
+	}
+
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	ipv4Pattern  = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+
+	// textPairPattern пара key=value текстового формата logrus
+	textPairPattern = regexp.MustCompile(`([\w.\-]+)=("(?:[^"\\]|\\.)*"|\S*)`)
+)
+
+// AnonymizeOptions настройки обезличивания логов для передачи подрядчикам
+// или в публичные баг-репорты
+type AnonymizeOptions struct {
+	// Salt секрет для псевдонимов: с одним Salt одинаковые идентификаторы
+	// получают одинаковые псевдонимы в разных выгрузках
+	Salt string
+
+	PseudonymKeys []string // поля-идентификаторы, по умолчанию user_id, client_ip и др.
+	StripKeys     []string // удаляемые поля в дополнение к ключам скрытия, по умолчанию email, phone и др.
+	MaxValueBytes int      // длина строковых значений, по умолчанию 256, меньше нуля - без усечения
+}
+
+// anonymizer обезличивает записи логов
+type anonymizer struct {
+	salt      []byte
+	pseudonym map[string]struct{}
+	strip     map[string]struct{}
+	maxValue  int
+}
+
+// Anonymize читает записи логов из r и пишет в w обезличенную копию:
+// идентификаторы заменяются псевдонимами, поля с персональными данными и
+// секретами удаляются, адреса почты и IP в тексте заменяются псевдонимами,
+// длинные значения усекаются. Строки JSON обрабатываются по полям, строки
+// текстового формата - по парам key=value
+func Anonymize(r io.Reader, w io.Writer, opts AnonymizeOptions) error {
+	a := newAnonymizer(opts)
+
+	br := bufio.NewReader(r)
+	bw := bufio.NewWriter(w)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if _, werr := bw.Write(a.line(bytes.TrimRight(line, "\r\n"))); werr != nil {
+				return werr
+			}
+			if werr := bw.WriteByte('\n'); werr != nil {
+				return werr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// newAnonymizer создает обезличиватель с ключами по умолчанию
+func newAnonymizer(opts AnonymizeOptions) *anonymizer {
+	pseudonymKeys := opts.PseudonymKeys
+	if len(pseudonymKeys) == 0 {
+		pseudonymKeys = defaultPseudonymKeys
+	}
+	stripKeys := opts.StripKeys
+	if len(stripKeys) == 0 {
+		stripKeys = defaultStripKeys
+	}
+
+	a := &anonymizer{
+		salt:      []byte(opts.Salt),
+		pseudonym: newRedactKeys(pseudonymKeys),
+		strip:     newRedactKeys(append(append([]string(nil), stripKeys...), defaultRedactKeys...)),
+		maxValue:  opts.MaxValueBytes,
+	}
+	if a.maxValue == 0 {
+		a.maxValue = defaultAnonymizeValueBytes
+	}
+	return a
+}
+
+// line обезличивает одну запись
+func (a *anonymizer) line(line []byte) []byte {
+	var entry map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if err := dec.Decode(&entry); err != nil {
+		return []byte(a.textLine(string(line)))
+	}
+
+	out, err := json.Marshal(a.fields(entry))
+	if err != nil {
+		return []byte(fmt.Sprintf(`{"anonymize_error":%q}`, err.Error()))
+	}
+	return out
+}
+
+// fields обезличивает поля записи JSON
+func (a *anonymizer) fields(fields map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		switch {
+		case a.matches(a.strip, key):
+			continue
+		case a.matches(a.pseudonym, key):
+			out[key] = a.pseudonymize(fmt.Sprint(value))
+		default:
+			out[key] = a.value(value)
+		}
+	}
+	return out
+}
+
+// value обезличивает значение поля без особых правил
+func (a *anonymizer) value(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return a.text(v)
+	case map[string]interface{}:
+		return a.fields(v)
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = a.value(item)
+		}
+		return out
+	}
+	return value
+}
+
+// textLine обезличивает строку текстового формата по парам key=value
+func (a *anonymizer) textLine(line string) string {
+	if !textPairPattern.MatchString(line) {
+		return a.text(line)
+	}
+	return textPairPattern.ReplaceAllStringFunc(line, func(pair string) string {
+		m := textPairPattern.FindStringSubmatch(pair)
+		key, raw := m[1], m[2]
+		if a.matches(a.strip, key) {
+			return ""
+		}
+		value := raw
+		if unquoted, err := unquoteText(raw); err == nil {
+			value = unquoted
+		}
+		if a.matches(a.pseudonym, key) {
+			return key + "=" + a.pseudonymize(value)
+		}
+		anonymized := a.text(value)
+		if anonymized == value {
+			return pair
+		}
+		return key + "=" + fmt.Sprintf("%q", anonymized)
+	})
+}
+
+// text заменяет адреса почты и IP псевдонимами и усекает длинный текст
+func (a *anonymizer) text(s string) string {
+	s = emailPattern.ReplaceAllStringFunc(s, a.pseudonymize)
+	s = ipv4Pattern.ReplaceAllStringFunc(s, a.pseudonymize)
+	if a.maxValue > 0 && len(s) > a.maxValue {
+		return fmt.Sprintf("%s...(%d bytes)", strings.ToValidUTF8(s[:a.maxValue], ""), len(s))
+	}
+	return s
+}
+
+// pseudonymize возвращает стабильный псевдоним значения для заданной соли
+func (a *anonymizer) pseudonymize(value string) string {
+	mac := hmac.New(sha256.New, a.salt)
+	mac.Write([]byte(value))
+	return "anon-" + hex.EncodeToString(mac.Sum(nil)[:6])
+}
+
+// matches проверяет ключ поля без учета регистра, в том числе последний
+// сегмент составного ключа: http.client.ip
+func (a *anonymizer) matches(keys map[string]struct{}, key string) bool {
+	key = strings.ToLower(key)
+	if _, ok := keys[key]; ok {
+		return true
+	}
+	if i := strings.LastIndexByte(key, '.'); i >= 0 {
+		_, ok := keys[key[i+1:]]
+		return ok
+	}
+	return false
+}
+
+// unquoteText снимает кавычки со значения текстового формата
+func unquoteText(raw string) (string, error) {
+	if !strings.HasPrefix(raw, `"`) {
+		return raw, nil
+	}
+	var s string
+	err := json.Unmarshal([]byte(raw), &s)
+	return s, err
+}
@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnonymize_JSON(t *testing.T) {
+	input := `{"level":"info","msg":"login from 203.0.113.7 by bob@example.com","user_id":"u-42","email":"bob@example.com","password":"s3cret","http.client.ip":"203.0.113.7","attempt":3,"body":"` + strings.Repeat("x", 80) + `","nested":{"phone":"+100","note":"ok"}}
+`
+	var out bytes.Buffer
+	require.NoError(t, Anonymize(strings.NewReader(input), &out, AnonymizeOptions{Salt: "s", MaxValueBytes: 64}))
+
+	entries := decodeEntries(t, &out)
+	require.Len(t, entries, 1)
+	entry := entries[0]
+
+	a := newAnonymizer(AnonymizeOptions{Salt: "s"})
+	ip, user := a.pseudonymize("203.0.113.7"), a.pseudonymize("u-42")
+	assert.Equal(t, "login from "+ip+" by "+a.pseudonymize("bob@example.com"), entry["msg"])
+	assert.Equal(t, user, entry["user_id"])
+	assert.Equal(t, ip, entry["http.client.ip"], "same value gets the same pseudonym")
+	assert.NotContains(t, entry, "email")
+	assert.NotContains(t, entry, "password")
+	assert.Equal(t, float64(3), entry["attempt"])
+	assert.Equal(t, strings.Repeat("x", 64)+"...(80 bytes)", entry["body"])
+	assert.Equal(t, map[string]interface{}{"note": "ok"}, entry["nested"])
+}
+
+func TestAnonymize_Text(t *testing.T) {
+	input := `time="2024-01-15T10:30:00Z" level=info msg="payment by alice@example.com" user_id=u-7 token=abc service=billing` + "\n\n"
+
+	var out bytes.Buffer
+	require.NoError(t, Anonymize(strings.NewReader(input), &out, AnonymizeOptions{Salt: "s"}))
+
+	a := newAnonymizer(AnonymizeOptions{Salt: "s"})
+	line := strings.TrimSpace(out.String())
+	assert.Contains(t, line, `time="2024-01-15T10:30:00Z" level=info`)
+	assert.Contains(t, line, `msg="payment by `+a.pseudonymize("alice@example.com")+`"`)
+	assert.Contains(t, line, "user_id="+a.pseudonymize("u-7"))
+	assert.Contains(t, line, "service=billing")
+	assert.NotContains(t, line, "token")
+	assert.NotContains(t, line, "alice")
+}
+
+func TestAnonymize_SaltChangesPseudonyms(t *testing.T) {
+	one := newAnonymizer(AnonymizeOptions{Salt: "one"})
+	two := newAnonymizer(AnonymizeOptions{Salt: "two"})
+	assert.Equal(t, one.pseudonymize("u-1"), one.pseudonymize("u-1"))
+	assert.NotEqual(t, one.pseudonymize("u-1"), two.pseudonymize("u-1"))
+}
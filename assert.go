@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// maxStackFrames сколько кадров стека пишется в запись о нарушении инварианта
+const maxStackFrames = 32
+
+// AssertTrue проверяет инвариант: если cond ложно, пишет Error с сообщением,
+// полями и стеком вызова. В режиме Development после записи вызывается panic.
+// Возвращает cond, чтобы вызывающий код мог обработать нарушение сам
+func (l *Logger) AssertTrue(cond bool, msg string, fields map[string]interface{}) bool {
+	l = l.orNop()
+	if cond {
+		return true
+	}
+
+	l.withFields().
+		WithFields(fields).
+		WithField("stack", callerStack(3)).
+		Error("assertion failed: " + msg)
+
+	if l.core.development {
+		panic("assertion failed: " + msg)
+	}
+	return false
+}
+
+// callerStack возвращает стек вызова, начиная с кадра skip, в виде
+// строк "функция file:line"
+func callerStack(skip int) []string {
+	pcs := make([]uintptr, maxStackFrames)
+	n := runtime.Callers(skip, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var stack []string
+	for {
+		frame, more := frames.Next()
+		// Кадры рантайма (runtime.main, runtime.goexit) не несут информации
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			stack = append(stack, frame.Function+" "+frame.File+":"+strconv.Itoa(frame.Line))
+		}
+		if !more {
+			break
+		}
+	}
+	return stack
+}
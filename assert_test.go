@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_AssertTrue(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	assert.True(t, logger.AssertTrue(true, "balance is positive", nil))
+	assert.Empty(t, buf.String())
+
+	ok := logger.AssertTrue(false, "balance is positive", map[string]interface{}{"balance": -5})
+	assert.False(t, ok)
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	entry := entries[0]
+	assert.Equal(t, "error", entry["level"])
+	assert.Equal(t, "assertion failed: balance is positive", entry["msg"])
+	assert.Equal(t, -5.0, entry["balance"])
+
+	stack, ok := entry["stack"].([]interface{})
+	require.True(t, ok)
+	require.NotEmpty(t, stack)
+	assert.Contains(t, stack[0], "TestLogger_AssertTrue")
+	assert.Contains(t, entry["func"], "TestLogger_AssertTrue")
+}
+
+func TestLogger_AssertTrueDevelopmentPanics(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(Config{Level: TraceLevel, Writers: []io.Writer{buf}, Development: true})
+	require.NoError(t, err)
+
+	assert.PanicsWithValue(t, "assertion failed: queue is empty", func() {
+		logger.AssertTrue(false, "queue is empty", nil)
+	})
+	assert.Len(t, decodeEntries(t, buf), 1)
+}
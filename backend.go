@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Бэкенды вывода записей. Другие бэкенды, например zap из модуля
+// github.com/ex-rate/logger/zapbackend, подключаются через RegisterBackend
+const (
+	LogrusBackend = "logrus"
+	SlogBackend   = "slog"
+)
+
+// Backend выводит записи, прошедшие конвейер логгера (проверку уровня,
+// семплирование, правила и скрытие полей), вместо форматтеров logrus и
+// Output. Поля записи нельзя менять и сохранять после возврата из Write
+type Backend interface {
+	Write(entry Entry) error
+	Sync() error
+	Close() error
+}
+
+// BackendFactory создает бэкенд по конфигурации логгера
+type BackendFactory func(config Config) (Backend, error)
+
+// backends зарегистрированные бэкенды по имени
+var backends = struct {
+	mu     sync.RWMutex
+	byName map[string]BackendFactory
+}{byName: map[string]BackendFactory{SlogBackend: newSlogBackend}}
+
+// RegisterBackend регистрирует бэкенд, который выбирается Config.Backend.
+// Обычно вызывается из init пакета бэкенда, например
+// github.com/ex-rate/logger/zapbackend. Бэкенд с тем же именем заменяется
+func RegisterBackend(name string, factory BackendFactory) {
+	backends.mu.Lock()
+	defer backends.mu.Unlock()
+	backends.byName[name] = factory
+}
+
+// lookupBackend возвращает фабрику бэкенда по имени
+func lookupBackend(name string) (BackendFactory, bool) {
+	backends.mu.RLock()
+	defer backends.mu.RUnlock()
+	factory, ok := backends.byName[name]
+	return factory, ok
+}
+
+// usesBackend проверяет, выводит ли записи бэкенд вместо форматтеров logrus
+func usesBackend(config Config) bool {
+	return config.Backend != "" && config.Backend != LogrusBackend
+}
+
+// openBackend создает бэкенд из Config.Backend и назначение для него
+func openBackend(config Config) (*sink, logFile, error) {
+	factory, ok := lookupBackend(config.Backend)
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported backend: %s", config.Backend)
+	}
+	b, err := factory(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open %s backend: %w", config.Backend, err)
+	}
+	return &sink{name: config.Backend, backend: b}, backendFile{b}, nil
+}
+
+// backendFile закрывает и сбрасывает бэкенд вместе с файлами логов
+type backendFile struct {
+	Backend
+}
+
+func (f backendFile) Write(p []byte) (int, error) {
+	return 0, errors.New("backend does not accept formatted entries")
+}
+
+func (f backendFile) Reopen() error { return nil }
+
+// slogBackend передает записи обработчику Config.SlogHandler
+type slogBackend struct {
+	handler slog.Handler
+}
+
+// newSlogBackend создает бэкенд slog
+func newSlogBackend(config Config) (Backend, error) {
+	if config.SlogHandler == nil {
+		return nil, errors.New("slog handler is required for slog backend")
+	}
+	return slogBackend{handler: config.SlogHandler}, nil
+}
+
+func (b slogBackend) Write(entry Entry) error {
+	ctx := entry.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return b.handler.Handle(ctx, slogRecord(entry))
+}
+
+func (b slogBackend) Sync() error  { return nil }
+func (b slogBackend) Close() error { return nil }
+
+// slogLevels уровни slog для уровней логгера. Trace, Fatal и Panic
+// отстоят от соседних уровней на тот же шаг 4, что и уровни slog
+var slogLevels = map[Level]slog.Level{
+	TraceLevel: slog.LevelDebug - 4,
+	DebugLevel: slog.LevelDebug,
+	InfoLevel:  slog.LevelInfo,
+	WarnLevel:  slog.LevelWarn,
+	ErrorLevel: slog.LevelError,
+	FatalLevel: slog.LevelError + 4,
+	PanicLevel: slog.LevelError + 8,
+}
+
+// entryContext возвращает контекст записи
+func entryContext(entry *logrus.Entry) context.Context {
+	if entry.Context != nil {
+		return entry.Context
+	}
+	return context.Background()
+}
+
+// slogRecord переводит запись в запись slog. Поля, в том числе file и
+// func, передаются атрибутами в порядке ключей
+func slogRecord(entry Entry) slog.Record {
+	r := slog.NewRecord(entry.Time(), slogLevels[entry.Level()], entry.Message(), 0)
+
+	keys := make([]string, 0, len(entry.fields))
+	for k := range entry.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := entry.fields[k]
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		r.AddAttrs(slog.Any(k, v))
+	}
+	return r
+}
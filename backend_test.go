@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_SlogBackend(t *testing.T) {
+	var out, extra bytes.Buffer
+	handler := slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug - 4})
+	logger, err := New(Config{
+		Level:       TraceLevel,
+		Backend:     SlogBackend,
+		SlogHandler: handler,
+		Writers:     []io.Writer{&extra},
+		Redact:      true,
+
+		ReportCaller: true,
+	})
+	require.NoError(t, err)
+
+	orders := logger.WithService("orders")
+	orders.WithField("password", "secret").WithError(assert.AnError).Error("charge failed")
+	orders.Trace("trace detail")
+
+	entries := decodeEntries(t, &out)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "ERROR", entries[0]["level"])
+	assert.Equal(t, "charge failed", entries[0]["msg"])
+	assert.Equal(t, "orders", entries[0]["service"])
+	assert.Equal(t, redactedValue, entries[0]["password"])
+	assert.Equal(t, assert.AnError.Error(), entries[0]["error"])
+	assert.Contains(t, entries[0]["file"], "backend_test.go")
+	assert.Equal(t, "DEBUG-4", entries[1]["level"])
+
+	assert.Len(t, decodeEntries(t, &extra), 2, "writers use formatters with any backend")
+}
+
+func TestConfig_ValidateBackend(t *testing.T) {
+	err := Config{Backend: SlogBackend}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "slog handler is required")
+
+	err = Config{Backend: SlogBackend, SlogHandler: slog.Default().Handler(), Output: ConsoleOutput}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "output is not used with slog backend")
+
+	err = Config{Backend: "unknown", Output: ConsoleOutput}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported backend: unknown")
+
+	assert.NoError(t, Config{Backend: SlogBackend, SlogHandler: slog.Default().Handler()}.Validate())
+}
+
+// memoryBackend бэкенд, который запоминает записи
+type memoryBackend struct {
+	entries []Entry
+	synced  int
+	closed  bool
+}
+
+func (b *memoryBackend) Write(entry Entry) error {
+	b.entries = append(b.entries, entry.WithFields(nil))
+	return nil
+}
+
+func (b *memoryBackend) Sync() error {
+	b.synced++
+	return nil
+}
+
+func (b *memoryBackend) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestRegisterBackend(t *testing.T) {
+	backend := &memoryBackend{}
+	var opened Config
+	RegisterBackend("memory", func(config Config) (Backend, error) {
+		opened = config
+		return backend, nil
+	})
+	t.Cleanup(func() {
+		backends.mu.Lock()
+		delete(backends.byName, "memory")
+		backends.mu.Unlock()
+	})
+
+	config := Config{Level: InfoLevel, Backend: "memory", Output: FileOutput, FilePath: "app.log", Redact: true}
+	require.NoError(t, config.Validate())
+	logger, err := New(config)
+	require.NoError(t, err)
+
+	logger.WithField("password", "secret").Info("started")
+	logger.Debug("hidden")
+	require.NoError(t, logger.Close())
+
+	assert.Equal(t, "app.log", opened.FilePath, "the backend writes Output itself")
+	require.Len(t, backend.entries, 1)
+	assert.Equal(t, "started", backend.entries[0].Message())
+	password, _ := backend.entries[0].Field("password")
+	assert.Equal(t, redactedValue, password)
+	assert.True(t, backend.closed)
+	assert.NotZero(t, backend.synced)
+	assert.NoFileExists(t, "app.log")
+}
+
+func TestRegisterBackend_FactoryError(t *testing.T) {
+	RegisterBackend("broken", func(Config) (Backend, error) {
+		return nil, assert.AnError
+	})
+	t.Cleanup(func() {
+		backends.mu.Lock()
+		delete(backends.byName, "broken")
+		backends.mu.Unlock()
+	})
+
+	_, err := New(Config{Backend: "broken"})
+	assert.ErrorIs(t, err, assert.AnError)
+}
@@ -0,0 +1,143 @@
+package logger
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+// defaultFileBatchInterval период записи накопленной пачки по умолчанию
+const defaultFileBatchInterval = 100 * time.Millisecond
+
+// vectorWriter пишет несколько буферов одним системным вызовом
+type vectorWriter interface {
+	writev(bufs [][]byte) error
+}
+
+// flusher назначение, которое копит записи и может записать их немедленно
+type flusher interface {
+	flush() error
+}
+
+// batchWriter копит записи файла логов и пишет их пачкой: по числу записей,
+// по таймеру или сразу после записи уровня Error и серьезнее или критичной
+// записи. Обычный файл на Linux пишется одним вызовом writev, остальные -
+// одним Write
+type batchWriter struct {
+	file       logFile
+	maxEntries int
+
+	mu      sync.Mutex
+	pending [][]byte
+	err     error // ошибка фоновой записи, возвращается следующим вызовом
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newBatchWriter оборачивает файл логов в пакетную запись
+func newBatchWriter(file logFile, maxEntries int, interval time.Duration) *batchWriter {
+	if interval <= 0 {
+		interval = defaultFileBatchInterval
+	}
+	w := &batchWriter{
+		file:       file,
+		maxEntries: maxEntries,
+		pending:    make([][]byte, 0, maxEntries),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go w.run(interval)
+	return w
+}
+
+// run пишет накопленные записи по таймеру
+func (w *batchWriter) run(interval time.Duration) {
+	defer close(w.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.mu.Lock()
+			if err := w.flushLocked(); err != nil {
+				w.err = err
+			}
+			w.mu.Unlock()
+		}
+	}
+}
+
+// Write добавляет запись в пачку
+func (w *batchWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.err; err != nil {
+		w.err = nil
+		return 0, err
+	}
+	w.pending = append(w.pending, bytes.Clone(p))
+	if len(w.pending) >= w.maxEntries {
+		if err := w.flushLocked(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// flush пишет накопленные записи
+func (w *batchWriter) flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flushLocked()
+}
+
+// flushLocked пишет накопленные записи под блокировкой mu
+func (w *batchWriter) flushLocked() error {
+	if len(w.pending) == 0 {
+		return nil
+	}
+	var err error
+	if vw, ok := w.file.(vectorWriter); ok {
+		err = vw.writev(w.pending)
+	} else {
+		_, err = w.file.Write(bytes.Join(w.pending, nil))
+	}
+	clear(w.pending)
+	w.pending = w.pending[:0]
+	return err
+}
+
+// Sync пишет накопленные записи и сбрасывает файл на диск
+func (w *batchWriter) Sync() error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+	return w.file.Sync()
+}
+
+// Reopen пишет накопленные записи в старый файл и переоткрывает его
+func (w *batchWriter) Reopen() error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+	return w.file.Reopen()
+}
+
+// Close останавливает таймер, пишет накопленные записи и закрывает файл
+func (w *batchWriter) Close() error {
+	select {
+	case <-w.stop:
+	default:
+		close(w.stop)
+	}
+	<-w.done
+	err := w.flush()
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_FileBatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "plain file", config: Config{}},
+		{name: "rotating file", config: Config{MaxSizeMB: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "batch.log")
+			config := tt.config
+			config.Level = InfoLevel
+			config.Output = FileOutput
+			config.FilePath = path
+			config.FileBatchEntries = 3
+			config.FileBatchInterval = time.Hour
+
+			logger, err := New(config)
+			require.NoError(t, err)
+
+			read := func() string {
+				data, err := os.ReadFile(path)
+				require.NoError(t, err)
+				return string(data)
+			}
+
+			logger.Info("first")
+			logger.Info("second")
+			assert.Empty(t, read(), "entries wait for a full batch")
+
+			logger.Info("third")
+			assert.Equal(t, 3, strings.Count(read(), "\n"))
+
+			logger.Info("fourth")
+			logger.Error("failure")
+			assert.Contains(t, read(), "failure", "errors flush the batch")
+
+			logger.Info("last")
+			require.NoError(t, logger.Sync())
+			assert.Contains(t, read(), "last")
+
+			logger.Info("on close")
+			require.NoError(t, logger.Close())
+			assert.Equal(t, 7, strings.Count(read(), "\n"))
+		})
+	}
+}
+
+func TestBatchWriter_Interval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "batch.log")
+	file, err := newFileWriter(path)
+	require.NoError(t, err)
+
+	w := newBatchWriter(file, 1000, 10*time.Millisecond)
+	defer w.Close()
+
+	_, err = w.Write([]byte("entry\n"))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		data, _ := os.ReadFile(path)
+		return string(data) == "entry\n"
+	}, time.Second, 5*time.Millisecond)
+}
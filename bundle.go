@@ -0,0 +1,238 @@
+package logger
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maskedValue значение секрета в конфигурации отладочного архива
+const maskedValue = "[MASKED]"
+
+// bundleManifest описание отладочного архива
+type bundleManifest struct {
+	CreatedAt time.Time         `json:"created_at"`
+	Hostname  string            `json:"hostname"`
+	PID       int               `json:"pid"`
+	Files     []string          `json:"files"`
+	Skipped   map[string]string `json:"skipped,omitempty"` // файл и причина, по которой его нет
+}
+
+// bundleState действующие уровни и таргетинг логгера
+type bundleState struct {
+	Level         Level            `json:"level"`
+	ServiceLevels map[string]Level `json:"service_levels"`
+	PoolLevels    map[string]Level `json:"pool_levels"`
+	Targeting     Targeting        `json:"targeting"`
+}
+
+// bundleRuntime состояние процесса на момент сборки архива
+type bundleRuntime struct {
+	GoVersion    string                 `json:"go_version"`
+	GOOS         string                 `json:"goos"`
+	GOARCH       string                 `json:"goarch"`
+	NumCPU       int                    `json:"num_cpu"`
+	GOMAXPROCS   int                    `json:"gomaxprocs"`
+	Goroutines   int                    `json:"goroutines"`
+	UptimeS      int64                  `json:"uptime_s"`
+	Entries      map[string]uint64      `json:"entries"`
+	HeapAlloc    uint64                 `json:"heap_alloc_bytes"`
+	HeapInuse    uint64                 `json:"heap_inuse_bytes"`
+	Sys          uint64                 `json:"sys_bytes"`
+	NumGC        uint32                 `json:"num_gc"`
+	PauseTotalNs uint64                 `json:"gc_pause_total_ns"`
+	Resources    map[string]interface{} `json:"resources,omitempty"` // сведения cgroup, если включены
+}
+
+// bundleSinks состояние назначений вывода
+type bundleSinks struct {
+	Latencies []SinkLatency   `json:"latencies"`
+	Migration *MigrationStats `json:"migration,omitempty"`
+}
+
+// DebugBundle пишет в w архив tar.gz для баг-репорта: последние записи
+// кольцевого файла (ring.ndjson), действующую конфигурацию без секретов
+// (config.yaml), уровни и таргетинг (state.json), состояние процесса
+// (runtime.json), стеки всех горутин (goroutines.txt) и задержки назначений
+// вывода (sinks.json). Файлы, которые собрать не удалось, перечислены в
+// manifest.json. Архив также отдают GET /bundle в AdminHandler и
+// logctl bundle
+func (l *Logger) DebugBundle(w io.Writer) error {
+	l = l.orNop()
+	hostname, _ := os.Hostname()
+	manifest := bundleManifest{CreatedAt: time.Now().UTC(), Hostname: hostname, PID: os.Getpid()}
+	files := make(map[string][]byte)
+	add := func(name string, build func() ([]byte, error)) {
+		data, err := build()
+		if err != nil {
+			if manifest.Skipped == nil {
+				manifest.Skipped = make(map[string]string)
+			}
+			manifest.Skipped[name] = err.Error()
+			return
+		}
+		manifest.Files = append(manifest.Files, name)
+		files[name] = data
+	}
+
+	add("ring.ndjson", func() ([]byte, error) {
+		var ring bytes.Buffer
+		err := l.Snapshot(&ring)
+		return ring.Bytes(), err
+	})
+	add("config.yaml", l.bundleConfig)
+	add("state.json", func() ([]byte, error) {
+		return bundleJSON(bundleState{
+			Level:         l.GetLevel(),
+			ServiceLevels: l.ServiceLevels(),
+			PoolLevels:    l.PoolLevels(),
+			Targeting:     l.Targeting(),
+		})
+	})
+	add("runtime.json", func() ([]byte, error) {
+		return bundleJSON(l.bundleRuntime())
+	})
+	add("goroutines.txt", func() ([]byte, error) {
+		var goroutines bytes.Buffer
+		err := pprof.Lookup("goroutine").WriteTo(&goroutines, 2)
+		return goroutines.Bytes(), err
+	})
+	add("sinks.json", func() ([]byte, error) {
+		return bundleJSON(l.bundleSinks())
+	})
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeTarFile(tw, "manifest.json", data, manifest.CreatedAt); err != nil {
+		return err
+	}
+	for _, name := range manifest.Files {
+		if err := writeTarFile(tw, name, files[name], manifest.CreatedAt); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// bundleConfig возвращает действующую конфигурацию в YAML со скрытыми секретами
+func (l *Logger) bundleConfig() ([]byte, error) {
+	config := l.core.config.Load()
+	if config == nil {
+		return nil, errors.New("logger has no config")
+	}
+	c := *config
+	if c.Elasticsearch.Password != "" {
+		c.Elasticsearch.Password = maskedValue
+	}
+	if c.Elasticsearch.APIKey != "" {
+		c.Elasticsearch.APIKey = maskedValue
+	}
+	if c.Splunk.Token != "" {
+		c.Splunk.Token = maskedValue
+	}
+	if c.Datadog.APIKey != "" {
+		c.Datadog.APIKey = maskedValue
+	}
+	// Окружение плагинов скрывается целиком, настройки - по ключам скрытия
+	secrets := newRedactKeys(nil)
+	c.Plugins = make([]PluginConfig, len(config.Plugins))
+	for i, p := range config.Plugins {
+		if p.Env != nil {
+			env := make(map[string]string, len(p.Env))
+			for k := range p.Env {
+				env[k] = maskedValue
+			}
+			p.Env = env
+		}
+		if p.Options != nil {
+			options := make(map[string]interface{}, len(p.Options))
+			for k, v := range p.Options {
+				if _, ok := secrets[strings.ToLower(k)]; ok {
+					v = maskedValue
+				}
+				options[k] = v
+			}
+			p.Options = options
+		}
+		c.Plugins[i] = p
+	}
+	return yaml.Marshal(c)
+}
+
+// bundleRuntime собирает состояние процесса
+func (l *Logger) bundleRuntime() bundleRuntime {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	entries := make(map[string]uint64, len(l.core.entries))
+	for level := range l.core.entries {
+		entries[Level(level).String()] = l.core.entries[level].Load()
+	}
+	stats := bundleRuntime{
+		GoVersion:    runtime.Version(),
+		GOOS:         runtime.GOOS,
+		GOARCH:       runtime.GOARCH,
+		NumCPU:       runtime.NumCPU(),
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+		Goroutines:   runtime.NumGoroutine(),
+		UptimeS:      int64(time.Since(l.core.started).Seconds()),
+		Entries:      entries,
+		HeapAlloc:    mem.HeapAlloc,
+		HeapInuse:    mem.HeapInuse,
+		Sys:          mem.Sys,
+		NumGC:        mem.NumGC,
+		PauseTotalNs: mem.PauseTotalNs,
+	}
+	if resources := l.core.resources.Load(); resources != nil {
+		stats.Resources = *resources
+	}
+	return stats
+}
+
+// bundleSinks собирает задержки назначений и статистику миграции
+func (l *Logger) bundleSinks() bundleSinks {
+	sinks := bundleSinks{Latencies: l.SinkLatencies()}
+	l.core.mu.RLock()
+	migrating := l.core.migration != nil
+	l.core.mu.RUnlock()
+	if migrating {
+		stats := l.MigrationStats()
+		sinks.Migration = &stats
+	}
+	return sinks
+}
+
+// bundleJSON кодирует часть архива в JSON с отступами
+func bundleJSON(v interface{}) ([]byte, error) {
+	return json.MarshalIndent(v, "", "  ")
+}
+
+// writeTarFile добавляет в архив обычный файл
+func writeTarFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readBundle распаковывает отладочный архив в файлы по именам
+func readBundle(t *testing.T, r io.Reader) map[string]string {
+	t.Helper()
+	gz, err := gzip.NewReader(r)
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+	files := make(map[string]string)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[hdr.Name] = string(data)
+	}
+}
+
+func TestLogger_DebugBundle(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := New(Config{
+		Level:         InfoLevel,
+		Writers:       []io.Writer{&buf},
+		ServiceLevels: map[string]Level{"billing": DebugLevel},
+	})
+	require.NoError(t, err)
+	defer log.Close()
+	// Секреты в конфигурации не попадают в архив
+	config := *log.core.config.Load()
+	config.Elasticsearch.Password = "hunter2"
+	log.core.config.Store(&config)
+	log.Error("payment failed")
+
+	var bundle bytes.Buffer
+	require.NoError(t, log.DebugBundle(&bundle))
+	files := readBundle(t, &bundle)
+
+	var manifest bundleManifest
+	require.NoError(t, json.Unmarshal([]byte(files["manifest.json"]), &manifest))
+	assert.Equal(t, []string{"config.yaml", "state.json", "runtime.json", "goroutines.txt", "sinks.json"}, manifest.Files)
+	assert.Contains(t, manifest.Skipped["ring.ndjson"], "ring file")
+	assert.NotContains(t, files, "ring.ndjson")
+
+	assert.Contains(t, files["config.yaml"], "level: info")
+	assert.Contains(t, files["config.yaml"], "password: '[MASKED]'")
+	assert.NotContains(t, files["config.yaml"], "hunter2")
+
+	var state bundleState
+	require.NoError(t, json.Unmarshal([]byte(files["state.json"]), &state))
+	assert.Equal(t, InfoLevel, state.Level)
+	assert.Equal(t, DebugLevel, state.ServiceLevels["billing"])
+
+	var stats bundleRuntime
+	require.NoError(t, json.Unmarshal([]byte(files["runtime.json"]), &stats))
+	assert.EqualValues(t, 1, stats.Entries["error"])
+	assert.Positive(t, stats.Goroutines)
+
+	assert.Contains(t, files["goroutines.txt"], "TestLogger_DebugBundle")
+	assert.Contains(t, files["sinks.json"], `"latencies"`)
+}
@@ -0,0 +1,207 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxCallerDepth сколько кадров стека просматривается в поисках места вызова
+const maxCallerDepth = 32
+
+// bridgePackages пакеты, через которые записи попадают в логгер: их кадры
+// пропускаются так же, как кадры самого логгера
+var bridgePackages = []string{
+	reflect.TypeOf(Logger{}).PkgPath() + ".",
+	reflect.TypeOf(Logger{}).PkgPath() + "/", // мосты logrbridge, zapbackend и др.
+	reflect.TypeOf(logrus.Entry{}).PkgPath() + ".",
+	"log.",
+	"log/slog.",
+}
+
+// callerFormat формат полей места вызова
+type callerFormat struct {
+	fullPath   bool              // полный путь файла вместо имени
+	trim       []string          // префиксы, отрезаемые от пути и функции
+	split      bool              // отдельные поля file, line и function
+	trimModule bool              // отрезать путь до vendor и кэша модулей
+	module     string            // путь главного модуля со слешем, отрезается от пути и функции
+	sources    map[string]string // суффиксы сгенерированных файлов и их исходников
+}
+
+// newCallerFormat создает формат полей места вызова по конфигурации
+func newCallerFormat(config Config) *callerFormat {
+	f := &callerFormat{
+		fullPath:   config.CallerFullPath,
+		trim:       config.CallerTrimPrefixes,
+		split:      config.CallerSplitFields,
+		trimModule: config.CallerTrimModule,
+		sources:    config.CallerSourceFiles,
+	}
+	if f.trimModule {
+		if module := mainModule(); module != "" {
+			f.module = module + "/"
+		}
+	}
+	return f
+}
+
+// mainModule возвращает путь главного модуля из сведений о сборке
+func mainModule() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.Main.Path
+	}
+	return ""
+}
+
+// addFields добавляет поля места вызова: func и file в виде file:line или
+// отдельные file, line и function
+func (f *callerFormat) addFields(data logrus.Fields, frame runtime.Frame) {
+	file, function := f.sourceFile(frame.File), frame.Function
+	if f.trimModule {
+		file = trimDependencyDir(file)
+	}
+	if !f.fullPath {
+		file = filepath.Base(file)
+	}
+	if f.module != "" {
+		file = strings.TrimPrefix(file, f.module)
+		function = strings.TrimPrefix(function, f.module)
+	}
+	file = trimFirstPrefix(file, f.trim)
+	function = trimFirstPrefix(function, f.trim)
+
+	// Свои поля пользователя с теми же ключами сохраняются под fields.*,
+	// как это делают форматтеры logrus
+	for _, key := range f.keys() {
+		if v, ok := data[key]; ok {
+			data["fields."+key] = v
+		}
+	}
+	if f.split {
+		data["file"] = file
+		data["line"] = frame.Line
+		data["function"] = function
+		return
+	}
+	data["func"] = function
+	data["file"] = fmt.Sprintf("%s:%d", file, frame.Line)
+}
+
+// keys возвращает ключи полей места вызова
+func (f *callerFormat) keys() []string {
+	if f.split {
+		return []string{"file", "line", "function"}
+	}
+	return []string{"func", "file"}
+}
+
+// sourceFile заменяет имя сгенерированного файла именем исходника по самому
+// длинному подходящему суффиксу: api.pb.go -> api.proto. Номер строки
+// остается от сгенерированного файла; директивы //line Go учитывает сам
+func (f *callerFormat) sourceFile(file string) string {
+	match := ""
+	for generated := range f.sources {
+		if len(generated) > len(match) && strings.HasSuffix(file, generated) {
+			match = generated
+		}
+	}
+	if match == "" {
+		return file
+	}
+	return strings.TrimSuffix(file, match) + f.sources[match]
+}
+
+// trimDependencyDir отрезает путь до каталога vendor или кэша модулей:
+// /src/app/vendor/github.com/lib/pq/conn.go -> github.com/lib/pq/conn.go
+func trimDependencyDir(file string) string {
+	if i := strings.LastIndex(file, "/vendor/"); i >= 0 {
+		return file[i+len("/vendor/"):]
+	}
+	if i := strings.Index(file, "/pkg/mod/"); i >= 0 {
+		return file[i+len("/pkg/mod/"):]
+	}
+	return file
+}
+
+// trimFirstPrefix отрезает первый подходящий префикс
+func trimFirstPrefix(s string, prefixes []string) string {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return strings.TrimPrefix(s, prefix)
+		}
+	}
+	return s
+}
+
+// ctxCallerKey место вызова, известное до записи (из записи slog)
+type ctxCallerKey struct{}
+
+// withCallerFrame сохраняет в контексте место вызова, которое withCaller
+// возьмет вместо поиска по стеку
+func withCallerFrame(ctx context.Context, frame runtime.Frame) context.Context {
+	return context.WithValue(ctx, ctxCallerKey{}, frame)
+}
+
+// withCaller добавляет в запись поля места вызова. Обработчик slog передает
+// место вызова из записи slog через контекст, иначе оно ищется по стеку при
+// записи, а не при создании *logrus.Entry, поэтому сохраненная запись
+// WithField указывает на вызов Info, а не на вызов WithField
+func withCaller(entry *logrus.Entry, format *callerFormat) *logrus.Entry {
+	var frame runtime.Frame
+	ok := false
+	if entry.Context != nil {
+		frame, ok = entry.Context.Value(ctxCallerKey{}).(runtime.Frame)
+	}
+	if !ok {
+		if frame, ok = callerFrame(); !ok {
+			return entry
+		}
+	}
+
+	data := make(logrus.Fields, len(entry.Data)+3)
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	format.addFields(data, frame)
+
+	withFrame := *entry
+	withFrame.Data = data
+	return &withFrame
+}
+
+// callerFrame возвращает первый кадр стека вне логгера, logrus и пакетов log
+func callerFrame() (runtime.Frame, bool) {
+	var pcs [maxCallerDepth]uintptr
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !internalFrame(frame) {
+			return frame, true
+		}
+		if !more {
+			return runtime.Frame{}, false
+		}
+	}
+}
+
+// internalFrame проверяет, относится ли кадр к логгеру или мосту в него.
+// Тесты пакета считаются внешним кодом
+func internalFrame(frame runtime.Frame) bool {
+	if frame.Function == "" {
+		return false
+	}
+	for _, pkg := range bridgePackages {
+		if strings.HasPrefix(frame.Function, pkg) {
+			return !strings.HasSuffix(frame.File, "_test.go")
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nextLine возвращает строку, следующую за вызовом, в формате поля file
+func nextLine() string {
+	_, _, line, _ := runtime.Caller(1)
+	return "caller_test.go:" + strconv.Itoa(line+1)
+}
+
+func TestLogger_ReportCaller(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	entry := logger.WithField("order_id", 42)
+	stored := nextLine()
+	entry.Info("stored entry")
+	direct := nextLine()
+	logger.Info("direct")
+	std := nextLine()
+	logger.StdLogger(WarnLevel).Print("std")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 3)
+	assert.Equal(t, stored, entries[0]["file"])
+	assert.Contains(t, entries[0]["func"], "TestLogger_ReportCaller")
+	assert.Equal(t, direct, entries[1]["file"])
+	assert.Equal(t, std, entries[2]["file"])
+}
+
+func TestLogger_ReportCallerUserFileField(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	line := nextLine()
+	logger.WithField("file", "invoice.pdf").Info("uploaded")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, line, entries[0]["file"])
+	assert.Equal(t, "invoice.pdf", entries[0]["fields.file"])
+	assert.Contains(t, entries[0]["func"], "TestLogger_ReportCallerUserFileField")
+}
+
+func TestInternalFrame_Subpackages(t *testing.T) {
+	assert.True(t, internalFrame(runtime.Frame{Function: "github.com/ex-rate/logger/logrbridge.(*sink).Info", File: "/src/logrbridge/logrbridge.go"}))
+	assert.True(t, internalFrame(runtime.Frame{Function: "github.com/ex-rate/logger.(*Logger).Info", File: "/src/logger.go"}))
+	assert.False(t, internalFrame(runtime.Frame{Function: "github.com/ex-rate/logger/logrbridge.TestSink", File: "/src/logrbridge/logrbridge_test.go"}))
+	assert.False(t, internalFrame(runtime.Frame{Function: "github.com/ex-rate/loggerx.Info", File: "/src/loggerx.go"}))
+	assert.False(t, internalFrame(runtime.Frame{Function: "main.main", File: "/src/main.go"}))
+}
+
+func TestLogger_ReportCallerDisabled(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(Config{Level: TraceLevel, Writers: []io.Writer{buf}})
+	require.NoError(t, err)
+
+	logger.Info("no caller")
+	logger.WithField("user_id", 1).Info("no caller")
+
+	for _, entry := range decodeEntries(t, buf) {
+		assert.NotContains(t, entry, "file")
+		assert.NotContains(t, entry, "func")
+	}
+
+	require.NoError(t, logger.ApplyConfig(Config{Level: TraceLevel, Writers: []io.Writer{buf}, ReportCaller: true}))
+	buf.Reset()
+	logger.Info("with caller")
+	assert.Contains(t, decodeEntries(t, buf)[0]["file"], "caller_test.go")
+}
+
+func TestLogger_CallerFormat(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(Config{
+		Level:              TraceLevel,
+		Writers:            []io.Writer{buf},
+		ReportCaller:       true,
+		CallerFullPath:     true,
+		CallerTrimPrefixes: []string{"/nonexistent/", "github.com/ex-rate/"},
+		CallerSplitFields:  true,
+	})
+	require.NoError(t, err)
+
+	line := nextLine()
+	logger.Info("split caller")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	entry := entries[0]
+	assert.True(t, filepath.IsAbs(entry["file"].(string)))
+	assert.True(t, strings.HasSuffix(entry["file"].(string), "/caller_test.go"))
+	assert.Equal(t, line, "caller_test.go:"+strconv.Itoa(int(entry["line"].(float64))))
+	assert.Equal(t, "logger.TestLogger_CallerFormat", entry["function"])
+	assert.NotContains(t, entry, "func")
+}
+
+func TestCallerFormat_TrimPath(t *testing.T) {
+	frame := runtime.Frame{File: "/src/app/internal/api/handler.go", Line: 12, Function: "example.com/app/internal/api.Serve"}
+	data := make(map[string]interface{})
+
+	format := &callerFormat{fullPath: true, trim: []string{"/src/app/", "example.com/app/"}}
+	format.addFields(data, frame)
+	assert.Equal(t, "internal/api/handler.go:12", data["file"])
+	assert.Equal(t, "internal/api.Serve", data["func"])
+}
+
+func TestCallerFormat_TrimModule(t *testing.T) {
+	format := newCallerFormat(Config{
+		CallerFullPath:    true,
+		CallerTrimModule:  true,
+		CallerSourceFiles: map[string]string{".go": ".go", ".pb.go": ".proto"},
+	})
+	assert.Equal(t, "github.com/ex-rate/logger/", format.module)
+
+	tests := []struct {
+		frame    runtime.Frame
+		file, fn string
+	}{
+		{
+			frame: runtime.Frame{File: "github.com/ex-rate/logger/internal/api/handler.go", Line: 3, Function: "github.com/ex-rate/logger/internal/api.Serve"},
+			file:  "internal/api/handler.go:3", fn: "internal/api.Serve",
+		},
+		{
+			frame: runtime.Frame{File: "/src/app/vendor/github.com/lib/pq/conn.go", Line: 7, Function: "github.com/lib/pq.(*conn).query"},
+			file:  "github.com/lib/pq/conn.go:7", fn: "github.com/lib/pq.(*conn).query",
+		},
+		{
+			frame: runtime.Frame{File: "/root/go/pkg/mod/github.com/lib/pq@v1.10.9/conn.go", Line: 9, Function: "github.com/lib/pq.(*conn).query"},
+			file:  "github.com/lib/pq@v1.10.9/conn.go:9", fn: "github.com/lib/pq.(*conn).query",
+		},
+		{
+			frame: runtime.Frame{File: "github.com/ex-rate/logger/api/orders.pb.go", Line: 120, Function: "github.com/ex-rate/logger/api.(*Order).Reset"},
+			file:  "api/orders.proto:120", fn: "api.(*Order).Reset",
+		},
+	}
+	for _, tt := range tests {
+		data := make(map[string]interface{})
+		format.addFields(data, tt.frame)
+		assert.Equal(t, tt.file, data["file"])
+		assert.Equal(t, tt.fn, data["func"])
+	}
+}
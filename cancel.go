@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// OnCancel записывает в лог отмену контекста во время операции: причину отмены
+// (context.Cause), сколько длилась операция и какой сервис ее выполнял.
+// Возвращаемую функцию нужно вызвать по завершении операции, чтобы снять
+// наблюдение; она сообщает, было ли оно снято до отмены
+func (l *Logger) OnCancel(ctx context.Context, operation string) (stop func() bool) {
+	start := time.Now()
+
+	return context.AfterFunc(ctx, func() {
+		l.cancelEntry(ctx, operation, start).Warn("operation canceled")
+	})
+}
+
+// cancelEntry собирает поля записи об отмене операции
+func (l *Logger) cancelEntry(ctx context.Context, operation string, start time.Time) *logrus.Entry {
+	fields := logrus.Fields{
+		"operation":   operation,
+		"duration_ms": time.Since(start).Milliseconds(),
+		"ctx_err":     ctx.Err().Error(),
+	}
+	if cause := context.Cause(ctx); cause != nil {
+		fields["cause"] = cause.Error()
+	}
+	return l.withFields().WithFields(fields)
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_OnCancel(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+	buf := &lockedBuffer{}
+	setTestSink(logger, buf, &logrus.JSONFormatter{})
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	logger.WithService("billing").OnCancel(ctx, "charge")
+
+	cancel(assert.AnError)
+
+	require.Eventually(t, func() bool {
+		return len(decodeEntries(t, buf)) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	entry := decodeEntries(t, buf)[0]
+	assert.Equal(t, "operation canceled", entry["msg"])
+	assert.Equal(t, "warning", entry["level"])
+	assert.Equal(t, "charge", entry["operation"])
+	assert.Equal(t, "billing", entry["service"])
+	assert.Equal(t, context.Canceled.Error(), entry["ctx_err"])
+	assert.Equal(t, assert.AnError.Error(), entry["cause"])
+	assert.Contains(t, entry, "duration_ms")
+}
+
+func TestLogger_OnCancel_Stopped(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stop := logger.OnCancel(ctx, "charge")
+
+	assert.True(t, stop())
+	cancel()
+
+	time.Sleep(10 * time.Millisecond)
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultPartialLineTimeout время ожидания продолжения строки без перевода
+// строки, после которого она пишется как есть
+const defaultPartialLineTimeout = time.Second
+
+// levelDetectBytes длина начала строки, в которой ищется уровень: обычно
+// перед уровнем идут только время и имя компонента
+const levelDetectBytes = 64
+
+// ansiPattern escape-последовательности терминала: CSI (цвета, перемещение
+// курсора) и OSC (заголовок окна, ссылки)
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+
+// levelKeywordPattern уровень в начале строки: слово в верхнем регистре
+// (ERROR, WARN) или пара level=error в формате logfmt. Слова в нижнем
+// регистре не учитываются, чтобы "no error" не становилось ошибкой
+var levelKeywordPattern = regexp.MustCompile(`(?:^|[^A-Za-z])(TRACE|DEBUG|INFO|NOTICE|WARN|WARNING|ERROR|ERR|CRIT|CRITICAL|FATAL|PANIC)(?:$|[^A-Za-z])|(?i:level=)"?([a-zA-Z]+)`)
+
+// CaptureOptions настройки перехвата вывода подпроцесса
+type CaptureOptions struct {
+	// Name пишется в поле process, по умолчанию имя исполняемого файла
+	Name string
+	// PartialLineTimeout время ожидания конца строки, по умолчанию 1 секунда
+	PartialLineTimeout time.Duration
+}
+
+// CaptureCommand направляет stdout и stderr подпроцесса в логгер: каждая
+// строка пишется отдельной записью с полями process и stream. ANSI-цвета
+// удаляются, уровень определяется по префиксу или слову уровня в начале
+// строки, а строка без перевода строки пишется после PartialLineTimeout.
+// Возвращенную функцию нужно вызвать после cmd.Wait, чтобы записать остаток
+// вывода
+func (l *Logger) CaptureCommand(cmd *exec.Cmd, opts CaptureOptions) (flush func()) {
+	name := opts.Name
+	if name == "" {
+		name = filepath.Base(cmd.Path)
+	}
+	timeout := opts.PartialLineTimeout
+	if timeout <= 0 {
+		timeout = defaultPartialLineTimeout
+	}
+
+	newWriter := func(stream string) *lineWriter {
+		return &lineWriter{
+			logger:  l.with(logrus.Fields{"process": name, "stream": stream}),
+			level:   InfoLevel,
+			parse:   true,
+			capture: true,
+			timeout: timeout,
+		}
+	}
+	stdout, stderr := newWriter("stdout"), newWriter("stderr")
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+
+	return func() {
+		stdout.Close()
+		stderr.Close()
+	}
+}
+
+// stripANSI удаляет из строки escape-последовательности терминала
+func stripANSI(s string) string {
+	if !strings.Contains(s, "\x1b") {
+		return s
+	}
+	return ansiPattern.ReplaceAllString(s, "")
+}
+
+// detectLevel ищет уровень в начале строки
+func detectLevel(line string) (Level, bool) {
+	head := line
+	if len(head) > levelDetectBytes {
+		head = head[:levelDetectBytes]
+	}
+	m := levelKeywordPattern.FindStringSubmatch(head)
+	if m == nil {
+		return 0, false
+	}
+	keyword := m[1]
+	if keyword == "" {
+		keyword = m[2]
+	}
+	level, ok := prefixLevels[strings.ToLower(keyword)]
+	return level, ok
+}
@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStripANSI(t *testing.T) {
+	assert.Equal(t, "ERROR failed", stripANSI("\x1b[31;1mERROR\x1b[0m failed"))
+	assert.Equal(t, "title", stripANSI("\x1b]0;window\x07title"))
+	assert.Equal(t, "plain", stripANSI("plain"))
+}
+
+func TestDetectLevel(t *testing.T) {
+	tests := []struct {
+		line  string
+		level Level
+		ok    bool
+	}{
+		{line: "2024-01-15T10:00:00Z ERROR db: timeout", level: ErrorLevel, ok: true},
+		{line: "10:00:00 | WARNING | disk almost full", level: WarnLevel, ok: true},
+		{line: `time=10:00 level=debug msg="cache miss"`, level: DebugLevel, ok: true},
+		{line: `ts=1 LEVEL="warn" msg=x`, level: WarnLevel, ok: true},
+		{line: "request finished with no error", ok: false},
+		{line: "INFORMATION only", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			level, ok := detectLevel(tt.line)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.level, level)
+			}
+		})
+	}
+}
+
+func TestLogger_CaptureCommand(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh is not available")
+	}
+
+	logger, buf := newBufferLogger(t)
+	cmd := exec.Command(sh, "-c", `printf '\033[33mWARN\033[0m cache cold\n'; printf 'E1 ERROR failed\n' >&2; printf 'no newline'`)
+	flush := logger.CaptureCommand(cmd, CaptureOptions{Name: "tool"})
+	require.NoError(t, cmd.Run())
+	flush()
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 3)
+	byMsg := make(map[string]map[string]interface{}, len(entries))
+	for _, entry := range entries {
+		assert.Equal(t, "tool", entry["process"])
+		byMsg[entry["msg"].(string)] = entry
+	}
+
+	require.Contains(t, byMsg, "WARN cache cold")
+	assert.Equal(t, "warning", byMsg["WARN cache cold"]["level"])
+	assert.Equal(t, "stdout", byMsg["WARN cache cold"]["stream"])
+	require.Contains(t, byMsg, "E1 ERROR failed")
+	assert.Equal(t, "error", byMsg["E1 ERROR failed"]["level"])
+	assert.Equal(t, "stderr", byMsg["E1 ERROR failed"]["stream"])
+	require.Contains(t, byMsg, "no newline")
+	assert.Equal(t, "info", byMsg["no newline"]["level"])
+}
+
+func TestLineWriter_PartialLineTimeout(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+	buf := &lockedBuffer{}
+	setTestSink(logger, buf, &logrus.JSONFormatter{})
+	w := &lineWriter{logger: logger, level: InfoLevel, parse: true, capture: true, timeout: 20 * time.Millisecond}
+	defer w.Close()
+
+	_, err := w.Write([]byte("Downloading... "))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("50%"))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return len(decodeEntries(t, buf)) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "Downloading... 50%", decodeEntries(t, buf)[0]["msg"])
+}
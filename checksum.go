@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"regexp"
+)
+
+// ChecksumField поле контрольной суммы записи
+const ChecksumField = "checksum"
+
+var (
+	// ErrNoChecksum запись без контрольной суммы
+	ErrNoChecksum = errors.New("entry has no checksum")
+	// ErrChecksumMismatch контрольная сумма не совпадает с содержимым записи
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+)
+
+// checksumTable таблица CRC-32C (Castagnoli)
+var checksumTable = crc32.MakeTable(crc32.Castagnoli)
+
+// textChecksum контрольная сумма в конце текстовой записи
+var textChecksum = regexp.MustCompile(` ` + ChecksumField + `=([0-9a-f]{8})$`)
+
+// entryChecksum возвращает CRC-32C канонического представления записи
+func entryChecksum(canonical []byte) string {
+	return fmt.Sprintf("%08x", crc32.Checksum(canonical, checksumTable))
+}
+
+// canonicalJSON возвращает каноническое представление JSON-записи: компактный
+// JSON с ключами по алфавиту без поля checksum, числа сохраняются как записаны.
+// Поэтому сумма не зависит от порядка ключей и пробелов, которые может
+// поменять доставщик логов
+func canonicalJSON(line []byte) (map[string]interface{}, []byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	var entry map[string]interface{}
+	if err := dec.Decode(&entry); err != nil {
+		return nil, nil, err
+	}
+	sum := entry[ChecksumField]
+	delete(entry, ChecksumField)
+	canonical, err := json.Marshal(entry)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sum != nil {
+		entry[ChecksumField] = sum
+	}
+	return entry, canonical, nil
+}
+
+// stampChecksum добавляет контрольную сумму в отформатированную запись:
+// в JSON - полем checksum, в текст - парой checksum=... в конце строки
+func stampChecksum(data []byte) ([]byte, error) {
+	line := bytes.TrimSuffix(data, []byte("\n"))
+	newline := len(line) < len(data)
+
+	var out []byte
+	if bytes.HasPrefix(line, []byte("{")) {
+		_, canonical, err := canonicalJSON(line)
+		if err != nil {
+			return nil, fmt.Errorf("checksum: %w", err)
+		}
+		end := bytes.LastIndexByte(line, '}')
+		out = make([]byte, 0, len(data)+24)
+		out = append(out, line[:end]...)
+		if len(bytes.TrimSpace(line[1:end])) > 0 {
+			out = append(out, ',')
+		}
+		out = append(out, `"`+ChecksumField+`":"`+entryChecksum(canonical)+`"`...)
+		out = append(out, line[end:]...)
+	} else {
+		out = make([]byte, 0, len(data)+20)
+		out = append(out, line...)
+		out = append(out, " "+ChecksumField+"="+entryChecksum(line)...)
+	}
+	if newline {
+		out = append(out, '\n')
+	}
+	return out, nil
+}
+
+// VerifyChecksum проверяет контрольную сумму строки лога, записанной с
+// Config.Checksum. Возвращает ErrNoChecksum для строки без суммы и
+// ErrChecksumMismatch, если строку изменили после записи
+func VerifyChecksum(line []byte) error {
+	line = bytes.TrimRight(line, "\r\n")
+	if bytes.HasPrefix(bytes.TrimSpace(line), []byte("{")) {
+		entry, canonical, err := canonicalJSON(line)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrChecksumMismatch, err)
+		}
+		sum, ok := entry[ChecksumField].(string)
+		if !ok {
+			return ErrNoChecksum
+		}
+		if sum != entryChecksum(canonical) {
+			return ErrChecksumMismatch
+		}
+		return nil
+	}
+
+	m := textChecksum.FindSubmatchIndex(line)
+	if m == nil {
+		return ErrNoChecksum
+	}
+	if string(line[m[2]:m[3]]) != entryChecksum(line[:m[0]]) {
+		return ErrChecksumMismatch
+	}
+	return nil
+}
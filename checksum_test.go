@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_ChecksumJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(Config{
+		Level:        InfoLevel,
+		Destinations: []Destination{{Writer: buf, Format: JSONFormat}},
+		Checksum:     true,
+	})
+	require.NoError(t, err)
+
+	logger.WithService("orders").WithFields(map[string]interface{}{
+		"amount": 10.25,
+		"id":     int64(9007199254740993),
+		"tags":   []string{"<b>", "x"},
+	}).Info("order created")
+
+	line := strings.TrimSpace(buf.String())
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Regexp(t, `^[0-9a-f]{8}$`, entries[0][ChecksumField])
+	assert.NoError(t, VerifyChecksum([]byte(line)))
+
+	// Порядок ключей и пробелы не влияют на сумму
+	_, canonical, err := canonicalJSON([]byte(line))
+	require.NoError(t, err)
+	reordered := strings.Replace(string(canonical), "{", `{"checksum":"`+entries[0][ChecksumField].(string)+`", `, 1)
+	assert.NoError(t, VerifyChecksum([]byte(reordered)))
+
+	// Порча значения обнаруживается
+	corrupted := strings.Replace(line, "order created", "order cr3ated", 1)
+	assert.ErrorIs(t, VerifyChecksum([]byte(corrupted)), ErrChecksumMismatch)
+	assert.ErrorIs(t, VerifyChecksum([]byte(line[:len(line)/2])), ErrChecksumMismatch)
+}
+
+func TestLogger_ChecksumText(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(Config{
+		Level:        InfoLevel,
+		Destinations: []Destination{{Writer: buf, Format: TextFormat}},
+		Checksum:     true,
+	})
+	require.NoError(t, err)
+
+	logger.WithField("user", "alice").Warn("login failed")
+
+	line := buf.String()
+	assert.Regexp(t, ` checksum=[0-9a-f]{8}\n$`, line)
+	assert.NoError(t, VerifyChecksum([]byte(line)))
+	assert.ErrorIs(t, VerifyChecksum([]byte(strings.Replace(line, "alice", "mallory", 1))), ErrChecksumMismatch)
+}
+
+func TestVerifyChecksum_Missing(t *testing.T) {
+	assert.ErrorIs(t, VerifyChecksum([]byte(`{"level":"info","msg":"plain"}`)), ErrNoChecksum)
+	assert.ErrorIs(t, VerifyChecksum([]byte(`level=info msg=plain`)), ErrNoChecksum)
+}
+
+func TestLogger_ChecksumDisabled(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	logger.Info("plain")
+
+	assert.NotContains(t, decodeEntries(t, buf)[0], ChecksumField)
+	assert.ErrorIs(t, VerifyChecksum(buf.Bytes()), ErrNoChecksum)
+}
+
+func BenchmarkLogger_Checksum(b *testing.B) {
+	logger, err := New(Config{
+		Level:        InfoLevel,
+		Destinations: []Destination{{Writer: io.Discard, Format: JSONFormat}},
+		Checksum:     true,
+	})
+	require.NoError(b, err)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.WithField("i", i).Info("benchmark")
+	}
+}
@@ -0,0 +1,687 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultCloudWatchFlushInterval = time.Second
+	defaultCloudWatchQueueSize     = 10000
+	defaultCloudWatchMaxRetries    = 5
+	defaultCloudWatchRetryBackoff  = 200 * time.Millisecond
+	defaultCloudWatchTimeout       = 10 * time.Second
+
+	// Ограничения PutLogEvents: событий и байт в запросе (сообщение плюс 26
+	// байт на событие), размер одного события и охват пачки по времени
+	cloudWatchMaxBatchEvents = 10000
+	cloudWatchMaxBatchBytes  = 1048576
+	cloudWatchEventOverhead  = 26
+	cloudWatchMaxEventBytes  = 262144
+	cloudWatchMaxBatchSpan   = 24 * time.Hour
+
+	// maxCloudWatchRetryBackoff предел паузы между повторами
+	maxCloudWatchRetryBackoff = 30 * time.Second
+
+	// cloudWatchTarget префикс X-Amz-Target JSON API CloudWatch Logs
+	cloudWatchTarget = "Logs_20140328."
+	// ecsCredentialsHost адрес точки учетных данных контейнера ECS
+	ecsCredentialsHost = "http://169.254.170.2"
+)
+
+// CloudWatchConfig настройки вывода в AWS CloudWatch Logs. Учетные данные
+// берутся из окружения: AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY и
+// AWS_SESSION_TOKEN (Lambda) или точка учетных данных контейнера
+// AWS_CONTAINER_CREDENTIALS_RELATIVE_URI либо AWS_CONTAINER_CREDENTIALS_FULL_URI
+// (ECS и Fargate)
+type CloudWatchConfig struct {
+	Region   string `yaml:"region"`    // по умолчанию AWS_REGION или AWS_DEFAULT_REGION
+	LogGroup string `yaml:"log_group"` // группа; создается, если ее нет
+	// LogStream поток в группе; создается, если его нет. По умолчанию имя хоста
+	LogStream string `yaml:"log_stream"`
+	Endpoint  string `yaml:"endpoint"` // адрес API вместо https://logs.REGION.amazonaws.com, например VPC endpoint
+
+	// BatchSize записей в одном PutLogEvents, по умолчанию и не больше 10000.
+	// Пачка также ограничена 1 МБ и сутками между записями
+	BatchSize     int           `yaml:"batch_size"`
+	FlushInterval time.Duration `yaml:"flush_interval"` // по умолчанию 1s
+	// QueueSize предел записей в памяти, ожидающих отправки. Записи сверх
+	// него отбрасываются. По умолчанию 10000
+	QueueSize    int           `yaml:"queue_size"`
+	MaxRetries   int           `yaml:"max_retries"`   // повторов при троттлинге, 5xx и сетевых ошибках, по умолчанию 5
+	RetryBackoff time.Duration `yaml:"retry_backoff"` // первая пауза перед повтором, дальше удваивается; по умолчанию 200ms
+	Timeout      time.Duration `yaml:"timeout"`       // таймаут запроса, по умолчанию 10s
+}
+
+// region возвращает регион из настроек или окружения
+func (c CloudWatchConfig) region() string {
+	return firstNonEmpty(c.Region, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"))
+}
+
+// validate проверяет настройки CloudWatch Logs
+func (c CloudWatchConfig) validate() error {
+	var errs []error
+	if c.region() == "" {
+		errs = append(errs, errors.New("cloudwatch region is required"))
+	}
+	if c.LogGroup == "" {
+		errs = append(errs, errors.New("cloudwatch log group is required"))
+	}
+	if c.Endpoint != "" {
+		if u, err := url.Parse(c.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("invalid cloudwatch endpoint: %s", c.Endpoint))
+		}
+	}
+	if c.BatchSize > cloudWatchMaxBatchEvents {
+		errs = append(errs, fmt.Errorf("cloudwatch batch size must not exceed %d", cloudWatchMaxBatchEvents))
+	}
+	if c.BatchSize < 0 || c.QueueSize < 0 || c.MaxRetries < 0 || c.FlushInterval < 0 || c.RetryBackoff < 0 || c.Timeout < 0 {
+		errs = append(errs, errors.New("cloudwatch limits must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// openCloudWatchSink создает назначение с фоновой отправкой в PutLogEvents
+func openCloudWatchSink(config Config) (*cloudWatchConn, *sink, error) {
+	c := config.CloudWatch
+	if err := c.validate(); err != nil {
+		return nil, nil, err
+	}
+	conn := newCloudWatchConn(c)
+	formatter := &cloudWatchEventFormatter{inner: cloudWatchFormatter()}
+	return conn, &sink{name: "cloudwatch", writer: conn, formatter: formatter, binary: true}, nil
+}
+
+// cloudWatchEventFormatter предваряет запись в JSON, как в режиме Serverless,
+// временем в миллисекундах: CloudWatch упорядочивает события по нему, а не
+// по времени приема
+type cloudWatchEventFormatter struct {
+	inner logrus.Formatter
+}
+
+// Format возвращает строку "миллисекунды документ"
+func (f *cloudWatchEventFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	doc, err := f.inner.Format(entry)
+	if err != nil {
+		return nil, err
+	}
+	out := strconv.AppendInt(make([]byte, 0, len(doc)+21), entry.Time.UnixMilli(), 10)
+	out = append(out, ' ')
+	return append(out, bytes.TrimRight(doc, "\n")...), nil
+}
+
+// cloudWatchEvent событие PutLogEvents
+type cloudWatchEvent struct {
+	Timestamp int64  `json:"timestamp"`
+	Message   string `json:"message"`
+}
+
+// size возвращает размер события по правилам лимита пачки
+func (e cloudWatchEvent) size() int {
+	return len(e.Message) + cloudWatchEventOverhead
+}
+
+// parseCloudWatchEvent разбирает вывод cloudWatchEventFormatter. Сообщения
+// больше допустимого события обрезаются по границе символа
+func parseCloudWatchEvent(p []byte) (cloudWatchEvent, error) {
+	ts, doc, ok := bytes.Cut(p, []byte{' '})
+	if !ok {
+		return cloudWatchEvent{}, errors.New("cloudwatch: malformed entry")
+	}
+	ms, err := strconv.ParseInt(string(ts), 10, 64)
+	if err != nil {
+		return cloudWatchEvent{}, fmt.Errorf("cloudwatch: malformed entry timestamp: %w", err)
+	}
+	if limit := cloudWatchMaxEventBytes - cloudWatchEventOverhead; len(doc) > limit {
+		doc = doc[:limit]
+		for len(doc) > 0 {
+			if r, size := utf8.DecodeLastRune(doc); r != utf8.RuneError || size > 1 {
+				break
+			}
+			doc = doc[:len(doc)-1]
+		}
+	}
+	return cloudWatchEvent{Timestamp: ms, Message: string(doc)}, nil
+}
+
+// cloudWatchConn копит события в ограниченной очереди и отправляет их
+// пачками в PutLogEvents из фоновой горутины. Группа и поток создаются
+// перед первой отправкой. Ошибки отправки возвращаются следующим вызовом
+// Write, как у вывода в Elasticsearch
+type cloudWatchConn struct {
+	config   CloudWatchConfig
+	region   string
+	endpoint string
+	client   *http.Client
+	creds    *awsCredentials
+
+	queue chan cloudWatchEvent
+	flush chan chan struct{}
+	done  chan struct{}
+
+	// Используются только горутиной отправки
+	ready bool
+	token string
+
+	mu      sync.Mutex
+	err     error
+	dropped int
+	closed  bool
+}
+
+// newCloudWatchConn заполняет значения по умолчанию и запускает отправку
+func newCloudWatchConn(c CloudWatchConfig) *cloudWatchConn {
+	if c.LogStream == "" {
+		c.LogStream, _ = os.Hostname()
+	}
+	if c.BatchSize == 0 {
+		c.BatchSize = cloudWatchMaxBatchEvents
+	}
+	if c.FlushInterval == 0 {
+		c.FlushInterval = defaultCloudWatchFlushInterval
+	}
+	if c.QueueSize == 0 {
+		c.QueueSize = defaultCloudWatchQueueSize
+	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = defaultCloudWatchMaxRetries
+	}
+	if c.RetryBackoff == 0 {
+		c.RetryBackoff = defaultCloudWatchRetryBackoff
+	}
+	if c.Timeout == 0 {
+		c.Timeout = defaultCloudWatchTimeout
+	}
+	region := c.region()
+	client := &http.Client{Timeout: c.Timeout}
+	conn := &cloudWatchConn{
+		config:   c,
+		region:   region,
+		endpoint: firstNonEmpty(c.Endpoint, "https://logs."+region+".amazonaws.com"),
+		client:   client,
+		creds:    &awsCredentials{client: client},
+		queue:    make(chan cloudWatchEvent, c.QueueSize),
+		flush:    make(chan chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go conn.run()
+	return conn
+}
+
+// Write ставит запись в очередь. При заполненной очереди запись
+// отбрасывается, число отброшенных записей сообщается ошибкой
+func (c *cloudWatchConn) Write(p []byte) (int, error) {
+	event, err := parseCloudWatchEvent(p)
+	if err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return 0, errors.New("cloudwatch output is closed")
+	}
+	select {
+	case c.queue <- event:
+	default:
+		c.dropped++
+	}
+	return len(p), c.takeErrLocked()
+}
+
+// takeErrLocked возвращает накопленную ошибку отправки и сбрасывает ее
+func (c *cloudWatchConn) takeErrLocked() error {
+	err := c.err
+	if c.dropped > 0 {
+		err = errors.Join(err, fmt.Errorf("cloudwatch queue is full: %d entries dropped", c.dropped))
+		c.dropped = 0
+	}
+	c.err = nil
+	return err
+}
+
+// setErr запоминает ошибку фоновой отправки
+func (c *cloudWatchConn) setErr(err error) {
+	c.mu.Lock()
+	c.err = errors.Join(c.err, err)
+	c.mu.Unlock()
+}
+
+// run собирает пачки из очереди и отправляет их по числу записей, по
+// размеру, по таймеру и по запросу Sync
+func (c *cloudWatchConn) run() {
+	defer close(c.done)
+	ticker := time.NewTicker(c.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]cloudWatchEvent, 0, c.config.BatchSize)
+	size := 0
+	send := func() {
+		if len(batch) > 0 {
+			c.send(batch)
+			batch = make([]cloudWatchEvent, 0, c.config.BatchSize)
+			size = 0
+		}
+	}
+	add := func(event cloudWatchEvent) {
+		if size+event.size() > cloudWatchMaxBatchBytes {
+			send()
+		}
+		batch = append(batch, event)
+		size += event.size()
+		if len(batch) >= c.config.BatchSize {
+			send()
+		}
+	}
+	for {
+		select {
+		case event, ok := <-c.queue:
+			if !ok {
+				send()
+				return
+			}
+			add(event)
+		case <-ticker.C:
+			send()
+		case ack := <-c.flush:
+			for drained := false; !drained; {
+				select {
+				case event := <-c.queue:
+					add(event)
+				default:
+					drained = true
+				}
+			}
+			send()
+			close(ack)
+		}
+	}
+}
+
+// send упорядочивает пачку по времени и отправляет ее частями, каждая из
+// которых укладывается в сутки
+func (c *cloudWatchConn) send(events []cloudWatchEvent) {
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Timestamp < events[j].Timestamp })
+	for len(events) > 0 {
+		n := 1
+		for n < len(events) && events[n].Timestamp-events[0].Timestamp < cloudWatchMaxBatchSpan.Milliseconds() {
+			n++
+		}
+		c.sendBatch(events[:n])
+		events = events[n:]
+	}
+}
+
+// sendBatch отправляет пачку, повторяя ее с экспоненциальной паузой при
+// троттлинге, 5xx и сетевых ошибках
+func (c *cloudWatchConn) sendBatch(events []cloudWatchEvent) {
+	backoff := c.config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := c.prepare()
+		if err == nil {
+			err = c.putLogEvents(events)
+		}
+		var apiErr *cloudWatchError
+		if err == nil || (errors.As(err, &apiErr) && !apiErr.retryable()) {
+			if err != nil {
+				c.setErr(err)
+			}
+			return
+		}
+		if attempt == c.config.MaxRetries {
+			c.setErr(fmt.Errorf("cloudwatch: %d entries dropped after %d retries: %w", len(events), attempt, err))
+			return
+		}
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxCloudWatchRetryBackoff)
+	}
+}
+
+// prepare создает группу и поток, если это еще не сделано. Отказ в
+// создании группы не мешает записи в уже существующую группу
+func (c *cloudWatchConn) prepare() error {
+	if c.ready {
+		return nil
+	}
+	err := c.call("CreateLogGroup", map[string]string{"logGroupName": c.config.LogGroup}, nil)
+	var apiErr *cloudWatchError
+	if err != nil && !errors.As(err, &apiErr) {
+		return err
+	}
+	err = c.call("CreateLogStream", map[string]string{
+		"logGroupName":  c.config.LogGroup,
+		"logStreamName": c.config.LogStream,
+	}, nil)
+	if err != nil && !(errors.As(err, &apiErr) && apiErr.Type == "ResourceAlreadyExistsException") {
+		return err
+	}
+	c.ready = true
+	c.token = ""
+	return nil
+}
+
+// cloudWatchPutRequest тело PutLogEvents
+type cloudWatchPutRequest struct {
+	LogGroupName  string            `json:"logGroupName"`
+	LogStreamName string            `json:"logStreamName"`
+	LogEvents     []cloudWatchEvent `json:"logEvents"`
+	SequenceToken string            `json:"sequenceToken,omitempty"`
+}
+
+// cloudWatchPutResponse ответ PutLogEvents. Индексы отклоненных событий:
+// TooNew включительно, TooOld и Expired - исключительно
+type cloudWatchPutResponse struct {
+	NextSequenceToken string `json:"nextSequenceToken"`
+	Rejected          *struct {
+		TooNewStart *int `json:"tooNewLogEventStartIndex"`
+		TooOldEnd   *int `json:"tooOldLogEventEndIndex"`
+		ExpiredEnd  *int `json:"expiredLogEventEndIndex"`
+	} `json:"rejectedLogEventsInfo"`
+}
+
+// putLogEvents отправляет пачку с текущим токеном последовательности. При
+// устаревшем токене запрос повторяется с ожидаемым, пропавший поток
+// создается заново
+func (c *cloudWatchConn) putLogEvents(events []cloudWatchEvent) error {
+	for attempt := 0; ; attempt++ {
+		var resp cloudWatchPutResponse
+		err := c.call("PutLogEvents", cloudWatchPutRequest{
+			LogGroupName:  c.config.LogGroup,
+			LogStreamName: c.config.LogStream,
+			LogEvents:     events,
+			SequenceToken: c.token,
+		}, &resp)
+
+		var apiErr *cloudWatchError
+		if errors.As(err, &apiErr) && attempt < 2 {
+			switch apiErr.Type {
+			case "InvalidSequenceTokenException":
+				c.token = apiErr.ExpectedSequenceToken
+				continue
+			case "DataAlreadyAcceptedException":
+				c.token = apiErr.ExpectedSequenceToken
+				return nil
+			case "ResourceNotFoundException":
+				c.ready = false
+				if err := c.prepare(); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		c.token = resp.NextSequenceToken
+		if r := resp.Rejected; r != nil {
+			rejected := 0
+			if r.TooOldEnd != nil {
+				rejected = max(rejected, *r.TooOldEnd)
+			}
+			if r.ExpiredEnd != nil {
+				rejected = max(rejected, *r.ExpiredEnd)
+			}
+			if r.TooNewStart != nil {
+				rejected += len(events) - *r.TooNewStart
+			}
+			if rejected > 0 {
+				c.setErr(fmt.Errorf("cloudwatch: %d entries rejected as too old or too new", rejected))
+			}
+		}
+		return nil
+	}
+}
+
+// cloudWatchError ошибка API CloudWatch Logs
+type cloudWatchError struct {
+	Status                int
+	Type                  string
+	Message               string
+	ExpectedSequenceToken string
+}
+
+func (e *cloudWatchError) Error() string {
+	return fmt.Sprintf("cloudwatch: %s (status %d): %s", e.Type, e.Status, e.Message)
+}
+
+// retryable сообщает, имеет ли смысл повторить запрос позже
+func (e *cloudWatchError) retryable() bool {
+	switch e.Type {
+	case "ThrottlingException", "ServiceUnavailableException", "RequestExpired":
+		return true
+	}
+	return e.Status == http.StatusTooManyRequests || e.Status >= 500
+}
+
+// call выполняет подписанный запрос JSON API CloudWatch Logs
+func (c *cloudWatchConn) call(action string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	creds, err := c.creds.get()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", cloudWatchTarget+action)
+	signAWSRequest(req, body, creds, c.region, "logs", time.Now())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Type                  string `json:"__type"`
+			Message               string `json:"message"`
+			MessageUpper          string `json:"Message"`
+			ExpectedSequenceToken string `json:"expectedSequenceToken"`
+		}
+		_ = json.Unmarshal(data, &e)
+		apiErr := &cloudWatchError{
+			Status:                resp.StatusCode,
+			Type:                  e.Type[strings.LastIndexByte(e.Type, '#')+1:],
+			Message:               firstNonEmpty(e.Message, e.MessageUpper, string(bytes.TrimSpace(data))),
+			ExpectedSequenceToken: e.ExpectedSequenceToken,
+		}
+		if apiErr.Type == "" {
+			apiErr.Type = http.StatusText(resp.StatusCode)
+		}
+		return apiErr
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// Sync отправляет накопленные записи и возвращает ошибки отправки
+func (c *cloudWatchConn) Sync() error {
+	ack := make(chan struct{})
+	select {
+	case c.flush <- ack:
+		<-ack
+	case <-c.done:
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.takeErrLocked()
+}
+
+// Reopen ничего не делает: каждый запрос открывает соединение заново
+// при необходимости
+func (c *cloudWatchConn) Reopen() error {
+	return nil
+}
+
+// Close отправляет записи из очереди и останавливает отправку
+func (c *cloudWatchConn) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	close(c.queue)
+	c.mu.Unlock()
+
+	<-c.done
+	c.client.CloseIdleConnections()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.takeErrLocked()
+}
+
+// awsCredential ключи доступа AWS
+type awsCredential struct {
+	AccessKeyID     string    `json:"AccessKeyId"`
+	SecretAccessKey string    `json:"SecretAccessKey"`
+	SessionToken    string    `json:"Token"`
+	Expiration      time.Time `json:"Expiration"`
+}
+
+// awsCredentials получает ключи из окружения или точки учетных данных
+// контейнера ECS и обновляет временные ключи незадолго до истечения
+type awsCredentials struct {
+	client *http.Client
+
+	mu     sync.Mutex
+	cached awsCredential
+}
+
+// get возвращает действующие ключи
+func (a *awsCredentials) get() (awsCredential, error) {
+	if id, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" {
+		return awsCredential{AccessKeyID: id, SecretAccessKey: secret, SessionToken: os.Getenv("AWS_SESSION_TOKEN")}, nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cached.AccessKeyID != "" && time.Until(a.cached.Expiration) > 5*time.Minute {
+		return a.cached, nil
+	}
+	creds, err := a.container()
+	if err != nil {
+		return awsCredential{}, err
+	}
+	a.cached = creds
+	return creds, nil
+}
+
+// container запрашивает временные ключи у точки учетных данных ECS
+func (a *awsCredentials) container() (awsCredential, error) {
+	endpoint := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if relative := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); relative != "" {
+		endpoint = ecsCredentialsHost + relative
+	}
+	if endpoint == "" {
+		return awsCredential{}, errors.New("cloudwatch: no AWS credentials: set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY or run in ECS")
+	}
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return awsCredential{}, err
+	}
+	if token := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN"); token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return awsCredential{}, fmt.Errorf("cloudwatch: container credentials: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return awsCredential{}, fmt.Errorf("cloudwatch: container credentials: unexpected status %s", resp.Status)
+	}
+	var creds awsCredential
+	if err := json.NewDecoder(resp.Body).Decode(&creds); err != nil {
+		return awsCredential{}, fmt.Errorf("cloudwatch: container credentials: %w", err)
+	}
+	return creds, nil
+}
+
+// signAWSRequest подписывает запрос по AWS Signature Version 4
+func signAWSRequest(req *http.Request, body []byte, creds awsCredential, region, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	bodyHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+creds.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// hmacSHA256 возвращает HMAC-SHA256 данных
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
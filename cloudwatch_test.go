@@ -0,0 +1,326 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logsServer тестовый CloudWatch Logs: хранит группы, потоки и события и
+// проверяет токены последовательности. fail позволяет вернуть ошибку на
+// очередной вызов действия
+type logsServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	calls   []string
+	streams map[string][]cloudWatchEvent
+	batches [][]cloudWatchEvent
+	token   int
+	auth    string
+	fail    func(action string, call int) (status int, errType string)
+}
+
+func newLogsServer(t *testing.T) *logsServer {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	s := &logsServer{streams: make(map[string][]cloudWatchEvent)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *logsServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	action := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), cloudWatchTarget)
+	s.calls = append(s.calls, action)
+	s.auth = r.Header.Get("Authorization")
+	respondErr := func(status int, errType string, extra string) {
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"__type":"com.amazonaws.logs#%s","message":"%s"%s}`, errType, errType, extra)
+	}
+	if s.fail != nil {
+		if status, errType := s.fail(action, len(s.calls)); status != 0 {
+			respondErr(status, errType, "")
+			return
+		}
+	}
+
+	var req cloudWatchPutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	key := req.LogGroupName + "/" + req.LogStreamName
+	switch action {
+	case "CreateLogGroup":
+		fmt.Fprint(w, `{}`)
+	case "CreateLogStream":
+		if _, ok := s.streams[key]; ok {
+			respondErr(http.StatusBadRequest, "ResourceAlreadyExistsException", "")
+			return
+		}
+		s.streams[key] = nil
+		fmt.Fprint(w, `{}`)
+	case "PutLogEvents":
+		if expected := fmt.Sprint(s.token); s.token > 0 && req.SequenceToken != expected {
+			respondErr(http.StatusBadRequest, "InvalidSequenceTokenException", `,"expectedSequenceToken":"`+expected+`"`)
+			return
+		}
+		s.streams[key] = append(s.streams[key], req.LogEvents...)
+		s.batches = append(s.batches, req.LogEvents)
+		s.token++
+		fmt.Fprintf(w, `{"nextSequenceToken":"%d"}`, s.token)
+	default:
+		respondErr(http.StatusBadRequest, "UnknownOperationException", "")
+	}
+}
+
+func (s *logsServer) events(key string) []cloudWatchEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.streams[key]
+}
+
+func TestSignAWSRequest(t *testing.T) {
+	// Пример из документации AWS Signature Version 4
+	req, err := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	creds := awsCredential{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+
+	signAWSRequest(req, nil, creds, "us-east-1", "iam", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+	assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, "+
+		"SignedHeaders=content-type;host;x-amz-date, "+
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7", req.Header.Get("Authorization"))
+}
+
+func TestCloudWatchOutput(t *testing.T) {
+	server := newLogsServer(t)
+
+	log, err := New(Config{
+		Level:  InfoLevel,
+		Output: CloudWatchOutput,
+		CloudWatch: CloudWatchConfig{
+			Region:    "eu-central-1",
+			LogGroup:  "/ecs/orders",
+			LogStream: "task-1",
+			Endpoint:  server.URL,
+		},
+	})
+	require.NoError(t, err)
+
+	log.WithField("order_id", 42).Info("order created")
+	log.Warn("payment delayed")
+	require.NoError(t, log.Sync())
+	log.Info("order shipped")
+	require.NoError(t, log.Close())
+
+	events := server.events("/ecs/orders/task-1")
+	require.Len(t, events, 3)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(events[0].Message), &doc))
+	assert.Equal(t, "order created", doc["message"])
+	assert.EqualValues(t, 42, doc["order_id"])
+	assert.InDelta(t, time.Now().UnixMilli(), events[0].Timestamp, float64(time.Minute.Milliseconds()))
+	assert.Equal(t, []string{"CreateLogGroup", "CreateLogStream", "PutLogEvents", "PutLogEvents"}, server.calls)
+	assert.Contains(t, server.auth, "Credential=AKIDEXAMPLE/")
+	assert.Contains(t, server.auth, "/eu-central-1/logs/aws4_request")
+}
+
+func TestCloudWatchOutput_PanicSendsQueue(t *testing.T) {
+	server := newLogsServer(t)
+
+	log, err := New(Config{
+		Level:  InfoLevel,
+		Output: CloudWatchOutput,
+		CloudWatch: CloudWatchConfig{
+			Region:        "eu-central-1",
+			LogGroup:      "app",
+			LogStream:     "web",
+			Endpoint:      server.URL,
+			FlushInterval: time.Hour,
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { log.Close() })
+
+	log.Info("queued")
+	assert.Panics(t, func() { log.Panic("invariant broken") })
+
+	// Запись Panic и очередь отправлены до паники, без Sync и Close
+	events := server.events("app/web")
+	require.Len(t, events, 2)
+	assert.Contains(t, events[0].Message, "queued")
+	assert.Contains(t, events[1].Message, "invariant broken")
+}
+
+func TestCloudWatchOutput_FatalFlushesQueue(t *testing.T) {
+	server := newLogsServer(t)
+	code := stubExit(t)
+
+	log, err := New(Config{
+		Level:  InfoLevel,
+		Output: CloudWatchOutput,
+		CloudWatch: CloudWatchConfig{
+			Region:        "eu-central-1",
+			LogGroup:      "app",
+			LogStream:     "web",
+			Endpoint:      server.URL,
+			FlushInterval: time.Hour,
+		},
+	})
+	require.NoError(t, err)
+
+	log.Info("queued")
+	log.Fatal("shutting down")
+
+	assert.Equal(t, 1, *code)
+	events := server.events("app/web")
+	require.Len(t, events, 2)
+	assert.Contains(t, events[1].Message, "shutting down")
+}
+
+func TestCloudWatchConn_SequenceToken(t *testing.T) {
+	// Поток уже существует, а в него писал другой процесс
+	server := newLogsServer(t)
+	server.streams["app/web"] = nil
+	server.token = 7
+	conn := newCloudWatchConn(CloudWatchConfig{Region: "us-east-1", LogGroup: "app", LogStream: "web", Endpoint: server.URL})
+
+	_, err := conn.Write([]byte(`1700000000000 {"message":"first"}`))
+	require.NoError(t, err)
+	require.NoError(t, conn.Sync())
+	_, err = conn.Write([]byte(`1700000000001 {"message":"second"}`))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	assert.Len(t, server.events("app/web"), 2)
+	assert.Equal(t, []string{"CreateLogGroup", "CreateLogStream", "PutLogEvents", "PutLogEvents", "PutLogEvents"}, server.calls)
+}
+
+func TestCloudWatchConn_Throttling(t *testing.T) {
+	server := newLogsServer(t)
+	server.fail = func(action string, call int) (int, string) {
+		switch {
+		case action == "CreateLogGroup":
+			return http.StatusBadRequest, "AccessDeniedException"
+		case action == "PutLogEvents" && call <= 4:
+			return http.StatusBadRequest, "ThrottlingException"
+		}
+		return 0, ""
+	}
+	conn := newCloudWatchConn(CloudWatchConfig{Region: "us-east-1", LogGroup: "app", LogStream: "web", Endpoint: server.URL, RetryBackoff: time.Millisecond})
+
+	_, err := conn.Write([]byte(`1700000000000 {"message":"throttled"}`))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+	assert.Len(t, server.events("app/web"), 1)
+	assert.Equal(t, []string{"CreateLogGroup", "CreateLogStream", "PutLogEvents", "PutLogEvents", "PutLogEvents"}, server.calls)
+}
+
+func TestCloudWatchConn_Rejected(t *testing.T) {
+	server := newLogsServer(t)
+	server.fail = func(action string, _ int) (int, string) {
+		if action == "PutLogEvents" {
+			return http.StatusBadRequest, "InvalidParameterException"
+		}
+		return 0, ""
+	}
+	conn := newCloudWatchConn(CloudWatchConfig{Region: "us-east-1", LogGroup: "app", Endpoint: server.URL})
+
+	_, err := conn.Write([]byte(`1700000000000 {}`))
+	require.NoError(t, err)
+	assert.ErrorContains(t, conn.Sync(), "cloudwatch: InvalidParameterException (status 400)")
+	require.NoError(t, conn.Close())
+}
+
+func TestCloudWatchConn_Batches(t *testing.T) {
+	server := newLogsServer(t)
+	conn := newCloudWatchConn(CloudWatchConfig{Region: "us-east-1", LogGroup: "app", LogStream: "web", Endpoint: server.URL})
+
+	// Записи приходят не по порядку и охватывают больше суток
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	for _, at := range []time.Time{day.Add(time.Hour), day, day.Add(25 * time.Hour)} {
+		_, err := conn.Write([]byte(fmt.Sprintf(`%d {"at":%q}`, at.UnixMilli(), at)))
+		require.NoError(t, err)
+	}
+	require.NoError(t, conn.Close())
+
+	require.Len(t, server.batches, 2)
+	require.Len(t, server.batches[0], 2)
+	assert.Equal(t, day.UnixMilli(), server.batches[0][0].Timestamp)
+	assert.Equal(t, day.Add(time.Hour).UnixMilli(), server.batches[0][1].Timestamp)
+	assert.Equal(t, day.Add(25*time.Hour).UnixMilli(), server.batches[1][0].Timestamp)
+}
+
+func TestParseCloudWatchEvent_Truncate(t *testing.T) {
+	// Обрезка не разрывает многобайтовый символ
+	doc := strings.Repeat("я", cloudWatchMaxEventBytes)
+	event, err := parseCloudWatchEvent([]byte("1 " + doc))
+	require.NoError(t, err)
+	assert.LessOrEqual(t, event.size(), cloudWatchMaxEventBytes)
+	assert.Equal(t, strings.Repeat("я", (cloudWatchMaxEventBytes-cloudWatchEventOverhead)/2), event.Message)
+
+	_, err = parseCloudWatchEvent([]byte("plain"))
+	assert.Error(t, err)
+}
+
+func TestAWSCredentials_Container(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Authorization") != "task-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprintf(w, `{"AccessKeyId":"ASIA1","SecretAccessKey":"s","Token":"session","Expiration":%q}`,
+			time.Now().Add(time.Hour).Format(time.RFC3339))
+	}))
+	defer server.Close()
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "")
+	t.Setenv("AWS_CONTAINER_CREDENTIALS_FULL_URI", server.URL+"/creds")
+	t.Setenv("AWS_CONTAINER_AUTHORIZATION_TOKEN", "task-token")
+
+	creds := &awsCredentials{client: server.Client()}
+	for i := 0; i < 2; i++ {
+		c, err := creds.get()
+		require.NoError(t, err)
+		assert.Equal(t, "ASIA1", c.AccessKeyID)
+		assert.Equal(t, "session", c.SessionToken)
+	}
+	assert.Equal(t, 1, requests)
+
+	t.Setenv("AWS_CONTAINER_CREDENTIALS_FULL_URI", "")
+	_, err := (&awsCredentials{client: server.Client()}).get()
+	assert.ErrorContains(t, err, "no AWS credentials")
+}
+
+func TestCloudWatchConfig_Validate(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	err := Config{
+		Output:     CloudWatchOutput,
+		CloudWatch: CloudWatchConfig{Endpoint: "logs.internal", BatchSize: 20000},
+	}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cloudwatch region is required")
+	assert.Contains(t, err.Error(), "cloudwatch log group is required")
+	assert.Contains(t, err.Error(), "invalid cloudwatch endpoint: logs.internal")
+	assert.Contains(t, err.Error(), "cloudwatch batch size must not exceed 10000")
+
+	t.Setenv("AWS_REGION", "eu-west-1")
+	assert.NoError(t, Config{Output: CloudWatchOutput, CloudWatch: CloudWatchConfig{LogGroup: "app"}}.Validate())
+}
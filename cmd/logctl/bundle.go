@@ -0,0 +1,112 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ex-rate/logger"
+)
+
+// runBundle выполняет команду bundle: скачивает отладочный архив с
+// AdminHandler работающего процесса или, если процесс уже упал, собирает
+// архив из его кольцевого файла
+func runBundle(args []string) error {
+	fs := flag.NewFlagSet("bundle", flag.ExitOnError)
+	output := fs.String("o", "", "output file (default debug-bundle-TIME.tar.gz)")
+	timeout := fs.Duration("timeout", 30*time.Second, "admin request timeout")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: logctl bundle [-o FILE] [-timeout D] ADMIN_URL|RING_FILE")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("admin url or ring file is required")
+	}
+	source := fs.Arg(0)
+
+	var data []byte
+	var err error
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		data, err = fetchBundle(source, *timeout)
+	} else {
+		data, err = ringBundle(source)
+	}
+	if err != nil {
+		return err
+	}
+
+	name := *output
+	if name == "" {
+		name = "debug-bundle-" + time.Now().UTC().Format("20060102T150405Z") + ".tar.gz"
+	}
+	if err := os.WriteFile(name, data, 0640); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, name)
+	return nil
+}
+
+// fetchBundle скачивает архив GET /bundle с адреса AdminHandler
+func fetchBundle(adminURL string, timeout time.Duration) ([]byte, error) {
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(strings.TrimRight(adminURL, "/") + "/bundle")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("admin: unexpected status %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// ringBundle собирает архив из кольцевого файла остановленного процесса:
+// в нем только записи самописца и manifest.json
+func ringBundle(path string) ([]byte, error) {
+	var ring bytes.Buffer
+	if err := logger.ReadRingFile(path, &ring); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	manifest, err := json.MarshalIndent(map[string]interface{}{
+		"created_at": time.Now().UTC(),
+		"ring_file":  path,
+		"files":      []string{"ring.ndjson"},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	gz := gzip.NewWriter(&out)
+	tw := tar.NewWriter(gz)
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{{"manifest.json", manifest}, {"ring.ndjson", ring.Bytes()}} {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0o644, Size: int64(len(f.data)), ModTime: time.Now()}); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
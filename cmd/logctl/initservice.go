@@ -0,0 +1,109 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// scaffold шаблоны каркаса сервиса
+//
+//go:embed scaffold/*.tmpl
+var scaffold embed.FS
+
+// serviceNamePattern допустимое имя сервиса логгера
+var serviceNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+// scaffoldData параметры шаблонов каркаса
+type scaffoldData struct {
+	Module string // путь модуля Go
+	Name   string // имя сервиса в логах
+}
+
+// runInitService выполняет команду init-service
+func runInitService(args []string) error {
+	flags := flag.NewFlagSet("init-service", flag.ExitOnError)
+	module := flags.String("module", "", "Go module path (default service name)")
+	name := flags.String("name", "", "service name in logs (default directory name)")
+	force := flags.Bool("force", false, "overwrite existing files")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("usage: logctl init-service [-module PATH] [-name NAME] [-force] DIR")
+	}
+	dir := flags.Arg(0)
+
+	data := scaffoldData{Name: *name, Module: *module}
+	if data.Name == "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		data.Name = strings.ToLower(filepath.Base(abs))
+	}
+	if !serviceNamePattern.MatchString(data.Name) {
+		return fmt.Errorf("invalid service name %q: use lowercase letters, digits, '_', '-' and '.'", data.Name)
+	}
+	if data.Module == "" {
+		data.Module = data.Name
+	}
+
+	files, err := renderScaffold(dir, data, *force)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		fmt.Println("created", file)
+	}
+	fmt.Printf("\nnext steps:\n  cd %s\n  go mod tidy\n  go test ./...\n  go run . -log-config logger.yaml\n", dir)
+	return nil
+}
+
+// renderScaffold создает файлы каркаса в dir и возвращает их пути.
+// Существующие файлы перезаписываются только с force
+func renderScaffold(dir string, data scaffoldData, force bool) ([]string, error) {
+	tmpls, err := fs.Glob(scaffold, "scaffold/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_EXCL
+	if force {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	}
+
+	var created []string
+	for _, name := range tmpls {
+		tmpl, err := template.ParseFS(scaffold, name)
+		if err != nil {
+			return created, err
+		}
+		path := filepath.Join(dir, strings.TrimSuffix(filepath.Base(name), ".tmpl"))
+		file, err := os.OpenFile(path, flags, 0644)
+		if errors.Is(err, fs.ErrExist) {
+			return created, fmt.Errorf("%s already exists, use -force to overwrite", path)
+		}
+		if err != nil {
+			return created, err
+		}
+		if err := tmpl.Execute(file, data); err != nil {
+			file.Close()
+			return created, fmt.Errorf("%s: %w", path, err)
+		}
+		if err := file.Close(); err != nil {
+			return created, err
+		}
+		created = append(created, path)
+	}
+	return created, nil
+}
@@ -0,0 +1,228 @@
+// Команда logctl - утилиты для работы с логами сервисов.
+//
+//	logctl export [-salt S] [-max-value N] [-o FILE] [FILE...]
+//	logctl verify [-q] [FILE...]
+//	logctl init-service [-module PATH] [-name NAME] [-force] DIR
+//	logctl bundle [-o FILE] ADMIN_URL|RING_FILE
+//
+// export пишет обезличенную копию логов для передачи подрядчикам или в
+// публичные баг-репорты. verify проверяет контрольные суммы записей
+// (Config.Checksum) и печатает испорченные строки. Файлы .gz распаковываются,
+// без файлов читается stdin. init-service создает каркас HTTP-сервиса с
+// подключенным логгером: передачей request_id через контекст, журналом
+// запросов, корректной остановкой и тестом на loggertest. bundle скачивает
+// отладочный архив (Logger.DebugBundle) с AdminHandler процесса или
+// собирает его из кольцевого файла упавшего процесса
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ex-rate/logger"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "export":
+		err = runExport(os.Args[2:])
+	case "verify":
+		err = runVerify(os.Args[2:])
+	case "init-service":
+		err = runInitService(os.Args[2:])
+	case "bundle":
+		err = runBundle(os.Args[2:])
+	case "help", "-h", "--help":
+		usage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "logctl: unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logctl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// usage печатает список команд
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: logctl <command> [flags]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "commands:")
+	fmt.Fprintln(os.Stderr, "  export    write an anonymized copy of log files")
+	fmt.Fprintln(os.Stderr, "  verify    check entry checksums and report corrupted lines")
+	fmt.Fprintln(os.Stderr, "  init-service  generate a service skeleton wired with the logger")
+	fmt.Fprintln(os.Stderr, "  bundle    download a debug bundle or build one from a ring file")
+}
+
+// runExport выполняет команду export
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	salt := fs.String("salt", os.Getenv("LOGCTL_SALT"), "secret for stable pseudonyms (default $LOGCTL_SALT, random if empty)")
+	maxValue := fs.Int("max-value", 0, "max bytes of string values, negative disables truncation (default 256)")
+	pseudonym := fs.String("pseudonym-keys", "", "comma-separated identifier fields to pseudonymize")
+	strip := fs.String("strip-keys", "", "comma-separated PII fields to remove")
+	output := fs.String("o", "", "output file (default stdout)")
+	fs.Parse(args)
+
+	opts := logger.AnonymizeOptions{
+		Salt:          *salt,
+		PseudonymKeys: splitKeys(*pseudonym),
+		StripKeys:     splitKeys(*strip),
+		MaxValueBytes: *maxValue,
+	}
+	// Без соли псевдонимы не связываются с другими выгрузками
+	if opts.Salt == "" {
+		buf := make([]byte, 16)
+		if _, err := rand.Read(buf); err != nil {
+			return err
+		}
+		opts.Salt = hex.EncodeToString(buf)
+	}
+
+	if *output == "" {
+		return exportFiles(fs.Args(), os.Stdout, opts)
+	}
+	file, err := os.OpenFile(*output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	if err := exportFiles(fs.Args(), file, opts); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// exportFiles обезличивает файлы по очереди, без файлов - stdin
+func exportFiles(paths []string, out io.Writer, opts logger.AnonymizeOptions) error {
+	if len(paths) == 0 {
+		return logger.Anonymize(os.Stdin, out, opts)
+	}
+	for _, path := range paths {
+		if err := exportFile(path, out, opts); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// exportFile обезличивает один файл
+func exportFile(path string, out io.Writer, opts logger.AnonymizeOptions) error {
+	in, closeFile, err := openLog(path)
+	if err != nil {
+		return err
+	}
+	defer closeFile()
+	return logger.Anonymize(in, out, opts)
+}
+
+// openLog открывает файл лога, распаковывая сжатые зарегистрированными
+// кодеками файлы
+func openLog(path string) (io.Reader, func(), error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	codec, ok := logger.CodecForFile(path)
+	if !ok {
+		return file, func() { file.Close() }, nil
+	}
+	r, err := codec.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return r, func() { r.Close(); file.Close() }, nil
+}
+
+// verifyStats итоги проверки контрольных сумм
+type verifyStats struct {
+	ok, missing, corrupted int
+}
+
+// runVerify выполняет команду verify
+func runVerify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	quiet := fs.Bool("q", false, "print only the summary")
+	fs.Parse(args)
+
+	var stats verifyStats
+	var report io.Writer = os.Stdout
+	if *quiet {
+		report = nil
+	}
+	if fs.NArg() == 0 {
+		if err := verifyLog("-", os.Stdin, report, &stats); err != nil {
+			return err
+		}
+	}
+	for _, path := range fs.Args() {
+		in, closeFile, err := openLog(path)
+		if err != nil {
+			return err
+		}
+		err = verifyLog(path, in, report, &stats)
+		closeFile()
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "%d ok, %d corrupted, %d without checksum\n", stats.ok, stats.corrupted, stats.missing)
+	if stats.corrupted > 0 {
+		return fmt.Errorf("%d corrupted entries", stats.corrupted)
+	}
+	return nil
+}
+
+// verifyLog проверяет строки лога и печатает испорченные в report
+func verifyLog(name string, in io.Reader, report io.Writer, stats *verifyStats) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64<<10), 16<<20)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		switch err := logger.VerifyChecksum(line); {
+		case err == nil:
+			stats.ok++
+		case errors.Is(err, logger.ErrNoChecksum):
+			stats.missing++
+		default:
+			stats.corrupted++
+			if report != nil {
+				fmt.Fprintf(report, "%s:%d: %v\n", name, n, err)
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// splitKeys разбирает список ключей через запятую
+func splitKeys(s string) []string {
+	var keys []string
+	for _, key := range strings.Split(s, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
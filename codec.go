@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultCodec кодек сжатия ротированных файлов по умолчанию
+const DefaultCodec = "gzip"
+
+// Codec алгоритм сжатия. Кодеки регистрируются один раз через RegisterCodec
+// и используются везде, где логгер сжимает или распаковывает данные:
+// при сжатии ротированных файлов, приеме сжатых запросов в IngestHandler
+// и чтении архивов в logctl
+type Codec interface {
+	// Name имя кодека в конфигурации и заголовке Content-Encoding
+	Name() string
+	// Ext расширение сжатых файлов вместе с точкой, например .gz
+	Ext() string
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// codecs зарегистрированные кодеки по имени
+var codecs = struct {
+	mu     sync.RWMutex
+	byName map[string]Codec
+}{byName: map[string]Codec{DefaultCodec: gzipCodec{}}}
+
+// RegisterCodec регистрирует кодек сжатия. Обычно вызывается из init пакета
+// кодека, например github.com/ex-rate/logger/codecs. Кодек с тем же именем
+// заменяется
+func RegisterCodec(codec Codec) {
+	codecs.mu.Lock()
+	defer codecs.mu.Unlock()
+	codecs.byName[strings.ToLower(codec.Name())] = codec
+}
+
+// LookupCodec возвращает кодек по имени
+func LookupCodec(name string) (Codec, bool) {
+	codecs.mu.RLock()
+	defer codecs.mu.RUnlock()
+	codec, ok := codecs.byName[strings.ToLower(name)]
+	return codec, ok
+}
+
+// CodecForFile возвращает кодек по расширению файла
+func CodecForFile(path string) (Codec, bool) {
+	codecs.mu.RLock()
+	defer codecs.mu.RUnlock()
+	for _, codec := range codecs.byName {
+		if strings.HasSuffix(path, codec.Ext()) {
+			return codec, true
+		}
+	}
+	return nil, false
+}
+
+// Codecs возвращает имена зарегистрированных кодеков по алфавиту
+func Codecs() []string {
+	codecs.mu.RLock()
+	defer codecs.mu.RUnlock()
+	names := make([]string, 0, len(codecs.byName))
+	for name := range codecs.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupCodec возвращает кодек или ошибку с именами доступных кодеков
+func lookupCodec(name string) (Codec, error) {
+	codec, ok := LookupCodec(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown compression codec: %s (available: %s)", name, strings.Join(Codecs(), ", "))
+	}
+	return codec, nil
+}
+
+// trimCodecExt убирает из имени файла расширение сжатия
+func trimCodecExt(name string) string {
+	if codec, ok := CodecForFile(name); ok {
+		return strings.TrimSuffix(name, codec.Ext())
+	}
+	return name
+}
+
+// compressedExists проверяет, есть ли сжатая копия файла
+func compressedExists(path string) bool {
+	for _, name := range Codecs() {
+		if codec, ok := LookupCodec(name); ok && fileExists(path+codec.Ext()) {
+			return true
+		}
+	}
+	return false
+}
+
+// gzipCodec встроенный кодек gzip
+type gzipCodec struct{}
+
+func (gzipCodec) Name() string { return "gzip" }
+func (gzipCodec) Ext() string  { return ".gz" }
+
+func (gzipCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+func (gzipCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// zlibCodec тестовый кодек, регистрируемый как сторонний
+type zlibCodec struct{}
+
+func (zlibCodec) Name() string { return "zlib" }
+func (zlibCodec) Ext() string  { return ".zz" }
+
+func (zlibCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return zlib.NewWriter(w), nil
+}
+
+func (zlibCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return zlib.NewReader(r)
+}
+
+func init() {
+	RegisterCodec(zlibCodec{})
+}
+
+func TestCodecRegistry(t *testing.T) {
+	codec, ok := LookupCodec("GZIP")
+	require.True(t, ok)
+	assert.Equal(t, ".gz", codec.Ext())
+
+	codec, ok = CodecForFile("app-2024-01-02T10-00-00.000.log.zz")
+	require.True(t, ok)
+	assert.Equal(t, "zlib", codec.Name())
+
+	_, ok = CodecForFile("app.log")
+	assert.False(t, ok)
+	assert.Contains(t, Codecs(), "gzip")
+	assert.Contains(t, Codecs(), "zlib")
+	assert.Equal(t, "app-1.log", trimCodecExt("app-1.log.gz"))
+}
+
+func TestRotatingWriter_CompressCodec(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	w, err := newRotatingWriter(Config{FilePath: path, Compress: true, CompressCodec: "zlib"})
+	require.NoError(t, err)
+	defer w.Close()
+	w.maxSize = 5
+
+	_, err = w.Write([]byte("first\n"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("second\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	backups, err := listBackups(path)
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	require.True(t, strings.HasSuffix(backups[0], ".zz"), backups[0])
+
+	data, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	plain, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "first\n", string(plain))
+}
+
+func TestConfig_ValidateCompressCodec(t *testing.T) {
+	err := Config{Output: ConsoleOutput, Compress: true, CompressCodec: "brotli"}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown compression codec: brotli")
+
+	assert.NoError(t, Config{Output: ConsoleOutput, Compress: true}.Validate())
+	// Кодек без Compress не используется и не проверяется
+	assert.NoError(t, Config{Output: ConsoleOutput, CompressCodec: "brotli"}.Validate())
+}
+
+func TestLogger_IngestHandler_ContentEncoding(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	handler := logger.IngestHandler(IngestOptions{MaxBodyBytes: 512})
+
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	_, err := io.WriteString(gz, `[{"level": "warn", "message": "slow render"}]`)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	rec := postBatch(handler, body.String(), func(r *http.Request) {
+		r.Header.Set("Content-Encoding", "gzip")
+	})
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "slow render", entries[0]["msg"])
+
+	rec = postBatch(handler, "[]", func(r *http.Request) {
+		r.Header.Set("Content-Encoding", "br")
+	})
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+	// Лимит тела действует и после распаковки
+	body.Reset()
+	gz = gzip.NewWriter(&body)
+	_, err = io.WriteString(gz, `[{"level": "info", "message": "`+strings.Repeat("a", 4096)+`"}]`)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	require.Less(t, body.Len(), 512)
+
+	rec = postBatch(handler, body.String(), func(r *http.Request) {
+		r.Header.Set("Content-Encoding", "gzip")
+	})
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
@@ -0,0 +1,71 @@
+// Package codecs регистрирует в логгере кодеки сжатия zstd, snappy и lz4.
+// Достаточно импорта ради побочного эффекта:
+//
+//	import _ "github.com/ex-rate/logger/codecs"
+//
+// после чего кодеки доступны в Config.CompressCodec, заголовке
+// Content-Encoding запросов IngestHandler и везде, где логгер ищет кодек через
+// logger.LookupCodec. Кодеки вынесены в отдельный модуль, чтобы основной пакет
+// не зависел от сторонних библиотек сжатия
+package codecs
+
+import (
+	"io"
+
+	"github.com/ex-rate/logger"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+func init() {
+	logger.RegisterCodec(Zstd{})
+	logger.RegisterCodec(Snappy{})
+	logger.RegisterCodec(LZ4{})
+}
+
+// Zstd кодек Zstandard
+type Zstd struct{}
+
+func (Zstd) Name() string { return "zstd" }
+func (Zstd) Ext() string  { return ".zst" }
+
+func (Zstd) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return zstd.NewWriter(w)
+}
+
+func (Zstd) NewReader(r io.Reader) (io.ReadCloser, error) {
+	dec, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return dec.IOReadCloser(), nil
+}
+
+// Snappy кодек snappy в потоковом формате (framing format)
+type Snappy struct{}
+
+func (Snappy) Name() string { return "snappy" }
+func (Snappy) Ext() string  { return ".sz" }
+
+func (Snappy) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return snappy.NewBufferedWriter(w), nil
+}
+
+func (Snappy) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(snappy.NewReader(r)), nil
+}
+
+// LZ4 кодек LZ4 в формате кадров
+type LZ4 struct{}
+
+func (LZ4) Name() string { return "lz4" }
+func (LZ4) Ext() string  { return ".lz4" }
+
+func (LZ4) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return lz4.NewWriter(w), nil
+}
+
+func (LZ4) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(lz4.NewReader(r)), nil
+}
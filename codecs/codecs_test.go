@@ -0,0 +1,46 @@
+package codecs
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ex-rate/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodecs_Registered(t *testing.T) {
+	for _, name := range []string{"zstd", "snappy", "lz4"} {
+		_, ok := logger.LookupCodec(name)
+		assert.True(t, ok, name)
+	}
+
+	codec, ok := logger.CodecForFile("/var/log/app-2024-01-02T10-00-00.000.log.zst")
+	require.True(t, ok)
+	assert.Equal(t, "zstd", codec.Name())
+}
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	data := strings.Repeat(`{"level":"info","msg":"request served","status":200}`+"\n", 100)
+
+	for _, codec := range []logger.Codec{Zstd{}, Snappy{}, LZ4{}} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			var compressed bytes.Buffer
+			w, err := codec.NewWriter(&compressed)
+			require.NoError(t, err)
+			_, err = io.WriteString(w, data)
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+			assert.Less(t, compressed.Len(), len(data))
+
+			r, err := codec.NewReader(&compressed)
+			require.NoError(t, err)
+			defer r.Close()
+			got, err := io.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, data, string(got))
+		})
+	}
+}
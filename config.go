@@ -0,0 +1,347 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NewFromFile читает конфигурацию из YAML- или JSON-файла, проверяет ее
+// и создает логгер
+func NewFromFile(path string) (*Logger, error) {
+	config, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return New(config)
+}
+
+// LoadConfig читает конфигурацию из YAML- или JSON-файла. Уровни задаются
+// названиями (debug, info, warn), интервалы - строками вида 30s.
+// Если уровень не указан, используется Info. Из файла с профилями
+// читается профиль из переменной LOG_PROFILE (см. LoadConfigProfile)
+func LoadConfig(path string) (Config, error) {
+	return LoadConfigProfile(path, os.Getenv(ProfileEnv))
+}
+
+// LoadConfigProfile читает конфигурацию профиля profile из файла вида
+// profiles: {default: {...}, dev: {...}, prod: {...}}. Профиль глубоко
+// сливается с default: вложенные объекты - по ключам, остальные значения
+// заменяются. Пустой profile выбирает default. В файле без profiles
+// profile не используется
+func LoadConfigProfile(path, profile string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read config: %w", err)
+	}
+	data, err = selectProfile(data, profile)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	config := Config{Level: InfoLevel}
+
+	// JSON является подмножеством YAML, поэтому оба формата разбираются одним декодером
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil {
+		return Config{}, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Validate проверяет конфигурацию и возвращает все найденные ошибки
+func (c Config) Validate() error {
+	var errs []error
+
+	if c.Level > TraceLevel {
+		errs = append(errs, fmt.Errorf("unsupported level: %d", c.Level))
+	}
+
+	for service, level := range c.ServiceLevels {
+		if service == "" || level > TraceLevel {
+			errs = append(errs, fmt.Errorf("invalid service level: %q=%d", service, level))
+		}
+	}
+	for pool, level := range c.PoolLevels {
+		if pool == "" || level > TraceLevel {
+			errs = append(errs, fmt.Errorf("invalid pool level: %q=%d", pool, level))
+		}
+	}
+
+	switch c.Backend {
+	case "", LogrusBackend:
+	case SlogBackend:
+		if c.SlogHandler == nil {
+			errs = append(errs, errors.New("slog handler is required for slog backend"))
+		}
+		if c.Output != "" {
+			errs = append(errs, errors.New("output is not used with slog backend"))
+		}
+	default:
+		if _, ok := lookupBackend(c.Backend); !ok {
+			errs = append(errs, fmt.Errorf("unsupported backend: %s", c.Backend))
+		}
+	}
+
+	switch c.Output {
+	case "":
+		if len(c.Writers) == 0 && len(c.Destinations) == 0 && len(c.Plugins) == 0 && !usesBackend(c) {
+			errs = append(errs, errors.New("output type or writers are required"))
+		}
+	case FileOutput:
+		if c.FilePath == "" {
+			errs = append(errs, errors.New("file path is required for file output"))
+		}
+	case ConsoleOutput, BothOutput, JournaldOutput:
+	case SyslogOutput:
+		if err := c.Syslog.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	case GELFOutput:
+		if err := c.GELF.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	case EventLogOutput:
+		if err := c.EventLog.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	case ElasticsearchOutput:
+		if err := c.Elasticsearch.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	case CloudWatchOutput:
+		if err := c.CloudWatch.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	case SplunkOutput:
+		if err := c.Splunk.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	case DatadogOutput:
+		if err := c.Datadog.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unsupported output type: %s", c.Output))
+	}
+
+	if (c.Output == FileOutput || c.Output == BothOutput) && c.FilePath != "" && !c.Serverless {
+		if err := checkWritable(c.FilePath, c.DisableDirCreation); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if c.RingFilePath != "" && !c.Serverless {
+		if err := checkWritable(c.RingFilePath, c.DisableDirCreation); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for class, rf := range c.RetentionFiles {
+		if !validRetentionClass(class) {
+			errs = append(errs, fmt.Errorf("unsupported retention class: %s", class))
+		}
+		if rf.FilePath == "" {
+			errs = append(errs, fmt.Errorf("retention class %s: file path is required", class))
+		} else if !c.Serverless {
+			if err := checkWritable(rf.FilePath, c.DisableDirCreation); err != nil {
+				errs = append(errs, fmt.Errorf("retention class %s: %w", class, err))
+			}
+		}
+		if rf.MaxBackups < 0 || rf.MaxAge < 0 || rf.MaxTotalSizeMB < 0 {
+			errs = append(errs, fmt.Errorf("retention class %s: limits must not be negative", class))
+		}
+	}
+	for i, h := range c.LegalHolds {
+		if !h.To.IsZero() && h.To.Before(h.From) {
+			errs = append(errs, fmt.Errorf("legal hold %d: end is before start", i))
+		}
+	}
+	if c.RingFileSizeMB < 0 {
+		errs = append(errs, errors.New("ring file size must not be negative"))
+	}
+
+	for i, d := range c.Destinations {
+		if d.Writer == nil {
+			errs = append(errs, fmt.Errorf("destination %d: writer is required", i))
+		}
+	}
+	for _, p := range c.Plugins {
+		if err := p.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.Migration != nil {
+		if c.Migration.New.Writer == nil {
+			errs = append(errs, errors.New("migration destination writer is required"))
+		}
+		if c.Migration.CompareRate < 0 || c.Migration.CompareRate > 1 {
+			errs = append(errs, fmt.Errorf("migration compare rate must be between 0 and 1: %v", c.Migration.CompareRate))
+		}
+	}
+
+	formats := []string{c.Format, c.ConsoleFormat, c.FileFormat}
+	levels := []string{c.ConsoleLevel, c.FileLevel, c.RingFileLevel}
+	for _, d := range c.Destinations {
+		formats = append(formats, d.Format)
+		levels = append(levels, d.Level)
+	}
+	if c.Migration != nil {
+		formats = append(formats, c.Migration.New.Format)
+		levels = append(levels, c.Migration.New.Level)
+	}
+
+	for _, format := range formats {
+		if format != "" && format != TextFormat && format != JSONFormat {
+			errs = append(errs, fmt.Errorf("unsupported format: %s", format))
+		}
+	}
+
+	for _, level := range levels {
+		if level == "" {
+			continue
+		}
+		if _, err := ParseLevel(level); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	filters := []string{c.ConsoleFilter, c.FileFilter}
+	for _, d := range c.Destinations {
+		filters = append(filters, d.Filter)
+	}
+	if c.Migration != nil {
+		filters = append(filters, c.Migration.New.Filter)
+	}
+	for _, filter := range filters {
+		if _, err := compileFilter(filter); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if _, err := compileExprs(c.Drop); err != nil {
+		errs = append(errs, err)
+	}
+	for i, rule := range c.LevelRules {
+		if rule.Level > TraceLevel {
+			errs = append(errs, fmt.Errorf("level rule %d: unsupported level: %d", i, rule.Level))
+		}
+		if _, err := compileExpr(rule.When); err != nil {
+			errs = append(errs, fmt.Errorf("level rule %d: %w", i, err))
+		}
+	}
+
+	switch c.Rotation {
+	case NoRotation, RotateHourly, RotateDaily:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported rotation: %s", c.Rotation))
+	}
+
+	if c.MaxSizeMB < 0 || c.MaxBackups < 0 || c.MaxAge < 0 || c.MaxTotalSizeMB < 0 {
+		errs = append(errs, errors.New("rotation limits must not be negative"))
+	}
+	if c.Compress {
+		if _, err := lookupCodec(firstNonEmpty(c.CompressCodec, DefaultCodec)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.Targeting.Percent < 0 || c.Targeting.Percent > 100 {
+		errs = append(errs, fmt.Errorf("targeting percent must be between 0 and 100: %v", c.Targeting.Percent))
+	}
+	if c.MaxFieldSize < 0 || c.FieldPreviewBytes < 0 {
+		errs = append(errs, errors.New("field size limits must not be negative"))
+	}
+	if c.HeartbeatInterval < 0 {
+		errs = append(errs, errors.New("heartbeat interval must not be negative"))
+	}
+	if !validServiceNameMode(c.ServiceNameMode) {
+		errs = append(errs, fmt.Errorf("unsupported service name mode: %s", c.ServiceNameMode))
+	}
+	if c.MaxServiceNameLength < 0 {
+		errs = append(errs, errors.New("max service name length must not be negative"))
+	}
+	if c.MisuseMaxFields < 0 || c.MisuseMaxCollection < 0 {
+		errs = append(errs, errors.New("misuse limits must not be negative"))
+	}
+	if c.ErrorDebugWindow < 0 || c.ErrorDebugCooldown < 0 {
+		errs = append(errs, errors.New("error debug window and cooldown must not be negative"))
+	}
+	if c.DroppedSummaryInterval < 0 {
+		errs = append(errs, errors.New("dropped summary interval must not be negative"))
+	}
+	if c.ResourceAnnotationInterval < 0 {
+		errs = append(errs, errors.New("resource annotation interval must not be negative"))
+	}
+	if c.SpoolSize < 0 {
+		errs = append(errs, errors.New("spool size must not be negative"))
+	}
+	if c.FileBatchEntries < 0 || c.FileBatchInterval < 0 {
+		errs = append(errs, errors.New("file batch settings must not be negative"))
+	}
+	for i, rule := range c.SLORules {
+		if err := rule.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("slo rule %d: %w", i, err))
+		}
+	}
+	if len(c.SLORules) > 0 && c.AlertSink == nil {
+		errs = append(errs, errors.New("alert sink is required for slo rules"))
+	}
+	if c.SlowSinkThreshold < 0 {
+		errs = append(errs, errors.New("slow sink threshold must not be negative"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkWritable проверяет, что в файл логов можно писать: существующий файл
+// доступен на запись, иначе можно создать ближайший существующий каталог
+// пути. Проверка ничего не создает на диске: Validate вызывают и LoadConfig,
+// и утилиты проверки конфигурации
+func checkWritable(path string, disableDirCreation bool) error {
+	if info, err := os.Stat(path); err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("log file is a directory: %s", path)
+		}
+		if err := checkAccess(path, false); err != nil {
+			return fmt.Errorf("log file is not writable: %w", err)
+		}
+		return nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("log file is not accessible: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("log directory is not a directory: %s", dir)
+			}
+			break
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("log directory is not accessible: %w", err)
+		}
+		if disableDirCreation {
+			return fmt.Errorf("log directory does not exist: %s", dir)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	if err := checkAccess(dir, true); err != nil {
+		return fmt.Errorf("log directory is not writable: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfig записывает файл конфигурации во временный каталог
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoadConfig_YAML(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfig(t, "logger.yaml", `
+level: debug
+output: file
+file_path: `+filepath.Join(dir, "app.log")+`
+format: json
+console_level: warn
+max_size_mb: 10
+rotation: daily
+heartbeat_interval: 30s
+targeting:
+  level: trace
+  percent: 5
+  user_ids: [u1, u2]
+`)
+
+	config, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, DebugLevel, config.Level)
+	assert.Equal(t, FileOutput, config.Output)
+	assert.Equal(t, "warn", config.ConsoleLevel)
+	assert.Equal(t, 10, config.MaxSizeMB)
+	assert.Equal(t, RotateDaily, config.Rotation)
+	assert.Equal(t, 30*time.Second, config.HeartbeatInterval)
+	assert.Equal(t, TraceLevel, config.Targeting.Level)
+	assert.Equal(t, []string{"u1", "u2"}, config.Targeting.UserIDs)
+}
+
+func TestLoadConfig_JSON(t *testing.T) {
+	path := writeConfig(t, "logger.json", `{
+	"level": "warn",
+	"output": "console",
+	"format": "text"
+}`)
+
+	config, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, WarnLevel, config.Level)
+	assert.Equal(t, ConsoleOutput, config.Output)
+	assert.Equal(t, TextFormat, config.Format)
+}
+
+func TestLoadConfig_DefaultLevel(t *testing.T) {
+	config, err := LoadConfig(writeConfig(t, "logger.yaml", "output: console\n"))
+	require.NoError(t, err)
+	assert.Equal(t, InfoLevel, config.Level)
+}
+
+func TestLoadConfig_Errors(t *testing.T) {
+	tests := map[string]string{
+		"unknown level":   "level: loud\noutput: console\n",
+		"unknown field":   "output: console\nlevle: debug\n",
+		"invalid output":  "output: kafka\n",
+		"missing path":    "output: file\n",
+		"invalid format":  "output: console\nformat: xml\n",
+		"invalid percent": "output: console\ntargeting:\n  percent: 150\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadConfig(writeConfig(t, "logger.yaml", content))
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestConfig_ValidateJoinsErrors(t *testing.T) {
+	err := Config{Output: "kafka", Format: "xml", MaxBackups: -1}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported output type: kafka")
+	assert.Contains(t, err.Error(), "unsupported format: xml")
+	assert.Contains(t, err.Error(), "must not be negative")
+}
+
+func TestNewFromFile(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	path := writeConfig(t, "logger.yaml", "level: info\noutput: file\nfile_path: "+logPath+"\n")
+
+	logger, err := NewFromFile(path)
+	require.NoError(t, err)
+	logger.Debug("skipped")
+	logger.Info("written")
+	require.NoError(t, logger.Close())
+
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "skipped")
+	assert.Contains(t, string(data), "written")
+}
+
+func TestNew_ValidatesConfig(t *testing.T) {
+	_, err := New(Config{Output: "kafka", Format: "xml", ConsoleLevel: "loud"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported output type: kafka")
+	assert.Contains(t, err.Error(), "unsupported format: xml")
+	assert.Contains(t, err.Error(), `unknown log level: "loud"`)
+}
+
+func TestConfig_ValidateFilePath(t *testing.T) {
+	dir := t.TempDir()
+
+	notDir := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(notDir, nil, 0600))
+	err := Config{Output: FileOutput, FilePath: filepath.Join(notDir, "app.log")}.Validate()
+	assert.ErrorContains(t, err, "not a directory")
+
+	missing := filepath.Join(dir, "missing", "app.log")
+	err = Config{Output: FileOutput, FilePath: missing, DisableDirCreation: true}.Validate()
+	assert.ErrorContains(t, err, "log directory does not exist")
+
+	// Проверка ничего не создает: ни каталогов, ни пробных файлов, время
+	// изменения каталога остается прежним
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.NoError(t, os.Chtimes(dir, past, past))
+	assert.NoError(t, Config{Output: FileOutput, FilePath: missing}.Validate())
+	assert.NoError(t, Config{Output: FileOutput, FilePath: filepath.Join(dir, "app.log")}.Validate())
+	_, err = os.Stat(filepath.Dir(missing))
+	assert.True(t, os.IsNotExist(err), "validation must not create directories")
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "validation must not create files")
+	info, err := os.Stat(dir)
+	require.NoError(t, err)
+	assert.True(t, info.ModTime().Equal(past), "validation must not touch the directory")
+
+	err = Config{Output: FileOutput, FilePath: dir}.Validate()
+	assert.ErrorContains(t, err, "log file is a directory")
+}
+
+func TestConfig_ValidateDestinations(t *testing.T) {
+	err := Config{
+		Destinations: []Destination{{Format: "xml", Level: "loud"}},
+		Migration:    &Migration{CompareRate: 2},
+	}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "destination 0: writer is required")
+	assert.Contains(t, err.Error(), "unsupported format: xml")
+	assert.Contains(t, err.Error(), "unknown log level")
+	assert.Contains(t, err.Error(), "migration destination writer is required")
+	assert.Contains(t, err.Error(), "compare rate")
+}
@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Connection журнал жизненного цикла WebSocket или потокового соединения:
+// открытие, число и объем сообщений в обе стороны, закрытие с кодом и длительностью.
+// Методы учета сообщений безопасны для вызова из разных горутин
+type Connection struct {
+	logger *Logger
+	start  time.Time
+
+	messagesIn  atomic.Int64
+	messagesOut atomic.Int64
+	bytesIn     atomic.Int64
+	bytesOut    atomic.Int64
+
+	closeOnce sync.Once
+}
+
+// ConnectionInfo параметры соединения для записи об открытии
+type ConnectionInfo struct {
+	ID         string // идентификатор соединения, по умолчанию случайный
+	Protocol   string // websocket, sse, grpc-stream и т.п.
+	RemoteAddr string
+	Path       string
+}
+
+// OpenConnection пишет запись connection opened и возвращает журнал соединения.
+// Все записи соединения содержат поле conn_id
+func (l *Logger) OpenConnection(info ConnectionInfo) *Connection {
+	if info.ID == "" {
+		info.ID = newConnectionID()
+	}
+
+	fields := logrus.Fields{"conn_id": info.ID}
+	if info.Protocol != "" {
+		fields["protocol"] = info.Protocol
+	}
+	c := &Connection{logger: l.with(fields), start: time.Now()}
+
+	entry := c.logger.withFields()
+	if info.RemoteAddr != "" {
+		entry = entry.WithField("remote_addr", info.RemoteAddr)
+	}
+	if info.Path != "" {
+		entry = entry.WithField("path", info.Path)
+	}
+	entry.Info("connection opened")
+	return c
+}
+
+// Logger возвращает логгер соединения с полем conn_id
+func (c *Connection) Logger() *Logger {
+	return c.logger
+}
+
+// Received учитывает входящее сообщение размером size байт
+func (c *Connection) Received(size int) {
+	c.messagesIn.Add(1)
+	c.bytesIn.Add(int64(size))
+}
+
+// Sent учитывает исходящее сообщение размером size байт
+func (c *Connection) Sent(size int) {
+	c.messagesOut.Add(1)
+	c.bytesOut.Add(int64(size))
+}
+
+// Close пишет запись connection closed с кодом закрытия, причиной, длительностью
+// и счетчиками сообщений. Ошибка err, если она есть, повышает уровень до Warn.
+// Повторные вызовы ничего не делают
+func (c *Connection) Close(code int, reason string, err error) {
+	c.closeOnce.Do(func() {
+		entry := c.logger.withFields().WithFields(logrus.Fields{
+			"close_code":   code,
+			"duration_ms":  time.Since(c.start).Milliseconds(),
+			"messages_in":  c.messagesIn.Load(),
+			"messages_out": c.messagesOut.Load(),
+			"bytes_in":     c.bytesIn.Load(),
+			"bytes_out":    c.bytesOut.Load(),
+		})
+		if reason != "" {
+			entry = entry.WithField("close_reason", reason)
+		}
+		if err != nil {
+			entry.WithError(err).Warn("connection closed")
+			return
+		}
+		entry.Info("connection closed")
+	})
+}
+
+// newConnectionID возвращает случайный идентификатор соединения
+func newConnectionID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
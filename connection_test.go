@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_OpenConnection(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	conn := logger.OpenConnection(ConnectionInfo{Protocol: "websocket", RemoteAddr: "10.0.0.1:5555", Path: "/ws"})
+	conn.Logger().Debug("subscribed")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn.Received(10)
+			conn.Sent(100)
+		}()
+	}
+	wg.Wait()
+	conn.Close(1000, "normal closure", nil)
+	conn.Close(1006, "", nil)
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 3)
+
+	opened := entries[0]
+	id, _ := opened["conn_id"].(string)
+	assert.Len(t, id, 16)
+	assert.Equal(t, "connection opened", opened["msg"])
+	assert.Equal(t, "websocket", opened["protocol"])
+	assert.Equal(t, "10.0.0.1:5555", opened["remote_addr"])
+	assert.Equal(t, "/ws", opened["path"])
+
+	assert.Equal(t, id, entries[1]["conn_id"])
+
+	closed := entries[2]
+	assert.Equal(t, "connection closed", closed["msg"])
+	assert.Equal(t, "info", closed["level"])
+	assert.Equal(t, id, closed["conn_id"])
+	assert.Equal(t, 1000.0, closed["close_code"])
+	assert.Equal(t, "normal closure", closed["close_reason"])
+	assert.Equal(t, 10.0, closed["messages_in"])
+	assert.Equal(t, 10.0, closed["messages_out"])
+	assert.Equal(t, 100.0, closed["bytes_in"])
+	assert.Equal(t, 1000.0, closed["bytes_out"])
+	assert.Contains(t, closed, "duration_ms")
+}
+
+func TestConnection_CloseWithError(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	conn := logger.OpenConnection(ConnectionInfo{ID: "c-1"})
+	conn.Close(1011, "", errors.New("write timeout"))
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "c-1", entries[1]["conn_id"])
+	assert.Equal(t, "warning", entries[1]["level"])
+	assert.Equal(t, "write timeout", entries[1]["error"])
+	assert.NotContains(t, entries[1], "close_reason")
+}
@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Message метаданные сообщения, полученного из брокера очередей
+type Message struct {
+	// System название брокера: kafka, nats, rabbitmq
+	System string
+	// Raw исходное сообщение клиентской библиотеки (sarama, kafka-go, nats, amqp)
+	Raw interface{}
+
+	fields logrus.Fields
+}
+
+// KafkaMessage создает метаданные сообщения Kafka
+func KafkaMessage(topic string, partition int32, offset int64, raw interface{}) Message {
+	return Message{
+		System: "kafka",
+		Raw:    raw,
+		fields: logrus.Fields{
+			"topic":     topic,
+			"partition": partition,
+			"offset":    offset,
+		},
+	}
+}
+
+// NATSMessage создает метаданные сообщения NATS
+func NATSMessage(subject string, raw interface{}) Message {
+	return Message{
+		System: "nats",
+		Raw:    raw,
+		fields: logrus.Fields{
+			"subject": subject,
+		},
+	}
+}
+
+// RabbitMQMessage создает метаданные сообщения RabbitMQ
+func RabbitMQMessage(queue string, deliveryTag uint64, raw interface{}) Message {
+	return Message{
+		System: "rabbitmq",
+		Raw:    raw,
+		fields: logrus.Fields{
+			"queue":        queue,
+			"delivery_tag": deliveryTag,
+		},
+	}
+}
+
+// Fields возвращает поля сообщения для логирования
+func (m Message) Fields() map[string]interface{} {
+	fields := make(map[string]interface{}, len(m.fields)+1)
+	for k, v := range m.fields {
+		fields[k] = v
+	}
+	fields["broker"] = m.System
+	return fields
+}
+
+// ConsumerHandler обрабатывает одно сообщение из очереди
+type ConsumerHandler func(ctx context.Context, msg Message) error
+
+// ConsumerMiddleware оборачивает обработчик сообщений: создает дочерний логгер
+// с метаданными сообщения, кладет его в контекст и логирует результат обработки
+func (l *Logger) ConsumerMiddleware(next ConsumerHandler) ConsumerHandler {
+	return func(ctx context.Context, msg Message) error {
+		msgLogger := l.with(msg.Fields())
+		start := time.Now()
+
+		err := next(NewContext(ctx, msgLogger), msg)
+
+		entry := msgLogger.withFields().WithField("duration_ms", time.Since(start).Milliseconds())
+		if err != nil {
+			entry.WithError(err).Error("message processing failed")
+		} else {
+			entry.Debug("message processed")
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_ConsumerMiddleware(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	var ctxLogger *Logger
+	handler := logger.ConsumerMiddleware(func(ctx context.Context, msg Message) error {
+		ctxLogger = FromContext(ctx)
+		ctxLogger.Info("handling")
+		return nil
+	})
+
+	err := handler(context.Background(), KafkaMessage("orders", 3, 42, nil))
+	require.NoError(t, err)
+	require.NotNil(t, ctxLogger)
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "handling", entries[0]["msg"])
+	assert.Equal(t, "orders", entries[0]["topic"])
+	assert.Equal(t, float64(3), entries[0]["partition"])
+	assert.Equal(t, float64(42), entries[0]["offset"])
+	assert.Equal(t, "kafka", entries[0]["broker"])
+	assert.Equal(t, "message processed", entries[1]["msg"])
+	assert.Contains(t, entries[1], "duration_ms")
+}
+
+func TestLogger_ConsumerMiddleware_Error(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	handler := logger.ConsumerMiddleware(func(ctx context.Context, msg Message) error {
+		return assert.AnError
+	})
+
+	err := handler(context.Background(), RabbitMQMessage("payments", 7, nil))
+	assert.ErrorIs(t, err, assert.AnError)
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "error", entries[0]["level"])
+	assert.Equal(t, float64(7), entries[0]["delivery_tag"])
+	assert.Equal(t, assert.AnError.Error(), entries[0]["error"])
+}
+
+func TestMessage_Fields(t *testing.T) {
+	msg := NATSMessage("events.created", nil)
+	assert.Equal(t, map[string]interface{}{
+		"broker":  "nats",
+		"subject": "events.created",
+	}, msg.Fields())
+}
@@ -0,0 +1,77 @@
+package logger
+
+import "context"
+
+// ctxLoggerKey ключ для хранения логгера в контексте
+type ctxLoggerKey struct{}
+
+// ctxInternalKey помечает служебные записи самого логгера
+type ctxInternalKey struct{}
+
+// NewContext возвращает копию контекста, содержащую логгер
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, ctxLoggerKey{}, l)
+}
+
+// FromContext возвращает логгер из контекста или nil, если его там нет
+func FromContext(ctx context.Context) *Logger {
+	l, _ := ctx.Value(ctxLoggerKey{}).(*Logger)
+	return l
+}
+
+// WithContext возвращает дочерний логгер, привязанный к контексту запроса.
+// Идентификаторы запроса, трейса и пользователя из контекста и поля
+// зарегистрированных извлекателей добавляются к его записям.
+// Для семплированных трейсов, помеченных контекстов и пользователей из правил
+// таргетинга детализация дочернего логгера повышается
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	l = l.orNop()
+	child := l.clone()
+	if fields := l.contextFields(ctx); len(fields) > 0 {
+		child = l.with(fields)
+	}
+	child.ctx = ctx
+	if floor := l.contextFloor(ctx); child.floor < floor {
+		child.floor = floor
+	}
+	return child
+}
+
+// contextFloor возвращает минимальную детализацию для запроса из контекста
+func (l *Logger) contextFloor(ctx context.Context) Level {
+	var floor Level
+	if l.traceDebug(ctx) {
+		floor = DebugLevel
+	}
+	if level, ok := l.targetLevel(ctx); ok && floor < level {
+		floor = level
+	}
+	return floor
+}
+
+// TraceCtx логирует сообщение на уровне Trace с контекстом запроса
+func (l *Logger) TraceCtx(ctx context.Context, args ...interface{}) {
+	l.WithContext(ctx).Trace(args...)
+}
+
+// DebugCtx логирует сообщение на уровне Debug с контекстом запроса
+func (l *Logger) DebugCtx(ctx context.Context, args ...interface{}) {
+	l.WithContext(ctx).Debug(args...)
+}
+
+// InfoCtx логирует сообщение на уровне Info с контекстом запроса. Запись
+// в сетевой вывод ждет места в очереди (SpoolSize) не дольше дедлайна ctx,
+// и логирование не задерживает запрос
+func (l *Logger) InfoCtx(ctx context.Context, args ...interface{}) {
+	l.WithContext(ctx).Info(args...)
+}
+
+// WarnCtx логирует сообщение на уровне Warn с контекстом запроса
+func (l *Logger) WarnCtx(ctx context.Context, args ...interface{}) {
+	l.WithContext(ctx).Warn(args...)
+}
+
+// ErrorCtx логирует сообщение на уровне Error с контекстом запроса
+func (l *Logger) ErrorCtx(ctx context.Context, args ...interface{}) {
+	l.WithContext(ctx).Error(args...)
+}
@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"os/exec"
+)
+
+// Переменные окружения, через которые идентификаторы запроса и трейса
+// передаются дочерним процессам
+const (
+	RequestIDEnv = "LOGGER_REQUEST_ID"
+	TraceIDEnv   = "LOGGER_TRACE_ID"
+)
+
+// correlationEnv поля записей и переменные окружения с их значениями
+var correlationEnv = []struct{ field, env string }{
+	{"request_id", RequestIDEnv},
+	{"trace_id", TraceIDEnv},
+}
+
+// CorrelationEnv возвращает переменные окружения вида KEY=value с
+// идентификаторами запроса и трейса из контекста
+func CorrelationEnv(ctx context.Context) []string {
+	var env []string
+	if id := RequestIDFromContext(ctx); id != "" {
+		env = append(env, RequestIDEnv+"="+id)
+	}
+	if id := TraceIDFromContext(ctx); id != "" {
+		env = append(env, TraceIDEnv+"="+id)
+	}
+	return env
+}
+
+// InheritCorrelation передает команде идентификаторы запроса и трейса из
+// контекста через окружение. Если cmd.Env не задано, команда получает
+// окружение текущего процесса с добавленными переменными
+func InheritCorrelation(ctx context.Context, cmd *exec.Cmd) {
+	env := CorrelationEnv(ctx)
+	if len(env) == 0 {
+		return
+	}
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, env...)
+}
+
+// ContextFromEnv возвращает копию контекста с идентификаторами запроса и
+// трейса, унаследованными от родительского процесса
+func ContextFromEnv(ctx context.Context) context.Context {
+	if id := os.Getenv(RequestIDEnv); id != "" {
+		ctx = ContextWithRequestID(ctx, id)
+	}
+	if id := os.Getenv(TraceIDEnv); id != "" {
+		ctx = ContextWithTraceID(ctx, id)
+	}
+	return ctx
+}
+
+// inheritedFields добавляет к полям по умолчанию идентификаторы,
+// унаследованные от родительского процесса
+func inheritedFields(fields map[string]interface{}) {
+	for _, c := range correlationEnv {
+		if id := os.Getenv(c.env); id != "" {
+			fields[c.field] = id
+		}
+	}
+}
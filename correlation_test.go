@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorrelationEnv(t *testing.T) {
+	assert.Empty(t, CorrelationEnv(context.Background()))
+
+	ctx := ContextWithTraceID(ContextWithRequestID(context.Background(), "req-1"), "trace-1")
+	assert.Equal(t, []string{"LOGGER_REQUEST_ID=req-1", "LOGGER_TRACE_ID=trace-1"}, CorrelationEnv(ctx))
+}
+
+func TestInheritCorrelation(t *testing.T) {
+	t.Setenv("KEEP", "yes")
+	ctx := ContextWithTraceID(context.Background(), "trace-1")
+
+	cmd := exec.Command("sh", "-c", `echo "$KEEP $LOGGER_TRACE_ID"`)
+	InheritCorrelation(ctx, cmd)
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "yes trace-1\n", string(out))
+
+	own := &exec.Cmd{Env: []string{"ONLY=1"}}
+	InheritCorrelation(ctx, own)
+	assert.Equal(t, []string{"ONLY=1", "LOGGER_TRACE_ID=trace-1"}, own.Env)
+
+	untouched := &exec.Cmd{}
+	InheritCorrelation(context.Background(), untouched)
+	assert.Nil(t, untouched.Env)
+}
+
+func TestContextFromEnv(t *testing.T) {
+	t.Setenv(RequestIDEnv, "req-parent")
+	t.Setenv(TraceIDEnv, "trace-parent")
+
+	ctx := ContextFromEnv(context.Background())
+	assert.Equal(t, "req-parent", RequestIDFromContext(ctx))
+	assert.Equal(t, "trace-parent", TraceIDFromContext(ctx))
+}
+
+func TestLogger_InheritedCorrelationFields(t *testing.T) {
+	t.Setenv(TraceIDEnv, "trace-parent")
+
+	buf := &lockedBuffer{}
+	logger, err := New(Config{Level: InfoLevel, Writers: []io.Writer{buf}})
+	require.NoError(t, err)
+
+	logger.Info("child started")
+	logger.WithContext(ContextWithTraceID(context.Background(), "trace-own")).Info("own request")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "trace-parent", entries[0]["trace_id"])
+	assert.NotContains(t, entries[0], "request_id")
+	assert.Equal(t, "trace-own", entries[1]["trace_id"])
+}
@@ -0,0 +1,414 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultDatadogSite          = "datadoghq.com"
+	defaultDatadogSource        = "go"
+	defaultDatadogFlushInterval = time.Second
+	defaultDatadogQueueSize     = 10000
+	defaultDatadogMaxRetries    = 5
+	defaultDatadogRetryBackoff  = 200 * time.Millisecond
+	defaultDatadogTimeout       = 10 * time.Second
+
+	// Ограничения приема логов Datadog: записей в запросе и размер запроса
+	// до сжатия
+	datadogMaxBatchEntries = 1000
+	datadogMaxBatchBytes   = 5000000
+
+	// maxDatadogRetryBackoff предел паузы между повторами
+	maxDatadogRetryBackoff = 30 * time.Second
+)
+
+// DatadogConfig настройки вывода в Datadog Logs API
+type DatadogConfig struct {
+	APIKey string `yaml:"api_key"` // по умолчанию DD_API_KEY
+	// Site сайт Datadog: datadoghq.com, datadoghq.eu, us3.datadoghq.com и
+	// т.д. По умолчанию DD_SITE или datadoghq.com
+	Site     string `yaml:"site"`
+	Endpoint string `yaml:"endpoint"` // адрес приема вместо https://http-intake.logs.SITE/api/v2/logs, например прокси
+
+	// Service сервис записей без WithService. Записи с WithService
+	// получают имя своего сервиса
+	Service  string `yaml:"service"`
+	Source   string `yaml:"source"`   // ddsource, по умолчанию go
+	Tags     string `yaml:"tags"`     // ddtags, например env:prod,team:payments
+	Hostname string `yaml:"hostname"` // по умолчанию имя хоста
+	// Compression сжатие тела запроса: gzip (по умолчанию) или none
+	Compression string `yaml:"compression"`
+
+	// BatchSize записей в одном запросе, по умолчанию и не больше 1000.
+	// Запрос также ограничен 5 МБ до сжатия
+	BatchSize     int           `yaml:"batch_size"`
+	FlushInterval time.Duration `yaml:"flush_interval"` // по умолчанию 1s
+	// QueueSize предел записей в памяти, ожидающих отправки. Записи сверх
+	// него отбрасываются. По умолчанию 10000
+	QueueSize    int           `yaml:"queue_size"`
+	MaxRetries   int           `yaml:"max_retries"`   // повторов запроса при 408, 429, 5xx и сетевых ошибках, по умолчанию 5
+	RetryBackoff time.Duration `yaml:"retry_backoff"` // первая пауза перед повтором, дальше удваивается; по умолчанию 200ms
+	Timeout      time.Duration `yaml:"timeout"`       // таймаут запроса, по умолчанию 10s
+}
+
+// apiKey возвращает ключ API из настроек или окружения
+func (c DatadogConfig) apiKey() string {
+	return firstNonEmpty(c.APIKey, os.Getenv("DD_API_KEY"))
+}
+
+// intakeURL возвращает адрес приема логов
+func (c DatadogConfig) intakeURL() string {
+	if c.Endpoint != "" {
+		return c.Endpoint
+	}
+	return "https://http-intake.logs." + firstNonEmpty(c.Site, os.Getenv("DD_SITE"), defaultDatadogSite) + "/api/v2/logs"
+}
+
+// validate проверяет настройки Datadog
+func (c DatadogConfig) validate() error {
+	var errs []error
+	if c.apiKey() == "" {
+		errs = append(errs, errors.New("datadog api key is required"))
+	}
+	if u, err := url.Parse(c.intakeURL()); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("invalid datadog endpoint: %s", c.intakeURL()))
+	}
+	switch c.Compression {
+	case "", "gzip", "none":
+	default:
+		errs = append(errs, fmt.Errorf("unsupported datadog compression: %s", c.Compression))
+	}
+	if c.BatchSize > datadogMaxBatchEntries {
+		errs = append(errs, fmt.Errorf("datadog batch size must not exceed %d", datadogMaxBatchEntries))
+	}
+	if c.BatchSize < 0 || c.QueueSize < 0 || c.MaxRetries < 0 || c.FlushInterval < 0 || c.RetryBackoff < 0 || c.Timeout < 0 {
+		errs = append(errs, errors.New("datadog limits must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// openDatadogSink создает назначение с фоновой отправкой в Datadog
+func openDatadogSink(config Config) (*datadogConn, *sink, error) {
+	c := config.Datadog
+	if err := c.validate(); err != nil {
+		return nil, nil, err
+	}
+	hostname, _ := os.Hostname()
+	formatter := &datadogFormatter{
+		inner: &logrus.JSONFormatter{
+			TimestampFormat: time.RFC3339Nano,
+			FieldMap: logrus.FieldMap{
+				logrus.FieldKeyTime:  "timestamp",
+				logrus.FieldKeyMsg:   "message",
+				logrus.FieldKeyLevel: "status",
+			},
+		},
+		service:  c.Service,
+		source:   firstNonEmpty(c.Source, defaultDatadogSource),
+		tags:     c.Tags,
+		hostname: firstNonEmpty(c.Hostname, hostname),
+	}
+	conn := newDatadogConn(c)
+	return conn, &sink{name: "datadog", writer: conn, formatter: formatter, binary: true}, nil
+}
+
+// datadogFormatter кодирует запись в JSON с зарезервированными атрибутами
+// Datadog: message, status, timestamp, service, ddsource, ddtags и hostname
+type datadogFormatter struct {
+	inner    logrus.Formatter
+	service  string
+	source   string
+	tags     string
+	hostname string
+}
+
+// Format возвращает запись без перевода строки: пачка отправляется массивом
+func (f *datadogFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	data := make(logrus.Fields, len(entry.Data)+4)
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	if service, _ := data["service"].(string); service == "" {
+		data["service"] = f.service
+	}
+	if data["service"] == "" {
+		delete(data, "service")
+	}
+	data["ddsource"] = f.source
+	if f.tags != "" {
+		data["ddtags"] = f.tags
+	}
+	if f.hostname != "" {
+		data["hostname"] = f.hostname
+	}
+	e := *entry
+	e.Data = data
+	out, err := f.inner.Format(&e)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(out, "\n"), nil
+}
+
+// datadogConn копит записи в ограниченной очереди и отправляет их пачками
+// из фоновой горутины. Ошибки отправки возвращаются следующим вызовом
+// Write, как у вывода в Elasticsearch
+type datadogConn struct {
+	config    DatadogConfig
+	apiKey    string
+	intakeURL string
+	client    *http.Client
+
+	queue chan []byte
+	flush chan chan struct{}
+	done  chan struct{}
+
+	mu      sync.Mutex
+	err     error
+	dropped int
+	closed  bool
+}
+
+// newDatadogConn заполняет значения по умолчанию и запускает отправку
+func newDatadogConn(c DatadogConfig) *datadogConn {
+	if c.Compression == "" {
+		c.Compression = "gzip"
+	}
+	if c.BatchSize == 0 {
+		c.BatchSize = datadogMaxBatchEntries
+	}
+	if c.FlushInterval == 0 {
+		c.FlushInterval = defaultDatadogFlushInterval
+	}
+	if c.QueueSize == 0 {
+		c.QueueSize = defaultDatadogQueueSize
+	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = defaultDatadogMaxRetries
+	}
+	if c.RetryBackoff == 0 {
+		c.RetryBackoff = defaultDatadogRetryBackoff
+	}
+	if c.Timeout == 0 {
+		c.Timeout = defaultDatadogTimeout
+	}
+	conn := &datadogConn{
+		config:    c,
+		apiKey:    c.apiKey(),
+		intakeURL: c.intakeURL(),
+		client:    &http.Client{Timeout: c.Timeout},
+		queue:     make(chan []byte, c.QueueSize),
+		flush:     make(chan chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go conn.run()
+	return conn
+}
+
+// Write ставит запись в очередь. При заполненной очереди запись
+// отбрасывается, число отброшенных записей сообщается ошибкой
+func (c *datadogConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return 0, errors.New("datadog output is closed")
+	}
+	select {
+	case c.queue <- append([]byte(nil), p...):
+	default:
+		c.dropped++
+	}
+	return len(p), c.takeErrLocked()
+}
+
+// takeErrLocked возвращает накопленную ошибку отправки и сбрасывает ее
+func (c *datadogConn) takeErrLocked() error {
+	err := c.err
+	if c.dropped > 0 {
+		err = errors.Join(err, fmt.Errorf("datadog queue is full: %d entries dropped", c.dropped))
+		c.dropped = 0
+	}
+	c.err = nil
+	return err
+}
+
+// setErr запоминает ошибку фоновой отправки
+func (c *datadogConn) setErr(err error) {
+	c.mu.Lock()
+	c.err = errors.Join(c.err, err)
+	c.mu.Unlock()
+}
+
+// run собирает пачки из очереди и отправляет их по числу записей, по
+// размеру, по таймеру и по запросу Sync
+func (c *datadogConn) run() {
+	defer close(c.done)
+	ticker := time.NewTicker(c.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([][]byte, 0, c.config.BatchSize)
+	size := 2 // скобки массива
+	send := func() {
+		if len(batch) > 0 {
+			c.send(batch)
+			batch = batch[:0]
+			size = 2
+		}
+	}
+	add := func(entry []byte) {
+		// Запятая перед записью
+		if len(batch) > 0 && size+len(entry)+1 > datadogMaxBatchBytes {
+			send()
+		}
+		batch = append(batch, entry)
+		size += len(entry) + 1
+		if len(batch) >= c.config.BatchSize {
+			send()
+		}
+	}
+	for {
+		select {
+		case entry, ok := <-c.queue:
+			if !ok {
+				send()
+				return
+			}
+			add(entry)
+		case <-ticker.C:
+			send()
+		case ack := <-c.flush:
+			for drained := false; !drained; {
+				select {
+				case entry := <-c.queue:
+					add(entry)
+				default:
+					drained = true
+				}
+			}
+			send()
+			close(ack)
+		}
+	}
+}
+
+// send отправляет пачку массивом JSON, повторяя ее при 408, 429, 5xx и
+// сетевых ошибках с экспоненциальной паузой
+func (c *datadogConn) send(entries [][]byte) {
+	body, err := c.encode(entries)
+	if err != nil {
+		c.setErr(fmt.Errorf("datadog logs: %w", err))
+		return
+	}
+	backoff := c.config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := c.post(body, len(entries))
+		if retry == nil {
+			if err != nil {
+				c.setErr(err)
+			}
+			return
+		}
+		if attempt == c.config.MaxRetries {
+			c.setErr(fmt.Errorf("datadog logs: %d entries dropped after %d retries: %w", len(entries), attempt, retry))
+			return
+		}
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxDatadogRetryBackoff)
+	}
+}
+
+// encode собирает массив записей и сжимает его, если сжатие включено
+func (c *datadogConn) encode(entries [][]byte) ([]byte, error) {
+	data := make([]byte, 0, datadogMaxBatchBytes)
+	data = append(data, '[')
+	data = append(data, bytes.Join(entries, []byte{','})...)
+	data = append(data, ']')
+	if c.config.Compression != "gzip" {
+		return data, nil
+	}
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	if _, err := gz.Write(data); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// post отправляет пачку из count записей одним запросом. retry - причина
+// повторить запрос, err - ошибка, которую повторять бесполезно
+func (c *datadogConn) post(body []byte, count int) (retry, err error) {
+	req, err := http.NewRequest(http.MethodPost, c.intakeURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("DD-API-KEY", c.apiKey)
+	if c.config.Compression == "gzip" {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err, nil
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	switch {
+	case resp.StatusCode < 300:
+		return nil, nil
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("unexpected status: %s", resp.Status), nil
+	}
+	return nil, fmt.Errorf("datadog logs: %d entries rejected: unexpected status %s: %s", count, resp.Status, bytes.TrimSpace(data))
+}
+
+// Sync отправляет накопленные записи и возвращает ошибки отправки
+func (c *datadogConn) Sync() error {
+	ack := make(chan struct{})
+	select {
+	case c.flush <- ack:
+		<-ack
+	case <-c.done:
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.takeErrLocked()
+}
+
+// Reopen ничего не делает: каждый запрос открывает соединение заново
+// при необходимости
+func (c *datadogConn) Reopen() error {
+	return nil
+}
+
+// Close отправляет записи из очереди и останавливает отправку
+func (c *datadogConn) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	close(c.queue)
+	c.mu.Unlock()
+
+	<-c.done
+	c.client.CloseIdleConnections()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.takeErrLocked()
+}
@@ -0,0 +1,226 @@
+package logger
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// intakeServer тестовый прием логов Datadog: запоминает пачки и отвечает
+// статусом из respond
+type intakeServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests int
+	batches  [][]map[string]interface{}
+	apiKey   string
+	encoding string
+	respond  func(request int) int
+}
+
+func newIntakeServer(t *testing.T, respond func(request int) int) *intakeServer {
+	s := &intakeServer{respond: respond}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *intakeServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	s.apiKey = r.Header.Get("DD-API-KEY")
+	s.encoding = r.Header.Get("Content-Encoding")
+	if s.respond != nil {
+		if status := s.respond(s.requests); status != http.StatusAccepted {
+			http.Error(w, `{"errors":[{"status":"error"}]}`, status)
+			return
+		}
+	}
+
+	var body io.Reader = r.Body
+	if s.encoding == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, "bad gzip", http.StatusBadRequest)
+			return
+		}
+		body = gz
+	}
+	var batch []map[string]interface{}
+	if err := json.NewDecoder(body).Decode(&batch); err != nil {
+		http.Error(w, "bad json", http.StatusBadRequest)
+		return
+	}
+	s.batches = append(s.batches, batch)
+	w.WriteHeader(http.StatusAccepted)
+	fmt.Fprint(w, "{}")
+}
+
+func (s *intakeServer) received() [][]map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]map[string]interface{}(nil), s.batches...)
+}
+
+func TestDatadogOutput(t *testing.T) {
+	server := newIntakeServer(t, nil)
+
+	log, err := New(Config{
+		Level:  InfoLevel,
+		Output: DatadogOutput,
+		Datadog: DatadogConfig{
+			APIKey:   "secret",
+			Endpoint: server.URL,
+			Service:  "api-server",
+			Tags:     "env:prod,team:payments",
+			Hostname: "web-1",
+		},
+	})
+	require.NoError(t, err)
+
+	log.WithService("billing").WithField("order_id", 42).Info("order created")
+	log.Warn("payment delayed")
+	require.NoError(t, log.Sync())
+
+	batches := server.received()
+	require.Len(t, batches, 1)
+	require.Len(t, batches[0], 2)
+	first, second := batches[0][0], batches[0][1]
+	assert.Equal(t, "order created", first["message"])
+	assert.Equal(t, "info", first["status"])
+	assert.Equal(t, "billing", first["service"])
+	assert.Equal(t, "go", first["ddsource"])
+	assert.Equal(t, "env:prod,team:payments", first["ddtags"])
+	assert.Equal(t, "web-1", first["hostname"])
+	assert.EqualValues(t, 42, first["order_id"])
+	_, err = time.Parse(time.RFC3339Nano, first["timestamp"].(string))
+	assert.NoError(t, err)
+	// Записи без WithService получают сервис из настроек
+	assert.Equal(t, "api-server", second["service"])
+	assert.Equal(t, "warning", second["status"])
+
+	assert.Equal(t, "secret", server.apiKey)
+	assert.Equal(t, "gzip", server.encoding)
+	require.NoError(t, log.Close())
+}
+
+func TestDatadogOutput_FatalFlushesQueue(t *testing.T) {
+	server := newIntakeServer(t, nil)
+	code := stubExit(t)
+
+	log, err := New(Config{
+		Level:   InfoLevel,
+		Output:  DatadogOutput,
+		Datadog: DatadogConfig{APIKey: "secret", Endpoint: server.URL, FlushInterval: time.Hour},
+	})
+	require.NoError(t, err)
+
+	log.Info("queued")
+	log.Fatal("shutting down")
+
+	assert.Equal(t, 1, *code)
+	var messages []interface{}
+	for _, batch := range server.received() {
+		for _, entry := range batch {
+			messages = append(messages, entry["message"])
+		}
+	}
+	assert.Equal(t, []interface{}{"queued", "shutting down"}, messages)
+}
+
+func TestDatadogConn_Batches(t *testing.T) {
+	server := newIntakeServer(t, nil)
+	conn := newDatadogConn(DatadogConfig{APIKey: "k", Endpoint: server.URL, BatchSize: 2, Compression: "none"})
+
+	for i := 0; i < 5; i++ {
+		_, err := conn.Write([]byte(fmt.Sprintf(`{"n":%d}`, i)))
+		require.NoError(t, err)
+	}
+	require.NoError(t, conn.Close())
+
+	var sizes []int
+	for _, batch := range server.received() {
+		sizes = append(sizes, len(batch))
+	}
+	assert.Equal(t, []int{2, 2, 1}, sizes)
+	assert.Empty(t, server.encoding)
+}
+
+func TestDatadogConn_BatchBytes(t *testing.T) {
+	server := newIntakeServer(t, nil)
+	conn := newDatadogConn(DatadogConfig{APIKey: "k", Endpoint: server.URL})
+
+	// Три записи по 2 МБ не помещаются в один запрос 5 МБ
+	entry := []byte(`{"blob":"` + strings.Repeat("x", 2000000) + `"}`)
+	for i := 0; i < 3; i++ {
+		_, err := conn.Write(entry)
+		require.NoError(t, err)
+	}
+	require.NoError(t, conn.Sync())
+	require.NoError(t, conn.Close())
+
+	var sizes []int
+	for _, batch := range server.received() {
+		sizes = append(sizes, len(batch))
+	}
+	assert.Equal(t, []int{2, 1}, sizes)
+}
+
+func TestDatadogConn_Retry(t *testing.T) {
+	server := newIntakeServer(t, func(request int) int {
+		if request == 1 {
+			return http.StatusTooManyRequests
+		}
+		return http.StatusAccepted
+	})
+	conn := newDatadogConn(DatadogConfig{APIKey: "k", Endpoint: server.URL, RetryBackoff: time.Millisecond})
+
+	_, err := conn.Write([]byte(`{"n":1}`))
+	require.NoError(t, err)
+	require.NoError(t, conn.Sync())
+	assert.Len(t, server.received(), 1)
+	require.NoError(t, conn.Close())
+	assert.Equal(t, 2, server.requests)
+}
+
+func TestDatadogConn_Rejected(t *testing.T) {
+	server := newIntakeServer(t, func(int) int { return http.StatusForbidden })
+	conn := newDatadogConn(DatadogConfig{APIKey: "k", Endpoint: server.URL, RetryBackoff: time.Millisecond})
+
+	_, err := conn.Write([]byte(`{"n":1}`))
+	require.NoError(t, err)
+	assert.ErrorContains(t, conn.Sync(), "datadog logs: 1 entries rejected: unexpected status 403 Forbidden")
+	require.NoError(t, conn.Close())
+	assert.Equal(t, 1, server.requests)
+}
+
+func TestDatadogConfig_Validate(t *testing.T) {
+	t.Setenv("DD_API_KEY", "")
+	err := Config{
+		Output:  DatadogOutput,
+		Datadog: DatadogConfig{Endpoint: "intake.internal", Compression: "zstd", BatchSize: 5000},
+	}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "datadog api key is required")
+	assert.Contains(t, err.Error(), "invalid datadog endpoint: intake.internal")
+	assert.Contains(t, err.Error(), "unsupported datadog compression: zstd")
+	assert.Contains(t, err.Error(), "datadog batch size must not exceed 1000")
+
+	t.Setenv("DD_API_KEY", "from-env")
+	t.Setenv("DD_SITE", "datadoghq.eu")
+	c := DatadogConfig{}
+	require.NoError(t, c.validate())
+	assert.Equal(t, "https://http-intake.logs.datadoghq.eu/api/v2/logs", c.intakeURL())
+}
@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	defaultLogger   atomic.Pointer[Logger]
+	fallbackOnce    sync.Once
+	fallbackDefault *Logger
+)
+
+// SetDefault задает логгер пакетных функций Info, Error и др. Логгер по
+// умолчанию нужен небольшим программам и коду init, который пишет логи до
+// того, как логгер передан зависимостям
+func SetDefault(l *Logger) {
+	defaultLogger.Store(l)
+}
+
+// Default возвращает логгер пакетных функций. До вызова SetDefault это
+// логгер уровня Info, который пишет текст в консоль
+func Default() *Logger {
+	if l := defaultLogger.Load(); l != nil {
+		return l
+	}
+	fallbackOnce.Do(func() {
+		l, err := New(Config{Level: InfoLevel, Output: ConsoleOutput})
+		if err != nil {
+			panic("logger: default logger: " + err.Error())
+		}
+		fallbackDefault = l
+	})
+	return fallbackDefault
+}
+
+// Trace логирует сообщение уровня Trace логгером по умолчанию
+func Trace(args ...interface{}) {
+	Default().Trace(args...)
+}
+
+// Debug логирует сообщение уровня Debug логгером по умолчанию
+func Debug(args ...interface{}) {
+	Default().Debug(args...)
+}
+
+// Debugf логирует форматированное сообщение уровня Debug логгером по умолчанию
+func Debugf(format string, args ...interface{}) {
+	Default().Debugf(format, args...)
+}
+
+// Info логирует сообщение уровня Info логгером по умолчанию
+func Info(args ...interface{}) {
+	Default().Info(args...)
+}
+
+// Infof логирует форматированное сообщение уровня Info логгером по умолчанию
+func Infof(format string, args ...interface{}) {
+	Default().Infof(format, args...)
+}
+
+// Warn логирует сообщение уровня Warn логгером по умолчанию
+func Warn(args ...interface{}) {
+	Default().Warn(args...)
+}
+
+// Warnf логирует форматированное сообщение уровня Warn логгером по умолчанию
+func Warnf(format string, args ...interface{}) {
+	Default().Warnf(format, args...)
+}
+
+// Error логирует сообщение уровня Error логгером по умолчанию
+func Error(args ...interface{}) {
+	Default().Error(args...)
+}
+
+// Errorf логирует форматированное сообщение уровня Error логгером по умолчанию
+func Errorf(format string, args ...interface{}) {
+	Default().Errorf(format, args...)
+}
@@ -0,0 +1,41 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	logger.SetLevel(InfoLevel)
+	previous := defaultLogger.Load()
+	SetDefault(logger)
+	t.Cleanup(func() { SetDefault(previous) })
+
+	assert.Same(t, logger, Default())
+
+	Debug("hidden")
+	Info("service", " starting")
+	Warnf("retry %d", 2)
+	Error("failed")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "service starting", entries[0]["msg"])
+	assert.Equal(t, "retry 2", entries[1]["msg"])
+	assert.Equal(t, "error", entries[2]["level"])
+	assert.Contains(t, entries[0]["file"], "default_test.go", "caller skips package functions")
+}
+
+func TestDefault_Fallback(t *testing.T) {
+	previous := defaultLogger.Load()
+	SetDefault(nil)
+	t.Cleanup(func() { SetDefault(previous) })
+
+	fallback := Default()
+	require.NotNil(t, fallback)
+	assert.Same(t, fallback, Default())
+	assert.Equal(t, InfoLevel, fallback.GetLevel())
+}
@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"os"
+	"runtime/debug"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultFields возвращает поля, которые добавляются в каждую запись:
+// идентификаторы из окружения родительского процесса, DefaultFields и,
+// при ProcessFields, hostname, pid, app и version процесса.
+// Пустой результат - nil
+func defaultFields(config Config) logrus.Fields {
+	fields := make(logrus.Fields, len(config.DefaultFields)+4)
+	inheritedFields(fields)
+	for k, v := range config.DefaultFields {
+		fields[k] = v
+	}
+
+	if config.ProcessFields {
+		if hostname, err := os.Hostname(); err == nil {
+			fields["hostname"] = hostname
+		}
+		fields["pid"] = os.Getpid()
+		if app := firstNonEmpty(config.AppName, buildPath()); app != "" {
+			fields["app"] = app
+		}
+		if version := firstNonEmpty(config.AppVersion, buildVersion()); version != "" {
+			fields["version"] = version
+		}
+	} else {
+		if config.AppName != "" {
+			fields["app"] = config.AppName
+		}
+		if config.AppVersion != "" {
+			fields["version"] = config.AppVersion
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+// buildPath возвращает путь главного пакета из сведений о сборке
+func buildPath() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.Path
+	}
+	return ""
+}
+
+// buildVersion возвращает версию главного модуля из сведений о сборке.
+// У локальной сборки версии нет
+func buildVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return ""
+}
+
+// withDefaults добавляет в запись поля по умолчанию, которых в ней еще нет
+func (c *core) withDefaults(entry *logrus.Entry) *logrus.Entry {
+	defaults := c.defaults.Load()
+	if defaults == nil {
+		return entry
+	}
+
+	data := make(logrus.Fields, len(entry.Data)+len(*defaults))
+	for k, v := range *defaults {
+		data[k] = v
+	}
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+
+	enriched := *entry
+	enriched.Data = data
+	return &enriched
+}
+
+// setDefaults задает поля по умолчанию из конфигурации
+func (c *core) setDefaults(config Config) {
+	if fields := defaultFields(config); fields != nil {
+		c.defaults.Store(&fields)
+		return
+	}
+	c.defaults.Store(nil)
+}
@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_DefaultFields(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(Config{
+		Level:         InfoLevel,
+		Writers:       []io.Writer{buf},
+		DefaultFields: map[string]interface{}{"region": "eu-west-1", "team": "payments"},
+		ProcessFields: true,
+		AppName:       "billing-api",
+		AppVersion:    "1.4.2",
+	})
+	require.NoError(t, err)
+
+	logger.Info("started")
+	logger.WithField("team", "checkout").Info("overridden")
+
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "eu-west-1", entries[0]["region"])
+	assert.Equal(t, "payments", entries[0]["team"])
+	assert.Equal(t, hostname, entries[0]["hostname"])
+	assert.Equal(t, float64(os.Getpid()), entries[0]["pid"])
+	assert.Equal(t, "billing-api", entries[0]["app"])
+	assert.Equal(t, "1.4.2", entries[0]["version"])
+	assert.Equal(t, "checkout", entries[1]["team"], "entry fields win over defaults")
+
+	buf.Reset()
+	require.NoError(t, logger.ApplyConfig(Config{Level: InfoLevel, Writers: []io.Writer{buf}}))
+	logger.Info("plain")
+	entry := decodeEntries(t, buf)[0]
+	assert.NotContains(t, entry, "region")
+	assert.NotContains(t, entry, "hostname")
+}
+
+func TestDefaultFields_ProcessDefaults(t *testing.T) {
+	assert.Nil(t, defaultFields(Config{}))
+	assert.Equal(t, "billing-api", defaultFields(Config{AppName: "billing-api"})["app"])
+
+	fields := defaultFields(Config{ProcessFields: true})
+	assert.Equal(t, os.Getpid(), fields["pid"])
+	assert.NotEmpty(t, fields["app"], "app defaults to the main package path")
+}
+
+func TestConfigFromEnv_DefaultFields(t *testing.T) {
+	t.Setenv("LOG_DEFAULT_FIELDS", "region=eu-west-1, team=payments")
+	t.Setenv("LOG_PROCESS_FIELDS", "true")
+	t.Setenv("LOG_APP_VERSION", "1.4.2")
+
+	config, err := ConfigFromEnv("LOG")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"region": "eu-west-1", "team": "payments"}, config.DefaultFields)
+	assert.True(t, config.ProcessFields)
+	assert.Equal(t, "1.4.2", config.AppVersion)
+
+	t.Setenv("LOG_DEFAULT_FIELDS", "region")
+	_, err = ConfigFromEnv("LOG")
+	assert.ErrorContains(t, err, "LOG_DEFAULT_FIELDS")
+}
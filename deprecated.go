@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// deprecations вызовы устаревших путей кода за время жизни процесса
+var deprecations sync.Map // key -> *atomic.Uint64
+
+// Deprecated отмечает вызов устаревшего пути кода key. Предупреждение
+// с рекомендацией advice пишется один раз на ключ за время жизни процесса,
+// последующие вызовы только увеличивают счетчик
+func (l *Logger) Deprecated(key, advice string) {
+	counter, loaded := deprecations.LoadOrStore(key, new(atomic.Uint64))
+	counter.(*atomic.Uint64).Add(1)
+	if loaded {
+		return
+	}
+
+	l.withFields().WithFields(map[string]interface{}{
+		"deprecated": key,
+		"advice":     advice,
+	}).Warn("deprecated code path called")
+}
+
+// DeprecationCounts возвращает число вызовов устаревших путей кода по ключам
+func DeprecationCounts() map[string]uint64 {
+	counts := make(map[string]uint64)
+	deprecations.Range(func(key, counter interface{}) bool {
+		counts[key.(string)] = counter.(*atomic.Uint64).Load()
+		return true
+	})
+	return counts
+}
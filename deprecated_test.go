@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_DeprecatedOnce(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	logger.Deprecated("test.once.v1", "use v2")
+	logger.WithService("orders").Deprecated("test.once.v1", "use v2")
+	logger.Deprecated("test.once.legacy", "remove call")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "deprecated code path called", entries[0]["msg"])
+	assert.Equal(t, "warning", entries[0]["level"])
+	assert.Equal(t, "test.once.v1", entries[0]["deprecated"])
+	assert.Equal(t, "use v2", entries[0]["advice"])
+	assert.Equal(t, "test.once.legacy", entries[1]["deprecated"])
+
+	counts := DeprecationCounts()
+	assert.Equal(t, uint64(2), counts["test.once.v1"])
+	assert.Equal(t, uint64(1), counts["test.once.legacy"])
+}
+
+func TestLogger_DeprecatedConcurrent(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+	buf := &lockedBuffer{}
+	setTestSink(logger, buf, &logrus.JSONFormatter{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger.Deprecated("test.concurrent", "use v2")
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, decodeEntries(t, buf), 1)
+	assert.Equal(t, uint64(50), DeprecationCounts()["test.concurrent"])
+}
@@ -0,0 +1,148 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DiffOp одна операция структурированного диффа в стиле JSON Patch (RFC 6902).
+// Path корня документа - пустая строка
+type DiffOp struct {
+	Op    string      `json:"op"` // add, remove или replace
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"` // новое значение для add и replace
+	Old   interface{} `json:"old"`   // прежнее значение для remove и replace
+}
+
+// MarshalJSON пишет value у add и replace и old у remove и replace, в том
+// числе null: по RFC 6902 value обязательно, а null - допустимое значение
+func (op DiffOp) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Op    string       `json:"op"`
+		Path  string       `json:"path"`
+		Value *interface{} `json:"value,omitempty"`
+		Old   *interface{} `json:"old,omitempty"`
+	}{Op: op.Op, Path: op.Path}
+	if op.Op != "remove" {
+		out.Value = &op.Value
+	}
+	if op.Op != "add" {
+		out.Old = &op.Old
+	}
+	return json.Marshal(out)
+}
+
+// WithDiff добавляет к логу поле key с диффом между старым и новым значением.
+// Значения сравниваются по их JSON-представлению
+func (l *Logger) WithDiff(key string, oldVal, newVal interface{}) *logrus.Entry {
+	ops, err := Diff(oldVal, newVal)
+	if err != nil {
+		return l.withFields().WithField(key, fmt.Sprintf("<diff error: %v>", err))
+	}
+	return l.withFields().WithField(key, ops)
+}
+
+// Diff вычисляет дифф между двумя значениями в стиле JSON Patch
+func Diff(oldVal, newVal interface{}) ([]DiffOp, error) {
+	oldDoc, err := toJSONValue(oldVal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode old value: %w", err)
+	}
+	newDoc, err := toJSONValue(newVal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode new value: %w", err)
+	}
+
+	ops := []DiffOp{}
+	diffValues("", oldDoc, newDoc, &ops)
+	return ops, nil
+}
+
+// toJSONValue приводит значение к дереву map/slice/скаляров через JSON
+func toJSONValue(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// diffValues рекурсивно сравнивает два JSON-значения
+func diffValues(path string, oldVal, newVal interface{}, ops *[]DiffOp) {
+	switch oldTyped := oldVal.(type) {
+	case map[string]interface{}:
+		if newTyped, ok := newVal.(map[string]interface{}); ok {
+			diffObjects(path, oldTyped, newTyped, ops)
+			return
+		}
+	case []interface{}:
+		if newTyped, ok := newVal.([]interface{}); ok {
+			diffArrays(path, oldTyped, newTyped, ops)
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(oldVal, newVal) {
+		*ops = append(*ops, DiffOp{Op: "replace", Path: path, Value: newVal, Old: oldVal})
+	}
+}
+
+// diffObjects сравнивает объекты, обходя ключи в стабильном порядке
+func diffObjects(path string, oldObj, newObj map[string]interface{}, ops *[]DiffOp) {
+	keys := make([]string, 0, len(oldObj)+len(newObj))
+	for k := range oldObj {
+		keys = append(keys, k)
+	}
+	for k := range newObj {
+		if _, ok := oldObj[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		childPath := path + "/" + escapePointer(k)
+		oldChild, inOld := oldObj[k]
+		newChild, inNew := newObj[k]
+
+		switch {
+		case !inOld:
+			*ops = append(*ops, DiffOp{Op: "add", Path: childPath, Value: newChild})
+		case !inNew:
+			*ops = append(*ops, DiffOp{Op: "remove", Path: childPath, Old: oldChild})
+		default:
+			diffValues(childPath, oldChild, newChild, ops)
+		}
+	}
+}
+
+// diffArrays сравнивает массивы поэлементно
+func diffArrays(path string, oldArr, newArr []interface{}, ops *[]DiffOp) {
+	common := min(len(oldArr), len(newArr))
+	for i := 0; i < common; i++ {
+		diffValues(path+"/"+strconv.Itoa(i), oldArr[i], newArr[i], ops)
+	}
+	for i := common; i < len(newArr); i++ {
+		*ops = append(*ops, DiffOp{Op: "add", Path: path + "/" + strconv.Itoa(i), Value: newArr[i]})
+	}
+	// Удаляем с конца, чтобы индексы оставались корректными при применении патча
+	for i := len(oldArr) - 1; i >= common; i-- {
+		*ops = append(*ops, DiffOp{Op: "remove", Path: path + "/" + strconv.Itoa(i), Old: oldArr[i]})
+	}
+}
+
+// escapePointer экранирует ключ для JSON Pointer (RFC 6901)
+func escapePointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	type limits struct {
+		RPS   int      `json:"rps"`
+		Hosts []string `json:"hosts"`
+	}
+	type config struct {
+		Name   string            `json:"name"`
+		Limits limits            `json:"limits"`
+		Labels map[string]string `json:"labels"`
+	}
+
+	oldVal := config{
+		Name:   "api",
+		Limits: limits{RPS: 100, Hosts: []string{"a", "b", "c"}},
+		Labels: map[string]string{"env": "dev", "team/owner": "core"},
+	}
+	newVal := config{
+		Name:   "api",
+		Limits: limits{RPS: 200, Hosts: []string{"a", "x"}},
+		Labels: map[string]string{"env": "prod", "tier": "1"},
+	}
+
+	ops, err := Diff(oldVal, newVal)
+	require.NoError(t, err)
+	assert.Equal(t, []DiffOp{
+		{Op: "replace", Path: "/labels/env", Value: "prod", Old: "dev"},
+		{Op: "remove", Path: "/labels/team~1owner", Old: "core"},
+		{Op: "add", Path: "/labels/tier", Value: "1"},
+		{Op: "replace", Path: "/limits/hosts/1", Value: "x", Old: "b"},
+		{Op: "remove", Path: "/limits/hosts/2", Old: "c"},
+		{Op: "replace", Path: "/limits/rps", Value: float64(200), Old: float64(100)},
+	}, ops)
+}
+
+func TestDiff_Equal(t *testing.T) {
+	ops, err := Diff(map[string]int{"a": 1}, map[string]int{"a": 1})
+	require.NoError(t, err)
+	assert.Empty(t, ops)
+}
+
+func TestLogger_WithDiff(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	logger.WithDiff("config", map[string]string{"level": "info"}, map[string]string{"level": "debug"}).Info("config changed")
+	logger.WithDiff("broken", func() {}, nil).Info("unencodable")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"op": "replace", "path": "/level", "value": "debug", "old": "info"},
+	}, entries[0]["config"])
+	assert.Contains(t, entries[1]["broken"], "<diff error:")
+}
+
+func TestDiff_RootAndNullValues(t *testing.T) {
+	ops, err := Diff("a", "b")
+	require.NoError(t, err)
+	assert.Equal(t, []DiffOp{{Op: "replace", Path: "", Value: "b", Old: "a"}}, ops)
+
+	ops, err = Diff(map[string]interface{}{"a": 1, "b": nil}, map[string]interface{}{"a": nil, "c": nil})
+	require.NoError(t, err)
+	data, err := json.Marshal(ops)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"op":"replace","path":"/a","value":null,"old":1},
+		{"op":"remove","path":"/b","old":null},
+		{"op":"add","path":"/c","value":null}
+	]`, string(data))
+}
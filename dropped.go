@@ -0,0 +1,160 @@
+package logger
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// droppedKey группа отброшенных записей: сервис, уровень и отпечаток сообщения
+type droppedKey struct {
+	service     string
+	level       Level
+	fingerprint string
+}
+
+// droppedGroup счетчик отброшенных записей группы с примером сообщения
+type droppedGroup struct {
+	count   uint64
+	message string
+}
+
+// droppedCounter считает записи, отброшенные семплированием, между итоговыми
+// записями dropped entries
+type droppedCounter struct {
+	mu     sync.Mutex
+	groups map[droppedKey]*droppedGroup
+	since  time.Time
+}
+
+// newDroppedCounter создает пустой счетчик
+func newDroppedCounter() *droppedCounter {
+	return &droppedCounter{groups: make(map[droppedKey]*droppedGroup), since: time.Now()}
+}
+
+// record учитывает отброшенную запись
+func (c *droppedCounter) record(entry *logrus.Entry) {
+	service, _ := entry.Data["service"].(string)
+	key := droppedKey{service: service, level: Level(entry.Level), fingerprint: messageFingerprint(entry.Message)}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	g, ok := c.groups[key]
+	if !ok {
+		g = &droppedGroup{message: entry.Message}
+		c.groups[key] = g
+	}
+	g.count++
+}
+
+// take возвращает накопленные группы и начало окна и начинает новое окно
+func (c *droppedCounter) take() (map[droppedKey]*droppedGroup, time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	groups, since := c.groups, c.since
+	c.groups = make(map[droppedKey]*droppedGroup)
+	c.since = time.Now()
+	return groups, since
+}
+
+// messageFingerprint возвращает отпечаток сообщения: сообщения, отличающиеся
+// только числами (идентификаторами, размерами), попадают в одну группу
+func messageFingerprint(msg string) string {
+	h := fnv.New64a()
+	buf := make([]byte, 0, len(msg))
+	digits := false
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c >= '0' && c <= '9' {
+			if !digits {
+				buf = append(buf, '#')
+			}
+			digits = true
+			continue
+		}
+		digits = false
+		buf = append(buf, c)
+	}
+	h.Write(buf)
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// StartDroppedSummary периодически пишет итоговые записи dropped entries с
+// числом отброшенных семплированием записей по сервису, уровню и отпечатку
+// сообщения, чтобы аналитика могла восстановить настоящее число событий.
+// Возвращает функцию остановки, которая пишет итоги последнего окна
+func (l *Logger) StartDroppedSummary(interval time.Duration) (stop func()) {
+	if l == nil {
+		nilReceiver()
+		return func() {}
+	}
+	counter := newDroppedCounter()
+	l.core.dropped.Store(counter)
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				l.droppedSummary(counter)
+				return
+			case <-ticker.C:
+				l.droppedSummary(counter)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.core.dropped.CompareAndSwap(counter, nil)
+			close(done)
+			wg.Wait()
+		})
+	}
+}
+
+// droppedSummary пишет итоговые записи окна, по одной на группу
+func (l *Logger) droppedSummary(counter *droppedCounter) {
+	groups, since := counter.take()
+	keys := make([]droppedKey, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.service != b.service {
+			return a.service < b.service
+		}
+		if a.level != b.level {
+			return a.level < b.level
+		}
+		return a.fingerprint < b.fingerprint
+	})
+
+	window := time.Since(since).Seconds()
+	for _, key := range keys {
+		g := groups[key]
+		l.internalFields().WithFields(logrus.Fields{
+			"service":        key.service,
+			"dropped_level":  key.level.String(),
+			"fingerprint":    key.fingerprint,
+			"dropped_count":  g.count,
+			"sample_message": g.message,
+			"window_s":       window,
+			"sample_rate":    l.core.samplingRate(),
+		}).Log(logrus.Level(InfoLevel), "dropped entries")
+	}
+}
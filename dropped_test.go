@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_DroppedSummary(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	logger.SetSamplingRate(0)
+
+	stop := logger.StartDroppedSummary(time.Hour)
+	orders := logger.WithService("orders")
+	for i := 0; i < 3; i++ {
+		orders.Infof("order %d created", i)
+	}
+	orders.Debug("cache miss")
+	logger.WithService("search").Info("query served")
+	orders.Warn("stock low")
+	stop()
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 4)
+	assert.Equal(t, "stock low", entries[0]["msg"])
+
+	summaries := entries[1:]
+	for _, s := range summaries {
+		assert.Equal(t, "dropped entries", s["msg"])
+		assert.Equal(t, float64(0), s["sample_rate"])
+	}
+	assert.Equal(t, "orders", summaries[0]["service"])
+	assert.Equal(t, "info", summaries[0]["dropped_level"])
+	assert.Equal(t, float64(3), summaries[0]["dropped_count"])
+	assert.Equal(t, "order 0 created", summaries[0]["sample_message"])
+	assert.Equal(t, messageFingerprint("order 0 created"), summaries[0]["fingerprint"])
+	assert.Equal(t, "debug", summaries[1]["dropped_level"])
+	assert.Equal(t, "search", summaries[2]["service"])
+
+	// После остановки записи не считаются
+	orders.Info("order 9 created")
+	assert.Nil(t, logger.core.dropped.Load())
+}
+
+func TestMessageFingerprint(t *testing.T) {
+	assert.Equal(t, messageFingerprint("order 1 created in 15ms"), messageFingerprint("order 42 created in 7ms"))
+	assert.NotEqual(t, messageFingerprint("order 1 created"), messageFingerprint("order 1 deleted"))
+}
@@ -0,0 +1,461 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultElasticsearchIndex шаблон индекса по умолчанию: индекс на день
+	defaultElasticsearchIndex = "logs-%{+yyyy.MM.dd}"
+
+	defaultElasticsearchBatchSize     = 500
+	defaultElasticsearchFlushInterval = time.Second
+	defaultElasticsearchQueueSize     = 10000
+	defaultElasticsearchMaxRetries    = 5
+	defaultElasticsearchRetryBackoff  = 200 * time.Millisecond
+	defaultElasticsearchTimeout       = 10 * time.Second
+
+	// maxElasticsearchRetryBackoff предел паузы между повторами
+	maxElasticsearchRetryBackoff = 30 * time.Second
+)
+
+// elasticsearchDateTokens элементы даты в шаблоне индекса и их раскладка Go
+var elasticsearchDateTokens = []struct{ token, layout string }{
+	{"yyyy", "2006"},
+	{"yy", "06"},
+	{"MM", "01"},
+	{"dd", "02"},
+	{"HH", "15"},
+}
+
+// ElasticsearchConfig настройки вывода в Elasticsearch или OpenSearch
+type ElasticsearchConfig struct {
+	Address string `yaml:"address"` // адрес кластера, например http://es.internal:9200
+	// Index шаблон имени индекса. %{+yyyy.MM.dd} заменяется датой записи в
+	// UTC (yyyy, yy, MM, dd, HH). По умолчанию logs-%{+yyyy.MM.dd}
+	Index    string `yaml:"index"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	APIKey   string `yaml:"api_key"` // заголовок Authorization: ApiKey вместо логина и пароля
+
+	BatchSize     int           `yaml:"batch_size"`     // записей в одном запросе _bulk, по умолчанию 500
+	FlushInterval time.Duration `yaml:"flush_interval"` // по умолчанию 1s
+	// QueueSize предел записей в памяти, ожидающих отправки. Записи сверх
+	// него отбрасываются. По умолчанию 10000
+	QueueSize    int           `yaml:"queue_size"`
+	MaxRetries   int           `yaml:"max_retries"`   // повторов запроса при 429, 5xx и сетевых ошибках, по умолчанию 5
+	RetryBackoff time.Duration `yaml:"retry_backoff"` // первая пауза перед повтором, дальше удваивается; по умолчанию 200ms
+	Timeout      time.Duration `yaml:"timeout"`       // таймаут запроса, по умолчанию 10s
+}
+
+// validate проверяет настройки Elasticsearch
+func (c ElasticsearchConfig) validate() error {
+	var errs []error
+	if c.Address == "" {
+		errs = append(errs, errors.New("elasticsearch address is required"))
+	} else if u, err := url.Parse(c.Address); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("invalid elasticsearch address: %s", c.Address))
+	}
+	if _, err := parseIndexPattern(firstNonEmpty(c.Index, defaultElasticsearchIndex)); err != nil {
+		errs = append(errs, err)
+	}
+	if c.BatchSize < 0 || c.QueueSize < 0 || c.MaxRetries < 0 || c.FlushInterval < 0 || c.RetryBackoff < 0 || c.Timeout < 0 {
+		errs = append(errs, errors.New("elasticsearch limits must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// indexPattern шаблон индекса: постоянные части и раскладки даты
+type indexPattern struct {
+	parts   []string // четные - текст, нечетные - раскладка time.Format
+	dynamic bool
+}
+
+// parseIndexPattern разбирает шаблон индекса с датами %{+...}
+func parseIndexPattern(pattern string) (*indexPattern, error) {
+	p := &indexPattern{}
+	rest := pattern
+	for {
+		start := strings.Index(rest, "%{+")
+		if start < 0 {
+			p.parts = append(p.parts, rest)
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("invalid elasticsearch index %q: unterminated %%{", pattern)
+		}
+		layout, err := dateLayout(rest[start+3 : start+end])
+		if err != nil {
+			return nil, fmt.Errorf("invalid elasticsearch index %q: %w", pattern, err)
+		}
+		p.parts = append(p.parts, rest[:start], layout)
+		p.dynamic = true
+		rest = rest[start+end+1:]
+	}
+	// Проверяется только текст шаблона: yyyy и MM - элементы даты
+	for i := 0; i < len(p.parts); i += 2 {
+		if strings.ToLower(p.parts[i]) != p.parts[i] {
+			return nil, fmt.Errorf("invalid elasticsearch index %q: index names must be lowercase", pattern)
+		}
+	}
+	return p, nil
+}
+
+// dateLayout переводит формат даты вида yyyy.MM.dd в раскладку Go
+func dateLayout(format string) (string, error) {
+	var b strings.Builder
+	for format != "" {
+		matched := false
+		for _, t := range elasticsearchDateTokens {
+			if strings.HasPrefix(format, t.token) {
+				b.WriteString(t.layout)
+				format = format[len(t.token):]
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		switch format[0] {
+		case '.', '-', '_':
+			b.WriteByte(format[0])
+			format = format[1:]
+		default:
+			return "", fmt.Errorf("unsupported date format element at %q", format)
+		}
+	}
+	return b.String(), nil
+}
+
+// index возвращает имя индекса для времени записи
+func (p *indexPattern) index(t time.Time) string {
+	if !p.dynamic {
+		return p.parts[0]
+	}
+	t = t.UTC()
+	var b strings.Builder
+	for i, part := range p.parts {
+		if i%2 == 0 {
+			b.WriteString(part)
+		} else {
+			b.WriteString(t.Format(part))
+		}
+	}
+	return b.String()
+}
+
+// openElasticsearchSink создает назначение с фоновой отправкой в _bulk
+func openElasticsearchSink(config Config) (*elasticsearchConn, *sink, error) {
+	c := config.Elasticsearch
+	if err := c.validate(); err != nil {
+		return nil, nil, err
+	}
+	pattern, _ := parseIndexPattern(firstNonEmpty(c.Index, defaultElasticsearchIndex))
+	inner, err := newFormatter(JSONFormat, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn := newElasticsearchConn(c)
+	formatter := &elasticsearchFormatter{inner: inner, pattern: pattern}
+	return conn, &sink{name: "elasticsearch", writer: conn, formatter: formatter, binary: true}, nil
+}
+
+// elasticsearchFormatter добавляет к записи в JSON строку действия _bulk
+type elasticsearchFormatter struct {
+	inner   logrus.Formatter
+	pattern *indexPattern
+}
+
+// Format возвращает строку действия create и документ
+func (f *elasticsearchFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	doc, err := f.inner.Format(entry)
+	if err != nil {
+		return nil, err
+	}
+	action, err := json.Marshal(map[string]map[string]string{
+		"create": {"_index": f.pattern.index(entry.Time)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(action)+len(doc)+2)
+	out = append(out, action...)
+	out = append(out, '\n')
+	out = append(out, bytes.TrimRight(doc, "\n")...)
+	return append(out, '\n'), nil
+}
+
+// elasticsearchConn копит записи в ограниченной очереди и отправляет их
+// пачками в _bulk из фоновой горутины. Ошибки отправки возвращаются
+// следующим вызовом Write, как у пакетной записи в файл
+type elasticsearchConn struct {
+	config  ElasticsearchConfig
+	bulkURL string
+	client  *http.Client
+
+	queue chan []byte
+	flush chan chan struct{}
+	done  chan struct{}
+
+	mu      sync.Mutex
+	err     error
+	dropped int
+	closed  bool
+}
+
+// newElasticsearchConn заполняет значения по умолчанию и запускает отправку
+func newElasticsearchConn(c ElasticsearchConfig) *elasticsearchConn {
+	if c.BatchSize == 0 {
+		c.BatchSize = defaultElasticsearchBatchSize
+	}
+	if c.FlushInterval == 0 {
+		c.FlushInterval = defaultElasticsearchFlushInterval
+	}
+	if c.QueueSize == 0 {
+		c.QueueSize = defaultElasticsearchQueueSize
+	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = defaultElasticsearchMaxRetries
+	}
+	if c.RetryBackoff == 0 {
+		c.RetryBackoff = defaultElasticsearchRetryBackoff
+	}
+	if c.Timeout == 0 {
+		c.Timeout = defaultElasticsearchTimeout
+	}
+	conn := &elasticsearchConn{
+		config:  c,
+		bulkURL: strings.TrimRight(c.Address, "/") + "/_bulk",
+		client:  &http.Client{Timeout: c.Timeout},
+		queue:   make(chan []byte, c.QueueSize),
+		flush:   make(chan chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go conn.run()
+	return conn
+}
+
+// Write ставит запись в очередь. При заполненной очереди запись
+// отбрасывается, число отброшенных записей сообщается ошибкой
+func (c *elasticsearchConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return 0, errors.New("elasticsearch output is closed")
+	}
+	select {
+	case c.queue <- append([]byte(nil), p...):
+	default:
+		c.dropped++
+	}
+	return len(p), c.takeErrLocked()
+}
+
+// takeErrLocked возвращает накопленную ошибку отправки и сбрасывает ее
+func (c *elasticsearchConn) takeErrLocked() error {
+	err := c.err
+	if c.dropped > 0 {
+		err = errors.Join(err, fmt.Errorf("elasticsearch queue is full: %d entries dropped", c.dropped))
+		c.dropped = 0
+	}
+	c.err = nil
+	return err
+}
+
+// setErr запоминает ошибку фоновой отправки
+func (c *elasticsearchConn) setErr(err error) {
+	c.mu.Lock()
+	c.err = errors.Join(c.err, err)
+	c.mu.Unlock()
+}
+
+// run собирает пачки из очереди и отправляет их по размеру, по таймеру
+// и по запросу Sync
+func (c *elasticsearchConn) run() {
+	defer close(c.done)
+	ticker := time.NewTicker(c.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([][]byte, 0, c.config.BatchSize)
+	send := func() {
+		if len(batch) > 0 {
+			c.send(batch)
+			batch = batch[:0]
+		}
+	}
+	for {
+		select {
+		case doc, ok := <-c.queue:
+			if !ok {
+				send()
+				return
+			}
+			batch = append(batch, doc)
+			if len(batch) >= c.config.BatchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case ack := <-c.flush:
+			for drained := false; !drained; {
+				select {
+				case doc := <-c.queue:
+					batch = append(batch, doc)
+					if len(batch) >= c.config.BatchSize {
+						send()
+					}
+				default:
+					drained = true
+				}
+			}
+			send()
+			close(ack)
+		}
+	}
+}
+
+// send отправляет пачку, повторяя записи, которые можно повторить, с
+// экспоненциальной паузой
+func (c *elasticsearchConn) send(docs [][]byte) {
+	backoff := c.config.RetryBackoff
+	for attempt := 0; len(docs) > 0; attempt++ {
+		retry, cause, err := c.bulk(docs)
+		if err != nil {
+			c.setErr(err)
+		}
+		if len(retry) == 0 {
+			return
+		}
+		if attempt == c.config.MaxRetries {
+			c.setErr(fmt.Errorf("elasticsearch bulk: %d entries dropped after %d retries: %w", len(retry), attempt, cause))
+			return
+		}
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxElasticsearchRetryBackoff)
+		docs = retry
+	}
+}
+
+// elasticsearchBulkResponse ответ _bulk: результат по каждому документу
+type elasticsearchBulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// bulk отправляет документы одним запросом и возвращает документы для
+// повтора с причиной повтора. Ошибка err описывает документы, которые
+// повторять бесполезно
+func (c *elasticsearchConn) bulk(docs [][]byte) (retry [][]byte, cause, err error) {
+	req, err := http.NewRequest(http.MethodPost, c.bulkURL, bytes.NewReader(bytes.Join(docs, nil)))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	switch {
+	case c.config.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+c.config.APIKey)
+	case c.config.Username != "":
+		req.SetBasicAuth(c.config.Username, c.config.Password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return docs, err, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return docs, fmt.Errorf("unexpected status: %s", resp.Status), nil
+	}
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, nil, fmt.Errorf("elasticsearch bulk: unexpected status %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+
+	var result elasticsearchBulkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, nil, fmt.Errorf("elasticsearch bulk: decode response: %w", err)
+	}
+	if !result.Errors {
+		return nil, nil, nil
+	}
+	rejected := 0
+	var reason string
+	for i, item := range result.Items {
+		if i >= len(docs) {
+			break
+		}
+		for _, r := range item {
+			switch {
+			case r.Status == http.StatusTooManyRequests || r.Status >= 500:
+				retry = append(retry, docs[i])
+				cause = fmt.Errorf("entry status %d", r.Status)
+			case r.Status >= 300:
+				rejected++
+				if reason == "" && r.Error != nil {
+					reason = r.Error.Type + ": " + r.Error.Reason
+				}
+			}
+		}
+	}
+	if rejected > 0 {
+		err = fmt.Errorf("elasticsearch bulk: %d entries rejected: %s", rejected, reason)
+	}
+	return retry, cause, err
+}
+
+// Sync отправляет накопленные записи и возвращает ошибки отправки
+func (c *elasticsearchConn) Sync() error {
+	ack := make(chan struct{})
+	select {
+	case c.flush <- ack:
+		<-ack
+	case <-c.done:
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.takeErrLocked()
+}
+
+// Reopen ничего не делает: каждый запрос открывает соединение заново
+// при необходимости
+func (c *elasticsearchConn) Reopen() error {
+	return nil
+}
+
+// Close отправляет записи из очереди и останавливает отправку
+func (c *elasticsearchConn) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	close(c.queue)
+	c.mu.Unlock()
+
+	<-c.done
+	c.client.CloseIdleConnections()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.takeErrLocked()
+}
@@ -0,0 +1,238 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bulkServer тестовый _bulk: запоминает документы по индексам и отвечает
+// статусами из respond
+type bulkServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests int
+	docs     map[string][]map[string]interface{}
+	auth     string
+	respond  func(request, item int) int
+}
+
+func newBulkServer(t *testing.T, respond func(request, item int) int) *bulkServer {
+	s := &bulkServer{docs: make(map[string][]map[string]interface{}), respond: respond}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *bulkServer) handle(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/_bulk" || r.Header.Get("Content-Type") != "application/x-ndjson" {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	s.auth = r.Header.Get("Authorization")
+
+	var items []string
+	failed := false
+	sc := bufio.NewScanner(r.Body)
+	for i := 0; sc.Scan(); i++ {
+		var action map[string]map[string]string
+		if err := json.Unmarshal(sc.Bytes(), &action); err != nil || !sc.Scan() {
+			http.Error(w, "bad action", http.StatusBadRequest)
+			return
+		}
+		status := http.StatusCreated
+		if s.respond != nil {
+			status = s.respond(s.requests, i)
+		}
+		if status == http.StatusCreated {
+			var doc map[string]interface{}
+			_ = json.Unmarshal(sc.Bytes(), &doc)
+			index := action["create"]["_index"]
+			s.docs[index] = append(s.docs[index], doc)
+			items = append(items, `{"create":{"status":201}}`)
+			continue
+		}
+		failed = true
+		items = append(items, fmt.Sprintf(`{"create":{"status":%d,"error":{"type":"mapper_parsing_exception","reason":"failed to parse"}}}`, status))
+	}
+	fmt.Fprintf(w, `{"errors":%t,"items":[%s]}`, failed, strings.Join(items, ","))
+}
+
+func (s *bulkServer) indexed(index string) []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.docs[index]
+}
+
+func TestIndexPattern(t *testing.T) {
+	at := time.Date(2024, 1, 15, 23, 30, 0, 0, time.FixedZone("MSK", 3*3600))
+
+	p, err := parseIndexPattern("logs-%{+yyyy.MM.dd}")
+	require.NoError(t, err)
+	assert.Equal(t, "logs-2024.01.15", p.index(at))
+
+	p, err = parseIndexPattern("app-%{+yy_MM}-%{+HH}")
+	require.NoError(t, err)
+	assert.Equal(t, "app-24_01-20", p.index(at))
+
+	p, err = parseIndexPattern("logs")
+	require.NoError(t, err)
+	assert.Equal(t, "logs", p.index(at))
+
+	_, err = parseIndexPattern("logs-%{+yyyy.MM.dd")
+	assert.ErrorContains(t, err, "unterminated")
+	_, err = parseIndexPattern("logs-%{+yyyy.Www}")
+	assert.ErrorContains(t, err, "unsupported date format element")
+	_, err = parseIndexPattern("Logs-%{+yyyy}")
+	assert.ErrorContains(t, err, "lowercase")
+}
+
+func TestElasticsearchOutput(t *testing.T) {
+	server := newBulkServer(t, nil)
+
+	log, err := New(Config{
+		Level:  InfoLevel,
+		Output: ElasticsearchOutput,
+		Elasticsearch: ElasticsearchConfig{
+			Address: server.URL,
+			Index:   "orders-%{+yyyy.MM.dd}",
+			APIKey:  "secret",
+		},
+	})
+	require.NoError(t, err)
+
+	log.WithField("order_id", 42).Info("order created")
+	log.Warn("payment delayed")
+	require.NoError(t, log.Sync())
+
+	docs := server.indexed("orders-" + time.Now().UTC().Format("2006.01.02"))
+	require.Len(t, docs, 2)
+	assert.Equal(t, "order created", docs[0]["msg"])
+	assert.EqualValues(t, 42, docs[0]["order_id"])
+	assert.Equal(t, "payment delayed", docs[1]["msg"])
+	assert.Equal(t, "ApiKey secret", server.auth)
+	require.NoError(t, log.Close())
+}
+
+// stubExit подменяет завершение процесса после Fatal и возвращает код выхода
+func stubExit(t *testing.T) *int {
+	code := -1
+	exit = func(c int) { code = c }
+	t.Cleanup(func() { exit = os.Exit })
+	return &code
+}
+
+func TestElasticsearchOutput_FatalFlushesQueue(t *testing.T) {
+	server := newBulkServer(t, nil)
+	code := stubExit(t)
+
+	log, err := New(Config{
+		Level:         InfoLevel,
+		Output:        ElasticsearchOutput,
+		Elasticsearch: ElasticsearchConfig{Address: server.URL, Index: "logs", FlushInterval: time.Hour},
+	})
+	require.NoError(t, err)
+
+	log.Info("queued")
+	log.Fatal("shutting down")
+
+	assert.Equal(t, 1, *code)
+	docs := server.indexed("logs")
+	require.Len(t, docs, 2)
+	assert.Equal(t, "queued", docs[0]["msg"])
+	assert.Equal(t, "shutting down", docs[1]["msg"])
+}
+
+func TestElasticsearchConn_Retry(t *testing.T) {
+	// Первый запрос целиком получает 429, во втором второй документ
+	// отклоняется, а третий снова получает 429
+	server := newBulkServer(t, func(request, item int) int {
+		switch {
+		case request == 1:
+			return http.StatusTooManyRequests
+		case request == 2 && item == 1:
+			return http.StatusBadRequest
+		case request == 2 && item == 2:
+			return http.StatusTooManyRequests
+		}
+		return http.StatusCreated
+	})
+	conn := newElasticsearchConn(ElasticsearchConfig{Address: server.URL, RetryBackoff: time.Millisecond})
+
+	for i := 0; i < 3; i++ {
+		_, err := conn.Write([]byte(fmt.Sprintf("{\"create\":{\"_index\":\"logs\"}}\n{\"n\":%d}\n", i)))
+		require.NoError(t, err)
+	}
+	err := conn.Sync()
+	assert.ErrorContains(t, err, "1 entries rejected: mapper_parsing_exception")
+
+	docs := server.indexed("logs")
+	require.Len(t, docs, 2)
+	assert.EqualValues(t, 0, docs[0]["n"])
+	assert.EqualValues(t, 2, docs[1]["n"])
+	require.NoError(t, conn.Close())
+	assert.Equal(t, 3, server.requests)
+}
+
+func TestElasticsearchConn_RetriesExhausted(t *testing.T) {
+	server := newBulkServer(t, func(int, int) int { return http.StatusServiceUnavailable })
+	conn := newElasticsearchConn(ElasticsearchConfig{Address: server.URL, MaxRetries: 2, RetryBackoff: time.Millisecond})
+
+	_, err := conn.Write([]byte("{\"create\":{\"_index\":\"logs\"}}\n{}\n"))
+	require.NoError(t, err)
+	assert.ErrorContains(t, conn.Close(), "1 entries dropped after 2 retries")
+	assert.Equal(t, 3, server.requests)
+}
+
+func TestElasticsearchConn_QueueFull(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		_, _ = io.Copy(io.Discard, r.Body)
+		fmt.Fprint(w, `{"errors":false,"items":[]}`)
+	}))
+	defer server.Close()
+	conn := newElasticsearchConn(ElasticsearchConfig{Address: server.URL, BatchSize: 1, QueueSize: 1})
+
+	doc := []byte("{\"create\":{\"_index\":\"logs\"}}\n{}\n")
+	_, err := conn.Write(doc)
+	require.NoError(t, err)
+	// Первая запись отправляется, вторая ждет в очереди, третья отбрасывается
+	require.Eventually(t, func() bool { return len(conn.queue) == 0 }, time.Second, time.Millisecond)
+	_, err = conn.Write(doc)
+	require.NoError(t, err)
+	_, err = conn.Write(doc)
+	assert.ErrorContains(t, err, "elasticsearch queue is full: 1 entries dropped")
+
+	close(release)
+	assert.NoError(t, conn.Close())
+}
+
+func TestElasticsearchConfig_Validate(t *testing.T) {
+	err := Config{
+		Output:        ElasticsearchOutput,
+		Elasticsearch: ElasticsearchConfig{Address: "es.internal:9200", Index: "logs-%{+ww}", QueueSize: -1},
+	}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid elasticsearch address: es.internal:9200")
+	assert.Contains(t, err.Error(), "unsupported date format element")
+	assert.Contains(t, err.Error(), "elasticsearch limits must not be negative")
+
+	err = Config{Output: ElasticsearchOutput}.Validate()
+	assert.ErrorContains(t, err, "elasticsearch address is required")
+}
@@ -0,0 +1,172 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxCycleCheckNodes ограничивает обход значения при поиске циклов,
+// чтобы проверка больших полей не замедляла логирование
+const maxCycleCheckNodes = 10000
+
+// safeFormat форматирует запись, защищаясь от паник в String()/MarshalJSON.
+// Самоссылающиеся значения должны быть заменены заранее через withoutCycles
+func safeFormat(f logrus.Formatter, entry *logrus.Entry) ([]byte, error) {
+	data, err := tryFormat(f, entry)
+	if err == nil {
+		return data, nil
+	}
+
+	// Заменяем поля, которые не удается закодировать, и пробуем еще раз
+	entry = replaceFields(entry, probeValue)
+	return tryFormat(f, entry)
+}
+
+// tryFormat вызывает форматтер, превращая панику в ошибку
+func tryFormat(f logrus.Formatter, entry *logrus.Entry) (data []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("formatter panic: %v", r)
+		}
+	}()
+	return f.Format(entry)
+}
+
+// probeValue проверяет, что значение кодируется без паник и ошибок
+func probeValue(v interface{}) (err error) {
+	// Ошибки форматтеры выводят через Error()
+	if e, ok := v.(error); ok {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		_ = e.Error()
+		return nil
+	}
+
+	_, err = safeMarshal(v)
+	return err
+}
+
+// safeMarshal кодирует значение в JSON, превращая панику в ошибку
+func safeMarshal(v interface{}) (data []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return json.Marshal(v)
+}
+
+// replaceFields возвращает копию записи, в которой поля, не прошедшие
+// проверку, заменены описанием ошибки. Исходная запись не меняется
+func replaceFields(entry *logrus.Entry, check func(v interface{}) error) *logrus.Entry {
+	return transformFields(entry, func(key string, value interface{}) (interface{}, bool) {
+		if err := check(value); err != nil {
+			return fmt.Sprintf("<encoding error: %v>", err), true
+		}
+		return value, false
+	})
+}
+
+// transformFields возвращает копию записи с измененными значениями полей.
+// Если ни одно поле не изменилось, возвращается исходная запись. Исходная
+// запись не меняется, так как она может использоваться хуками
+func transformFields(entry *logrus.Entry, fn func(key string, value interface{}) (interface{}, bool)) *logrus.Entry {
+	var data logrus.Fields
+	for key, value := range entry.Data {
+		newValue, changed := fn(key, value)
+		if !changed {
+			continue
+		}
+		if data == nil {
+			data = make(logrus.Fields, len(entry.Data))
+			for k, v := range entry.Data {
+				data[k] = v
+			}
+		}
+		data[key] = newValue
+	}
+	if data == nil {
+		return entry
+	}
+
+	transformed := *entry
+	transformed.Data = data
+	return &transformed
+}
+
+// withoutCycles заменяет самоссылающиеся значения полей описанием ошибки:
+// текстовые форматтеры ушли бы на них в бесконечную рекурсию. Обход идет
+// рефлексией, поэтому вызывается один раз на запись до записи в назначения
+func withoutCycles(entry *logrus.Entry) *logrus.Entry {
+	return replaceFields(entry, func(v interface{}) error {
+		if hasCycle(v) {
+			return fmt.Errorf("cycle detected in %T", v)
+		}
+		return nil
+	})
+}
+
+// hasCycle проверяет, ссылается ли значение само на себя
+func hasCycle(v interface{}) bool {
+	// Частые скалярные значения не могут содержать ссылок
+	switch v.(type) {
+	case nil, string, bool, int, int64, int32, uint, uint64, uint32, float64, float32,
+		time.Time, time.Duration, []byte:
+		return false
+	}
+	budget := maxCycleCheckNodes
+	return walkCycle(reflect.ValueOf(v), make(map[uintptr]bool), &budget)
+}
+
+// walkCycle обходит значение, отслеживая ссылки на текущем пути обхода
+func walkCycle(v reflect.Value, path map[uintptr]bool, budget *int) bool {
+	if *budget <= 0 || !v.IsValid() {
+		return false
+	}
+	*budget--
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return false
+		}
+		ptr := v.Pointer()
+		if path[ptr] {
+			return true
+		}
+		path[ptr] = true
+		defer delete(path, ptr)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return walkCycle(v.Elem(), path, budget)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if walkCycle(v.Field(i), path, budget) {
+				return true
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if walkCycle(iter.Value(), path, budget) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if walkCycle(v.Index(i), path, budget) {
+				return true
+			}
+		}
+	}
+	return false
+}
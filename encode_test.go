@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// panicMarshaler значение, чей MarshalJSON паникует
+type panicMarshaler struct{}
+
+func (panicMarshaler) MarshalJSON() ([]byte, error) {
+	panic("boom")
+}
+
+// panicStringer значение, чей String паникует
+type panicStringer struct{}
+
+func (panicStringer) String() string {
+	panic("boom")
+}
+
+// node самоссылающаяся структура
+type node struct {
+	Name   string
+	Parent *node
+	Nodes  []*node
+}
+
+func TestLogger_PanickingMarshaler(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	assert.NotPanics(t, func() {
+		logger.WithFields(map[string]interface{}{
+			"bad":  panicMarshaler{},
+			"good": "value",
+		}).Info("message")
+	})
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Contains(t, entries[0]["bad"], "<encoding error: panic: boom")
+	assert.Equal(t, "value", entries[0]["good"])
+}
+
+func TestLogger_PanickingStringer_Text(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	setTestSink(logger, buf, &logrus.TextFormatter{})
+
+	assert.NotPanics(t, func() {
+		logger.WithField("bad", panicStringer{}).Info("message")
+	})
+	assert.Contains(t, buf.String(), "message")
+}
+
+func TestLogger_CyclicValue(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	root := &node{Name: "root"}
+	root.Nodes = []*node{{Name: "child", Parent: root}}
+
+	selfMap := map[string]interface{}{}
+	selfMap["self"] = selfMap
+
+	logger.WithFields(map[string]interface{}{
+		"tree": root,
+		"map":  selfMap,
+	}).Info("message")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Contains(t, entries[0]["tree"], "<encoding error: cycle detected")
+	assert.Contains(t, entries[0]["map"], "<encoding error: cycle detected")
+}
+
+func TestLogger_CyclicValueTextSinks(t *testing.T) {
+	jsonBuf, textBuf := &bytes.Buffer{}, &bytes.Buffer{}
+	logger, err := New(Config{Level: InfoLevel, Destinations: []Destination{
+		{Writer: jsonBuf, Format: "json"},
+		{Writer: textBuf, Format: "text"},
+	}})
+	require.NoError(t, err)
+
+	selfMap := map[string]interface{}{}
+	selfMap["self"] = selfMap
+	logger.WithField("map", selfMap).Info("message")
+
+	assert.Contains(t, jsonBuf.String(), "cycle detected")
+	assert.Contains(t, textBuf.String(), "cycle detected")
+}
+
+func TestHasCycle(t *testing.T) {
+	shared := &node{Name: "shared"}
+	assert.False(t, hasCycle([]*node{shared, shared}))
+	assert.False(t, hasCycle(nil))
+	assert.False(t, hasCycle("plain"))
+
+	loop := &node{Name: "loop"}
+	loop.Parent = loop
+	assert.True(t, hasCycle(loop))
+}
@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Entry запись лога только для чтения. Методы With* не меняют запись, а
+// возвращают новую с копией полей (copy-on-write), поэтому запись можно
+// сохранять и передавать между горутинами. Значения полей не копируются:
+// вложенные map и срезы менять нельзя
+type Entry struct {
+	time    time.Time
+	level   Level
+	message string
+	fields  map[string]interface{}
+	ctx     context.Context
+}
+
+// NewEntry создает запись с копией полей fields. Нужна в основном для
+// тестов hooks
+func NewEntry(t time.Time, level Level, message string, fields map[string]interface{}) Entry {
+	copied := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		copied[k] = v
+	}
+	return Entry{time: t, level: level, message: message, fields: copied}
+}
+
+// entryView возвращает запись logrus только для чтения. Поля не копируются:
+// записи logrus в конвейере логгера меняются только через копию
+func entryView(entry *logrus.Entry) Entry {
+	return Entry{
+		time:    entry.Time,
+		level:   Level(entry.Level),
+		message: entry.Message,
+		fields:  entry.Data,
+		ctx:     entryContext(entry),
+	}
+}
+
+// Time возвращает время записи
+func (e Entry) Time() time.Time {
+	return e.time
+}
+
+// Level возвращает уровень записи
+func (e Entry) Level() Level {
+	return e.level
+}
+
+// Message возвращает сообщение записи
+func (e Entry) Message() string {
+	return e.message
+}
+
+// Context возвращает контекст записи или nil
+func (e Entry) Context() context.Context {
+	return e.ctx
+}
+
+// Field возвращает значение поля
+func (e Entry) Field(key string) (interface{}, bool) {
+	v, ok := e.fields[key]
+	return v, ok
+}
+
+// Fields возвращает копию полей записи
+func (e Entry) Fields() map[string]interface{} {
+	fields := make(map[string]interface{}, len(e.fields))
+	for k, v := range e.fields {
+		fields[k] = v
+	}
+	return fields
+}
+
+// Len возвращает число полей записи
+func (e Entry) Len() int {
+	return len(e.fields)
+}
+
+// Range вызывает fn для каждого поля, пока fn возвращает true. Порядок
+// полей не определен
+func (e Entry) Range(fn func(key string, value interface{}) bool) {
+	for k, v := range e.fields {
+		if !fn(k, v) {
+			return
+		}
+	}
+}
+
+// WithField возвращает запись с добавленным или замененным полем
+func (e Entry) WithField(key string, value interface{}) Entry {
+	return e.WithFields(map[string]interface{}{key: value})
+}
+
+// WithFields возвращает запись с добавленными или замененными полями
+func (e Entry) WithFields(fields map[string]interface{}) Entry {
+	copied := make(map[string]interface{}, len(e.fields)+len(fields))
+	for k, v := range e.fields {
+		copied[k] = v
+	}
+	for k, v := range fields {
+		copied[k] = v
+	}
+	e.fields = copied
+	return e
+}
+
+// WithoutField возвращает запись без поля key
+func (e Entry) WithoutField(key string) Entry {
+	if _, ok := e.fields[key]; !ok {
+		return e
+	}
+	copied := make(map[string]interface{}, len(e.fields))
+	for k, v := range e.fields {
+		if k != key {
+			copied[k] = v
+		}
+	}
+	e.fields = copied
+	return e
+}
+
+// WithContext возвращает запись с контекстом ctx
+func (e Entry) WithContext(ctx context.Context) Entry {
+	e.ctx = ctx
+	return e
+}
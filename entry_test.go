@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntry_CopyOnWrite(t *testing.T) {
+	fields := map[string]interface{}{"order_id": 42, "token": "secret"}
+	entry := NewEntry(time.Unix(0, 0), WarnLevel, "payment delayed", fields)
+	fields["order_id"] = 0
+
+	enriched := entry.WithField("region", "eu-west-1").WithoutField("token")
+	_, ok := entry.Field("region")
+	assert.False(t, ok)
+	token, _ := entry.Field("token")
+	assert.Equal(t, "secret", token)
+	order, _ := entry.Field("order_id")
+	assert.Equal(t, 42, order)
+
+	assert.Equal(t, map[string]interface{}{"order_id": 42, "region": "eu-west-1"}, enriched.Fields())
+	assert.Equal(t, WarnLevel, enriched.Level())
+	assert.Equal(t, "payment delayed", enriched.Message())
+
+	copied := entry.Fields()
+	copied["order_id"] = 7
+	order, _ = entry.Field("order_id")
+	assert.Equal(t, 42, order)
+
+	keys := 0
+	entry.Range(func(string, interface{}) bool {
+		keys++
+		return false
+	})
+	assert.Equal(t, 1, keys)
+	assert.Equal(t, 2, entry.Len())
+}
+
+// mutatingFormatter форматтер, который портит запись, как неаккуратный
+// сторонний форматтер
+type mutatingFormatter struct{}
+
+func (mutatingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	entry.Message = "mutated"
+	entry.Level = logrus.PanicLevel
+	entry.Data = logrus.Fields{"injected": true}
+	return nil, nil
+}
+
+func TestDispatcher_SinkEntryIsolated(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+
+	out := &bytes.Buffer{}
+	logger.logger.SetFormatter(&dispatcher{sinks: []*sink{
+		{writer: &bytes.Buffer{}, formatter: mutatingFormatter{}},
+		{writer: out, formatter: &logrus.JSONFormatter{}},
+	}})
+
+	logger.WithField("order_id", 42).Info("payment processed")
+
+	entries := decodeEntries(t, out)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "payment processed", entries[0]["msg"])
+	assert.Equal(t, "info", entries[0]["level"])
+	assert.EqualValues(t, 42, entries[0]["order_id"])
+	assert.NotContains(t, entries[0], "injected")
+}
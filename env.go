@@ -0,0 +1,255 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewFromEnv создает логгер по переменным окружения с префиксом prefix:
+// для префикса LOG читаются LOG_LEVEL, LOG_OUTPUT, LOG_FILE_PATH, LOG_FORMAT и др.
+// Без LOG_OUTPUT логгер пишет в консоль
+func NewFromEnv(prefix string) (*Logger, error) {
+	config, err := ConfigFromEnv(prefix)
+	if err != nil {
+		return nil, err
+	}
+	return New(config)
+}
+
+// ConfigFromEnv читает и проверяет конфигурацию из переменных окружения.
+// Названия переменных совпадают с ключами YAML в верхнем регистре. С пустым
+// префиксом читаются LEVEL, OUTPUT и др.
+func ConfigFromEnv(prefix string) (Config, error) {
+	if prefix = strings.TrimSuffix(prefix, "_"); prefix != "" {
+		prefix += "_"
+	}
+	e := envReader{prefix: prefix}
+
+	config := Config{
+		Level:  InfoLevel,
+		Output: ConsoleOutput,
+	}
+
+	if v, ok := e.lookup("LEVEL"); ok {
+		level, err := ParseLevel(v)
+		if err != nil {
+			e.errs = append(e.errs, fmt.Errorf("%sLEVEL: %w", e.prefix, err))
+		} else {
+			config.Level = level
+		}
+	}
+	if v, ok := e.lookup("SERVICE_LEVELS"); ok {
+		levels, err := ParseServiceLevels(v)
+		if err != nil {
+			e.errs = append(e.errs, fmt.Errorf("%sSERVICE_LEVELS: %w", e.prefix, err))
+		} else {
+			config.ServiceLevels = levels
+		}
+	}
+	if v, ok := e.lookup("POOL_LEVELS"); ok {
+		levels, err := ParseServiceLevels(v)
+		if err != nil {
+			e.errs = append(e.errs, fmt.Errorf("%sPOOL_LEVELS: %w", e.prefix, err))
+		} else {
+			config.PoolLevels = levels
+		}
+	}
+	if v, ok := e.lookup("OUTPUT"); ok {
+		config.Output = OutputType(v)
+	}
+	e.string("FILE_PATH", &config.FilePath)
+	e.string("FORMAT", &config.Format)
+	e.string("CONSOLE_FORMAT", &config.ConsoleFormat)
+	e.string("FILE_FORMAT", &config.FileFormat)
+	e.string("CONSOLE_LEVEL", &config.ConsoleLevel)
+	e.string("FILE_LEVEL", &config.FileLevel)
+	e.string("CONSOLE_FILTER", &config.ConsoleFilter)
+	e.string("FILE_FILTER", &config.FileFilter)
+	e.string("SYSLOG_NETWORK", &config.Syslog.Network)
+	e.string("SYSLOG_ADDRESS", &config.Syslog.Address)
+	e.string("SYSLOG_FACILITY", &config.Syslog.Facility)
+	e.string("SYSLOG_TAG", &config.Syslog.Tag)
+	e.string("SYSLOG_PROTOCOL", &config.Syslog.Protocol)
+	e.string("JOURNALD_SOCKET", &config.Journald.Socket)
+	e.string("JOURNALD_IDENTIFIER", &config.Journald.Identifier)
+	e.string("EVENTLOG_SOURCE", &config.EventLog.Source)
+	e.bool("EVENTLOG_INSTALL", &config.EventLog.Install)
+	e.string("GELF_NETWORK", &config.GELF.Network)
+	e.string("GELF_ADDRESS", &config.GELF.Address)
+	e.string("GELF_HOST", &config.GELF.Host)
+	e.string("GELF_COMPRESSION", &config.GELF.Compression)
+	e.int("GELF_CHUNK_SIZE", &config.GELF.ChunkSize)
+	e.string("ELASTICSEARCH_ADDRESS", &config.Elasticsearch.Address)
+	e.string("ELASTICSEARCH_INDEX", &config.Elasticsearch.Index)
+	e.string("ELASTICSEARCH_USERNAME", &config.Elasticsearch.Username)
+	e.string("ELASTICSEARCH_PASSWORD", &config.Elasticsearch.Password)
+	e.string("ELASTICSEARCH_API_KEY", &config.Elasticsearch.APIKey)
+	e.int("ELASTICSEARCH_BATCH_SIZE", &config.Elasticsearch.BatchSize)
+	e.duration("ELASTICSEARCH_FLUSH_INTERVAL", &config.Elasticsearch.FlushInterval)
+	e.int("ELASTICSEARCH_QUEUE_SIZE", &config.Elasticsearch.QueueSize)
+	e.string("CLOUDWATCH_REGION", &config.CloudWatch.Region)
+	e.string("CLOUDWATCH_LOG_GROUP", &config.CloudWatch.LogGroup)
+	e.string("CLOUDWATCH_LOG_STREAM", &config.CloudWatch.LogStream)
+	e.string("CLOUDWATCH_ENDPOINT", &config.CloudWatch.Endpoint)
+	e.int("CLOUDWATCH_BATCH_SIZE", &config.CloudWatch.BatchSize)
+	e.duration("CLOUDWATCH_FLUSH_INTERVAL", &config.CloudWatch.FlushInterval)
+	e.int("CLOUDWATCH_QUEUE_SIZE", &config.CloudWatch.QueueSize)
+	e.string("SPLUNK_ADDRESS", &config.Splunk.Address)
+	e.string("SPLUNK_TOKEN", &config.Splunk.Token)
+	e.string("SPLUNK_INDEX", &config.Splunk.Index)
+	e.string("SPLUNK_SOURCE", &config.Splunk.Source)
+	e.string("SPLUNK_SOURCETYPE", &config.Splunk.SourceType)
+	e.int("SPLUNK_BATCH_SIZE", &config.Splunk.BatchSize)
+	e.duration("SPLUNK_FLUSH_INTERVAL", &config.Splunk.FlushInterval)
+	e.int("SPLUNK_QUEUE_SIZE", &config.Splunk.QueueSize)
+	e.string("DATADOG_API_KEY", &config.Datadog.APIKey)
+	e.string("DATADOG_SITE", &config.Datadog.Site)
+	e.string("DATADOG_SERVICE", &config.Datadog.Service)
+	e.string("DATADOG_SOURCE", &config.Datadog.Source)
+	e.string("DATADOG_TAGS", &config.Datadog.Tags)
+	e.int("DATADOG_BATCH_SIZE", &config.Datadog.BatchSize)
+	e.duration("DATADOG_FLUSH_INTERVAL", &config.Datadog.FlushInterval)
+	e.int("DATADOG_QUEUE_SIZE", &config.Datadog.QueueSize)
+	e.int("SPOOL_SIZE", &config.SpoolSize)
+	e.bool("SPLIT_STDERR", &config.SplitStdErr)
+
+	e.bool("DISABLE_DIR_CREATION", &config.DisableDirCreation)
+	e.int("MAX_SIZE_MB", &config.MaxSizeMB)
+	e.int("MAX_BACKUPS", &config.MaxBackups)
+	e.bool("COMPRESS", &config.Compress)
+	e.string("COMPRESS_CODEC", &config.CompressCodec)
+	e.int("MAX_AGE", &config.MaxAge)
+	e.int("MAX_TOTAL_SIZE_MB", &config.MaxTotalSizeMB)
+	if v, ok := e.lookup("ROTATION"); ok {
+		config.Rotation = RotationPolicy(v)
+	}
+	e.string("RING_FILE_PATH", &config.RingFilePath)
+	e.int("RING_FILE_SIZE_MB", &config.RingFileSizeMB)
+	e.string("RING_FILE_LEVEL", &config.RingFileLevel)
+	e.int("FILE_BATCH_ENTRIES", &config.FileBatchEntries)
+	e.duration("FILE_BATCH_INTERVAL", &config.FileBatchInterval)
+
+	e.bool("REDACT", &config.Redact)
+	e.bool("CHECKSUM", &config.Checksum)
+	if v, ok := e.lookup("REDACT_KEYS"); ok {
+		config.RedactKeys = splitList(v)
+	}
+	e.int("MAX_FIELD_SIZE", &config.MaxFieldSize)
+	e.int("FIELD_PREVIEW_BYTES", &config.FieldPreviewBytes)
+	e.duration("HEARTBEAT_INTERVAL", &config.HeartbeatInterval)
+	e.duration("DROPPED_SUMMARY_INTERVAL", &config.DroppedSummaryInterval)
+	e.duration("RESOURCE_ANNOTATION_INTERVAL", &config.ResourceAnnotationInterval)
+	e.duration("ERROR_DEBUG_WINDOW", &config.ErrorDebugWindow)
+	e.duration("ERROR_DEBUG_COOLDOWN", &config.ErrorDebugCooldown)
+	e.duration("SLOW_SINK_THRESHOLD", &config.SlowSinkThreshold)
+	e.bool("DEVELOPMENT", &config.Development)
+	e.int("MISUSE_MAX_FIELDS", &config.MisuseMaxFields)
+	e.int("MISUSE_MAX_COLLECTION", &config.MisuseMaxCollection)
+	if v, ok := e.lookup("DEFAULT_FIELDS"); ok {
+		config.DefaultFields = make(map[string]interface{})
+		for _, pair := range splitList(v) {
+			key, value, found := strings.Cut(pair, "=")
+			if !found || strings.TrimSpace(key) == "" {
+				e.errs = append(e.errs, fmt.Errorf("%sDEFAULT_FIELDS: invalid pair %q", e.prefix, pair))
+				continue
+			}
+			config.DefaultFields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	e.bool("PROCESS_FIELDS", &config.ProcessFields)
+	e.string("APP_NAME", &config.AppName)
+	e.string("APP_VERSION", &config.AppVersion)
+	e.bool("REPORT_CALLER", &config.ReportCaller)
+	if v, ok := e.lookup("SERVICE_NAME_MODE"); ok {
+		config.ServiceNameMode = ServiceNameMode(v)
+	}
+	e.int("MAX_SERVICE_NAME_LENGTH", &config.MaxServiceNameLength)
+	e.bool("CALLER_FULL_PATH", &config.CallerFullPath)
+	if v, ok := e.lookup("CALLER_TRIM_PREFIXES"); ok {
+		config.CallerTrimPrefixes = splitList(v)
+	}
+	e.bool("CALLER_SPLIT_FIELDS", &config.CallerSplitFields)
+	e.bool("CALLER_TRIM_MODULE", &config.CallerTrimModule)
+	e.bool("SERVERLESS", &config.Serverless)
+
+	if err := errors.Join(e.errs...); err != nil {
+		return Config{}, err
+	}
+	if err := config.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid config from environment: %w", err)
+	}
+	return config, nil
+}
+
+// envReader читает переменные окружения с общим префиксом и копит ошибки разбора
+type envReader struct {
+	prefix string
+	errs   []error
+}
+
+// lookup возвращает непустое значение переменной
+func (e *envReader) lookup(name string) (string, bool) {
+	v, ok := os.LookupEnv(e.prefix + name)
+	v = strings.TrimSpace(v)
+	return v, ok && v != ""
+}
+
+func (e *envReader) string(name string, dst *string) {
+	if v, ok := e.lookup(name); ok {
+		*dst = v
+	}
+}
+
+func (e *envReader) bool(name string, dst *bool) {
+	v, ok := e.lookup(name)
+	if !ok {
+		return
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s%s: %w", e.prefix, name, err))
+		return
+	}
+	*dst = b
+}
+
+func (e *envReader) int(name string, dst *int) {
+	v, ok := e.lookup(name)
+	if !ok {
+		return
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s%s: %w", e.prefix, name, err))
+		return
+	}
+	*dst = n
+}
+
+func (e *envReader) duration(name string, dst *time.Duration) {
+	v, ok := e.lookup(name)
+	if !ok {
+		return
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s%s: %w", e.prefix, name, err))
+		return
+	}
+	*dst = d
+}
+
+// splitList разбирает список значений через запятую
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
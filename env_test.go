@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("LOG_OUTPUT", "both")
+	t.Setenv("LOG_FILE_PATH", path)
+	t.Setenv("LOG_FORMAT", "json")
+	t.Setenv("LOG_SPLIT_STDERR", "true")
+	t.Setenv("LOG_MAX_SIZE_MB", "50")
+	t.Setenv("LOG_ROTATION", "daily")
+	t.Setenv("LOG_REDACT_KEYS", "password, card_number")
+	t.Setenv("LOG_HEARTBEAT_INTERVAL", "1m")
+
+	config, err := ConfigFromEnv("LOG")
+	require.NoError(t, err)
+	assert.Equal(t, DebugLevel, config.Level)
+	assert.Equal(t, BothOutput, config.Output)
+	assert.Equal(t, path, config.FilePath)
+	assert.Equal(t, JSONFormat, config.Format)
+	assert.True(t, config.SplitStdErr)
+	assert.Equal(t, 50, config.MaxSizeMB)
+	assert.Equal(t, RotateDaily, config.Rotation)
+	assert.Equal(t, []string{"password", "card_number"}, config.RedactKeys)
+	assert.Equal(t, time.Minute, config.HeartbeatInterval)
+}
+
+func TestConfigFromEnv_Defaults(t *testing.T) {
+	config, err := ConfigFromEnv("EMPTY_")
+	require.NoError(t, err)
+	assert.Equal(t, InfoLevel, config.Level)
+	assert.Equal(t, ConsoleOutput, config.Output)
+}
+
+func TestConfigFromEnv_EmptyPrefix(t *testing.T) {
+	t.Setenv("LEVEL", "warn")
+	t.Setenv("FORMAT", "text")
+	t.Setenv("_LEVEL", "debug")
+
+	config, err := ConfigFromEnv("")
+	require.NoError(t, err)
+	assert.Equal(t, WarnLevel, config.Level)
+	assert.Equal(t, TextFormat, config.Format)
+
+	t.Setenv("LEVEL", "loud")
+	_, err = ConfigFromEnv("")
+	assert.ErrorContains(t, err, "LEVEL: ")
+	assert.NotContains(t, err.Error(), "_LEVEL")
+}
+
+func TestConfigFromEnv_Errors(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "loud")
+	t.Setenv("LOG_MAX_SIZE_MB", "big")
+	t.Setenv("LOG_COMPRESS", "maybe")
+
+	_, err := ConfigFromEnv("LOG")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "LOG_LEVEL")
+	assert.Contains(t, err.Error(), "LOG_MAX_SIZE_MB")
+	assert.Contains(t, err.Error(), "LOG_COMPRESS")
+}
+
+func TestConfigFromEnv_Invalid(t *testing.T) {
+	t.Setenv("LOG_OUTPUT", "file")
+
+	_, err := ConfigFromEnv("LOG")
+	assert.ErrorContains(t, err, "file path is required")
+}
+
+func TestNewFromEnv(t *testing.T) {
+	t.Setenv("APP_LOG_LEVEL", "warn")
+
+	logger, err := NewFromEnv("APP_LOG")
+	require.NoError(t, err)
+	assert.Equal(t, WarnLevel, logger.GetLevel())
+}
@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// errorDebug временно повышает уровень сервиса до Debug после его ошибки,
+// чтобы в логи попали подробности того, что происходило после сбоя
+type errorDebug struct {
+	window   time.Duration
+	cooldown time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	triggered map[string]time.Time                 // начало последнего окна сервиса
+	boosts    atomic.Pointer[map[string]time.Time] // конец окна по сервисам
+}
+
+// newErrorDebug создает захват по конфигурации, nil - выключен
+func newErrorDebug(config Config) *errorDebug {
+	if config.ErrorDebugWindow <= 0 {
+		return nil
+	}
+	return &errorDebug{
+		window:    config.ErrorDebugWindow,
+		cooldown:  config.ErrorDebugCooldown,
+		now:       time.Now,
+		triggered: make(map[string]time.Time),
+	}
+}
+
+// trigger открывает окно Debug для сервиса, если он не в окне и не в паузе
+// после него. Возвращает конец окна
+func (d *errorDebug) trigger(service string) (time.Time, bool) {
+	now := d.now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if last, ok := d.triggered[service]; ok && now.Sub(last) < d.window+d.cooldown {
+		return time.Time{}, false
+	}
+	d.triggered[service] = now
+
+	until := now.Add(d.window)
+	boosts := map[string]time.Time{service: until}
+	if current := d.boosts.Load(); current != nil {
+		for s, end := range *current {
+			if s != service && now.Before(end) {
+				boosts[s] = end
+			}
+		}
+	}
+	d.boosts.Store(&boosts)
+	return until, true
+}
+
+// boosted проверяет, открыто ли окно Debug для сервиса или его родителя
+func (d *errorDebug) boosted(service string) bool {
+	boosts := d.boosts.Load()
+	if boosts == nil || len(*boosts) == 0 || service == "" {
+		return false
+	}
+	now := d.now()
+	for {
+		if until, ok := (*boosts)[service]; ok && now.Before(until) {
+			return true
+		}
+		i := strings.LastIndexByte(service, '.')
+		if i < 0 {
+			return false
+		}
+		service = service[:i]
+	}
+}
+
+// triggerErrorDebug открывает окно Debug после ошибки сервиса логгера и
+// записывает об этом запись. Вызывается из форматирования, поэтому запись
+// пишется в отдельной горутине
+func (l *Logger) triggerErrorDebug() {
+	d := l.core.errorDebug.Load()
+	if d == nil || l.serviceName == "" {
+		return
+	}
+	if until, ok := d.trigger(l.serviceName); ok {
+		go l.withFields().WithFields(map[string]interface{}{
+			"debug_until": until,
+			"window_s":    d.window.Seconds(),
+		}).Info("debug capture started")
+	}
+}
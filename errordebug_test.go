@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// messages возвращает сообщения записей
+func messages(t *testing.T, buf *lockedBuffer) []string {
+	t.Helper()
+	var msgs []string
+	for _, entry := range decodeEntries(t, buf) {
+		msgs = append(msgs, entry["msg"].(string))
+	}
+	return msgs
+}
+
+func TestLogger_ErrorDebugCapture(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := New(Config{
+		Level:              InfoLevel,
+		Writers:            []io.Writer{buf},
+		ErrorDebugWindow:   30 * time.Second,
+		ErrorDebugCooldown: time.Minute,
+	})
+	require.NoError(t, err)
+	clock := &fakeClock{now: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+	logger.core.errorDebug.Load().now = clock.Now
+
+	orders := logger.WithService("orders")
+	billing := logger.WithService("billing")
+
+	orders.Debug("before error")
+	orders.Error("payment failed")
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"payment failed", "debug capture started"}, messages(t, buf))
+	}, time.Second, time.Millisecond)
+
+	orders.Debug("aftermath")
+	orders.WithGroup("db").Debug("aftermath in group")
+	billing.Debug("other service")
+	logger.Debug("root logger")
+
+	// Окно закончилось, пауза еще идет
+	clock.now = clock.now.Add(31 * time.Second)
+	orders.Debug("after window")
+	orders.Error("failed again")
+	orders.Debug("during cooldown")
+
+	assert.Equal(t, []string{
+		"payment failed", "debug capture started", "aftermath", "aftermath in group", "failed again",
+	}, messages(t, buf))
+
+	// После паузы ошибка снова открывает окно
+	clock.now = clock.now.Add(time.Minute)
+	orders.Error("failed after cooldown")
+	orders.Debug("second aftermath")
+	assert.Contains(t, messages(t, buf), "second aftermath")
+}
+
+func TestLogger_ErrorDebugCaptureDisabled(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	logger.SetLevel(InfoLevel)
+
+	orders := logger.WithService("orders")
+	orders.Error("payment failed")
+	orders.Debug("aftermath")
+
+	assert.Len(t, decodeEntries(t, buf), 1)
+}
@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Типы событий журнала Windows
+const (
+	EventError   = "error"
+	EventWarning = "warning"
+	EventInfo    = "info"
+)
+
+// defaultEventTypes типы событий для уровней по умолчанию
+var defaultEventTypes = map[Level]string{
+	PanicLevel: EventError,
+	FatalLevel: EventError,
+	ErrorLevel: EventError,
+	WarnLevel:  EventWarning,
+	InfoLevel:  EventInfo,
+	DebugLevel: EventInfo,
+	TraceLevel: EventInfo,
+}
+
+// eventTypeMarks первый байт отформатированной записи, по которому writer
+// журнала выбирает тип события
+var eventTypeMarks = map[string]byte{EventError: 'E', EventWarning: 'W', EventInfo: 'I'}
+
+// EventLogConfig настройки вывода в журнал событий Windows
+type EventLogConfig struct {
+	Source string `yaml:"source"` // источник событий, по умолчанию имя исполняемого файла
+	// Install регистрирует источник в реестре, если его еще нет. Требует прав
+	// администратора, обычно источник регистрирует установщик сервиса
+	Install bool   `yaml:"install"`
+	EventID uint32 `yaml:"event_id"` // идентификатор событий, по умолчанию 1
+	// EventTypes тип события (error, warning, info) для уровней. Не указанные
+	// уровни пишутся по умолчанию: Error и серьезнее - error, Warn - warning,
+	// остальные - info
+	EventTypes map[Level]string `yaml:"event_types"`
+}
+
+// validate проверяет настройки журнала событий
+func (c EventLogConfig) validate() error {
+	var errs []error
+	for level, eventType := range c.EventTypes {
+		if _, ok := eventTypeMarks[eventType]; !ok {
+			errs = append(errs, fmt.Errorf("unsupported event type for %s: %s", level, eventType))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// eventType возвращает тип события для уровня
+func (c EventLogConfig) eventType(level Level) string {
+	if eventType, ok := c.EventTypes[level]; ok {
+		return eventType
+	}
+	return defaultEventTypes[level]
+}
+
+// eventLogFormatter форматирует запись вложенным форматтером и помечает ее
+// типом события первым байтом
+type eventLogFormatter struct {
+	inner  logrus.Formatter
+	config EventLogConfig
+}
+
+// Format возвращает запись с меткой типа события
+func (f *eventLogFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	msg, err := f.inner.Format(entry)
+	if err != nil {
+		return nil, err
+	}
+	mark := eventTypeMarks[f.config.eventType(Level(entry.Level))]
+	return append([]byte{mark}, bytes.TrimRight(msg, "\n")...), nil
+}
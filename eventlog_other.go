@@ -0,0 +1,10 @@
+//go:build !windows
+
+package logger
+
+import "errors"
+
+// openEventLogSink недоступен: журнал событий есть только в Windows
+func openEventLogSink(config Config) (logFile, *sink, error) {
+	return nil, nil, errors.New("eventlog output is only supported on windows")
+}
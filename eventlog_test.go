@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventLogFormatter(t *testing.T) {
+	inner, err := newFormatter(TextFormat, false)
+	require.NoError(t, err)
+	f := &eventLogFormatter{inner: inner, config: EventLogConfig{
+		EventTypes: map[Level]string{WarnLevel: EventError, DebugLevel: EventWarning},
+	}}
+
+	format := func(level logrus.Level) string {
+		data, err := f.Format(&logrus.Entry{Level: level, Message: "disk almost full", Data: logrus.Fields{}})
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	assert.Equal(t, byte('E'), format(logrus.FatalLevel)[0])
+	assert.Equal(t, byte('E'), format(logrus.WarnLevel)[0])
+	assert.Equal(t, byte('I'), format(logrus.InfoLevel)[0])
+	assert.Equal(t, byte('W'), format(logrus.DebugLevel)[0])
+
+	msg := format(logrus.InfoLevel)
+	assert.Contains(t, msg, "disk almost full")
+	assert.NotContains(t, msg, "\n")
+}
+
+func TestEventLogConfig(t *testing.T) {
+	path := writeConfig(t, "logger.yaml", `
+output: eventlog
+eventlog:
+  source: OrdersService
+  event_id: 100
+  event_types:
+    warn: error
+    debug: warning
+`)
+	config, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "OrdersService", config.EventLog.Source)
+	assert.EqualValues(t, 100, config.EventLog.EventID)
+	assert.Equal(t, EventError, config.EventLog.eventType(WarnLevel))
+	assert.Equal(t, EventWarning, config.EventLog.eventType(DebugLevel))
+	assert.Equal(t, EventError, config.EventLog.eventType(PanicLevel))
+
+	err = Config{
+		Output:   EventLogOutput,
+		EventLog: EventLogConfig{EventTypes: map[Level]string{InfoLevel: "audit"}},
+	}.Validate()
+	assert.ErrorContains(t, err, "unsupported event type for info: audit")
+}
+
+func TestEventLogOutput_Unsupported(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("event log is available on windows")
+	}
+	_, err := New(Config{Output: EventLogOutput})
+	assert.ErrorContains(t, err, "eventlog output is only supported on windows")
+}
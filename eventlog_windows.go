@@ -0,0 +1,80 @@
+//go:build windows
+
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// openEventLogSink открывает журнал событий и создает назначение
+func openEventLogSink(config Config) (logFile, *sink, error) {
+	c := config.EventLog
+	source := firstNonEmpty(c.Source, strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe"))
+	if c.Install {
+		err := eventlog.InstallAsEventCreate(source, eventlog.Error|eventlog.Warning|eventlog.Info)
+		if err != nil && !strings.Contains(err.Error(), "registry key already exists") {
+			return nil, nil, fmt.Errorf("failed to install event source: %w", err)
+		}
+	}
+	inner, err := newFormatter(firstNonEmpty(config.Format, TextFormat), false)
+	if err != nil {
+		return nil, nil, err
+	}
+	log, err := eventlog.Open(source)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	w := &eventLogWriter{log: log, eventID: c.EventID}
+	if w.eventID == 0 {
+		w.eventID = 1
+	}
+	return w, &sink{name: "eventlog", writer: w, formatter: &eventLogFormatter{inner: inner, config: c}, binary: true}, nil
+}
+
+// eventLogWriter пишет записи в журнал событий с типом из метки записи
+type eventLogWriter struct {
+	log     *eventlog.Log
+	eventID uint32
+}
+
+// Write пишет одно событие
+func (w *eventLogWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	msg := string(p[1:])
+	var err error
+	switch p[0] {
+	case eventTypeMarks[EventError]:
+		err = w.log.Error(w.eventID, msg)
+	case eventTypeMarks[EventWarning]:
+		err = w.log.Warning(w.eventID, msg)
+	default:
+		err = w.log.Info(w.eventID, msg)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sync ничего не делает: события пишутся сразу
+func (w *eventLogWriter) Sync() error {
+	return nil
+}
+
+// Reopen ничего не делает: журнал событий не ротируется
+func (w *eventLogWriter) Reopen() error {
+	return nil
+}
+
+// Close закрывает журнал
+func (w *eventLogWriter) Close() error {
+	return w.log.Close()
+}
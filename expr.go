@@ -0,0 +1,578 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Выражения над записями - подмножество CEL для правил в конфигурации:
+//
+//	entry.fields.status >= 500 && entry.service.startsWith("edge")
+//	entry.level in ["error", "fatal"] || entry.message.matches("^timeout")
+//
+// Поддерживаются entry.level, entry.message, entry.service и
+// entry.fields.<key> (или entry.fields["http.status"]), литералы чисел, строк,
+// true, false, null и списков, операторы ==, !=, <, <=, >, >=, in, &&, ||, !
+// и методы строк startsWith, endsWith, contains и matches. Отсутствующее поле
+// равно null; сравнение значений разных типов ложно
+
+// expr скомпилированное выражение над записью
+type expr struct {
+	source string
+	eval   func(e *logrus.Entry) interface{}
+}
+
+// compileExpr разбирает выражение
+func compileExpr(source string) (*expr, error) {
+	tokens, err := lexExpr(source)
+	if err != nil {
+		return nil, fmt.Errorf("expression %q: %w", source, err)
+	}
+	p := &exprParser{tokens: tokens}
+	eval, err := p.parseOr()
+	if err == nil && p.peek().kind != tokEOF {
+		err = fmt.Errorf("unexpected %q", p.peek().text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("expression %q: %w", source, err)
+	}
+	return &expr{source: source, eval: eval}, nil
+}
+
+// compileFilter разбирает фильтр назначения, пустой фильтр - nil
+func compileFilter(source string) (*expr, error) {
+	if source == "" {
+		return nil, nil
+	}
+	return compileExpr(source)
+}
+
+// compileExprs разбирает список выражений
+func compileExprs(sources []string) ([]*expr, error) {
+	exprs := make([]*expr, 0, len(sources))
+	for _, source := range sources {
+		e, err := compileExpr(source)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, e)
+	}
+	return exprs, nil
+}
+
+// match вычисляет выражение как условие: только true считается совпадением
+func (x *expr) match(entry *logrus.Entry) bool {
+	v, _ := x.eval(entry).(bool)
+	return v
+}
+
+// LevelRule правило уровня: записи, подходящие под выражение When,
+// записываются с уровнем Level
+type LevelRule struct {
+	When  string `yaml:"when"`
+	Level Level  `yaml:"level"`
+}
+
+// levelRule скомпилированное правило уровня
+type levelRule struct {
+	when  *expr
+	level Level
+}
+
+// compileLevelRules разбирает правила уровней
+func compileLevelRules(rules []LevelRule) ([]levelRule, error) {
+	compiled := make([]levelRule, 0, len(rules))
+	for _, rule := range rules {
+		when, err := compileExpr(rule.When)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, levelRule{when: when, level: rule.Level})
+	}
+	return compiled, nil
+}
+
+// applyLevelRules возвращает запись с уровнем первого подходящего правила
+func applyLevelRules(rules []levelRule, entry *logrus.Entry) *logrus.Entry {
+	for _, rule := range rules {
+		if rule.when.match(entry) {
+			if Level(entry.Level) == rule.level {
+				return entry
+			}
+			changed := *entry
+			changed.Level = logrus.Level(rule.level)
+			return &changed
+		}
+	}
+	return entry
+}
+
+// matchAny проверяет, подходит ли запись хотя бы под одно выражение
+func matchAny(exprs []*expr, entry *logrus.Entry) bool {
+	for _, x := range exprs {
+		if x.match(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+}
+
+// lexExpr разбивает выражение на лексемы
+func lexExpr(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(s) && s[j] != c {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return nil, errors.New("unterminated string")
+			}
+			raw := s[i : j+1]
+			if c == '\'' {
+				raw = `"` + strings.ReplaceAll(s[i+1:j], `"`, `\"`) + `"`
+			}
+			text, err := strconv.Unquote(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string %s", s[i:j+1])
+			}
+			tokens = append(tokens, token{kind: tokString, text: text})
+			i = j + 1
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(s) && (s[j] >= '0' && s[j] <= '9' || s[j] == '.') {
+				j++
+			}
+			num, err := strconv.ParseFloat(s[i:j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %s", s[i:j])
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: s[i:j], num: num})
+			i = j
+		case c == '_' || unicode.IsLetter(rune(c)):
+			j := i
+			for j < len(s) && (s[j] == '_' || unicode.IsLetter(rune(s[j])) || s[j] >= '0' && s[j] <= '9') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: s[i:j]})
+			i = j
+		default:
+			op := ""
+			for _, candidate := range []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")", "[", "]", ".", ",", "-"} {
+				if strings.HasPrefix(s[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q", c)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokEOF}), nil
+}
+
+// exprParser разбирает лексемы рекурсивным спуском в функции вычисления
+type exprParser struct {
+	tokens []token
+	pos    int
+}
+
+type evalFunc = func(e *logrus.Entry) interface{}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept пропускает оператор или ключевое слово text, если оно следующее
+func (p *exprParser) accept(text string) bool {
+	t := p.peek()
+	if (t.kind == tokOp || t.kind == tokIdent) && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) expect(text string) error {
+	if !p.accept(text) {
+		return fmt.Errorf("expected %q, got %q", text, p.peek().text)
+	}
+	return nil
+}
+
+func (p *exprParser) parseOr() (evalFunc, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(e *logrus.Entry) interface{} { return truthy(l(e)) || truthy(right(e)) }
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (evalFunc, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(e *logrus.Entry) interface{} { return truthy(l(e)) && truthy(right(e)) }
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseComparison() (evalFunc, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	switch {
+	case t.kind == tokOp && (t.text == "==" || t.text == "!=" || t.text == "<" || t.text == "<=" || t.text == ">" || t.text == ">="):
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		op := t.text
+		return func(e *logrus.Entry) interface{} { return compareValues(op, left(e), right(e)) }, nil
+	case t.kind == tokIdent && t.text == "in":
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(e *logrus.Entry) interface{} {
+			v := left(e)
+			list, _ := right(e).([]interface{})
+			for _, item := range list {
+				if compareValues("==", v, item) == true {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (evalFunc, error) {
+	if p.accept("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(e *logrus.Entry) interface{} { return !truthy(operand(e)) }, nil
+	}
+	if p.accept("-") {
+		t := p.next()
+		if t.kind != tokNumber {
+			return nil, fmt.Errorf("expected number after '-', got %q", t.text)
+		}
+		return constant(-t.num), nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *exprParser) parsePostfix() (evalFunc, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		return constant(t.num), nil
+	case tokString:
+		return constant(t.text), nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return constant(true), nil
+		case "false":
+			return constant(false), nil
+		case "null":
+			return constant(nil), nil
+		case "entry":
+			return p.parseEntry()
+		}
+		return nil, fmt.Errorf("unknown identifier %q", t.text)
+	case tokOp:
+		switch t.text {
+		case "(":
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return p.parseMethods(inner)
+		case "[":
+			return p.parseList()
+		}
+	}
+	return nil, fmt.Errorf("unexpected %q", t.text)
+}
+
+// parseList разбирает литерал списка после '['
+func (p *exprParser) parseList() (evalFunc, error) {
+	var items []evalFunc
+	for !p.accept("]") {
+		if len(items) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		item, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return func(e *logrus.Entry) interface{} {
+		list := make([]interface{}, len(items))
+		for i, item := range items {
+			list[i] = item(e)
+		}
+		return list
+	}, nil
+}
+
+// parseEntry разбирает обращение к записи после entry
+func (p *exprParser) parseEntry() (evalFunc, error) {
+	if err := p.expect("."); err != nil {
+		return nil, err
+	}
+	t := p.next()
+	var eval evalFunc
+	switch t.text {
+	case "level":
+		eval = func(e *logrus.Entry) interface{} { return e.Level.String() }
+	case "message", "msg":
+		eval = func(e *logrus.Entry) interface{} { return e.Message }
+	case "service":
+		eval = func(e *logrus.Entry) interface{} { return normalizeExprValue(e.Data["service"]) }
+	case "fields":
+		key, err := p.parseFieldKey()
+		if err != nil {
+			return nil, err
+		}
+		eval = func(e *logrus.Entry) interface{} { return normalizeExprValue(e.Data[key]) }
+	default:
+		return nil, fmt.Errorf("unknown entry attribute %q", t.text)
+	}
+	return p.parseMethods(eval)
+}
+
+// parseFieldKey разбирает ключ поля: .key или ["key"]
+func (p *exprParser) parseFieldKey() (string, error) {
+	if p.accept("[") {
+		t := p.next()
+		if t.kind != tokString {
+			return "", fmt.Errorf("expected field name string, got %q", t.text)
+		}
+		return t.text, p.expect("]")
+	}
+	if err := p.expect("."); err != nil {
+		return "", err
+	}
+	t := p.next()
+	if t.kind != tokIdent {
+		return "", fmt.Errorf("expected field name, got %q", t.text)
+	}
+	return t.text, nil
+}
+
+// parseMethods разбирает вызовы методов строк после значения
+func (p *exprParser) parseMethods(target evalFunc) (evalFunc, error) {
+	for p.peek().kind == tokOp && p.peek().text == "." {
+		p.next()
+		name := p.next()
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		argToken := p.peek()
+		arg, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+
+		var method func(s, arg string) bool
+		switch name.text {
+		case "startsWith":
+			method = strings.HasPrefix
+		case "endsWith":
+			method = strings.HasSuffix
+		case "contains":
+			method = strings.Contains
+		case "matches":
+			// Шаблон-литерал компилируется один раз
+			if argToken.kind == tokString {
+				re, err := regexp.Compile(argToken.text)
+				if err != nil {
+					return nil, fmt.Errorf("invalid pattern %q: %w", argToken.text, err)
+				}
+				method = func(s, _ string) bool { return re.MatchString(s) }
+			} else {
+				method = func(s, pattern string) bool {
+					matched, err := regexp.MatchString(pattern, s)
+					return err == nil && matched
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unknown method %q", name.text)
+		}
+
+		t := target
+		target = func(e *logrus.Entry) interface{} {
+			s, ok := t(e).(string)
+			a, aok := arg(e).(string)
+			return ok && aok && method(s, a)
+		}
+	}
+	return target, nil
+}
+
+// constant возвращает функцию вычисления литерала
+func constant(v interface{}) evalFunc {
+	return func(*logrus.Entry) interface{} { return v }
+}
+
+// truthy проверяет, что значение - true
+func truthy(v interface{}) bool {
+	b, _ := v.(bool)
+	return b
+}
+
+// normalizeExprValue приводит значение поля к типам выражений:
+// числа к float64, строки и bool как есть, остальное - к строке
+func normalizeExprValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case nil, bool, string, float64:
+		return val
+	case fmt.Stringer:
+		return val.String()
+	case error:
+		return val.Error()
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint())
+	case reflect.Float32:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		return rv.Bool()
+	}
+	return fmt.Sprint(v)
+}
+
+// compareValues сравнивает значения одного типа; значения разных типов
+// равны только для != и никогда не упорядочены
+func compareValues(op string, a, b interface{}) interface{} {
+	switch av := a.(type) {
+	case float64:
+		if bv, ok := b.(float64); ok {
+			return compareOrdered(op, av, bv)
+		}
+	case string:
+		if bv, ok := b.(string); ok {
+			return compareOrdered(op, av, bv)
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			switch op {
+			case "==":
+				return av == bv
+			case "!=":
+				return av != bv
+			}
+			return false
+		}
+	case nil:
+		switch op {
+		case "==":
+			return b == nil
+		case "!=":
+			return b != nil
+		}
+		return false
+	}
+	return op == "!="
+}
+
+// compareOrdered сравнивает упорядоченные значения
+func compareOrdered[T float64 | string](op string, a, b T) bool {
+	switch op {
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpr_Match(t *testing.T) {
+	entry := &logrus.Entry{
+		Level:   logrus.WarnLevel,
+		Message: "upstream timeout after 30s",
+		Data: logrus.Fields{
+			"service":     "edge-proxy",
+			"status":      502,
+			"latency":     1.5,
+			"cached":      false,
+			"http.method": "GET",
+			"error":       errors.New("connection reset"),
+		},
+	}
+
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{`entry.fields.status >= 500 && entry.service.startsWith("edge")`, true},
+		{`entry.fields.status >= 500 && entry.service.startsWith("api")`, false},
+		{`entry.fields.status == 502`, true},
+		{`entry.fields.status != 502 || entry.fields.latency > 1`, true},
+		{`entry.level == "warning"`, true},
+		{`entry.level in ["error", "fatal"]`, false},
+		{`entry.fields.status in [500, 502, 503]`, true},
+		{`entry.message.matches("timeout after \\d+s$")`, true},
+		{`entry.msg.contains('timeout')`, true},
+		{`entry.service.endsWith("proxy")`, true},
+		{`entry.fields["http.method"] == "GET"`, true},
+		{`entry.fields.error.contains("reset")`, true},
+		{`!entry.fields.cached`, true},
+		{`entry.fields.missing == null`, true},
+		{`entry.fields.missing > 0`, false},
+		{`entry.fields.status == "502"`, false},
+		{`entry.fields.latency > -1 && (entry.fields.cached || entry.fields.status < 600)`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			x, err := compileExpr(tt.source)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, x.match(entry))
+		})
+	}
+}
+
+func TestExpr_NonBoolResultDoesNotMatch(t *testing.T) {
+	x, err := compileExpr(`entry.fields.status`)
+	require.NoError(t, err)
+
+	assert.False(t, x.match(&logrus.Entry{Data: logrus.Fields{"status": 500}}))
+}
+
+func TestExpr_CompileErrors(t *testing.T) {
+	for _, source := range []string{
+		``,
+		`entry.fields.status >=`,
+		`entry.unknown == 1`,
+		`status == 1`,
+		`entry.message.lower()`,
+		`entry.message.matches("(")`,
+		`"unterminated`,
+		`entry.fields.a == 1 )`,
+		`entry.fields.a # 1`,
+	} {
+		_, err := compileExpr(source)
+		assert.Error(t, err, source)
+	}
+}
+
+func TestExpr_Drop(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(Config{
+		Level:        TraceLevel,
+		Destinations: []Destination{{Writer: buf, Format: JSONFormat}},
+		Drop:         []string{`entry.fields.path == "/healthz" && entry.fields.status < 400`},
+	})
+	require.NoError(t, err)
+
+	logger.WithFields(map[string]interface{}{"path": "/healthz", "status": 200}).Info("request")
+	logger.WithFields(map[string]interface{}{"path": "/healthz", "status": 503}).Info("request")
+	logger.WithFields(map[string]interface{}{"path": "/orders", "status": 200}).Info("request")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, float64(503), entries[0]["status"])
+	assert.Equal(t, "/orders", entries[1]["path"])
+}
+
+func TestExpr_LevelRules(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(Config{
+		Level:        InfoLevel,
+		Destinations: []Destination{{Writer: buf, Format: JSONFormat}},
+		LevelRules: []LevelRule{
+			{When: `entry.fields.status >= 500`, Level: ErrorLevel},
+			{When: `entry.message.startsWith("cache miss")`, Level: DebugLevel},
+		},
+	})
+	require.NoError(t, err)
+
+	logger.WithField("status", 502).Info("request")
+	logger.Info("cache miss for key")
+	logger.WithField("status", 200).Info("request")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "error", entries[0]["level"])
+	assert.Equal(t, "info", entries[1]["level"])
+}
+
+func TestExpr_DestinationFilter(t *testing.T) {
+	edge := &bytes.Buffer{}
+	all := &bytes.Buffer{}
+	logger, err := New(Config{
+		Level: TraceLevel,
+		Destinations: []Destination{
+			{Writer: edge, Format: JSONFormat, Filter: `entry.service.startsWith("edge")`},
+			{Writer: all, Format: JSONFormat},
+		},
+	})
+	require.NoError(t, err)
+
+	logger.WithService("edge-proxy").Info("from edge")
+	logger.WithService("billing").Info("from billing")
+
+	entries := decodeEntries(t, edge)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "from edge", entries[0]["msg"])
+	assert.Len(t, decodeEntries(t, all), 2)
+}
+
+func TestExpr_ConfigValidation(t *testing.T) {
+	config := Config{
+		Output:     ConsoleOutput,
+		FileFilter: `entry.fields.status >=`,
+		Drop:       []string{`entry.nope`},
+		LevelRules: []LevelRule{{When: `entry.fields.a ==`, Level: ErrorLevel}},
+	}
+
+	err := config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `entry.fields.status >=`)
+	assert.Contains(t, err.Error(), `entry.nope`)
+	assert.Contains(t, err.Error(), "level rule 0")
+
+	_, err = New(Config{Output: ConsoleOutput, ConsoleFilter: `entry.level ==`})
+	assert.Error(t, err)
+}
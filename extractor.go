@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ContextExtractor возвращает поля, которые нужно добавить к записям логгера,
+// привязанного к контексту через WithContext. Например, идентификатор
+// трейса OpenTelemetry:
+//
+//	func(ctx context.Context) map[string]interface{} {
+//		sc := trace.SpanContextFromContext(ctx)
+//		if !sc.IsValid() {
+//			return nil
+//		}
+//		return map[string]interface{}{"trace_id": sc.TraceID().String()}
+//	}
+type ContextExtractor func(ctx context.Context) map[string]interface{}
+
+// ctxRequestIDKey ключ идентификатора запроса в контексте
+type ctxRequestIDKey struct{}
+
+// ctxTraceIDKey ключ идентификатора трейса в контексте
+type ctxTraceIDKey struct{}
+
+// ContextWithRequestID добавляет в контекст идентификатор запроса
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, ctxRequestIDKey{}, requestID)
+}
+
+// RequestIDFromContext возвращает идентификатор запроса из контекста
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(ctxRequestIDKey{}).(string)
+	return requestID
+}
+
+// ContextWithTraceID добавляет в контекст идентификатор трейса
+func ContextWithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, ctxTraceIDKey{}, traceID)
+}
+
+// TraceIDFromContext возвращает идентификатор трейса из контекста
+func TraceIDFromContext(ctx context.Context) string {
+	traceID, _ := ctx.Value(ctxTraceIDKey{}).(string)
+	return traceID
+}
+
+// contextExtractors извлекатели, общие для всех логгеров процесса
+var contextExtractors struct {
+	mu   sync.RWMutex
+	list []ContextExtractor
+}
+
+// RegisterContextExtractor регистрирует извлекатель полей из контекста для всех
+// логгеров. Обычно вызывается при инициализации пакета, который кладет свои
+// значения в контекст (арендатор, сессия, локаль). Поля извлекателей
+// отдельного логгера из AddContextExtractor перекрывают поля общих
+func RegisterContextExtractor(extractor ContextExtractor) {
+	contextExtractors.mu.Lock()
+	defer contextExtractors.mu.Unlock()
+	contextExtractors.list = append(contextExtractors.list, extractor)
+}
+
+// ContextValueExtractor возвращает извлекатель, который добавляет значение
+// ctx.Value(key) в поле field. Контекст без значения поле не добавляет
+func ContextValueExtractor(key interface{}, field string) ContextExtractor {
+	return func(ctx context.Context) map[string]interface{} {
+		value := ctx.Value(key)
+		if value == nil {
+			return nil
+		}
+		return map[string]interface{}{field: value}
+	}
+}
+
+// RegisterContextKey регистрирует для всех логгеров перенос значения
+// контекста по ключу key в поле field: арендатор из middleware авторизации,
+// локаль, версия API. Места вызова WithContext о ключе знать не нужно
+func RegisterContextKey(key interface{}, field string) {
+	RegisterContextExtractor(ContextValueExtractor(key, field))
+}
+
+// AddContextExtractor регистрирует извлекатель полей из контекста для логгера
+// и всех его дочерних логгеров. Извлекатели вызываются по порядку регистрации,
+// поля более поздних перекрывают поля более ранних
+func (l *Logger) AddContextExtractor(extractor ContextExtractor) {
+	if l == nil {
+		nilReceiver()
+		return
+	}
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+	l.core.extractors = append(l.core.extractors, extractor)
+}
+
+// contextFields собирает поля из контекста: стандартные идентификаторы,
+// затем поля общих извлекателей и извлекателей логгера
+func (l *Logger) contextFields(ctx context.Context) logrus.Fields {
+	fields := logrus.Fields{}
+	if id := RequestIDFromContext(ctx); id != "" {
+		fields["request_id"] = id
+	}
+	if id := TraceIDFromContext(ctx); id != "" {
+		fields["trace_id"] = id
+	}
+	if id := UserIDFromContext(ctx); id != "" {
+		fields["user_id"] = id
+	}
+
+	contextExtractors.mu.RLock()
+	global := contextExtractors.list
+	contextExtractors.mu.RUnlock()
+
+	l.core.mu.RLock()
+	own := l.core.extractors
+	l.core.mu.RUnlock()
+
+	for _, extract := range global {
+		for k, v := range extract(ctx) {
+			fields[k] = v
+		}
+	}
+	for _, extract := range own {
+		for k, v := range extract(ctx) {
+			fields[k] = v
+		}
+	}
+	return fields
+}
@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_WithContext_Fields(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	ctx := ContextWithRequestID(context.Background(), "req-1")
+	ctx = ContextWithTraceID(ctx, "trace-1")
+	ctx = ContextWithUserID(ctx, "u-42")
+
+	logger.WithContext(ctx).Info("with ids")
+	logger.WithContext(context.Background()).Info("without ids")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "req-1", entries[0]["request_id"])
+	assert.Equal(t, "trace-1", entries[0]["trace_id"])
+	assert.Equal(t, "u-42", entries[0]["user_id"])
+	assert.NotContains(t, entries[1], "request_id")
+	assert.NotContains(t, entries[1], "trace_id")
+	assert.NotContains(t, entries[1], "user_id")
+}
+
+func TestLogger_AddContextExtractor(t *testing.T) {
+	type tenantKey struct{}
+
+	logger, buf := newBufferLogger(t)
+	logger.AddContextExtractor(func(ctx context.Context) map[string]interface{} {
+		tenant, ok := ctx.Value(tenantKey{}).(string)
+		if !ok {
+			return nil
+		}
+		return map[string]interface{}{"tenant": tenant}
+	})
+	logger.AddContextExtractor(func(ctx context.Context) map[string]interface{} {
+		return map[string]interface{}{"trace_id": "from-extractor"}
+	})
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	ctx = ContextWithTraceID(ctx, "from-context")
+
+	// Извлекатели общие для дочерних логгеров
+	logger.WithService("orders").WithContext(ctx).Info("tenant request")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "acme", entries[0]["tenant"])
+	assert.Equal(t, "from-extractor", entries[0]["trace_id"])
+	assert.Equal(t, "orders", entries[0]["service"])
+}
+
+func TestRegisterContextExtractor(t *testing.T) {
+	type localeKey struct{}
+
+	RegisterContextExtractor(func(ctx context.Context) map[string]interface{} {
+		locale, ok := ctx.Value(localeKey{}).(string)
+		if !ok {
+			return nil
+		}
+		return map[string]interface{}{"locale": locale, "source": "global"}
+	})
+
+	logger, buf := newBufferLogger(t)
+	logger.AddContextExtractor(func(ctx context.Context) map[string]interface{} {
+		return map[string]interface{}{"source": "logger"}
+	})
+
+	ctx := context.WithValue(context.Background(), localeKey{}, "ru-RU")
+	logger.WithContext(ctx).Info("localized")
+	logger.WithContext(context.Background()).Info("default locale")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "ru-RU", entries[0]["locale"])
+	assert.Equal(t, "logger", entries[0]["source"])
+	assert.NotContains(t, entries[1], "locale")
+}
+
+func TestRegisterContextKey(t *testing.T) {
+	type tenantKey struct{}
+	type apiVersionKey struct{}
+
+	RegisterContextKey(tenantKey{}, "tenant_id")
+	logger, buf := newBufferLogger(t)
+	logger.AddContextExtractor(ContextValueExtractor(apiVersionKey{}, "api_version"))
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	ctx = context.WithValue(ctx, apiVersionKey{}, 2)
+	logger.WithContext(ctx).Info("tenant request")
+	logger.WithContext(context.Background()).Info("anonymous request")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "acme", entries[0]["tenant_id"])
+	assert.EqualValues(t, 2, entries[0]["api_version"])
+	assert.NotContains(t, entries[1], "tenant_id")
+	assert.NotContains(t, entries[1], "api_version")
+}
@@ -0,0 +1,155 @@
+package logger
+
+import (
+	"math"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fieldKind тип значения типизированного поля
+type fieldKind uint8
+
+const (
+	anyKind fieldKind = iota
+	stringKind
+	intKind
+	floatKind
+	boolKind
+	durationKind
+)
+
+// Field типизированное поле записи. Значение хранится без упаковки в
+// interface{}, поэтому записи с выключенным уровнем не выделяют память.
+// Записи с включенным уровнем упаковывают значения в карту полей logrus
+type Field struct {
+	Key   string
+	kind  fieldKind
+	num   int64
+	str   string
+	iface interface{}
+}
+
+// String создает строковое поле
+func String(key, value string) Field {
+	return Field{Key: key, kind: stringKind, str: value}
+}
+
+// Int создает целочисленное поле
+func Int(key string, value int) Field {
+	return Field{Key: key, kind: intKind, num: int64(value)}
+}
+
+// Int64 создает целочисленное поле
+func Int64(key string, value int64) Field {
+	return Field{Key: key, kind: intKind, num: value}
+}
+
+// Float64 создает поле с плавающей точкой
+func Float64(key string, value float64) Field {
+	return Field{Key: key, kind: floatKind, num: int64(math.Float64bits(value))}
+}
+
+// Bool создает логическое поле
+func Bool(key string, value bool) Field {
+	f := Field{Key: key, kind: boolKind}
+	if value {
+		f.num = 1
+	}
+	return f
+}
+
+// Duration создает поле длительности
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, kind: durationKind, num: int64(value)}
+}
+
+// Err создает поле error, как WithError
+func Err(err error) Field {
+	return Field{Key: logrus.ErrorKey, iface: err}
+}
+
+// Any создает поле произвольного типа
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, iface: value}
+}
+
+// value возвращает значение поля
+func (f Field) value() interface{} {
+	switch f.kind {
+	case stringKind:
+		return f.str
+	case intKind:
+		return f.num
+	case floatKind:
+		return math.Float64frombits(uint64(f.num))
+	case boolKind:
+		return f.num != 0
+	case durationKind:
+		return time.Duration(f.num)
+	}
+	return f.iface
+}
+
+// Log пишет сообщение уровня level с типизированными полями. При выключенном
+// уровне вызов не выделяет память. При включенном поля пишутся сразу в
+// карту записи logrus: это одна карта вместо двух у WithFields, но не ноль
+// аллокаций
+func (l *Logger) Log(level Level, msg string, fields ...Field) {
+	if !l.enabled(level) {
+		return
+	}
+	l.logFields(level, msg, fields)
+}
+
+// TraceFields пишет сообщение уровня Trace с типизированными полями
+func (l *Logger) TraceFields(msg string, fields ...Field) {
+	if l.enabled(TraceLevel) {
+		l.logFields(TraceLevel, msg, fields)
+	}
+}
+
+// DebugFields пишет сообщение уровня Debug с типизированными полями
+func (l *Logger) DebugFields(msg string, fields ...Field) {
+	if l.enabled(DebugLevel) {
+		l.logFields(DebugLevel, msg, fields)
+	}
+}
+
+// InfoFields пишет сообщение уровня Info с типизированными полями
+func (l *Logger) InfoFields(msg string, fields ...Field) {
+	if l.enabled(InfoLevel) {
+		l.logFields(InfoLevel, msg, fields)
+	}
+}
+
+// WarnFields пишет сообщение уровня Warn с типизированными полями
+func (l *Logger) WarnFields(msg string, fields ...Field) {
+	if l.enabled(WarnLevel) {
+		l.logFields(WarnLevel, msg, fields)
+	}
+}
+
+// ErrorFields пишет сообщение уровня Error с типизированными полями
+func (l *Logger) ErrorFields(msg string, fields ...Field) {
+	if l.enabled(ErrorLevel) {
+		l.logFields(ErrorLevel, msg, fields)
+	}
+}
+
+// logFields собирает поля записи в карту logrus и пишет запись. Карта и
+// упаковка значений в interface{} нужны logrus, поэтому включенный уровень
+// всегда выделяет память
+func (l *Logger) logFields(level Level, msg string, fields []Field) {
+	data := make(logrus.Fields, len(l.fields)+len(fields)+1)
+	for k, v := range l.fields {
+		data[k] = v
+	}
+	data["service"] = l.serviceName
+	for _, f := range fields {
+		data[f.Key] = f.value()
+	}
+
+	entry := &logrus.Entry{Logger: l.logger, Data: data, Context: NewContext(l.context(), l)}
+	entry.Log(logrus.Level(level), msg)
+}
@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_InfoFields(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	logger.With(map[string]interface{}{"request_id": "r-1"}).InfoFields("request served",
+		String("path", "/orders"),
+		Int("status", 200),
+		Float64("ratio", 0.5),
+		Bool("cached", true),
+		Duration("elapsed", 1500*time.Millisecond),
+		Err(errors.New("boom")),
+		Any("tags", []string{"a", "b"}),
+	)
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	entry := entries[0]
+	assert.Equal(t, "request served", entry["msg"])
+	assert.Equal(t, "info", entry["level"])
+	assert.Equal(t, "r-1", entry["request_id"])
+	assert.Equal(t, "/orders", entry["path"])
+	assert.Equal(t, float64(200), entry["status"])
+	assert.Equal(t, 0.5, entry["ratio"])
+	assert.Equal(t, true, entry["cached"])
+	assert.Equal(t, float64(1500*time.Millisecond), entry["elapsed"])
+	assert.Equal(t, "boom", entry["error"])
+	assert.Equal(t, []interface{}{"a", "b"}, entry["tags"])
+	assert.Contains(t, entry["file"], "field_test.go")
+	assert.Contains(t, entry["func"], "TestLogger_InfoFields")
+}
+
+func TestLogger_Log(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	logger.SetLevel(WarnLevel)
+
+	logger.Log(InfoLevel, "skipped", Int("n", 1))
+	logger.DebugFields("skipped")
+	logger.Log(ErrorLevel, "failed", Int("n", 2))
+	logger.WarnFields("warned")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "error", entries[0]["level"])
+	assert.Equal(t, float64(2), entries[0]["n"])
+	assert.Contains(t, entries[0]["file"], "field_test.go")
+	assert.Equal(t, "warning", entries[1]["level"])
+}
+
+func TestLogger_FieldsDisabledNoAlloc(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+	logger.SetLevel(ErrorLevel)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.DebugFields("skipped", String("path", "/orders"), Int("status", 200))
+	})
+	assert.Zero(t, allocs)
+}
+
+func newBenchmarkLogger(b *testing.B, level Level) *Logger {
+	b.Helper()
+
+	logger, err := New(Config{Level: level, Writers: []io.Writer{io.Discard}})
+	require.NoError(b, err)
+	return logger
+}
+
+func BenchmarkLogger_InfoFields(b *testing.B) {
+	logger := newBenchmarkLogger(b, InfoLevel)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.InfoFields("request served", String("path", "/orders"), Int("status", 200), Duration("elapsed", time.Millisecond))
+	}
+}
+
+func BenchmarkLogger_WithFieldsInfo(b *testing.B) {
+	logger := newBenchmarkLogger(b, InfoLevel)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.WithFields(map[string]interface{}{
+			"path":    "/orders",
+			"status":  200,
+			"elapsed": time.Millisecond,
+		}).Info("request served")
+	}
+}
+
+func BenchmarkLogger_DebugFieldsDisabled(b *testing.B) {
+	logger := newBenchmarkLogger(b, InfoLevel)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.DebugFields("request served", String("path", "/orders"), Int("status", 200), Duration("elapsed", time.Millisecond))
+	}
+}
+
+func BenchmarkLogger_WithFieldsDebugDisabled(b *testing.B) {
+	logger := newBenchmarkLogger(b, InfoLevel)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.WithFields(map[string]interface{}{
+			"path":    "/orders",
+			"status":  200,
+			"elapsed": time.Millisecond,
+		}).Debug("request served")
+	}
+}
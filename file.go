@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+)
+
+// defaultDirMode права на создаваемые каталоги логов
+const defaultDirMode os.FileMode = 0750
+
+// logFile открытый логгером файл, который нужно сбрасывать на диск,
+// переоткрывать и закрывать
+type logFile interface {
+	io.WriteCloser
+	Sync() error
+	Reopen() error
+}
+
+// openLogFile открывает файл логов, при необходимости оборачивая его в ротацию
+func openLogFile(config Config) (logFile, error) {
+	if !config.DisableDirCreation {
+		mode := config.DirMode
+		if mode == 0 {
+			mode = defaultDirMode
+		}
+		if err := os.MkdirAll(filepath.Dir(config.FilePath), mode); err != nil {
+			return nil, fmt.Errorf("failed to create log directory: %w", err)
+		}
+	}
+
+	if config.MaxSizeMB > 0 || config.Rotation != NoRotation {
+		return newRotatingWriter(config)
+	}
+	return newFileWriter(config.FilePath)
+}
+
+// fileWriter файл логов, который можно переоткрыть по тому же пути
+type fileWriter struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// newFileWriter открывает файл для дозаписи
+func newFileWriter(path string) (*fileWriter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return nil, err
+	}
+	return &fileWriter{path: path, file: file}, nil
+}
+
+// Write записывает данные в файл
+func (w *fileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Write(p)
+}
+
+// Sync сбрасывает файл на диск
+func (w *fileWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Sync()
+}
+
+// Close закрывает файл
+func (w *fileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+// Reopen закрывает файл и открывает заново по тому же пути
+func (w *fileWriter) Reopen() error {
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	old := w.file
+	w.file = file
+	if err := old.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
+		return err
+	}
+	return nil
+}
+
+// ReopenOnSignal переоткрывает файлы логов при получении сигнала
+// (по умолчанию SIGHUP). Возвращает функцию, отключающую обработчик
+func (l *Logger) ReopenOnSignal(signals ...os.Signal) (stop func()) {
+	if l == nil {
+		nilReceiver()
+		return func() {}
+	}
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-ch:
+				if err := l.Reopen(); err != nil {
+					l.WithError(err).WithField("signal", sig.String()).Error("failed to reopen log files")
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
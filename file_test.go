@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_Reopen(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{
+			name:   "plain file",
+			config: Config{},
+		},
+		{
+			name:   "rotating file",
+			config: Config{MaxSizeMB: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tempFile := filepath.Join(dir, "test.log")
+
+			config := tt.config
+			config.Level = InfoLevel
+			config.Output = FileOutput
+			config.FilePath = tempFile
+
+			logger, err := New(config)
+			require.NoError(t, err)
+			defer logger.Close()
+
+			logger.Info("before rotate")
+
+			// Имитируем logrotate: файл переименован, процесс пишет в новый
+			rotated := filepath.Join(dir, "test.log.1")
+			require.NoError(t, os.Rename(tempFile, rotated))
+			require.NoError(t, logger.Reopen())
+
+			logger.Info("after rotate")
+
+			content, err := os.ReadFile(rotated)
+			require.NoError(t, err)
+			assert.Contains(t, string(content), "before rotate")
+			assert.NotContains(t, string(content), "after rotate")
+
+			content, err = os.ReadFile(tempFile)
+			require.NoError(t, err)
+			assert.Contains(t, string(content), "after rotate")
+		})
+	}
+}
+
+func TestLogger_ReopenOnSignal(t *testing.T) {
+	dir := t.TempDir()
+	tempFile := filepath.Join(dir, "test.log")
+
+	logger, err := New(Config{
+		Level:    InfoLevel,
+		Output:   FileOutput,
+		FilePath: tempFile,
+	})
+	require.NoError(t, err)
+	defer logger.Close()
+
+	stop := logger.ReopenOnSignal()
+	defer stop()
+
+	require.NoError(t, os.Rename(tempFile, filepath.Join(dir, "test.log.1")))
+
+	process, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	if err := process.Signal(syscall.SIGHUP); err != nil {
+		t.Skipf("sending SIGHUP is not supported: %v", err)
+	}
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(tempFile)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"context"
+	"time"
+)
+
+// Ключи feature-флагов, управляющих логгером
+const (
+	FlagLevel        = "log.level"         // строковый флаг: trace, debug, info, ...
+	FlagSamplingRate = "log.sampling_rate" // числовой флаг от 0 до 1
+	FlagRedaction    = "log.redaction"     // булев флаг
+)
+
+// FlagProvider источник feature-флагов. Реализуется адаптером к LaunchDarkly,
+// OpenFeature или другому провайдеру
+type FlagProvider interface {
+	StringFlag(ctx context.Context, key string, defaultValue string) string
+	FloatFlag(ctx context.Context, key string, defaultValue float64) float64
+	BoolFlag(ctx context.Context, key string, defaultValue bool) bool
+}
+
+// ApplyFlags считывает флаги и применяет уровень, семплирование и скрытие полей
+func (l *Logger) ApplyFlags(ctx context.Context, flags FlagProvider) {
+	if l == nil {
+		nilReceiver()
+		return
+	}
+	levelName := flags.StringFlag(ctx, FlagLevel, l.GetLevel().String())
+	if level, err := ParseLevel(levelName); err == nil {
+		l.SetLevel(level)
+	} else {
+		l.WithField("flag", FlagLevel).WithError(err).Warn("invalid log level flag")
+	}
+
+	l.SetSamplingRate(flags.FloatFlag(ctx, FlagSamplingRate, l.SamplingRate()))
+	l.SetRedaction(flags.BoolFlag(ctx, FlagRedaction, l.Redaction()))
+}
+
+// WatchFlags применяет флаги сразу и затем с указанным интервалом,
+// пока не будет отменен контекст
+func (l *Logger) WatchFlags(ctx context.Context, flags FlagProvider, interval time.Duration) {
+	if l == nil {
+		nilReceiver()
+		return
+	}
+	l.ApplyFlags(ctx, flags)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				l.ApplyFlags(ctx, flags)
+			}
+		}
+	}()
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// staticFlags провайдер флагов с фиксированными значениями
+type staticFlags map[string]interface{}
+
+func (f staticFlags) StringFlag(ctx context.Context, key string, defaultValue string) string {
+	if v, ok := f[key].(string); ok {
+		return v
+	}
+	return defaultValue
+}
+
+func (f staticFlags) FloatFlag(ctx context.Context, key string, defaultValue float64) float64 {
+	if v, ok := f[key].(float64); ok {
+		return v
+	}
+	return defaultValue
+}
+
+func (f staticFlags) BoolFlag(ctx context.Context, key string, defaultValue bool) bool {
+	if v, ok := f[key].(bool); ok {
+		return v
+	}
+	return defaultValue
+}
+
+func TestLogger_ApplyFlags(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+
+	logger.ApplyFlags(context.Background(), staticFlags{
+		FlagLevel:        "warn",
+		FlagSamplingRate: 0.5,
+		FlagRedaction:    true,
+	})
+
+	assert.Equal(t, WarnLevel, logger.GetLevel())
+	assert.Equal(t, 0.5, logger.SamplingRate())
+	assert.True(t, logger.Redaction())
+}
+
+func TestLogger_ApplyFlags_Defaults(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	logger.SetLevel(InfoLevel)
+
+	logger.ApplyFlags(context.Background(), staticFlags{FlagLevel: "loud"})
+
+	assert.Equal(t, InfoLevel, logger.GetLevel())
+	assert.Equal(t, float64(1), logger.SamplingRate())
+	assert.False(t, logger.Redaction())
+
+	entries := decodeEntries(t, buf)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, FlagLevel, entries[0]["flag"])
+	}
+}
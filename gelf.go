@@ -0,0 +1,297 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Сжатие сообщений GELF по UDP
+const (
+	GELFCompressGzip = "gzip"
+	GELFCompressZlib = "zlib"
+	GELFCompressNone = "none"
+)
+
+const (
+	// defaultGELFChunkSize размер датаграммы, который проходит без
+	// фрагментации в большинстве сетей
+	defaultGELFChunkSize = 1420
+	// gelfChunkHeader размер заголовка части: магия, id сообщения, номер и число частей
+	gelfChunkHeader = 12
+	// maxGELFChunks больше частей Graylog не принимает
+	maxGELFChunks = 128
+)
+
+// gelfFieldName недопустимые символы в имени дополнительного поля GELF
+var gelfFieldName = regexp.MustCompile(`[^\w.\-]`)
+
+// GELFConfig настройки вывода в Graylog
+type GELFConfig struct {
+	Network string `yaml:"network"` // udp (по умолчанию) или tcp
+	Address string `yaml:"address"` // host:port входа GELF в Graylog
+	Host    string `yaml:"host"`    // поле host, по умолчанию имя машины
+	// Compression сжатие по UDP: gzip (по умолчанию), zlib или none.
+	// По TCP GELF передается без сжатия
+	Compression string `yaml:"compression"`
+	// ChunkSize максимальный размер датаграммы UDP, больше - сообщение
+	// делится на части. По умолчанию 1420
+	ChunkSize int `yaml:"chunk_size"`
+}
+
+// validate проверяет настройки GELF
+func (c GELFConfig) validate() error {
+	var errs []error
+	switch c.Network {
+	case "", "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6":
+	default:
+		errs = append(errs, fmt.Errorf("unsupported gelf network: %s", c.Network))
+	}
+	if c.Address == "" {
+		errs = append(errs, errors.New("gelf address is required"))
+	}
+	switch c.Compression {
+	case "", GELFCompressGzip, GELFCompressZlib, GELFCompressNone:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported gelf compression: %s", c.Compression))
+	}
+	if c.ChunkSize != 0 && c.ChunkSize <= gelfChunkHeader {
+		errs = append(errs, fmt.Errorf("gelf chunk size must be greater than %d", gelfChunkHeader))
+	}
+	return errors.Join(errs...)
+}
+
+// openGELFSink подключается к Graylog и создает назначение
+func openGELFSink(config Config) (*gelfConn, *sink, error) {
+	c := config.GELF
+	if err := c.validate(); err != nil {
+		return nil, nil, err
+	}
+	hostname, _ := os.Hostname()
+	conn := &gelfConn{
+		network:     firstNonEmpty(c.Network, "udp"),
+		address:     c.Address,
+		compression: firstNonEmpty(c.Compression, GELFCompressGzip),
+		chunkSize:   c.ChunkSize,
+	}
+	if conn.chunkSize == 0 {
+		conn.chunkSize = defaultGELFChunkSize
+	}
+	if err := conn.connect(); err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to gelf: %w", err)
+	}
+	formatter := &gelfFormatter{host: firstNonEmpty(c.Host, hostname, "-")}
+	return conn, &sink{name: "gelf", writer: conn, formatter: formatter, binary: true}, nil
+}
+
+// gelfFormatter кодирует запись в GELF 1.1: поля записи передаются
+// дополнительными полями с префиксом _
+type gelfFormatter struct {
+	host string
+}
+
+// Format возвращает запись в формате GELF
+func (f *gelfFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	severity, ok := syslogSeverities[entry.Level]
+	if !ok {
+		severity = 7
+	}
+	msg := map[string]interface{}{
+		"version":   "1.1",
+		"host":      f.host,
+		"timestamp": float64(entry.Time.UnixMilli()) / 1000,
+		"level":     severity,
+	}
+	short, _, multiline := strings.Cut(entry.Message, "\n")
+	msg["short_message"] = firstNonEmpty(short, "-")
+	if multiline {
+		msg["full_message"] = entry.Message
+	}
+	if entry.HasCaller() {
+		msg["_file"] = entry.Caller.File
+		msg["_line"] = entry.Caller.Line
+		msg["_function"] = entry.Caller.Function
+	}
+
+	for k, v := range entry.Data {
+		name := "_" + gelfFieldName.ReplaceAllString(k, "_")
+		// _id зарезервировано Graylog
+		if name == "_" || name == "_id" {
+			name += "_"
+		}
+		msg[name] = gelfValue(v)
+	}
+	return json.Marshal(msg)
+}
+
+// gelfValue возвращает значение дополнительного поля: GELF допускает
+// только числа и строки
+func gelfValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	}
+	return plainValue(v)
+}
+
+// gelfConn соединение с Graylog. По UDP сообщения сжимаются и при
+// необходимости делятся на части, по TCP разделяются нулевым байтом
+type gelfConn struct {
+	network     string
+	address     string
+	compression string
+	chunkSize   int
+
+	mu     sync.Mutex
+	conn   net.Conn
+	closed bool
+}
+
+// stream сообщает, что соединение потоковое
+func (c *gelfConn) stream() bool {
+	return strings.HasPrefix(c.network, "tcp")
+}
+
+// connect подключается к Graylog
+func (c *gelfConn) connect() error {
+	conn, err := net.DialTimeout(c.network, c.address, syslogDialTimeout)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		conn.Close()
+		return net.ErrClosed
+	}
+	if c.conn != nil {
+		c.conn.Close()
+	}
+	c.conn = conn
+	return nil
+}
+
+// Write отправляет одно сообщение. Потоковое соединение переоткрывается
+// при ошибке записи
+func (c *gelfConn) Write(p []byte) (int, error) {
+	if c.stream() {
+		msg := bytes.TrimRight(p, "\n")
+		frame := append(make([]byte, 0, len(msg)+1), msg...)
+		frame = append(frame, 0)
+		if err := c.send(frame); err == nil || errors.Is(err, net.ErrClosed) {
+			return len(p), err
+		}
+		if err := c.connect(); err != nil {
+			return 0, err
+		}
+		if err := c.send(frame); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	data, err := c.compress(p)
+	if err != nil {
+		return 0, err
+	}
+	chunks, err := gelfChunks(data, c.chunkSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, chunk := range chunks {
+		if err := c.send(chunk); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (c *gelfConn) send(p []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return net.ErrClosed
+	}
+	_, err := c.conn.Write(p)
+	return err
+}
+
+// compress сжимает сообщение для отправки по UDP
+func (c *gelfConn) compress(p []byte) ([]byte, error) {
+	var b bytes.Buffer
+	var w io.WriteCloser
+	switch c.compression {
+	case GELFCompressNone:
+		return p, nil
+	case GELFCompressZlib:
+		w = zlib.NewWriter(&b)
+	default:
+		w = gzip.NewWriter(&b)
+	}
+	if _, err := w.Write(p); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// gelfChunks делит сообщение на части GELF, если оно больше size
+func gelfChunks(data []byte, size int) ([][]byte, error) {
+	if len(data) <= size {
+		return [][]byte{data}, nil
+	}
+	payload := size - gelfChunkHeader
+	count := (len(data) + payload - 1) / payload
+	if count > maxGELFChunks {
+		return nil, fmt.Errorf("gelf message too large: %d bytes in %d chunks", len(data), count)
+	}
+
+	var id [8]byte
+	binary.BigEndian.PutUint64(id[:], rand.Uint64())
+	chunks := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		part := data[i*payload : min((i+1)*payload, len(data))]
+		chunk := make([]byte, 0, gelfChunkHeader+len(part))
+		chunk = append(chunk, 0x1e, 0x0f)
+		chunk = append(chunk, id[:]...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunks = append(chunks, append(chunk, part...))
+	}
+	return chunks, nil
+}
+
+// Sync ничего не делает: сообщения отправляются сразу
+func (c *gelfConn) Sync() error {
+	return nil
+}
+
+// Reopen переподключается к Graylog
+func (c *gelfConn) Reopen() error {
+	return c.connect()
+}
+
+// Close закрывает соединение
+func (c *gelfConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	return c.conn.Close()
+}
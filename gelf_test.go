@@ -0,0 +1,169 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGELFFormatter(t *testing.T) {
+	f := &gelfFormatter{host: "api-1"}
+	data, err := f.Format(&logrus.Entry{
+		Level:   logrus.ErrorLevel,
+		Message: "payment failed\nstack trace",
+		Time:    time.Date(2024, 1, 15, 10, 0, 0, 250e6, time.UTC),
+		Data: logrus.Fields{
+			"order_id":  42,
+			"error":     errors.New("declined"),
+			"id":        "spoofed",
+			"http path": "/pay",
+			"retry":     true,
+		},
+	})
+	require.NoError(t, err)
+
+	var msg map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &msg))
+	assert.Equal(t, "1.1", msg["version"])
+	assert.Equal(t, "api-1", msg["host"])
+	assert.Equal(t, "payment failed", msg["short_message"])
+	assert.Equal(t, "payment failed\nstack trace", msg["full_message"])
+	assert.EqualValues(t, 3, msg["level"])
+	assert.EqualValues(t, 1705312800.25, msg["timestamp"])
+	assert.EqualValues(t, 42, msg["_order_id"])
+	assert.Equal(t, "declined", msg["_error"])
+	assert.Equal(t, "spoofed", msg["_id_"])
+	assert.Equal(t, "/pay", msg["_http_path"])
+	assert.Equal(t, "true", msg["_retry"])
+}
+
+func TestGELFOutput_UDP(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer server.Close()
+
+	log, err := New(Config{
+		Level:  InfoLevel,
+		Output: GELFOutput,
+		GELF:   GELFConfig{Address: server.LocalAddr().String(), Host: "api-1"},
+	})
+	require.NoError(t, err)
+	defer log.Close()
+
+	log.WithField("order_id", 42).Warn("slow payment")
+	gz, err := gzip.NewReader(strings.NewReader(readDatagram(t, server)))
+	require.NoError(t, err)
+	var msg map[string]interface{}
+	require.NoError(t, json.NewDecoder(gz).Decode(&msg))
+	assert.Equal(t, "slow payment", msg["short_message"])
+	assert.EqualValues(t, 4, msg["level"])
+	assert.EqualValues(t, 42, msg["_order_id"])
+}
+
+func TestGELFOutput_Chunking(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer server.Close()
+
+	log, err := New(Config{
+		Level:  InfoLevel,
+		Output: GELFOutput,
+		GELF:   GELFConfig{Address: server.LocalAddr().String(), Compression: GELFCompressNone, ChunkSize: 512},
+	})
+	require.NoError(t, err)
+	defer log.Close()
+
+	payload := strings.Repeat("x", 2000)
+	log.WithField("payload", payload).Info("large")
+
+	var id []byte
+	var parts [][]byte
+	for {
+		chunk := []byte(readDatagram(t, server))
+		require.LessOrEqual(t, len(chunk), 512)
+		require.Equal(t, []byte{0x1e, 0x0f}, chunk[:2])
+		if id == nil {
+			id = chunk[2:10]
+			parts = make([][]byte, chunk[11])
+		}
+		assert.Equal(t, id, chunk[2:10])
+		parts[chunk[10]] = chunk[12:]
+		if !missingParts(parts) {
+			break
+		}
+	}
+	assert.Len(t, parts, 5)
+
+	var msg map[string]interface{}
+	require.NoError(t, json.Unmarshal(bytes.Join(parts, nil), &msg))
+	assert.Equal(t, "large", msg["short_message"])
+	assert.Equal(t, payload, msg["_payload"])
+}
+
+// missingParts проверяет, остались ли неполученные части сообщения
+func missingParts(parts [][]byte) bool {
+	for _, p := range parts {
+		if p == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGELFChunks_TooLarge(t *testing.T) {
+	_, err := gelfChunks(make([]byte, 200*maxGELFChunks), 100)
+	assert.ErrorContains(t, err, "gelf message too large")
+}
+
+func TestGELFOutput_TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	log, err := New(Config{
+		Level:  InfoLevel,
+		Output: GELFOutput,
+		GELF:   GELFConfig{Network: "tcp", Address: ln.Addr().String()},
+	})
+	require.NoError(t, err)
+	defer log.Close()
+
+	conn, err := ln.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	log.Info("first")
+	log.Info("second")
+
+	r := bufio.NewReader(conn)
+	for _, want := range []string{"first", "second"} {
+		frame, err := r.ReadBytes(0)
+		require.NoError(t, err)
+		var msg map[string]interface{}
+		require.NoError(t, json.Unmarshal(frame[:len(frame)-1], &msg))
+		assert.Equal(t, want, msg["short_message"])
+	}
+}
+
+func TestGELFConfig_Validate(t *testing.T) {
+	err := Config{
+		Output: GELFOutput,
+		GELF:   GELFConfig{Network: "http", Compression: "br", ChunkSize: 8},
+	}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported gelf network: http")
+	assert.Contains(t, err.Error(), "gelf address is required")
+	assert.Contains(t, err.Error(), "unsupported gelf compression: br")
+	assert.Contains(t, err.Error(), "gelf chunk size must be greater than 12")
+}
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// StartHeartbeat периодически пишет запись alive со временем работы процесса
+// и счетчиками записанных записей, чтобы мониторинг по логам мог обнаружить
+// тихую остановку процесса. Запись пишется независимо от уровня логгера.
+// Возвращает функцию остановки, которая дожидается завершения горутины
+func (l *Logger) StartHeartbeat(interval time.Duration) (stop func()) {
+	if l == nil {
+		nilReceiver()
+		return func() {}
+	}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				l.heartbeat()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+}
+
+// heartbeat пишет одну запись alive
+func (l *Logger) heartbeat() {
+	counts := make(map[string]uint64, len(logrus.AllLevels))
+	var total uint64
+	for _, level := range logrus.AllLevels {
+		n := l.core.entries[level].Load()
+		counts[level.String()] = n
+		total += n
+	}
+
+	l.internalFields().WithFields(logrus.Fields{
+		"uptime_s":      int64(time.Since(l.core.started).Seconds()),
+		"entries_total": total,
+		"entries":       counts,
+	}).Log(logrus.Level(InfoLevel), "alive")
+}
@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_Heartbeat(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	logger.SetLevel(ErrorLevel)
+
+	logger.Error("first")
+	logger.Error("second")
+	logger.Info("dropped")
+	logger.heartbeat()
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 3)
+
+	alive := entries[2]
+	assert.Equal(t, "alive", alive["msg"])
+	assert.Equal(t, "info", alive["level"])
+	assert.Equal(t, float64(2), alive["entries_total"])
+	assert.Equal(t, float64(2), alive["entries"].(map[string]interface{})["error"])
+	assert.Contains(t, alive, "uptime_s")
+}
+
+func TestLogger_StartHeartbeat(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	stop := logger.StartHeartbeat(10 * time.Millisecond)
+	time.Sleep(35 * time.Millisecond)
+	stop()
+	stop()
+
+	count := len(decodeEntries(t, buf))
+	assert.GreaterOrEqual(t, count, 2)
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Len(t, decodeEntries(t, buf), count)
+}
+
+func TestLogger_HeartbeatPipeline(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(Config{
+		Level:         ErrorLevel,
+		Writers:       []io.Writer{buf},
+		DefaultFields: map[string]interface{}{"env": "prod"},
+	})
+	require.NoError(t, err)
+
+	// Служебные записи получают поля по умолчанию, но не зависят от порога уровня
+	logger.heartbeat()
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "alive", entries[0]["msg"])
+	assert.Equal(t, "prod", entries[0]["env"])
+}
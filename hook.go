@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Hook обработчик записей приложения: обогащение, отправка, алерты.
+// Fire вызывается синхронно до форматирования для записей уровней Levels,
+// прошедших порог логгера. При включенном скрытии hook получает значения
+// чувствительных полей уже скрытыми
+type Hook interface {
+	Levels() []Level
+	Fire(entry *HookEntry) error
+}
+
+// HookEntry запись, передаваемая в Hook. Запись только для чтения: hook
+// меняет поля через SetField и DeleteField, которые подменяют Entry новой
+// копией. Изменения попадают в запись для следующих hooks и назначений,
+// но не затрагивают копии, сохраненные другими hooks
+type HookEntry struct {
+	Entry
+}
+
+// SetField добавляет или заменяет поле записи
+func (e *HookEntry) SetField(key string, value interface{}) {
+	e.Entry = e.Entry.WithField(key, value)
+}
+
+// DeleteField удаляет поле записи
+func (e *HookEntry) DeleteField(key string) {
+	e.Entry = e.Entry.WithoutField(key)
+}
+
+// AddHook подключает hook ко всем логгерам, созданным от этого логгера.
+// Ошибки и паники hook пишутся logrus в stderr и не прерывают запись.
+// Hook с методом Close (io.Closer) закрывается в Logger.Close
+func (l *Logger) AddHook(h Hook) {
+	if l == nil {
+		nilReceiver()
+		return
+	}
+	levels := make([]logrus.Level, 0, len(h.Levels()))
+	for _, level := range h.Levels() {
+		levels = append(levels, logrus.Level(level))
+	}
+	l.logger.AddHook(&hookAdapter{hook: h, levels: levels})
+
+	if closer, ok := h.(io.Closer); ok {
+		l.core.mu.Lock()
+		l.core.closers = append(l.core.closers, closer)
+		l.core.mu.Unlock()
+	}
+}
+
+// closeHooks закрывает hooks с методом Close
+func (c *core) closeHooks() error {
+	c.mu.RLock()
+	closers := c.closers
+	c.mu.RUnlock()
+
+	var errs []error
+	for _, closer := range closers {
+		if err := closer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// hookAdapter hook logrus, вызывающий Hook пакета
+type hookAdapter struct {
+	hook   Hook
+	levels []logrus.Level
+}
+
+// Levels возвращает уровни, для которых вызывается hook
+func (a *hookAdapter) Levels() []logrus.Level {
+	return a.levels
+}
+
+// Fire передает запись в hook и переносит изменения полей в запись
+func (a *hookAdapter) Fire(entry *logrus.Entry) (err error) {
+	view := entry
+	if l := entryLogger(entry); l != nil {
+		if !l.enabled(Level(entry.Level)) {
+			return nil
+		}
+		// Hooks отправляют записи во внешние системы в обход назначений,
+		// поэтому скрытие применяется до них
+		view = l.core.redactEntry(entry)
+	}
+
+	he := &HookEntry{Entry: entryView(view)}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("hook panic: %v", r)
+		}
+	}()
+	if err := a.hook.Fire(he); err != nil {
+		return err
+	}
+	// Поля Entry не меняются после создания, поэтому запись может
+	// ссылаться на них без копирования
+	entry.Data = he.fields
+	return nil
+}
@@ -0,0 +1,166 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingHook struct {
+	levels []Level
+	fire   func(entry *HookEntry) error
+
+	mu      sync.Mutex
+	entries []HookEntry
+}
+
+func (h *recordingHook) Levels() []Level {
+	return h.levels
+}
+
+func (h *recordingHook) Fire(entry *HookEntry) error {
+	h.mu.Lock()
+	h.entries = append(h.entries, *entry)
+	h.mu.Unlock()
+	if h.fire != nil {
+		return h.fire(entry)
+	}
+	return nil
+}
+
+func TestLogger_AddHook(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+	logger.SetLevel(InfoLevel)
+
+	hook := &recordingHook{levels: []Level{ErrorLevel, WarnLevel, InfoLevel, DebugLevel}}
+	logger.AddHook(hook)
+
+	api := logger.WithService("api")
+	api.WithField("order_id", 42).Warn("payment delayed")
+	api.Debug("below level")
+	api.Info("request handled")
+
+	require.Len(t, hook.entries, 2)
+	assert.Equal(t, WarnLevel, hook.entries[0].Level())
+	assert.Equal(t, "payment delayed", hook.entries[0].Message())
+	assert.Equal(t, "api", hook.entries[0].Fields()["service"])
+	assert.Equal(t, 42, hook.entries[0].Fields()["order_id"])
+	assert.False(t, hook.entries[0].Time().IsZero())
+	assert.NotNil(t, hook.entries[0].Context())
+	assert.Equal(t, InfoLevel, hook.entries[1].Level())
+}
+
+func TestLogger_AddHook_Levels(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+
+	hook := &recordingHook{levels: []Level{ErrorLevel}}
+	logger.AddHook(hook)
+
+	logger.Info("ignored")
+	logger.Error("alert")
+
+	require.Len(t, hook.entries, 1)
+	assert.Equal(t, "alert", hook.entries[0].Message())
+}
+
+func TestLogger_AddHook_EnrichesEntry(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	logger.AddHook(&recordingHook{levels: AllLevels, fire: func(entry *HookEntry) error {
+		entry.SetField("region", "eu-west-1")
+		entry.DeleteField("token")
+		return nil
+	}})
+
+	logger.WithField("token", "secret").Info("enriched")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "eu-west-1", entries[0]["region"])
+	assert.NotContains(t, entries[0], "token")
+}
+
+func TestLogger_AddHook_SeesRedactedFields(t *testing.T) {
+	logger, err := New(Config{Level: InfoLevel, Writers: []io.Writer{io.Discard}, Redact: true})
+	require.NoError(t, err)
+	hook := &recordingHook{levels: AllLevels}
+	logger.AddHook(hook)
+
+	logger.WithField("password", "hunter2").WithField("user", "alice").Info("login")
+
+	require.Len(t, hook.entries, 1)
+	assert.Equal(t, redactedValue, hook.entries[0].Fields()["password"])
+	assert.Equal(t, "alice", hook.entries[0].Fields()["user"])
+}
+
+func TestLogger_AddHook_RetainedEntryIsolated(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	// Первый hook сохраняет запись, как асинхронные hooks отправки,
+	// второй обогащает ее: сохраненная копия не должна меняться
+	first := &recordingHook{levels: AllLevels}
+	logger.AddHook(first)
+	logger.AddHook(&recordingHook{levels: AllLevels, fire: func(entry *HookEntry) error {
+		entry.SetField("region", "eu-west-1")
+		fields := entry.Fields()
+		fields["leaked"] = true
+		return nil
+	}})
+
+	logger.WithField("order_id", 42).Info("enriched")
+
+	require.Len(t, first.entries, 1)
+	_, ok := first.entries[0].Field("region")
+	assert.False(t, ok)
+	order, _ := first.entries[0].Field("order_id")
+	assert.Equal(t, 42, order)
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "eu-west-1", entries[0]["region"])
+	assert.NotContains(t, entries[0], "leaked")
+}
+
+func TestLogger_AddHook_ErrorsDoNotStopEntry(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	logger.AddHook(&recordingHook{levels: AllLevels, fire: func(entry *HookEntry) error {
+		entry.SetField("partial", true)
+		return errors.New("shipping failed")
+	}})
+	logger.AddHook(&recordingHook{levels: AllLevels, fire: func(*HookEntry) error {
+		panic("broken hook")
+	}})
+
+	logger.Info("still written")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "still written", entries[0]["msg"])
+	assert.NotContains(t, entries[0], "partial")
+}
+
+type closingHook struct {
+	recordingHook
+	closed int
+}
+
+func (h *closingHook) Close() error {
+	h.closed++
+	return errors.New("flush timeout")
+}
+
+func TestLogger_AddHook_ClosedWithLogger(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+
+	hook := &closingHook{recordingHook: recordingHook{levels: AllLevels}}
+	logger.AddHook(hook)
+
+	assert.ErrorContains(t, logger.Close(), "flush timeout")
+	assert.NoError(t, logger.Close())
+	assert.Equal(t, 1, hook.closed)
+}
@@ -0,0 +1,266 @@
+// Package email отправляет по почте сводки записей уровней Error, Fatal и Panic.
+//
+// Записи накапливаются и раз в Interval уходят одним письмом; в письмо попадает
+// не больше MaxEntries записей, остальные только считаются. Записи Fatal и
+// Panic отправляются сразу вместе с накопленными, но не чаще одного письма за
+// ImmediateInterval, чтобы шторм ошибок не превратился в тысячи писем
+package email
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ex-rate/logger"
+)
+
+const (
+	defaultInterval          = 5 * time.Minute
+	defaultImmediateInterval = time.Minute
+	defaultMaxEntries        = 100
+	defaultSubject           = "[logger]"
+)
+
+// Config настройки hook
+type Config struct {
+	Addr     string   `yaml:"addr"` // адрес SMTP-сервера host:port
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+	Subject  string   `yaml:"subject"` // префикс темы письма, по умолчанию [logger]
+
+	Levels []logger.Level `yaml:"levels"` // уровни записей, по умолчанию error, fatal и panic
+
+	Interval          time.Duration `yaml:"interval"`           // период сводок, по умолчанию 5m
+	ImmediateInterval time.Duration `yaml:"immediate_interval"` // минимум между срочными письмами, по умолчанию 1m
+	MaxEntries        int           `yaml:"max_entries"`        // записей в письме, по умолчанию 100
+
+	// SendMail отправляет письмо, по умолчанию smtp.SendMail
+	SendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error `yaml:"-"`
+	OnError  func(error)                                                                `yaml:"-"` // ошибки отправки, по умолчанию пишутся в stderr
+}
+
+// record запись в сводке
+type record struct {
+	time    time.Time
+	level   logger.Level
+	service string
+	message string
+	fields  map[string]interface{}
+}
+
+// Hook накапливает записи и отправляет их сводками
+type Hook struct {
+	config Config
+	auth   smtp.Auth
+	now    func() time.Time
+
+	mu            sync.Mutex
+	records       []record
+	omitted       int
+	lastImmediate time.Time
+	closed        bool
+
+	sendMu sync.Mutex // письма отправляются по одному
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// New проверяет настройки и запускает отправку сводок
+func New(config Config) (*Hook, error) {
+	if config.Addr == "" {
+		return nil, errors.New("smtp addr is required")
+	}
+	if config.From == "" || len(config.To) == 0 {
+		return nil, errors.New("email sender and recipients are required")
+	}
+	if len(config.Levels) == 0 {
+		config.Levels = []logger.Level{logger.PanicLevel, logger.FatalLevel, logger.ErrorLevel}
+	}
+	if config.Subject == "" {
+		config.Subject = defaultSubject
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaultInterval
+	}
+	if config.ImmediateInterval <= 0 {
+		config.ImmediateInterval = defaultImmediateInterval
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = defaultMaxEntries
+	}
+	if config.SendMail == nil {
+		config.SendMail = smtp.SendMail
+	}
+	if config.OnError == nil {
+		config.OnError = func(err error) { fmt.Fprintf(os.Stderr, "email: %v\n", err) }
+	}
+
+	h := &Hook{
+		config: config,
+		now:    time.Now,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if config.Username != "" {
+		host, _, err := net.SplitHostPort(config.Addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid smtp addr: %w", err)
+		}
+		h.auth = smtp.PlainAuth("", config.Username, config.Password, host)
+	}
+	go h.run()
+	return h, nil
+}
+
+// Levels возвращает уровни, для которых вызывается hook
+func (h *Hook) Levels() []logger.Level {
+	return h.config.Levels
+}
+
+// Fire добавляет запись в сводку; Fatal и Panic отправляют сводку сразу
+func (h *Hook) Fire(entry *logger.HookEntry) error {
+	value, _ := entry.Field("service")
+	service, _ := value.(string)
+	fields := entry.WithoutField("service").Fields()
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil
+	}
+	if len(h.records) < h.config.MaxEntries {
+		h.records = append(h.records, record{
+			time:    entry.Time(),
+			level:   entry.Level(),
+			service: service,
+			message: entry.Message(),
+			fields:  fields,
+		})
+	} else {
+		h.omitted++
+	}
+	immediate := false
+	if entry.Level() <= logger.FatalLevel {
+		now := h.now()
+		if h.lastImmediate.IsZero() || now.Sub(h.lastImmediate) >= h.config.ImmediateInterval {
+			h.lastImmediate = now
+			immediate = true
+		}
+	}
+	h.mu.Unlock()
+
+	if immediate {
+		h.flush()
+	}
+	return nil
+}
+
+// Close отправляет накопленные записи и останавливает отправку сводок
+func (h *Hook) Close() error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil
+	}
+	h.closed = true
+	h.mu.Unlock()
+
+	close(h.stop)
+	<-h.done
+	h.flush()
+	return nil
+}
+
+// run отправляет сводки раз в Interval
+func (h *Hook) run() {
+	defer close(h.done)
+	ticker := time.NewTicker(h.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-ticker.C:
+			h.flush()
+		}
+	}
+}
+
+// flush отправляет накопленные записи одним письмом
+func (h *Hook) flush() {
+	h.sendMu.Lock()
+	defer h.sendMu.Unlock()
+
+	h.mu.Lock()
+	records, omitted := h.records, h.omitted
+	h.records, h.omitted = nil, 0
+	h.mu.Unlock()
+
+	if len(records) == 0 {
+		return
+	}
+	msg := h.message(records, omitted)
+	if err := h.config.SendMail(h.config.Addr, h.auth, h.config.From, h.config.To, msg); err != nil {
+		h.config.OnError(err)
+	}
+}
+
+// message строит письмо со сводкой
+func (h *Hook) message(records []record, omitted int) []byte {
+	total := len(records) + omitted
+	subject := fmt.Sprintf("%s %d error entries", h.config.Subject, total)
+	for _, r := range records {
+		if r.level <= logger.FatalLevel {
+			subject = fmt.Sprintf("%s %s: %s", h.config.Subject, r.level, r.message)
+			if total > 1 {
+				subject += fmt.Sprintf(" (+%d more)", total-1)
+			}
+			break
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", h.config.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(h.config.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", headerValue(subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", h.now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+
+	for _, r := range records {
+		fmt.Fprintf(&b, "%s %s", r.time.Format(time.RFC3339), r.level)
+		if r.service != "" {
+			fmt.Fprintf(&b, " %s", r.service)
+		}
+		fmt.Fprintf(&b, ": %s\r\n", r.message)
+
+		keys := make([]string, 0, len(r.fields))
+		for k := range r.fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(&b, "    %s=%v\r\n", k, r.fields[k])
+		}
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&b, "\r\n%d more entries omitted\r\n", omitted)
+	}
+	return b.Bytes()
+}
+
+// headerValue убирает переводы строк из значения заголовка и кодирует
+// не-ASCII символы по RFC 2047
+func headerValue(s string) string {
+	return mime.QEncoding.Encode("utf-8", strings.NewReplacer("\r", " ", "\n", " ").Replace(s))
+}
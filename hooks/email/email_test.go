@@ -0,0 +1,142 @@
+package email
+
+import (
+	"io"
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ex-rate/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// outbox собирает отправленные письма
+type outbox struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (o *outbox) send(addr string, _ smtp.Auth, from string, to []string, msg []byte) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.messages = append(o.messages, string(msg))
+	return nil
+}
+
+func (o *outbox) sent() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]string(nil), o.messages...)
+}
+
+func newTestLogger(t *testing.T, config Config) (*logger.Logger, *Hook, *outbox) {
+	t.Helper()
+
+	box := &outbox{}
+	config.Addr = "smtp.example.com:587"
+	config.From = "alerts@example.com"
+	config.To = []string{"oncall@example.com", "team@example.com"}
+	config.SendMail = box.send
+	hook, err := New(config)
+	require.NoError(t, err)
+
+	l, err := logger.New(logger.Config{Level: logger.InfoLevel, Writers: []io.Writer{io.Discard}})
+	require.NoError(t, err)
+	l.AddHook(hook)
+	return l, hook, box
+}
+
+func TestHook_Digest(t *testing.T) {
+	l, _, box := newTestLogger(t, Config{Interval: time.Hour})
+
+	l.WithService("orders").WithField("order_id", 42).Error("payment failed")
+	l.Warn("not included")
+	l.Error("db timeout")
+	assert.Empty(t, box.sent())
+
+	require.NoError(t, l.Close())
+	require.Len(t, box.sent(), 1)
+	msg := box.sent()[0]
+	assert.Contains(t, msg, "To: oncall@example.com, team@example.com\r\n")
+	assert.Contains(t, msg, "Subject: [logger] 2 error entries\r\n")
+	assert.Contains(t, msg, "error orders: payment failed\r\n    order_id=42\r\n")
+	assert.Contains(t, msg, "error: db timeout\r\n")
+	assert.NotContains(t, msg, "not included")
+}
+
+func TestHook_DigestOnInterval(t *testing.T) {
+	l, _, box := newTestLogger(t, Config{Interval: 10 * time.Millisecond})
+
+	l.Error("db timeout")
+	assert.Eventually(t, func() bool { return len(box.sent()) == 1 }, time.Second, time.Millisecond)
+	require.NoError(t, l.Close())
+	assert.Len(t, box.sent(), 1)
+}
+
+func TestHook_Throttling(t *testing.T) {
+	l, hook, box := newTestLogger(t, Config{Interval: time.Hour, MaxEntries: 3, Subject: "[orders]"})
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	hook.now = func() time.Time { return now }
+
+	for i := 0; i < 1000; i++ {
+		l.Error("db timeout")
+	}
+	require.NoError(t, l.Close())
+
+	require.Len(t, box.sent(), 1)
+	msg := box.sent()[0]
+	assert.Contains(t, msg, "Subject: [orders] 1000 error entries\r\n")
+	assert.Equal(t, 3, strings.Count(msg, "db timeout"))
+	assert.Contains(t, msg, "997 more entries omitted")
+}
+
+func TestHook_ImmediateFatal(t *testing.T) {
+	_, hook, box := newTestLogger(t, Config{Interval: time.Hour})
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	hook.now = func() time.Time { return now }
+
+	fire := func(level logger.Level, msg string) {
+		require.NoError(t, hook.Fire(&logger.HookEntry{Entry: logger.NewEntry(now, level, msg, nil)}))
+	}
+
+	fire(logger.ErrorLevel, "db timeout")
+	fire(logger.FatalLevel, "cannot open store")
+	require.Len(t, box.sent(), 1)
+	assert.Contains(t, box.sent()[0], "Subject: [logger] fatal: cannot open store (+1 more)\r\n")
+	assert.Contains(t, box.sent()[0], "db timeout")
+
+	// Повторная Fatal в пределах ImmediateInterval ждет сводки
+	fire(logger.FatalLevel, "still broken")
+	assert.Len(t, box.sent(), 1)
+
+	now = now.Add(time.Minute)
+	fire(logger.PanicLevel, "corrupted index")
+	require.Len(t, box.sent(), 2)
+	assert.Contains(t, box.sent()[1], "still broken")
+
+	require.NoError(t, hook.Close())
+}
+
+func TestHook_EncodesSubject(t *testing.T) {
+	_, hook, box := newTestLogger(t, Config{})
+
+	require.NoError(t, hook.Fire(&logger.HookEntry{Entry: logger.NewEntry(time.Time{}, logger.FatalLevel, "нет связи\nс базой", nil)}))
+	require.NoError(t, hook.Close())
+
+	require.Len(t, box.sent(), 1)
+	assert.Contains(t, box.sent()[0], "Subject: =?utf-8?q?")
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	for _, config := range []Config{
+		{},
+		{Addr: "smtp.example.com:25"},
+		{Addr: "smtp.example.com", From: "a@example.com", To: []string{"b@example.com"}, Username: "user"},
+	} {
+		_, err := New(config)
+		assert.Error(t, err, config)
+	}
+}
@@ -0,0 +1,20 @@
+module github.com/ex-rate/logger/hooks/sentry
+
+go 1.24.5
+
+require (
+	github.com/ex-rate/logger v0.0.0
+	github.com/getsentry/sentry-go v0.29.1
+	github.com/stretchr/testify v1.11.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/sirupsen/logrus v1.9.3 // indirect
+	golang.org/x/sys v0.18.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/ex-rate/logger => ../../
@@ -0,0 +1,182 @@
+// Package sentry передает записи уровней Error, Fatal и Panic в Sentry.
+//
+// Hook подключается через Logger.AddHook и закрывается вместе с логгером:
+// Close дожидается отправки накопленных событий. Сообщение записи становится
+// сообщением события, поле service - тегом, остальные поля - extra. Ошибка из
+// WithError передается исключением со стеком ошибки, если он есть, иначе к
+// событию прикладывается стек места записи.
+// Пакет вынесен в отдельный модуль, чтобы основной пакет не зависел от sentry-go
+package sentry
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ex-rate/logger"
+	sentrygo "github.com/getsentry/sentry-go"
+)
+
+// defaultFlushTimeout время ожидания отправки событий при закрытии
+const defaultFlushTimeout = 2 * time.Second
+
+// errorKey поле ошибки, как у WithError
+const errorKey = "error"
+
+// Config настройки hook
+type Config struct {
+	DSN          string         `yaml:"dsn"`
+	Environment  string         `yaml:"environment"`
+	Release      string         `yaml:"release"`
+	ServerName   string         `yaml:"server_name"`
+	Levels       []logger.Level `yaml:"levels"`        // уровни событий, по умолчанию error, fatal и panic
+	FlushTimeout time.Duration  `yaml:"flush_timeout"` // ожидание отправки при закрытии, по умолчанию 2s
+}
+
+// Hook отправляет записи в Sentry
+type Hook struct {
+	hub          *sentrygo.Hub
+	levels       []logger.Level
+	flushTimeout time.Duration
+}
+
+// New создает клиент Sentry с собственным DSN и hook для него
+func New(config Config) (*Hook, error) {
+	if config.DSN == "" {
+		return nil, errors.New("sentry dsn is required")
+	}
+	client, err := sentrygo.NewClient(sentrygo.ClientOptions{
+		Dsn:         config.DSN,
+		Environment: config.Environment,
+		Release:     config.Release,
+		ServerName:  config.ServerName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sentry client: %w", err)
+	}
+	return NewWithHub(sentrygo.NewHub(client, sentrygo.NewScope()), config), nil
+}
+
+// NewWithHub создает hook для уже настроенного hub, например sentrygo.CurrentHub().
+// DSN и настройки клиента из config не используются
+func NewWithHub(hub *sentrygo.Hub, config Config) *Hook {
+	h := &Hook{hub: hub, levels: config.Levels, flushTimeout: config.FlushTimeout}
+	if len(h.levels) == 0 {
+		h.levels = []logger.Level{logger.PanicLevel, logger.FatalLevel, logger.ErrorLevel}
+	}
+	if h.flushTimeout <= 0 {
+		h.flushTimeout = defaultFlushTimeout
+	}
+	return h
+}
+
+// Levels возвращает уровни, для которых вызывается hook
+func (h *Hook) Levels() []logger.Level {
+	return h.levels
+}
+
+// Fire отправляет запись событием Sentry. Записи Fatal и Panic отправляются
+// синхронно: после них процесс обычно завершается
+func (h *Hook) Fire(entry *logger.HookEntry) error {
+	h.hub.CaptureEvent(newEvent(entry))
+	if entry.Level() <= logger.FatalLevel {
+		h.hub.Flush(h.flushTimeout)
+	}
+	return nil
+}
+
+// Close дожидается отправки накопленных событий
+func (h *Hook) Close() error {
+	if !h.hub.Flush(h.flushTimeout) {
+		return errors.New("sentry: flush timed out")
+	}
+	return nil
+}
+
+// newEvent переводит запись в событие Sentry
+func newEvent(entry *logger.HookEntry) *sentrygo.Event {
+	event := sentrygo.NewEvent()
+	event.Level = eventLevel(entry.Level())
+	event.Message = entry.Message()
+	event.Timestamp = entry.Time()
+	event.Logger = "github.com/ex-rate/logger"
+
+	for key, value := range entry.Fields() {
+		switch {
+		case key == "service":
+			event.Tags["service"] = fmt.Sprint(value)
+		case key == errorKey:
+			if err, ok := value.(error); ok {
+				event.Exception = []sentrygo.Exception{{
+					Type:       reflect.TypeOf(err).String(),
+					Value:      err.Error(),
+					Stacktrace: errorStacktrace(err),
+				}}
+				continue
+			}
+			event.Extra[key] = value
+		default:
+			if err, ok := value.(error); ok {
+				value = err.Error()
+			}
+			event.Extra[key] = value
+		}
+	}
+
+	if len(event.Exception) == 0 {
+		event.Threads = []sentrygo.Thread{{Stacktrace: callerStacktrace(), Current: true}}
+	}
+	return event
+}
+
+// eventLevel переводит уровень записи в уровень Sentry
+func eventLevel(level logger.Level) sentrygo.Level {
+	switch level {
+	case logger.PanicLevel, logger.FatalLevel:
+		return sentrygo.LevelFatal
+	case logger.ErrorLevel:
+		return sentrygo.LevelError
+	case logger.WarnLevel:
+		return sentrygo.LevelWarning
+	case logger.InfoLevel:
+		return sentrygo.LevelInfo
+	}
+	return sentrygo.LevelDebug
+}
+
+// errorStacktrace возвращает стек ошибки (pkg/errors, go-errors) или стек места записи
+func errorStacktrace(err error) *sentrygo.Stacktrace {
+	if stack := sentrygo.ExtractStacktrace(err); stack != nil {
+		return stack
+	}
+	return callerStacktrace()
+}
+
+// callerStacktrace возвращает стек места записи без кадров логгера и logrus
+func callerStacktrace() *sentrygo.Stacktrace {
+	stack := sentrygo.NewStacktrace()
+	if stack == nil {
+		return nil
+	}
+	frames := stack.Frames[:0]
+	for _, frame := range stack.Frames {
+		if internalModule(frame.Module) {
+			continue
+		}
+		frames = append(frames, frame)
+	}
+	stack.Frames = frames
+	return stack
+}
+
+// internalModule проверяет, что кадр принадлежит логгеру, logrus или этому пакету
+func internalModule(module string) bool {
+	for _, prefix := range []string{"github.com/sirupsen/logrus", "github.com/ex-rate/logger"} {
+		if module == prefix || strings.HasPrefix(module, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
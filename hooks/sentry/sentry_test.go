@@ -0,0 +1,90 @@
+package sentry
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/ex-rate/logger"
+	sentrygo "github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// capture собирает события, не отправляя их
+type capture struct {
+	mu     sync.Mutex
+	events []*sentrygo.Event
+}
+
+func (c *capture) beforeSend(event *sentrygo.Event, _ *sentrygo.EventHint) *sentrygo.Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = append(c.events, event)
+	return nil
+}
+
+func newTestLogger(t *testing.T, config Config) (*logger.Logger, *capture) {
+	t.Helper()
+
+	c := &capture{}
+	client, err := sentrygo.NewClient(sentrygo.ClientOptions{BeforeSend: c.beforeSend})
+	require.NoError(t, err)
+
+	l, err := logger.New(logger.Config{Level: logger.DebugLevel, Writers: []io.Writer{io.Discard}})
+	require.NoError(t, err)
+	l.AddHook(NewWithHub(sentrygo.NewHub(client, sentrygo.NewScope()), config))
+	return l, c
+}
+
+func TestHook_ForwardsErrors(t *testing.T) {
+	l, c := newTestLogger(t, Config{})
+
+	payments := l.WithService("payments")
+	payments.Info("not forwarded")
+	payments.WithField("order_id", 42).WithError(errors.New("card declined")).Error("charge failed")
+	require.NoError(t, l.Close())
+
+	require.Len(t, c.events, 1)
+	event := c.events[0]
+	assert.Equal(t, sentrygo.LevelError, event.Level)
+	assert.Equal(t, "charge failed", event.Message)
+	assert.Equal(t, "payments", event.Tags["service"])
+	assert.Equal(t, 42, event.Extra["order_id"])
+	require.Len(t, event.Exception, 1)
+	assert.Equal(t, "card declined", event.Exception[0].Value)
+	assert.Equal(t, "*errors.errorString", event.Exception[0].Type)
+}
+
+func TestHook_AttachesCallerStack(t *testing.T) {
+	l, c := newTestLogger(t, Config{})
+
+	l.Error("no error value")
+	require.NoError(t, l.Close())
+
+	require.Len(t, c.events, 1)
+	require.Len(t, c.events[0].Threads, 1)
+	stack := c.events[0].Threads[0].Stacktrace
+	require.NotNil(t, stack)
+	for _, frame := range stack.Frames {
+		assert.NotContains(t, frame.Module, "sirupsen/logrus")
+	}
+}
+
+func TestHook_Levels(t *testing.T) {
+	l, c := newTestLogger(t, Config{Levels: []logger.Level{logger.WarnLevel}})
+
+	l.Warn("degraded")
+	l.Error("not forwarded")
+	require.NoError(t, l.Close())
+
+	require.Len(t, c.events, 1)
+	assert.Equal(t, sentrygo.LevelWarning, c.events[0].Level)
+	assert.Equal(t, "degraded", c.events[0].Message)
+}
+
+func TestNew_RequiresDSN(t *testing.T) {
+	_, err := New(Config{})
+	assert.Error(t, err)
+}
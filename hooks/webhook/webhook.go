@@ -0,0 +1,290 @@
+// Package webhook отправляет записи уровней Error, Fatal и Panic в Slack,
+// Telegram или произвольный webhook, чтобы дежурные сразу узнавали об авариях.
+//
+// Сообщения строятся шаблоном text/template и отправляются в фоне; число
+// сообщений ограничено RateLimit за RateInterval, пропущенные сверх лимита
+// записи учитываются в следующем сообщении. Записи Fatal и Panic отправляются
+// синхронно: после них процесс обычно завершается
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/ex-rate/logger"
+)
+
+// Format формат тела запроса
+type Format string
+
+const (
+	// GenericFormat JSON с полями записи и готовым текстом text
+	GenericFormat Format = "generic"
+	// SlackFormat тело входящего webhook Slack: {"text": ...}
+	SlackFormat Format = "slack"
+	// TelegramFormat тело метода sendMessage Bot API: {"chat_id": ..., "text": ...}
+	TelegramFormat Format = "telegram"
+)
+
+// DefaultTemplate шаблон сообщения по умолчанию
+const DefaultTemplate = `[{{.Level}}]{{if .Service}} {{.Service}}:{{end}} {{.Message}}{{if .Suppressed}} (+{{.Suppressed}} suppressed){{end}}`
+
+const (
+	defaultRateLimit    = 10
+	defaultRateInterval = time.Minute
+	defaultTimeout      = 5 * time.Second
+	queueSize           = 64
+)
+
+// Config настройки hook
+type Config struct {
+	URL    string `yaml:"url"`     // адрес webhook; для Telegram - https://api.telegram.org/bot<token>/sendMessage
+	Format Format `yaml:"format"`  // generic (по умолчанию), slack или telegram
+	ChatID string `yaml:"chat_id"` // чат Telegram
+
+	// Template шаблон текста сообщения, по умолчанию DefaultTemplate.
+	// Доступны .Level, .Service, .Message, .Time, .Fields и .Suppressed
+	Template string `yaml:"template"`
+
+	Levels []logger.Level `yaml:"levels"` // уровни записей, по умолчанию error, fatal и panic
+
+	RateLimit    int           `yaml:"rate_limit"`    // сообщений за RateInterval, по умолчанию 10
+	RateInterval time.Duration `yaml:"rate_interval"` // по умолчанию 1m
+	Timeout      time.Duration `yaml:"timeout"`       // таймаут запроса, по умолчанию 5s
+
+	Client  *http.Client `yaml:"-"` // по умолчанию http.Client с Timeout
+	OnError func(error)  `yaml:"-"` // ошибки отправки, по умолчанию пишутся в stderr
+}
+
+// Message данные шаблона сообщения
+type Message struct {
+	Level      string
+	Service    string
+	Message    string
+	Time       time.Time
+	Fields     map[string]interface{}
+	Suppressed int // записи, пропущенные по лимиту с прошлого сообщения
+}
+
+// Hook отправляет записи в webhook
+type Hook struct {
+	config   Config
+	template *template.Template
+	client   *http.Client
+
+	mu          sync.Mutex
+	windowStart time.Time
+	sent        int
+	suppressed  int
+	now         func() time.Time
+
+	queue  chan Message
+	done   chan struct{}
+	closed bool
+}
+
+// New проверяет настройки и запускает фоновую отправку
+func New(config Config) (*Hook, error) {
+	if config.URL == "" {
+		return nil, errors.New("webhook url is required")
+	}
+	switch config.Format {
+	case "":
+		config.Format = GenericFormat
+	case GenericFormat, SlackFormat:
+	case TelegramFormat:
+		if config.ChatID == "" {
+			return nil, errors.New("telegram chat id is required")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported webhook format: %s", config.Format)
+	}
+	if config.Template == "" {
+		config.Template = DefaultTemplate
+	}
+	tmpl, err := template.New("webhook").Parse(config.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook template: %w", err)
+	}
+	if len(config.Levels) == 0 {
+		config.Levels = []logger.Level{logger.PanicLevel, logger.FatalLevel, logger.ErrorLevel}
+	}
+	if config.RateLimit <= 0 {
+		config.RateLimit = defaultRateLimit
+	}
+	if config.RateInterval <= 0 {
+		config.RateInterval = defaultRateInterval
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultTimeout
+	}
+	client := config.Client
+	if client == nil {
+		client = &http.Client{Timeout: config.Timeout}
+	}
+	if config.OnError == nil {
+		config.OnError = func(err error) { fmt.Fprintf(os.Stderr, "webhook: %v\n", err) }
+	}
+
+	h := &Hook{
+		config:   config,
+		template: tmpl,
+		client:   client,
+		now:      time.Now,
+		queue:    make(chan Message, queueSize),
+		done:     make(chan struct{}),
+	}
+	go h.run()
+	return h, nil
+}
+
+// Levels возвращает уровни, для которых вызывается hook
+func (h *Hook) Levels() []logger.Level {
+	return h.config.Levels
+}
+
+// Fire ставит запись в очередь отправки с учетом лимита
+func (h *Hook) Fire(entry *logger.HookEntry) error {
+	msg, ok := h.allow(entry)
+	if !ok {
+		return nil
+	}
+	if entry.Level() <= logger.FatalLevel {
+		h.send(msg)
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil
+	}
+	select {
+	case h.queue <- msg:
+	default:
+		h.suppressed += msg.Suppressed + 1
+	}
+	return nil
+}
+
+// Close отправляет сообщения из очереди и останавливает фоновую отправку
+func (h *Hook) Close() error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil
+	}
+	h.closed = true
+	close(h.queue)
+	h.mu.Unlock()
+
+	<-h.done
+	return nil
+}
+
+// allow проверяет лимит и строит сообщение. Записи Fatal и Panic отправляются
+// сверх лимита
+func (h *Hook) allow(entry *logger.HookEntry) (Message, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	if now.Sub(h.windowStart) >= h.config.RateInterval {
+		h.windowStart = now
+		h.sent = 0
+	}
+	if h.sent >= h.config.RateLimit && entry.Level() > logger.FatalLevel {
+		h.suppressed++
+		return Message{}, false
+	}
+	h.sent++
+
+	fields := entry.Fields()
+	service, _ := fields["service"].(string)
+	msg := Message{
+		Level:      entry.Level().String(),
+		Service:    service,
+		Message:    entry.Message(),
+		Time:       entry.Time(),
+		Fields:     fields,
+		Suppressed: h.suppressed,
+	}
+	h.suppressed = 0
+	return msg, true
+}
+
+// run отправляет сообщения из очереди
+func (h *Hook) run() {
+	defer close(h.done)
+	for msg := range h.queue {
+		h.send(msg)
+	}
+}
+
+// send отправляет сообщение и передает ошибку в OnError
+func (h *Hook) send(msg Message) {
+	if err := h.post(msg); err != nil {
+		h.config.OnError(err)
+	}
+}
+
+// post строит тело запроса и отправляет его
+func (h *Hook) post(msg Message) error {
+	var text bytes.Buffer
+	if err := h.template.Execute(&text, msg); err != nil {
+		return fmt.Errorf("render message: %w", err)
+	}
+
+	var payload interface{}
+	switch h.config.Format {
+	case SlackFormat:
+		payload = map[string]string{"text": text.String()}
+	case TelegramFormat:
+		payload = map[string]string{"chat_id": h.config.ChatID, "text": text.String()}
+	default:
+		payload = map[string]interface{}{
+			"level":      msg.Level,
+			"service":    msg.Service,
+			"message":    msg.Message,
+			"time":       msg.Time,
+			"fields":     jsonFields(msg.Fields),
+			"suppressed": msg.Suppressed,
+			"text":       text.String(),
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode payload: %w", err)
+	}
+
+	resp, err := h.client.Post(h.config.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return nil
+}
+
+// jsonFields приводит ошибки в полях к строкам: error кодируется в JSON как {}
+func jsonFields(fields map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,141 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ex-rate/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// server принимает тела запросов webhook
+type server struct {
+	*httptest.Server
+	mu     sync.Mutex
+	bodies []map[string]interface{}
+	status int
+}
+
+func newServer(t *testing.T) *server {
+	t.Helper()
+	s := &server{status: http.StatusOK}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := make(map[string]interface{})
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		s.mu.Lock()
+		s.bodies = append(s.bodies, body)
+		status := s.status
+		s.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newTestLogger(t *testing.T, config Config) (*logger.Logger, *Hook) {
+	t.Helper()
+
+	hook, err := New(config)
+	require.NoError(t, err)
+
+	l, err := logger.New(logger.Config{Level: logger.InfoLevel, Writers: []io.Writer{io.Discard}})
+	require.NoError(t, err)
+	l.AddHook(hook)
+	return l, hook
+}
+
+func TestHook_Slack(t *testing.T) {
+	srv := newServer(t)
+	l, _ := newTestLogger(t, Config{URL: srv.URL, Format: SlackFormat})
+
+	l.WithService("payments").Info("not sent")
+	l.WithService("payments").Error("charge failed")
+	require.NoError(t, l.Close())
+
+	require.Len(t, srv.bodies, 1)
+	assert.Equal(t, map[string]interface{}{"text": "[error] payments: charge failed"}, srv.bodies[0])
+}
+
+func TestHook_TelegramTemplate(t *testing.T) {
+	srv := newServer(t)
+	l, _ := newTestLogger(t, Config{
+		URL:      srv.URL,
+		Format:   TelegramFormat,
+		ChatID:   "-100123",
+		Template: `{{.Message}} order={{index .Fields "order_id"}}`,
+	})
+
+	l.WithField("order_id", 42).Error("refund failed")
+	require.NoError(t, l.Close())
+
+	require.Len(t, srv.bodies, 1)
+	assert.Equal(t, "-100123", srv.bodies[0]["chat_id"])
+	assert.Equal(t, "refund failed order=42", srv.bodies[0]["text"])
+}
+
+func TestHook_Generic(t *testing.T) {
+	srv := newServer(t)
+	l, _ := newTestLogger(t, Config{URL: srv.URL})
+
+	l.WithService("api").WithError(errors.New("db down")).Error("request failed")
+	require.NoError(t, l.Close())
+
+	require.Len(t, srv.bodies, 1)
+	body := srv.bodies[0]
+	assert.Equal(t, "error", body["level"])
+	assert.Equal(t, "api", body["service"])
+	assert.Equal(t, "request failed", body["message"])
+	assert.Equal(t, "db down", body["fields"].(map[string]interface{})["error"])
+	assert.Equal(t, "[error] api: request failed", body["text"])
+}
+
+func TestHook_RateLimit(t *testing.T) {
+	srv := newServer(t)
+	l, hook := newTestLogger(t, Config{URL: srv.URL, Format: SlackFormat, RateLimit: 2, RateInterval: time.Minute})
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	hook.now = func() time.Time { return now }
+
+	for i := 0; i < 5; i++ {
+		l.Error("db down")
+	}
+	now = now.Add(time.Minute)
+	l.Error("db still down")
+	require.NoError(t, l.Close())
+
+	require.Len(t, srv.bodies, 3)
+	assert.Equal(t, "[error] db down", srv.bodies[0]["text"])
+	assert.Equal(t, "[error] db still down (+3 suppressed)", srv.bodies[2]["text"])
+}
+
+func TestHook_SendErrors(t *testing.T) {
+	srv := newServer(t)
+	srv.status = http.StatusTooManyRequests
+
+	var errs []error
+	l, _ := newTestLogger(t, Config{URL: srv.URL, OnError: func(err error) { errs = append(errs, err) }})
+
+	l.Error("failed")
+	require.NoError(t, l.Close())
+
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "429")
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	for _, config := range []Config{
+		{},
+		{URL: "http://example.com", Format: "teams"},
+		{URL: "http://example.com", Format: TelegramFormat},
+		{URL: "http://example.com", Template: "{{.Message"},
+	} {
+		_, err := New(config)
+		assert.Error(t, err, config)
+	}
+}
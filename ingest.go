@@ -0,0 +1,296 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Ограничения приема клиентских логов по умолчанию
+const (
+	defaultIngestMaxBatch     = 50
+	defaultIngestMaxBodyBytes = 1 << 20
+	defaultIngestRate         = 10
+	defaultIngestBurst        = 50
+)
+
+// IngestOptions настройки приема логов от браузеров и мобильных клиентов
+type IngestOptions struct {
+	MaxBatch     int // записей в одном запросе, не больше Burst (по умолчанию 50)
+	MaxBodyBytes int // размер тела запроса до и после распаковки (по умолчанию 1 МБ)
+
+	// Schema схема записей: допустимые поля, их типы и размеры
+	Schema EntrySchema
+
+	// Ограничение частоты записей с одного IP: в среднем Rate в секунду
+	// с запасом Burst (по умолчанию 10 и 50)
+	Rate  float64
+	Burst int
+
+	// MaxClockSkew допустимое расхождение часов клиента и сервера. Если задано,
+	// к записям с timestamp добавляются received_at и clock_skew_ms, а записи
+	// с большим расхождением помечаются clock_skew_exceeded
+	MaxClockSkew time.Duration
+
+	// TrustProxy берет IP клиента из X-Forwarded-For: самый правый адрес, не
+	// входящий в TrustedProxies. Левые адреса дописывает сам клиент, им
+	// верить нельзя. Без TrustedProxies доверенным считается только прокси,
+	// от которого пришел запрос, и берется последний адрес заголовка
+	TrustProxy bool
+	// TrustedProxies подсети своих прокси и балансировщиков. Запрос не от
+	// них учитывается по адресу соединения
+	TrustedProxies []netip.Prefix
+	// UserID возвращает пользователя из данных аутентификации запроса.
+	// По умолчанию используется UserIDFromContext
+	UserID func(r *http.Request) string
+}
+
+// ClientEntry запись лога от клиента
+type ClientEntry struct {
+	Level     string                 `json:"level"`
+	Message   string                 `json:"message"`
+	Timestamp time.Time              `json:"timestamp"`
+	Fields    map[string]interface{} `json:"fields"`
+}
+
+// ingestResponse ответ на пакет клиентских записей
+type ingestResponse struct {
+	Accepted int      `json:"accepted"`
+	Rejected int      `json:"rejected"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// ingestReservedFields поля, которые заполняет сервер и клиент не может подменить
+var ingestReservedFields = map[string]struct{}{
+	"service": {}, "func": {}, "file": {}, "line": {}, "function": {}, "source": {}, "client_ip": {},
+	"user_id": {}, "client_time": {}, "user_agent": {}, "peer_cn": {}, "remote_addr": {},
+	"received_at": {}, "clock_skew_ms": {}, "clock_skew_exceeded": {}, "dropped_fields": {},
+	// Поля, которые читает конвейер логгера: класс хранения, приоритет,
+	// контрольная сумма, метрики, сводки и предупреждения о неправильном использовании
+	RetentionField: {}, PriorityField: {}, ChecksumField: {}, MetricTagField: {}, SummaryKeyField: {}, misuseField: {},
+	logrus.FieldKeyMsg: {}, logrus.FieldKeyLevel: {}, logrus.FieldKeyTime: {},
+}
+
+// IngestHandler возвращает HTTP-обработчик, принимающий POST с JSON-массивом
+// клиентских записей. Записи проверяются и очищаются по схеме, ограничиваются
+// по частоте с одного IP,
+// дополняются полями source=client, client_ip, user_id и user_agent и пишутся
+// через обычные назначения
+func (l *Logger) IngestHandler(opts IngestOptions) http.Handler {
+	if opts.MaxBatch <= 0 {
+		opts.MaxBatch = defaultIngestMaxBatch
+	}
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = defaultIngestMaxBodyBytes
+	}
+	if opts.Rate <= 0 {
+		opts.Rate = defaultIngestRate
+	}
+	if opts.Burst <= 0 {
+		opts.Burst = defaultIngestBurst
+	}
+	// Пакет больше запаса ограничителя никогда бы не прошел
+	if opts.MaxBatch > opts.Burst {
+		opts.MaxBatch = opts.Burst
+	}
+	if opts.UserID == nil {
+		opts.UserID = func(r *http.Request) string { return UserIDFromContext(r.Context()) }
+	}
+	limiter := newRateLimiter(opts.Rate, opts.Burst)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var batch []ClientEntry
+		body := http.MaxBytesReader(w, r.Body, int64(opts.MaxBodyBytes))
+		if encoding := r.Header.Get("Content-Encoding"); encoding != "" && encoding != "identity" {
+			codec, ok := LookupCodec(encoding)
+			if !ok {
+				http.Error(w, "unsupported content encoding: "+encoding, http.StatusUnsupportedMediaType)
+				return
+			}
+			decoded, err := codec.NewReader(body)
+			if err != nil {
+				http.Error(w, "invalid "+encoding+" body: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			defer decoded.Close()
+			// Лимит действует и на распакованное тело
+			body = http.MaxBytesReader(w, decoded, int64(opts.MaxBodyBytes))
+		}
+		if err := json.NewDecoder(body).Decode(&batch); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "invalid batch: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(batch) > opts.MaxBatch {
+			http.Error(w, "batch too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		received := time.Now()
+		ip := clientIP(r, opts)
+		if !limiter.allow(ip, len(batch), received) {
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+
+		server := logrus.Fields{"source": "client", "client_ip": ip}
+		if userID := opts.UserID(r); userID != "" {
+			server["user_id"] = userID
+		}
+		if ua := r.UserAgent(); ua != "" {
+			server["user_agent"] = ua
+		}
+		clientLogger := l.WithContext(r.Context()).with(server)
+
+		var resp ingestResponse
+		for i, entry := range batch {
+			entry, level, err := opts.Schema.Sanitize(entry)
+			if err != nil {
+				resp.Rejected++
+				resp.Errors = append(resp.Errors, fmt.Sprintf("entry %d: %v", i, err))
+				continue
+			}
+			resp.Accepted++
+			clientLogger.logClientEntry(level, entry, received, opts.MaxClockSkew)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+}
+
+// logClientEntry пишет проверенную клиентскую запись
+func (l *Logger) logClientEntry(level Level, entry ClientEntry, received time.Time, maxSkew time.Duration) {
+	if !l.enabled(level) {
+		return
+	}
+
+	fields := make(logrus.Fields, len(entry.Fields)+1)
+	for k, v := range entry.Fields {
+		fields[k] = v
+	}
+	if !entry.Timestamp.IsZero() {
+		fields["client_time"] = entry.Timestamp.Format(time.RFC3339Nano)
+	}
+	addClockSkew(fields, entry.Timestamp, received, maxSkew)
+	l.withFields().WithFields(fields).Log(logrus.Level(level), entry.Message)
+}
+
+// clientIP возвращает IP клиента
+func clientIP(r *http.Request, opts IngestOptions) string {
+	remote, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remote = r.RemoteAddr
+	}
+	if !opts.TrustProxy || (len(opts.TrustedProxies) > 0 && !trustedProxy(remote, opts.TrustedProxies)) {
+		return remote
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(header, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	if len(hops) == 0 {
+		return remote
+	}
+	// Каждый прокси дописывает адрес справа: первый справа адрес не из
+	// своих прокси записал свой прокси, а не клиент
+	for i := len(hops) - 1; i > 0; i-- {
+		if !trustedProxy(hops[i], opts.TrustedProxies) {
+			return hops[i]
+		}
+	}
+	return hops[0]
+}
+
+// trustedProxy проверяет, входит ли адрес в подсети доверенных прокси
+func trustedProxy(ip string, trusted []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// rateLimiter ограничитель частоты по ключу с алгоритмом token bucket
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// bucket запас токенов одного ключа
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// maxRateLimiterKeys после скольких ключей удаляются неактивные
+const maxRateLimiterKeys = 10000
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{rate: rate, burst: float64(burst), buckets: make(map[string]*bucket)}
+}
+
+// allow списывает n токенов ключа, если они есть
+func (rl *rateLimiter) allow(key string, n int, now time.Time) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	b, ok := rl.buckets[key]
+	if !ok {
+		if len(rl.buckets) >= maxRateLimiterKeys {
+			rl.sweep(now)
+		}
+		b = &bucket{tokens: rl.burst, last: now}
+		rl.buckets[key] = b
+	}
+
+	b.tokens = min(rl.burst, b.tokens+now.Sub(b.last).Seconds()*rl.rate)
+	b.last = now
+	if b.tokens < float64(n) {
+		return false
+	}
+	b.tokens -= float64(n)
+	return true
+}
+
+// sweep удаляет ключи, запас которых уже восстановился полностью
+func (rl *rateLimiter) sweep(now time.Time) {
+	for key, b := range rl.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*rl.rate >= rl.burst {
+			delete(rl.buckets, key)
+		}
+	}
+}
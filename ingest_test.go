@@ -0,0 +1,189 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// postBatch отправляет пакет клиентских записей в обработчик
+func postBatch(handler http.Handler, body string, modify ...func(*http.Request)) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/logs", strings.NewReader(body))
+	req.RemoteAddr = "203.0.113.7:40000"
+	for _, m := range modify {
+		m(req)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestLogger_IngestHandler(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	handler := logger.IngestHandler(IngestOptions{})
+
+	rec := postBatch(handler, `[
+		{"level": "error", "message": "checkout failed", "timestamp": "2024-01-15T10:00:00Z",
+		 "fields": {"component": "cart", "service": "spoofed", "client_ip": "1.1.1.1"}},
+		{"level": "fatal", "message": "boom"},
+		{"level": "info", "message": ""},
+		{"level": "debug", "message": "rendered"}
+	]`, func(r *http.Request) {
+		r.Header.Set("User-Agent", "test-agent")
+		*r = *r.WithContext(ContextWithUserID(context.Background(), "u-42"))
+	})
+
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	var resp ingestResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 2, resp.Accepted)
+	assert.Equal(t, 2, resp.Rejected)
+	require.Len(t, resp.Errors, 2)
+	assert.Contains(t, resp.Errors[0], "entry 1")
+	assert.Contains(t, resp.Errors[1], "entry 2")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	entry := entries[0]
+	assert.Equal(t, "error", entry["level"])
+	assert.Equal(t, "checkout failed", entry["msg"])
+	assert.Equal(t, "client", entry["source"])
+	assert.Equal(t, "203.0.113.7", entry["client_ip"])
+	assert.Equal(t, "u-42", entry["user_id"])
+	assert.Equal(t, "test-agent", entry["user_agent"])
+	assert.Equal(t, "cart", entry["component"])
+	assert.Equal(t, "", entry["service"])
+	assert.Equal(t, "2024-01-15T10:00:00Z", entry["client_time"])
+	assert.Equal(t, "debug", entries[1]["level"])
+}
+
+func TestLogger_IngestHandler_Rejects(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	handler := logger.IngestHandler(IngestOptions{MaxBatch: 2, MaxBodyBytes: 256})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/logs", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	assert.Equal(t, http.StatusBadRequest, postBatch(handler, `{"level": "info"}`).Code)
+
+	batch := `[{"level":"info","message":"a"},{"level":"info","message":"b"},{"level":"info","message":"c"}]`
+	assert.Equal(t, http.StatusRequestEntityTooLarge, postBatch(handler, batch).Code)
+
+	large := `[{"level":"info","message":"` + strings.Repeat("x", 300) + `"}]`
+	assert.Equal(t, http.StatusRequestEntityTooLarge, postBatch(handler, large).Code)
+
+	assert.Empty(t, buf.String())
+}
+
+func TestLogger_IngestHandler_RateLimit(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+	handler := logger.IngestHandler(IngestOptions{
+		Rate:           0.001,
+		Burst:          3,
+		TrustProxy:     true,
+		TrustedProxies: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+	})
+
+	batch := `[{"level":"info","message":"a"},{"level":"info","message":"b"}]`
+	forwarded := func(ip string) func(*http.Request) {
+		return func(r *http.Request) {
+			r.RemoteAddr = "10.0.0.2:4000"
+			r.Header.Set("X-Forwarded-For", ip+", 10.0.0.1")
+		}
+	}
+
+	assert.Equal(t, http.StatusAccepted, postBatch(handler, batch, forwarded("198.51.100.1")).Code)
+	assert.Equal(t, http.StatusTooManyRequests, postBatch(handler, batch, forwarded("198.51.100.1")).Code)
+	assert.Equal(t, http.StatusAccepted, postBatch(handler, batch, forwarded("198.51.100.2")).Code)
+}
+
+func TestClientIP(t *testing.T) {
+	proxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	tests := []struct {
+		name      string
+		opts      IngestOptions
+		remote    string
+		forwarded []string
+		want      string
+	}{
+		{"no proxy", IngestOptions{}, "203.0.113.7:1234", []string{"198.51.100.1"}, "203.0.113.7"},
+		{"one hop", IngestOptions{TrustProxy: true}, "10.0.0.2:1234", []string{"198.51.100.66, 203.0.113.7"}, "203.0.113.7"},
+		{"spoofed left", IngestOptions{TrustProxy: true, TrustedProxies: proxies}, "10.0.0.2:1234", []string{"198.51.100.66, 203.0.113.7, 10.0.0.1"}, "203.0.113.7"},
+		{"several headers", IngestOptions{TrustProxy: true, TrustedProxies: proxies}, "10.0.0.2:1234", []string{"198.51.100.66", "203.0.113.7, 10.0.0.1"}, "203.0.113.7"},
+		{"untrusted peer", IngestOptions{TrustProxy: true, TrustedProxies: proxies}, "203.0.113.9:1234", []string{"198.51.100.66"}, "203.0.113.9"},
+		{"only proxies", IngestOptions{TrustProxy: true, TrustedProxies: proxies}, "10.0.0.2:1234", []string{"10.0.0.3, 10.0.0.1"}, "10.0.0.3"},
+		{"no header", IngestOptions{TrustProxy: true}, "10.0.0.2:1234", nil, "10.0.0.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			r.RemoteAddr = tt.remote
+			for _, v := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			assert.Equal(t, tt.want, clientIP(r, tt.opts))
+		})
+	}
+}
+
+func TestLogger_IngestHandler_MaxBatchWithinBurst(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	entries := make([]string, defaultIngestMaxBatch)
+	for i := range entries {
+		entries[i] = `{"level":"info","message":"m"}`
+	}
+	batch := "[" + strings.Join(entries, ",") + "]"
+	rec := postBatch(logger.IngestHandler(IngestOptions{}), batch)
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	assert.Len(t, decodeEntries(t, buf), defaultIngestMaxBatch)
+
+	// MaxBatch больше Burst ограничивается запасом
+	handler := logger.IngestHandler(IngestOptions{MaxBatch: 10, Burst: 2})
+	three := `[{"level":"info","message":"a"},{"level":"info","message":"b"},{"level":"info","message":"c"}]`
+	assert.Equal(t, http.StatusRequestEntityTooLarge, postBatch(handler, three).Code)
+}
+
+func TestLogger_IngestHandler_PipelineFields(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	handler := logger.IngestHandler(IngestOptions{})
+
+	for _, key := range []string{RetentionField, PriorityField, ChecksumField, MetricTagField, SummaryKeyField, misuseField} {
+		t.Run(key, func(t *testing.T) {
+			buf.Reset()
+			value := `"spoofed"`
+			if key == MetricTagField {
+				value = "true"
+			}
+			rec := postBatch(handler, `[{"level":"info","message":"m","fields":{"`+key+`":`+value+`}}]`)
+			require.Equal(t, http.StatusAccepted, rec.Code)
+
+			entries := decodeEntries(t, buf)
+			require.Len(t, entries, 1)
+			assert.NotContains(t, entries[0], key)
+			assert.Equal(t, []interface{}{key}, entries[0]["dropped_fields"])
+		})
+	}
+}
+
+func TestRateLimiter_Refill(t *testing.T) {
+	rl := newRateLimiter(2, 4)
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	assert.True(t, rl.allow("a", 4, now))
+	assert.False(t, rl.allow("a", 1, now))
+	assert.True(t, rl.allow("a", 2, now.Add(time.Second)))
+	assert.False(t, rl.allow("a", 5, now.Add(time.Hour)), "burst caps accumulated tokens")
+
+	rl.sweep(now.Add(2 * time.Hour))
+	assert.Empty(t, rl.buckets)
+}
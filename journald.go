@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultJournaldSocket сокет нативного протокола journald
+const defaultJournaldSocket = "/run/systemd/journal/socket"
+
+// maxJournaldFieldName максимальная длина имени поля журнала
+const maxJournaldFieldName = 64
+
+// JournaldConfig настройки вывода в journald
+type JournaldConfig struct {
+	Socket     string `yaml:"socket"`     // по умолчанию /run/systemd/journal/socket
+	Identifier string `yaml:"identifier"` // SYSLOG_IDENTIFIER, по умолчанию имя исполняемого файла
+}
+
+// journaldReserved поля, которые заполняет сам логгер. Одноименные поля
+// записи получают префикс FIELD_
+var journaldReserved = map[string]bool{
+	"MESSAGE": true, "PRIORITY": true, "SYSLOG_IDENTIFIER": true,
+	"CODE_FILE": true, "CODE_LINE": true, "CODE_FUNC": true,
+}
+
+// journaldFormatter кодирует запись в нативный протокол journald: каждое
+// поле записи становится переменной журнала, уровень - полем PRIORITY
+type journaldFormatter struct {
+	identifier string
+}
+
+func newJournaldFormatter(config JournaldConfig) *journaldFormatter {
+	return &journaldFormatter{identifier: firstNonEmpty(config.Identifier, filepath.Base(os.Args[0]))}
+}
+
+// Format возвращает поля записи в нативном протоколе journald
+func (f *journaldFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	var b bytes.Buffer
+	severity, ok := syslogSeverities[entry.Level]
+	if !ok {
+		severity = 7
+	}
+	writeJournaldField(&b, "MESSAGE", entry.Message)
+	writeJournaldField(&b, "PRIORITY", strconv.Itoa(severity))
+	writeJournaldField(&b, "SYSLOG_IDENTIFIER", f.identifier)
+	if entry.HasCaller() {
+		writeJournaldField(&b, "CODE_FILE", entry.Caller.File)
+		writeJournaldField(&b, "CODE_LINE", strconv.Itoa(entry.Caller.Line))
+		writeJournaldField(&b, "CODE_FUNC", entry.Caller.Function)
+	}
+
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		name := journaldFieldName(k)
+		if name == "" {
+			continue
+		}
+		writeJournaldField(&b, name, plainValue(entry.Data[k]))
+	}
+	return b.Bytes(), nil
+}
+
+// writeJournaldField пишет поле KEY=value. Значения с переводом строки
+// пишутся в двоичном виде: имя, длина little-endian и само значение
+func writeJournaldField(b *bytes.Buffer, name, value string) {
+	b.WriteString(name)
+	if strings.IndexByte(value, '\n') < 0 {
+		b.WriteByte('=')
+		b.WriteString(value)
+		b.WriteByte('\n')
+		return
+	}
+	b.WriteByte('\n')
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(value)))
+	b.Write(size[:])
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
+
+// journaldFieldName приводит имя поля к правилам журнала: заглавные латинские
+// буквы, цифры и подчеркивание, не с подчеркивания и не с цифры, до 64 символов
+func journaldFieldName(key string) string {
+	name := []byte(strings.ToUpper(key))
+	for i, c := range name {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			name[i] = '_'
+		}
+	}
+	s := strings.TrimLeft(string(name), "_")
+	if s == "" {
+		return ""
+	}
+	if s[0] >= '0' && s[0] <= '9' || journaldReserved[s] {
+		s = "FIELD_" + s
+	}
+	if len(s) > maxJournaldFieldName {
+		s = s[:maxJournaldFieldName]
+	}
+	return s
+}
+
+// plainValue возвращает значение поля строкой для выводов без типов:
+// скаляры как есть, составные значения в JSON
+func plainValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case error:
+		return v.Error()
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return v.String()
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
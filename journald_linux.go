@@ -0,0 +1,117 @@
+//go:build linux
+
+package logger
+
+import (
+	"errors"
+	"net"
+	"os"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// openJournaldSink подключается к journald и создает назначение
+func openJournaldSink(config Config) (*journaldConn, *sink, error) {
+	conn := &journaldConn{addr: &net.UnixAddr{Name: firstNonEmpty(config.Journald.Socket, defaultJournaldSocket), Net: "unixgram"}}
+	if err := conn.connect(); err != nil {
+		return nil, nil, err
+	}
+	// Сокет не привязан к journald, поэтому его отсутствие проверяется отдельно
+	if _, err := os.Stat(conn.addr.Name); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return conn, &sink{name: "journald", writer: conn, formatter: newJournaldFormatter(config.Journald), binary: true}, nil
+}
+
+// journaldConn соединение с journald. Каждая запись отправляется отдельной
+// датаграммой; записи больше допустимого размера датаграммы передаются через
+// запечатанный memfd, как это делает sd_journal_send
+type journaldConn struct {
+	addr *net.UnixAddr
+
+	mu     sync.Mutex
+	conn   *net.UnixConn
+	closed bool
+}
+
+// connect открывает сокет для отправки в journald. Сокет не соединяется
+// с journald: через соединенный сокет нельзя передать дескриптор memfd
+func (c *journaldConn) connect() error {
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		conn.Close()
+		return net.ErrClosed
+	}
+	if c.conn != nil {
+		c.conn.Close()
+	}
+	c.conn = conn
+	return nil
+}
+
+// Write отправляет одну запись
+func (c *journaldConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return 0, net.ErrClosed
+	}
+	_, _, err := c.conn.WriteMsgUnix(p, nil, c.addr)
+	if errors.Is(err, unix.EMSGSIZE) || errors.Is(err, unix.ENOBUFS) {
+		err = c.sendMemfd(p)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// sendMemfd передает запись через memfd: journald читает ее из
+// полученного дескриптора
+func (c *journaldConn) sendMemfd(p []byte) error {
+	fd, err := unix.MemfdCreate("logger-journal", unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING)
+	if err != nil {
+		return err
+	}
+	file := os.NewFile(uintptr(fd), "logger-journal")
+	defer file.Close()
+
+	if _, err := file.Write(p); err != nil {
+		return err
+	}
+	seals := unix.F_SEAL_SHRINK | unix.F_SEAL_GROW | unix.F_SEAL_WRITE | unix.F_SEAL_SEAL
+	if _, err := unix.FcntlInt(file.Fd(), unix.F_ADD_SEALS, seals); err != nil {
+		return err
+	}
+	_, _, err = c.conn.WriteMsgUnix(nil, unix.UnixRights(int(file.Fd())), c.addr)
+	return err
+}
+
+// Sync ничего не делает: записи отправляются сразу
+func (c *journaldConn) Sync() error {
+	return nil
+}
+
+// Reopen пересоздает сокет
+func (c *journaldConn) Reopen() error {
+	return c.connect()
+}
+
+// Close закрывает соединение
+func (c *journaldConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	return c.conn.Close()
+}
@@ -0,0 +1,89 @@
+//go:build linux
+
+package logger
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// listenJournal создает сокет, принимающий записи вместо journald
+func listenJournal(t *testing.T) (*net.UnixConn, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn, path
+}
+
+// readJournal читает запись, в том числе переданную через memfd
+func readJournal(t *testing.T, conn *net.UnixConn) map[string]string {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, 64*1024)
+	oob := make([]byte, unix.CmsgSpace(4))
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	require.NoError(t, err)
+	if oobn == 0 {
+		return parseJournal(t, buf[:n])
+	}
+
+	msgs, err := unix.ParseSocketControlMessage(oob[:oobn])
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	fds, err := unix.ParseUnixRights(&msgs[0])
+	require.NoError(t, err)
+	file := os.NewFile(uintptr(fds[0]), "memfd")
+	defer file.Close()
+	_, err = file.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+	data, err := io.ReadAll(file)
+	require.NoError(t, err)
+	return parseJournal(t, data)
+}
+
+func TestJournaldOutput(t *testing.T) {
+	server, path := listenJournal(t)
+
+	log, err := New(Config{
+		Level:    InfoLevel,
+		Output:   JournaldOutput,
+		Journald: JournaldConfig{Socket: path, Identifier: "api"},
+		Checksum: true,
+	})
+	require.NoError(t, err)
+	defer log.Close()
+
+	log.WithService("billing").WithField("order_id", 42).Error("payment failed")
+	fields := readJournal(t, server)
+	assert.Equal(t, "payment failed", fields["MESSAGE"])
+	assert.Equal(t, "3", fields["PRIORITY"])
+	assert.Equal(t, "api", fields["SYSLOG_IDENTIFIER"])
+	assert.Equal(t, "billing", fields["SERVICE"])
+	assert.Equal(t, "42", fields["ORDER_ID"])
+	assert.NotContains(t, fields, "CHECKSUM")
+
+	// Запись больше датаграммы передается через memfd
+	log.WithField("payload", strings.Repeat("x", 512*1024)).Info("large")
+	fields = readJournal(t, server)
+	assert.Equal(t, "large", fields["MESSAGE"])
+	assert.Len(t, fields["PAYLOAD"], 512*1024)
+}
+
+func TestJournaldOutput_NoSocket(t *testing.T) {
+	_, err := New(Config{
+		Output:   JournaldOutput,
+		Journald: JournaldConfig{Socket: filepath.Join(t.TempDir(), "missing.sock")},
+	})
+	assert.ErrorContains(t, err, "failed to connect to journald")
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package logger
+
+import "errors"
+
+// openJournaldSink недоступен: journald есть только на Linux
+func openJournaldSink(config Config) (logFile, *sink, error) {
+	return nil, nil, errors.New("journald output is only supported on linux")
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// parseJournal разбирает сообщение нативного протокола journald
+func parseJournal(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	fields := map[string]string{}
+	for len(data) > 0 {
+		line := bytes.IndexByte(data, '\n')
+		require.GreaterOrEqual(t, line, 0)
+		if eq := bytes.IndexByte(data[:line], '='); eq >= 0 {
+			fields[string(data[:eq])] = string(data[eq+1 : line])
+			data = data[line+1:]
+			continue
+		}
+		name := string(data[:line])
+		data = data[line+1:]
+		size := binary.LittleEndian.Uint64(data[:8])
+		fields[name] = string(data[8 : 8+size])
+		require.Equal(t, byte('\n'), data[8+size])
+		data = data[9+size:]
+	}
+	return fields
+}
+
+func TestJournaldFormatter(t *testing.T) {
+	f := newJournaldFormatter(JournaldConfig{Identifier: "api"})
+	entry := &logrus.Entry{
+		Level:   logrus.WarnLevel,
+		Message: "slow query",
+		Time:    time.Now(),
+		Data: logrus.Fields{
+			"service":     "billing.db",
+			"duration_ms": 1250,
+			"query":       "SELECT *\nFROM orders",
+			"error":       errors.New("timeout"),
+			"tags":        []string{"db", "slow"},
+			"message":     "spoofed",
+			"http.status": 200,
+			"_hidden":     "x",
+			"2fa":         true,
+		},
+	}
+
+	data, err := f.Format(entry)
+	require.NoError(t, err)
+	fields := parseJournal(t, data)
+
+	assert.Equal(t, "slow query", fields["MESSAGE"])
+	assert.Equal(t, "4", fields["PRIORITY"])
+	assert.Equal(t, "api", fields["SYSLOG_IDENTIFIER"])
+	assert.Equal(t, "billing.db", fields["SERVICE"])
+	assert.Equal(t, "1250", fields["DURATION_MS"])
+	assert.Equal(t, "SELECT *\nFROM orders", fields["QUERY"])
+	assert.Equal(t, "timeout", fields["ERROR"])
+	assert.Equal(t, `["db","slow"]`, fields["TAGS"])
+	assert.Equal(t, "spoofed", fields["FIELD_MESSAGE"])
+	assert.Equal(t, "200", fields["HTTP_STATUS"])
+	assert.Equal(t, "x", fields["HIDDEN"])
+	assert.Equal(t, "true", fields["FIELD_2FA"])
+}
+
+func TestJournaldFieldName(t *testing.T) {
+	assert.Equal(t, "REQUEST_ID", journaldFieldName("request-id"))
+	assert.Equal(t, "", journaldFieldName("__"))
+	assert.Equal(t, "FIELD_PRIORITY", journaldFieldName("priority"))
+	assert.Len(t, journaldFieldName(string(bytes.Repeat([]byte("a"), 100))), maxJournaldFieldName)
+}
@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// latencyBuckets число интервалов гистограммы задержек: интервал i содержит
+// задержки меньше 2^i мкс, последний - все остальные (от 8 секунд)
+const latencyBuckets = 24
+
+const (
+	// slowSinkMinSamples число записей в окне, после которого проверяется p99
+	slowSinkMinSamples = 50
+	// slowSinkWarnInterval минимальный интервал между предупреждениями об одном назначении
+	slowSinkWarnInterval = time.Minute
+)
+
+// SinkLatency задержки записи в одно назначение с момента создания логгера
+// или последней перезагрузки конфигурации. Перцентили - верхние границы
+// интервалов гистограммы, то есть оценка сверху с точностью до 2 раз
+type SinkLatency struct {
+	Sink  string // stdout, stderr, file, writer[N], destination[N], migration
+	Count uint64
+	P50   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// latencyHistogram гистограмма задержек с экспоненциальными интервалами
+type latencyHistogram struct {
+	buckets [latencyBuckets]atomic.Uint64
+	count   atomic.Uint64
+}
+
+// observe учитывает задержку
+func (h *latencyHistogram) observe(d time.Duration) {
+	i := 0
+	if us := d.Microseconds(); us > 0 {
+		i = min(bits.Len64(uint64(us)), latencyBuckets-1)
+	}
+	h.buckets[i].Add(1)
+	h.count.Add(1)
+}
+
+// percentile возвращает верхнюю границу интервала, в который попадает доля p задержек
+func (h *latencyHistogram) percentile(p float64) time.Duration {
+	total := h.count.Load()
+	if total == 0 {
+		return 0
+	}
+	rank := uint64(p * float64(total))
+	if rank == 0 {
+		rank = 1
+	}
+	var seen uint64
+	for i := range h.buckets {
+		seen += h.buckets[i].Load()
+		if seen >= rank {
+			return time.Duration(1<<i) * time.Microsecond
+		}
+	}
+	return time.Duration(1<<(latencyBuckets-1)) * time.Microsecond
+}
+
+// reset очищает гистограмму
+func (h *latencyHistogram) reset() {
+	for i := range h.buckets {
+		h.buckets[i].Store(0)
+	}
+	h.count.Store(0)
+}
+
+// sinkLatency задержки записи в назначение: за все время и в текущем окне
+// проверки медленного назначения
+type sinkLatency struct {
+	total    latencyHistogram
+	window   latencyHistogram
+	max      atomic.Int64
+	lastWarn atomic.Int64 // время последнего предупреждения, UnixNano
+}
+
+// observe учитывает задержку записи
+func (s *sinkLatency) observe(d time.Duration) {
+	s.total.observe(d)
+	s.window.observe(d)
+	for {
+		cur := s.max.Load()
+		if int64(d) <= cur || s.max.CompareAndSwap(cur, int64(d)) {
+			return
+		}
+	}
+}
+
+// checkSlow проверяет p99 окна после накопления достаточного числа записей
+// и начинает новое окно. Возвращает p99 и true, если он больше порога и о
+// назначении давно не предупреждали
+func (s *sinkLatency) checkSlow(threshold time.Duration, now time.Time) (time.Duration, bool) {
+	if s.window.count.Load() < slowSinkMinSamples {
+		return 0, false
+	}
+	p99 := s.window.percentile(0.99)
+	s.window.reset()
+	if p99 <= threshold {
+		return p99, false
+	}
+	last := s.lastWarn.Load()
+	if now.UnixNano()-last < int64(slowSinkWarnInterval) {
+		return p99, false
+	}
+	return p99, s.lastWarn.CompareAndSwap(last, now.UnixNano())
+}
+
+// warnSlowSink предупреждает о медленном назначении. Вызывается отдельной
+// горутиной: запись из диспетчера под блокировкой logrus привела бы к дедлоку
+func (l *Logger) warnSlowSink(name string, p99, threshold time.Duration) {
+	if !l.enabled(WarnLevel) {
+		return
+	}
+	l.withFields().WithFields(map[string]interface{}{
+		"sink":         name,
+		"p99_ms":       float64(p99) / float64(time.Millisecond),
+		"threshold_ms": float64(threshold) / float64(time.Millisecond),
+	}).Warn("slow sink")
+}
+
+// SinkLatencies возвращает задержки записи во все назначения
+func (l *Logger) SinkLatencies() []SinkLatency {
+	l = l.orNop()
+	l.core.mu.RLock()
+	sinks := l.core.sinks
+	l.core.mu.RUnlock()
+
+	stats := make([]SinkLatency, 0, len(sinks))
+	for _, s := range sinks {
+		stats = append(stats, SinkLatency{
+			Sink:  s.name,
+			Count: s.latency.total.count.Load(),
+			P50:   s.latency.total.percentile(0.5),
+			P99:   s.latency.total.percentile(0.99),
+			Max:   time.Duration(s.latency.max.Load()),
+		})
+	}
+	return stats
+}
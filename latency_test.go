@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowWriter пишет в буфер с задержкой
+type slowWriter struct {
+	lockedBuffer
+	delay time.Duration
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+	return w.lockedBuffer.Write(p)
+}
+
+func TestLatencyHistogram(t *testing.T) {
+	var h latencyHistogram
+	assert.Equal(t, time.Duration(0), h.percentile(0.99))
+
+	for i := 0; i < 98; i++ {
+		h.observe(3 * time.Microsecond)
+	}
+	h.observe(100 * time.Microsecond)
+	h.observe(time.Hour)
+
+	assert.Equal(t, uint64(100), h.count.Load())
+	assert.Equal(t, 4*time.Microsecond, h.percentile(0.5))
+	assert.Equal(t, 128*time.Microsecond, h.percentile(0.99))
+	assert.Equal(t, time.Duration(1<<(latencyBuckets-1))*time.Microsecond, h.percentile(1))
+
+	h.reset()
+	assert.Equal(t, uint64(0), h.count.Load())
+}
+
+func TestSinkLatency_CheckSlow(t *testing.T) {
+	var s sinkLatency
+	now := time.Now()
+
+	for i := 0; i < slowSinkMinSamples-1; i++ {
+		s.observe(10 * time.Millisecond)
+	}
+	_, slow := s.checkSlow(time.Millisecond, now)
+	assert.False(t, slow, "not enough samples")
+
+	s.observe(10 * time.Millisecond)
+	p99, slow := s.checkSlow(time.Millisecond, now)
+	assert.True(t, slow)
+	assert.Greater(t, p99, time.Millisecond)
+	assert.Equal(t, uint64(0), s.window.count.Load())
+	assert.Equal(t, int64(10*time.Millisecond), s.max.Load())
+
+	// Повторное предупреждение не раньше slowSinkWarnInterval
+	for i := 0; i < slowSinkMinSamples; i++ {
+		s.observe(10 * time.Millisecond)
+	}
+	_, slow = s.checkSlow(time.Millisecond, now.Add(time.Second))
+	assert.False(t, slow)
+
+	for i := 0; i < slowSinkMinSamples; i++ {
+		s.observe(10 * time.Millisecond)
+	}
+	_, slow = s.checkSlow(time.Millisecond, now.Add(slowSinkWarnInterval))
+	assert.True(t, slow)
+}
+
+func TestLogger_SlowSinkWarning(t *testing.T) {
+	slow := &slowWriter{delay: 2 * time.Millisecond}
+	var fast bytes.Buffer
+	logger, err := New(Config{
+		Level: InfoLevel,
+		Destinations: []Destination{
+			{Writer: slow, Format: JSONFormat},
+			{Writer: &fast, Format: JSONFormat, Level: "error"},
+		},
+		SlowSinkThreshold: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < slowSinkMinSamples; i++ {
+		logger.Info("entry")
+	}
+
+	require.Eventually(t, func() bool {
+		for _, entry := range decodeEntries(t, slow) {
+			if entry["msg"] == "slow sink" {
+				return true
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+
+	var warning map[string]interface{}
+	for _, entry := range decodeEntries(t, slow) {
+		if entry["msg"] == "slow sink" {
+			warning = entry
+		}
+	}
+	assert.Equal(t, "warning", warning["level"])
+	assert.Equal(t, "destination[0]", warning["sink"])
+	assert.Equal(t, float64(1), warning["threshold_ms"])
+	assert.Greater(t, warning["p99_ms"], float64(1))
+
+	stats := logger.SinkLatencies()
+	require.Len(t, stats, 2)
+	assert.Equal(t, "destination[0]", stats[0].Sink)
+	assert.GreaterOrEqual(t, stats[0].Count, uint64(slowSinkMinSamples))
+	assert.GreaterOrEqual(t, stats[0].Max, 2*time.Millisecond)
+	assert.Equal(t, "destination[1]", stats[1].Sink)
+	assert.Equal(t, uint64(0), stats[1].Count)
+}
@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LegalHold запрет на удаление записей сервиса за период, например на время
+// судебного разбирательства. Записи под запретом помечаются классом хранения
+// legal_hold, а ротация не удаляет файлы, в которые они могли попасть
+type LegalHold struct {
+	Service string    `yaml:"service"` // пусто - все сервисы
+	From    time.Time `yaml:"from"`    // нулевое - с начала
+	To      time.Time `yaml:"to"`      // нулевое - без окончания
+	Reason  string    `yaml:"reason"`
+}
+
+// covers проверяет, попадает ли время записи в период запрета
+func (h LegalHold) covers(t time.Time) bool {
+	return !t.Before(h.From) && (h.To.IsZero() || !t.After(h.To))
+}
+
+// overlaps проверяет, пересекается ли период [start, end] с периодом запрета
+func (h LegalHold) overlaps(start, end time.Time) bool {
+	return !end.Before(h.From) && (h.To.IsZero() || !start.After(h.To))
+}
+
+// legalHeld проверяет, действует ли запрет на запись сервиса за время t
+func legalHeld(holds []LegalHold, service string, t time.Time) bool {
+	for _, h := range holds {
+		if (h.Service == "" || h.Service == service) && h.covers(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnderLegalHold проверяет, запрещено ли удалять записи сервиса за время t.
+// Внешние хранилища логов (например, таблицы в БД) должны проверять запрет
+// перед удалением старых записей
+func (l *Logger) UnderLegalHold(service string, t time.Time) bool {
+	l = l.orNop()
+	holds := l.core.legalHolds.Load()
+	return holds != nil && legalHeld(*holds, service, t)
+}
+
+// holdEntry помечает запись под запретом удаления классом хранения legal_hold
+func (c *core) holdEntry(entry *logrus.Entry) *logrus.Entry {
+	holds := c.legalHolds.Load()
+	if holds == nil || len(*holds) == 0 {
+		return entry
+	}
+	service, _ := entry.Data["service"].(string)
+	if !legalHeld(*holds, service, entry.Time) {
+		return entry
+	}
+	data := make(logrus.Fields, len(entry.Data)+1)
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	data[RetentionField] = string(RetentionLegalHold)
+
+	held := *entry
+	held.Data = data
+	return &held
+}
+
+// heldFiles возвращает ротированные файлы, которые нельзя удалять: их период
+// (от изменения предыдущего файла до своего изменения) пересекается с
+// каким-либо запретом. В файле записи всех сервисов, поэтому сервис запрета
+// здесь не учитывается
+func heldFiles(files []rotatedFile, holds []LegalHold) map[string]bool {
+	if len(holds) == 0 {
+		return nil
+	}
+	held := make(map[string]bool)
+	var start time.Time
+	for _, file := range files {
+		for _, h := range holds {
+			if h.overlaps(start, file.modTime) {
+				held[file.path] = true
+				break
+			}
+		}
+		start = file.modTime
+	}
+	return held
+}
+
+// releasable убирает из списка файлов те, что находятся под запретом удаления
+func (w *rotatingWriter) releasable(paths []string) ([]string, error) {
+	if len(w.holds) == 0 || len(paths) == 0 {
+		return paths, nil
+	}
+	files, err := w.rotatedFiles()
+	if err != nil {
+		return nil, err
+	}
+	held := heldFiles(files, w.holds)
+
+	free := paths[:0:0]
+	for _, path := range paths {
+		if !held[path] {
+			free = append(free, path)
+		}
+	}
+	return free, nil
+}
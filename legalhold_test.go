@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_LegalHoldMarksEntries(t *testing.T) {
+	buf := &bytes.Buffer{}
+	now := time.Now()
+	logger, err := New(Config{
+		Level:   InfoLevel,
+		Writers: []io.Writer{buf},
+		LegalHolds: []LegalHold{
+			{Service: "billing", From: now.Add(-time.Hour), Reason: "case 2024-17"},
+		},
+	})
+	require.NoError(t, err)
+
+	logger.WithService("billing").Info("invoice issued")
+	logger.WithService("billing").WithRetention(RetentionShort).Info("cache warmed")
+	logger.WithService("search").Info("query served")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "legal_hold", entries[0][RetentionField])
+	assert.Equal(t, "legal_hold", entries[1][RetentionField])
+	assert.NotContains(t, entries[2], RetentionField)
+
+	assert.True(t, logger.UnderLegalHold("billing", now))
+	assert.False(t, logger.UnderLegalHold("billing", now.Add(-2*time.Hour)))
+	assert.False(t, logger.UnderLegalHold("search", now))
+
+	require.NoError(t, logger.ApplyConfig(Config{Level: InfoLevel, Writers: []io.Writer{buf}}))
+	assert.False(t, logger.UnderLegalHold("billing", now))
+}
+
+func TestRotatingWriter_LegalHoldKeepsFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	now := time.Now()
+
+	oldest := backupName(path, now.AddDate(0, 0, -30))
+	held := backupName(path, now.AddDate(0, 0, -20))
+	old := backupName(path, now.AddDate(0, 0, -10))
+	writeRotated(t, oldest, 10, now.AddDate(0, 0, -30))
+	writeRotated(t, held, 10, now.AddDate(0, 0, -20))
+	writeRotated(t, old, 10, now.AddDate(0, 0, -10))
+
+	// Запрет на период внутри второго файла: записи за него есть только там
+	w, err := newRotatingWriter(Config{
+		FilePath:   path,
+		MaxSizeMB:  1,
+		MaxAge:     7,
+		MaxBackups: 1,
+		LegalHolds: []LegalHold{{
+			From: now.AddDate(0, 0, -25),
+			To:   now.AddDate(0, 0, -24),
+		}},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { w.file.Close() })
+
+	assert.NoFileExists(t, oldest)
+	assert.FileExists(t, held)
+	assert.NoFileExists(t, old)
+}
+
+func TestHeldFiles(t *testing.T) {
+	now := time.Now()
+	files := []rotatedFile{
+		{path: "a", modTime: now.Add(-3 * time.Hour)},
+		{path: "b", modTime: now.Add(-2 * time.Hour)},
+		{path: "c", modTime: now.Add(-time.Hour)},
+	}
+
+	held := heldFiles(files, []LegalHold{{From: now.Add(-150 * time.Minute)}})
+	assert.Equal(t, map[string]bool{"b": true, "c": true}, held)
+
+	held = heldFiles(files, []LegalHold{{To: now.Add(-4 * time.Hour)}})
+	assert.Equal(t, map[string]bool{"a": true}, held, "first file span has no known start")
+
+	assert.Nil(t, heldFiles(files, nil))
+}
+
+func TestConfig_ValidateLegalHold(t *testing.T) {
+	now := time.Now()
+	config := Config{Level: InfoLevel, LegalHolds: []LegalHold{{From: now, To: now.Add(-time.Hour)}}}
+	assert.ErrorContains(t, config.Validate(), "legal hold 0")
+}
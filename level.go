@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// Level представляет уровень логирования. В конфигурации задается названием:
+// trace, debug, info, warn, error, fatal, panic
+type Level logrus.Level
+
+// Уровни логирования
+const (
+	PanicLevel = Level(logrus.PanicLevel)
+	FatalLevel = Level(logrus.FatalLevel)
+	ErrorLevel = Level(logrus.ErrorLevel)
+	WarnLevel  = Level(logrus.WarnLevel)
+	InfoLevel  = Level(logrus.InfoLevel)
+	DebugLevel = Level(logrus.DebugLevel)
+	TraceLevel = Level(logrus.TraceLevel)
+)
+
+// AllLevels все уровни логирования от Panic до Trace
+var AllLevels = []Level{PanicLevel, FatalLevel, ErrorLevel, WarnLevel, InfoLevel, DebugLevel, TraceLevel}
+
+// ParseLevel возвращает уровень по названию без учета регистра
+func ParseLevel(name string) (Level, error) {
+	level, err := logrus.ParseLevel(name)
+	if err != nil {
+		return 0, fmt.Errorf("unknown log level: %q", name)
+	}
+	return Level(level), nil
+}
+
+// String возвращает название уровня
+func (l Level) String() string {
+	return logrus.Level(l).String()
+}
+
+// MarshalText кодирует уровень названием
+func (l Level) MarshalText() ([]byte, error) {
+	if l > TraceLevel {
+		return nil, fmt.Errorf("unknown log level: %d", l)
+	}
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText разбирает уровень по названию
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// UnmarshalYAML разбирает уровень из YAML по названию
+func (l *Level) UnmarshalYAML(value *yaml.Node) error {
+	var name string
+	if err := value.Decode(&name); err != nil {
+		return err
+	}
+	return l.UnmarshalText([]byte(name))
+}
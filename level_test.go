@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]Level{
+		"trace":   TraceLevel,
+		"DEBUG":   DebugLevel,
+		"info":    InfoLevel,
+		"warn":    WarnLevel,
+		"warning": WarnLevel,
+		"error":   ErrorLevel,
+		"fatal":   FatalLevel,
+		"panic":   PanicLevel,
+	}
+	for name, want := range tests {
+		level, err := ParseLevel(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, level, name)
+	}
+
+	_, err := ParseLevel("loud")
+	assert.EqualError(t, err, `unknown log level: "loud"`)
+}
+
+func TestLevel_Text(t *testing.T) {
+	data, err := json.Marshal(struct {
+		Level Level `json:"level"`
+	}{DebugLevel})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"level":"debug"}`, string(data))
+
+	var decoded struct {
+		Level Level `json:"level"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"level":"error"}`), &decoded))
+	assert.Equal(t, ErrorLevel, decoded.Level)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"level":"loud"}`), &decoded))
+
+	_, err = Level(42).MarshalText()
+	assert.Error(t, err)
+}
+
+func TestLevel_YAML(t *testing.T) {
+	var config struct {
+		Level Level `yaml:"level"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte("level: warn\n"), &config))
+	assert.Equal(t, WarnLevel, config.Level)
+
+	assert.Error(t, yaml.Unmarshal([]byte("level: loud\n"), &config))
+	assert.Error(t, yaml.Unmarshal([]byte("level: [debug]\n"), &config))
+
+	data, err := yaml.Marshal(config)
+	require.NoError(t, err)
+	assert.Equal(t, "level: warning\n", string(data))
+}
@@ -1,36 +1,37 @@
 package logger
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
-	"path/filepath"
-	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
-// Level представляет уровень логирования
-type Level = logrus.Level
-
-// Уровни логирования
-const (
-	PanicLevel = logrus.PanicLevel
-	FatalLevel = logrus.FatalLevel
-	ErrorLevel = logrus.ErrorLevel
-	WarnLevel  = logrus.WarnLevel
-	InfoLevel  = logrus.InfoLevel
-	DebugLevel = logrus.DebugLevel
-	TraceLevel = logrus.TraceLevel
-)
+// exit завершает процесс после записи Fatal, в тестах подменяется
+var exit = os.Exit
 
 // OutputType определяет тип вывода логов
 type OutputType string
 
 const (
-	ConsoleOutput OutputType = "console"
-	FileOutput    OutputType = "file"
-	BothOutput    OutputType = "both"
+	ConsoleOutput       OutputType = "console"
+	FileOutput          OutputType = "file"
+	BothOutput          OutputType = "both"
+	SyslogOutput        OutputType = "syslog"
+	JournaldOutput      OutputType = "journald"
+	EventLogOutput      OutputType = "eventlog"
+	GELFOutput          OutputType = "gelf"
+	ElasticsearchOutput OutputType = "elasticsearch"
+	CloudWatchOutput    OutputType = "cloudwatch"
+	SplunkOutput        OutputType = "splunk"
+	DatadogOutput       OutputType = "datadog"
 )
 
 // Config конфигурация логгера
@@ -39,123 +40,785 @@ type Config struct {
 	Output   OutputType `yaml:"output"`
 	FilePath string     `yaml:"file_path"`
 	Format   string     `yaml:"format"` // json или text
+
+	// Формат отдельных назначений поверх Format. Если не задан ни он, ни Format,
+	// в консоль пишется текст, в файл - JSON
+	ConsoleFormat string `yaml:"console_format"`
+	FileFormat    string `yaml:"file_format"`
+
+	// Syslog настройки вывода при Output: syslog
+	Syslog SyslogConfig `yaml:"syslog"`
+	// Journald настройки вывода при Output: journald
+	Journald JournaldConfig `yaml:"journald"`
+	// EventLog настройки вывода при Output: eventlog (журнал событий Windows)
+	EventLog EventLogConfig `yaml:"eventlog"`
+	// GELF настройки вывода при Output: gelf (Graylog)
+	GELF GELFConfig `yaml:"gelf"`
+	// Elasticsearch настройки вывода при Output: elasticsearch (или OpenSearch)
+	Elasticsearch ElasticsearchConfig `yaml:"elasticsearch"`
+	// CloudWatch настройки вывода при Output: cloudwatch (AWS CloudWatch Logs)
+	CloudWatch CloudWatchConfig `yaml:"cloudwatch"`
+	// Splunk настройки вывода при Output: splunk (HTTP Event Collector)
+	Splunk SplunkConfig `yaml:"splunk"`
+	// Datadog настройки вывода при Output: datadog (Datadog Logs API)
+	Datadog DatadogConfig `yaml:"datadog"`
+	// SpoolSize размер очереди сетевого вывода (syslog, gelf, плагины): записи с
+	// контекстом, не успевшие отправиться до его дедлайна, ждут в ней
+	// отправки. По умолчанию 1024
+	SpoolSize int `yaml:"spool_size"`
+
+	// Writers дополнительные назначения вывода: буфер, сетевое соединение и т.п.
+	// Пишутся в формате Format (по умолчанию JSON) и не закрываются логгером.
+	// Если Output не задан, логгер пишет только в них
+	Writers []io.Writer `yaml:"-"`
+
+	// Destinations назначения вывода со своим форматом и минимальным уровнем,
+	// например Warn и выше в stderr, а все записи - в файл
+	Destinations []Destination `yaml:"-"`
+	// Plugins назначения во внешних процессах, например собственные системы
+	// сбора логов команды, в дополнение к Output
+	Plugins []PluginConfig `yaml:"plugins"`
+
+	// Backend выбирает вывод записей: LogrusBackend (по умолчанию) - Output
+	// форматтерами logrus, SlogBackend - обработчик SlogHandler вместо Output,
+	// другие имена - бэкенды из RegisterBackend (zap из модуля zapbackend
+	// сам выводит Output). Writers и Destinations пишутся форматтерами при
+	// любом бэкенде
+	Backend     string       `yaml:"backend"`
+	SlogHandler slog.Handler `yaml:"-"`
+
+	// Migration двойная запись в основной вывод и новое назначение со сравнением
+	Migration *Migration `yaml:"-"`
+
+	// ServiceLevels собственные уровни логгеров сервисов (WithService) поверх Level.
+	// Уровень "*" заменяет Level
+	ServiceLevels map[string]Level `yaml:"service_levels"`
+	// PoolLevels уровни компонентов ForPool поверх уровней, заданных в коде
+	PoolLevels map[string]Level `yaml:"pool_levels"`
+
+	// Минимальный уровень записей для консоли и файла поверх Level:
+	// warn, error и т.п. (по умолчанию все записи)
+	ConsoleLevel string `yaml:"console_level"`
+	FileLevel    string `yaml:"file_level"`
+
+	// Выражения над записями (подмножество CEL, см. expr.go): ConsoleFilter и
+	// FileFilter пропускают в назначение только подходящие записи, записи под
+	// любым выражением Drop отбрасываются, первое подходящее правило
+	// LevelRules меняет уровень записи
+	ConsoleFilter string      `yaml:"console_filter"`
+	FileFilter    string      `yaml:"file_filter"`
+	Drop          []string    `yaml:"drop"`
+	LevelRules    []LevelRule `yaml:"level_rules"`
+
+	// SplitStdErr пишет в консоль записи Warn и серьезнее в stderr, остальные - в stdout
+	SplitStdErr bool `yaml:"split_stderr"`
+
+	// Каталог файла логов создается автоматически, если его нет
+	DisableDirCreation bool        `yaml:"disable_dir_creation"`
+	DirMode            os.FileMode `yaml:"dir_mode"` // права на создаваемые каталоги (по умолчанию 0750)
+
+	// Ротация файла по размеру
+	MaxSizeMB  int  `yaml:"max_size_mb"` // размер файла, после которого он ротируется (0 - без ротации)
+	MaxBackups int  `yaml:"max_backups"` // сколько ротированных файлов хранить (0 - все)
+	Compress   bool `yaml:"compress"`    // сжимать ротированные файлы
+	// CompressCodec кодек сжатия ротированных файлов, по умолчанию gzip
+	CompressCodec string `yaml:"compress_codec"`
+
+	// Хранение ротированных файлов
+	MaxAge         int `yaml:"max_age"`           // сколько дней хранить ротированные файлы (0 - без ограничения)
+	MaxTotalSizeMB int `yaml:"max_total_size_mb"` // общий объем логов на диске (0 - без ограничения)
+
+	// Rotation ротация файла по расписанию. FilePath может быть шаблоном
+	// времени в формате Go, например logs/app-2006-01-02.log
+	Rotation RotationPolicy `yaml:"rotation"`
+
+	// RingFilePath кольцевой файл-самописец фиксированного размера RingFileSizeMB
+	// (по умолчанию 16 МБ): новые записи затирают самые старые. Пишется в
+	// дополнение к Output, RingFileLevel ограничивает его уровень
+	RingFilePath   string `yaml:"ring_file_path"`
+	RingFileSizeMB int    `yaml:"ring_file_size_mb"`
+	RingFileLevel  string `yaml:"ring_file_level"`
+
+	// RetentionFiles файлы для записей классов хранения (WithRetention) со
+	// своими лимитами хранения. Записи этих классов не пишутся в FilePath
+	RetentionFiles map[RetentionClass]RetentionFile `yaml:"retention_files"`
+
+	// LegalHolds запреты удаления записей сервисов за периоды: такие записи
+	// помечаются классом legal_hold, а ротация не удаляет файлы с ними
+	LegalHolds []LegalHold `yaml:"legal_holds"`
+
+	// Пакетная запись в файл: записи копятся и пишутся пачкой из
+	// FileBatchEntries записей или раз в FileBatchInterval (по умолчанию 100 мс),
+	// записи Error и серьезнее и критичные записи - сразу. На Linux пачка
+	// пишется одним writev
+	FileBatchEntries  int           `yaml:"file_batch_entries"` // 0 - без пачек
+	FileBatchInterval time.Duration `yaml:"file_batch_interval"`
+
+	// Targeting повышает детализацию для выбранных пользователей
+	Targeting Targeting `yaml:"targeting"`
+
+	// Redact скрывает значения чувствительных полей
+	Redact     bool     `yaml:"redact"`
+	RedactKeys []string `yaml:"redact_keys"` // ключи для скрытия, по умолчанию password, token и др.
+
+	// Поля больше MaxFieldSize байт заменяются кратким описанием (0 - без ограничения)
+	MaxFieldSize      int `yaml:"max_field_size"`
+	FieldPreviewBytes int `yaml:"field_preview_bytes"` // сколько первых байт показать (по умолчанию 64)
+
+	// HeartbeatInterval период записей alive для мониторинга по логам (0 - выключено)
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval"`
+
+	// DroppedSummaryInterval период итоговых записей dropped entries с числом
+	// записей, отброшенных семплированием (0 - выключено)
+	DroppedSummaryInterval time.Duration `yaml:"dropped_summary_interval"`
+
+	// ResourceAnnotationInterval период чтения лимитов и потребления ресурсов
+	// cgroup, которые добавляются к записям Warn и серьезнее (0 - выключено)
+	ResourceAnnotationInterval time.Duration `yaml:"resource_annotation_interval"`
+
+	// ErrorDebugWindow после первой записи Error сервиса повышает его уровень
+	// до Debug на это время (0 - выключено). Следующее окно для сервиса
+	// открывается не раньше чем через ErrorDebugCooldown после конца предыдущего
+	ErrorDebugWindow   time.Duration `yaml:"error_debug_window"`
+	ErrorDebugCooldown time.Duration `yaml:"error_debug_cooldown"`
+
+	// SLORules правила SLO по доле ошибок, алерты по ним получает AlertSink.
+	// Правила задаются при создании логгера и не меняются при перезагрузке
+	SLORules  []SLORule `yaml:"slo_rules"`
+	AlertSink AlertSink `yaml:"-"`
+
+	// Checksum добавляет в каждую запись поле checksum - CRC-32C ее канонического
+	// представления, чтобы потребители могли обнаружить порчу при доставке и
+	// хранении (VerifyChecksum, logctl verify)
+	Checksum bool `yaml:"checksum"`
+
+	// SlowSinkThreshold порог p99 задержки записи в назначение, после которого
+	// пишется предупреждение slow sink (0 - выключено)
+	SlowSinkThreshold time.Duration `yaml:"slow_sink_threshold"`
+
+	// DefaultFields поля, которые добавляются в каждую запись, если в ней нет
+	// своего значения. ProcessFields добавляет hostname, pid, app и version:
+	// AppName и AppVersion, по умолчанию путь и версия модуля из сведений о сборке
+	DefaultFields map[string]interface{} `yaml:"default_fields"`
+	ProcessFields bool                   `yaml:"process_fields"`
+	AppName       string                 `yaml:"app_name"`
+	AppVersion    string                 `yaml:"app_version"`
+
+	// ReportCaller добавляет в записи поля func и file места вызова. Поиск
+	// места вызова по стеку заметно дороже остальной записи
+	ReportCaller bool `yaml:"report_caller"`
+
+	// Формат места вызова: полный путь файла вместо имени, префиксы модулей,
+	// отрезаемые от пути и функции, и отдельные поля file, line и function
+	// вместо func и file в виде file:line
+	CallerFullPath     bool     `yaml:"caller_full_path"`
+	CallerTrimPrefixes []string `yaml:"caller_trim_prefixes"`
+	CallerSplitFields  bool     `yaml:"caller_split_fields"`
+
+	// CallerTrimModule отрезает от места вызова путь главного модуля и путь до
+	// каталога vendor или кэша модулей. CallerSourceFiles заменяет суффиксы
+	// сгенерированных файлов суффиксами исходников: ".pb.go": ".proto"
+	CallerTrimModule  bool              `yaml:"caller_trim_module"`
+	CallerSourceFiles map[string]string `yaml:"caller_source_files"`
+
+	// ServiceNameMode приводит имена WithService и WithGroup к единому виду:
+	// нижний регистр, латинские буквы, цифры, '_', '-' и '.', не длиннее
+	// MaxServiceNameLength (по умолчанию 64). Уровни ServiceLevels задаются
+	// для приведенных имен
+	ServiceNameMode      ServiceNameMode `yaml:"service_name_mode"`
+	MaxServiceNameLength int             `yaml:"max_service_name_length"`
+
+	// Development режим разработки: нарушенные инварианты AssertTrue вызывают
+	// panic, а записи проверяются на неправильное использование логгера:
+	// больше MisuseMaxFields полей (по умолчанию 32), карты с нестроковыми
+	// ключами, карты и срезы больше MisuseMaxCollection элементов (по
+	// умолчанию 1000). О каждой проблеме один раз пишется Warn logger misuse,
+	// о вызове метода у nil *Logger - предупреждение в stderr
+	Development         bool `yaml:"development"`
+	MisuseMaxFields     int  `yaml:"misuse_max_fields"`
+	MisuseMaxCollection int  `yaml:"misuse_max_collection"`
+
+	// Serverless включает режим AWS Lambda: JSON для CloudWatch в stdout, без файлов
+	Serverless bool `yaml:"serverless"`
+}
+
+// Destination назначение вывода логов. Не закрывается логгером
+type Destination struct {
+	Writer io.Writer
+	Format string // json или text, по умолчанию Format или JSON
+	Level  string // минимальный уровень записей, по умолчанию все записи
+	Filter string // выражение над записью, по умолчанию все записи
 }
 
 // Logger основной логгер приложения
 type Logger struct {
 	logger      *logrus.Logger
+	core        *core
 	serviceName string
+	fields      logrus.Fields
+	ctx         context.Context
+	floor       Level    // минимальная детализация дочернего логгера поверх общего уровня
+	summary     *Summary // итоги пакетной задачи, в которые попадают записи логгера
+	pool        string   // компонент ForPool, уровень которого не зависит от сервиса
+}
+
+// core общее состояние родительского логгера и всех его дочерних логгеров
+type core struct {
+	level         atomic.Uint32
+	serviceLevels atomic.Pointer[map[string]Level] // собственные уровни сервисов
+	pools         atomic.Pointer[poolLevels]       // уровни компонентов ForPool
+	sampleRate    atomic.Uint64                    // доля сохраняемых записей уровня Info и ниже (биты float64)
+	redact        atomic.Bool
+	reportCaller  atomic.Bool
+	callerFormat  atomic.Pointer[callerFormat]
+	legalHolds    atomic.Pointer[[]LegalHold]
+	defaults      atomic.Pointer[logrus.Fields] // поля по умолчанию для каждой записи
+	resources     atomic.Pointer[logrus.Fields] // сведения cgroup для записей Warn и серьезнее
+	config        atomic.Pointer[Config]        // действующая конфигурация для отладочного архива
+	serviceNames  atomic.Pointer[serviceNamePolicy]
+	errorDebug    atomic.Pointer[errorDebug] // повышение до Debug после ошибки, nil - выключено
+	redactKeys    atomic.Pointer[map[string]struct{}]
+	fieldLimits   atomic.Pointer[fieldLimits] // ограничения размера полей
+
+	development bool
+	nop         bool // логгер NewNop отбрасывает все записи
+
+	mu         sync.RWMutex
+	sampler    TraceSampler
+	targeting  *targeting
+	extractors []ContextExtractor
+	closers    []io.Closer // hooks с методом Close, закрываются в Close
+
+	entries       [TraceLevel + 1]atomic.Uint64 // записанные записи по уровням
+	started       time.Time
+	stopHeartbeat func()
+	stopDropped   func()
+	stopResources func()
+	dropped       atomic.Pointer[droppedCounter] // счетчик отброшенных записей, nil - не считаются
+	stopReload    func()
+
+	// Назначения вывода меняются при перезагрузке конфигурации, защищены mu
+	migration *migration
+	sinks     []*sink
+	files     []logFile
+	closeOnce sync.Once
 }
 
 // New создает новый родительский логгер
 func New(config Config) (*Logger, error) {
+	if config.Serverless {
+		config = serverlessConfig(config)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
 	logger := logrus.New()
 
-	// Устанавливаем уровень логирования
-	logger.SetLevel(config.Level)
+	// logrus пропускает все записи, уровень проверяется на стороне Logger,
+	// чтобы дочерние логгеры могли быть детальнее родительского
+	logger.SetLevel(logrus.TraceLevel)
 
-	// Настраиваем формат вывода
-	if err := setupFormatter(logger, config); err != nil {
-		return nil, fmt.Errorf("failed to setup formatter: %w", err)
+	c := &core{started: time.Now()}
+	c.config.Store(&config)
+	c.level.Store(uint32(config.Level))
+	c.setServiceLevels(config.ServiceLevels)
+	c.setPoolLevels(config.PoolLevels)
+	c.targeting = newTargeting(config.Targeting)
+	c.setSampleRate(1)
+	c.redact.Store(config.Redact)
+	c.reportCaller.Store(config.ReportCaller)
+	c.callerFormat.Store(newCallerFormat(config))
+	c.legalHolds.Store(&config.LegalHolds)
+	c.setDefaults(config)
+	c.serviceNames.Store(newServiceNamePolicy(config))
+	c.errorDebug.Store(newErrorDebug(config))
+	c.setRedactKeys(config.RedactKeys)
+	c.fieldLimits.Store(newFieldLimits(config))
+	c.development = config.Development
+	if config.Development {
+		logger.AddHook(newMisuseHook(config))
+		nilWarnings.Store(true)
 	}
 
-	// Настраиваем вывод
-	if err := setupOutput(logger, config); err != nil {
-		return nil, fmt.Errorf("failed to setup output: %w", err)
+	// Настраиваем вывод: у каждого назначения свой формат
+	d, files, err := newDispatcher(config)
+	if err != nil {
+		return nil, err
 	}
+	c.files = files
+	c.migration = d.migration
+	c.sinks = d.sinks
+
+	// Записи форматируются и пишутся в назначения диспетчером,
+	// собственный вывод logrus не используется
+	logger.SetOutput(io.Discard)
+	logger.SetFormatter(d)
 
-	return &Logger{
+	l := &Logger{
 		logger:      logger,
+		core:        c,
 		serviceName: "", // Родительский логгер без имени сервиса
-	}, nil
+	}
+	// Перед завершением после Fatal отправляем записи из очередей сетевых
+	// назначений и пачек файлов, иначе они пропадут вместе с процессом
+	logger.ExitFunc = func(code int) {
+		_ = l.Close()
+		exit(code)
+	}
+
+	if config.HeartbeatInterval > 0 {
+		c.stopHeartbeat = l.StartHeartbeat(config.HeartbeatInterval)
+	}
+	if config.DroppedSummaryInterval > 0 {
+		c.stopDropped = l.StartDroppedSummary(config.DroppedSummaryInterval)
+	}
+	if config.ResourceAnnotationInterval > 0 {
+		stop, err := l.StartResourceAnnotation(config.ResourceAnnotationInterval)
+		if err != nil {
+			l.Close()
+			return nil, err
+		}
+		c.stopResources = stop
+	}
+	if len(config.SLORules) > 0 {
+		if err := l.EnableSLOAlerts(config.AlertSink, config.SLORules); err != nil {
+			return nil, err
+		}
+	}
+
+	return l, nil
 }
 
-// setupFormatter настраивает формат вывода логов
-func setupFormatter(logger *logrus.Logger, config Config) error {
-	// Для консоли всегда используем текстовый формат
-	// Для файла - JSON формат
-	switch config.Output {
-	case ConsoleOutput, BothOutput:
-		logger.SetFormatter(&logrus.TextFormatter{
+// newDispatcher открывает назначения вывода и создает диспетчер записей
+func newDispatcher(config Config) (*dispatcher, []logFile, error) {
+	sinks, files, err := setupOutput(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to setup output: %w", err)
+	}
+
+	d := &dispatcher{sinks: sinks, slowSink: config.SlowSinkThreshold, checksum: config.Checksum}
+	if d.drop, err = compileExprs(config.Drop); err != nil {
+		closeFiles(files)
+		return nil, nil, fmt.Errorf("invalid drop rule: %w", err)
+	}
+	if d.levelRules, err = compileLevelRules(config.LevelRules); err != nil {
+		closeFiles(files)
+		return nil, nil, fmt.Errorf("invalid level rule: %w", err)
+	}
+	if config.Migration != nil {
+		d.migration, d.sinks, err = newMigration(config, sinks)
+		if err != nil {
+			closeFiles(files)
+			return nil, nil, fmt.Errorf("failed to setup migration: %w", err)
+		}
+	}
+	return d, files, nil
+}
+
+// closeFiles закрывает файлы логов, возвращая все ошибки
+func closeFiles(files []logFile) error {
+	var errs []error
+	for _, file := range files {
+		errs = append(errs, file.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Форматы вывода
+const (
+	TextFormat = "text"
+	JSONFormat = "json"
+)
+
+// consoleFormatter возвращает формат вывода в консольный поток out
+func consoleFormatter(config Config, out *os.File) (logrus.Formatter, error) {
+	if config.Serverless {
+		return cloudWatchFormatter(), nil
+	}
+	return newFormatter(firstNonEmpty(config.ConsoleFormat, config.Format, TextFormat), isTerminal(out))
+}
+
+// fileFormatter возвращает формат вывода в файл
+func fileFormatter(config Config) (logrus.Formatter, error) {
+	return newFormatter(firstNonEmpty(config.FileFormat, config.Format, JSONFormat), false)
+}
+
+// newFormatter создает форматтер по названию формата
+func newFormatter(format string, colors bool) (logrus.Formatter, error) {
+	switch format {
+	case TextFormat:
+		return &logrus.TextFormatter{
 			FullTimestamp: true,
-		})
-	case FileOutput:
-		logger.SetFormatter(&logrus.JSONFormatter{})
+			ForceColors:   colors,
+		}, nil
+	case JSONFormat:
+		return &logrus.JSONFormatter{}, nil
+	}
+	return nil, fmt.Errorf("unsupported format: %s", format)
+}
+
+// firstNonEmpty возвращает первую непустую строку
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
 	}
-	return nil
+	return ""
 }
 
-// setupOutput настраивает вывод логов
-func setupOutput(logger *logrus.Logger, config Config) error {
-	var writers []io.Writer
+// setupOutput настраивает назначения вывода и возвращает открытые файлы
+func setupOutput(config Config) ([]*sink, []logFile, error) {
+	var sinks []*sink
+	var files []logFile
+
+	for i, w := range config.Writers {
+		formatter, err := newFormatter(firstNonEmpty(config.Format, JSONFormat), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		sinks = append(sinks, &sink{name: fmt.Sprintf("writer[%d]", i), writer: w, formatter: formatter})
+	}
+
+	for i, d := range config.Destinations {
+		if d.Writer == nil {
+			return nil, nil, fmt.Errorf("destination writer is required")
+		}
+		formatter, err := newFormatter(firstNonEmpty(d.Format, config.Format, JSONFormat), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		accept, err := minLevel(d.Level)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid destination level: %w", err)
+		}
+		filter, err := compileFilter(d.Filter)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid destination filter: %w", err)
+		}
+		sinks = append(sinks, &sink{name: fmt.Sprintf("destination[%d]", i), writer: d.Writer, formatter: formatter, accept: accept, filter: filter})
+	}
+
+	// Output выводит сам бэкенд, если он выбран
+	output := config.Output
+	if usesBackend(config) {
+		backendSink, file, err := openBackend(config)
+		if err != nil {
+			return nil, nil, err
+		}
+		sinks = append(sinks, backendSink)
+		files = append(files, file)
+		output = ""
+	}
+
+	switch output {
+	case "":
+		// Без Output логгер пишет только в Writers, Destinations и Plugins
+		if len(sinks) == 0 && len(config.Plugins) == 0 {
+			return nil, nil, fmt.Errorf("output type or writers are required")
+		}
 
-	switch config.Output {
 	case ConsoleOutput:
-		writers = append(writers, os.Stdout)
+		console, err := consoleSinks(config)
+		if err != nil {
+			return nil, nil, err
+		}
+		sinks = append(sinks, console...)
 
 	case FileOutput:
 		if config.FilePath == "" {
-			return fmt.Errorf("file path is required for file output")
+			return nil, nil, fmt.Errorf("file path is required for file output")
 		}
 
-		file, err := os.OpenFile(config.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+		file, fileSink, err := openFileSink(config)
 		if err != nil {
-			return fmt.Errorf("failed to open log file: %w", err)
+			return nil, nil, err
 		}
-		writers = append(writers, file)
+		sinks = append(sinks, fileSink)
+		files = append(files, file)
 
 	case BothOutput:
-		writers = append(writers, os.Stdout)
+		console, err := consoleSinks(config)
+		if err != nil {
+			return nil, nil, err
+		}
+		sinks = append(sinks, console...)
 
 		if config.FilePath != "" {
-			file, err := os.OpenFile(config.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+			file, fileSink, err := openFileSink(config)
 			if err != nil {
-				return fmt.Errorf("failed to open log file: %w", err)
+				return nil, nil, err
 			}
-			writers = append(writers, file)
+			sinks = append(sinks, fileSink)
+			files = append(files, file)
+		}
+
+	case SyslogOutput:
+		conn, syslogSink, err := openSyslogSink(config)
+		if err != nil {
+			return nil, nil, err
+		}
+		spool := newSpoolConn(conn, config.SpoolSize)
+		syslogSink.writer = spool
+		sinks = append(sinks, syslogSink)
+		files = append(files, spool)
+
+	case JournaldOutput:
+		conn, journaldSink, err := openJournaldSink(config)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to journald: %w", err)
 		}
+		sinks = append(sinks, journaldSink)
+		files = append(files, conn)
+
+	case EventLogOutput:
+		log, eventLogSink, err := openEventLogSink(config)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open event log: %w", err)
+		}
+		sinks = append(sinks, eventLogSink)
+		files = append(files, log)
+
+	case GELFOutput:
+		conn, gelfSink, err := openGELFSink(config)
+		if err != nil {
+			return nil, nil, err
+		}
+		spool := newSpoolConn(conn, config.SpoolSize)
+		gelfSink.writer = spool
+		sinks = append(sinks, gelfSink)
+		files = append(files, spool)
+
+	case ElasticsearchOutput:
+		conn, esSink, err := openElasticsearchSink(config)
+		if err != nil {
+			return nil, nil, err
+		}
+		sinks = append(sinks, esSink)
+		files = append(files, conn)
+
+	case CloudWatchOutput:
+		conn, cloudWatchSink, err := openCloudWatchSink(config)
+		if err != nil {
+			return nil, nil, err
+		}
+		sinks = append(sinks, cloudWatchSink)
+		files = append(files, conn)
+
+	case SplunkOutput:
+		conn, splunkSink, err := openSplunkSink(config)
+		if err != nil {
+			return nil, nil, err
+		}
+		sinks = append(sinks, splunkSink)
+		files = append(files, conn)
+
+	case DatadogOutput:
+		conn, datadogSink, err := openDatadogSink(config)
+		if err != nil {
+			return nil, nil, err
+		}
+		sinks = append(sinks, datadogSink)
+		files = append(files, conn)
 
 	default:
-		return fmt.Errorf("unsupported output type: %s", config.Output)
+		return nil, nil, fmt.Errorf("unsupported output type: %s", config.Output)
 	}
 
-	// Устанавливаем множественный вывод
-	if len(writers) > 1 {
-		logger.SetOutput(io.MultiWriter(writers...))
-	} else {
-		logger.SetOutput(writers[0])
+	if len(config.RetentionFiles) > 0 {
+		classSinks, classFiles, err := retentionSinks(config)
+		if err != nil {
+			closeFiles(files)
+			return nil, nil, err
+		}
+		sinks = append(sinks, classSinks...)
+		files = append(files, classFiles...)
+	}
+
+	if config.RingFilePath != "" {
+		ring, ringSink, err := openRingSink(config)
+		if err != nil {
+			closeFiles(files)
+			return nil, nil, err
+		}
+		sinks = append(sinks, ringSink)
+		files = append(files, ring)
+	}
+
+	for _, p := range config.Plugins {
+		conn, pluginSink, err := openPluginSink(p)
+		if err != nil {
+			closeFiles(files)
+			return nil, nil, err
+		}
+		spool := newSpoolConn(conn, config.SpoolSize)
+		pluginSink.writer = spool
+		sinks = append(sinks, pluginSink)
+		files = append(files, spool)
 	}
 
-	return nil
+	return sinks, files, nil
+}
+
+// consoleSinks создает назначения вывода в консоль. При SplitStdErr записи
+// уровня Warn и серьезнее пишутся в stderr, остальные - в stdout
+func consoleSinks(config Config) ([]*sink, error) {
+	accept, err := minLevel(config.ConsoleLevel)
+	if err != nil {
+		return nil, fmt.Errorf("invalid console level: %w", err)
+	}
+	filter, err := compileFilter(config.ConsoleFilter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid console filter: %w", err)
+	}
+
+	if !config.SplitStdErr {
+		formatter, err := consoleFormatter(config, os.Stdout)
+		if err != nil {
+			return nil, err
+		}
+		return []*sink{{name: "stdout", writer: os.Stdout, formatter: formatter, accept: accept, filter: filter}}, nil
+	}
+
+	stdout, err := consoleFormatter(config, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := consoleFormatter(config, os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	return []*sink{
+		{name: "stdout", writer: os.Stdout, formatter: stdout, accept: func(level Level) bool {
+			return level > WarnLevel && (accept == nil || accept(level))
+		}, filter: filter},
+		{name: "stderr", writer: os.Stderr, formatter: stderr, accept: func(level Level) bool {
+			return level <= WarnLevel && (accept == nil || accept(level))
+		}, filter: filter},
+	}, nil
+}
+
+// openFileSink открывает файл логов и создает назначение вывода в него
+func openFileSink(config Config) (logFile, *sink, error) {
+	formatter, err := fileFormatter(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	accept, err := minLevel(config.FileLevel)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid file level: %w", err)
+	}
+	filter, err := compileFilter(config.FileFilter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid file filter: %w", err)
+	}
+	file, err := openLogFile(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	if config.FileBatchEntries > 0 {
+		file = newBatchWriter(file, config.FileBatchEntries, config.FileBatchInterval)
+	}
+	return file, &sink{
+		name:      "file",
+		writer:    file,
+		formatter: formatter,
+		accept:    accept,
+		retention: excludeRetention(config.RetentionFiles),
+		filter:    filter,
+	}, nil
+}
+
+// openRingSink открывает кольцевой файл и создает назначение вывода в него
+func openRingSink(config Config) (*ringFile, *sink, error) {
+	formatter, err := newFormatter(firstNonEmpty(config.FileFormat, config.Format, JSONFormat), false)
+	if err != nil {
+		return nil, nil, err
+	}
+	accept, err := minLevel(config.RingFileLevel)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid ring file level: %w", err)
+	}
+	ring, err := openRingFile(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open ring file: %w", err)
+	}
+	return ring, &sink{name: "ring", writer: ring, formatter: formatter, accept: accept}, nil
 }
 
 // withFields добавляет стандартные поля к логу
 func (l *Logger) withFields() *logrus.Entry {
-	fields := make(map[string]interface{})
+	l = l.orNop()
+	fields := make(map[string]interface{}, len(l.fields)+3)
+	for k, v := range l.fields {
+		fields[k] = v
+	}
 	fields["service"] = l.serviceName
 
-	// Добавляем информацию о вызывающей функции
-	if pc, file, line, ok := runtime.Caller(2); ok {
-		fn := runtime.FuncForPC(pc)
-		if fn != nil {
-			fields["func"] = fn.Name()
+	return l.logger.WithContext(NewContext(l.context(), l)).WithFields(fields)
+}
+
+// internalFields возвращает запись для служебных сообщений логгера. Она
+// проходит конвейер как обычная (поля по умолчанию, ресурсы, правила
+// отбрасывания, скрытие), но не зависит от порога уровня и семплирования
+func (l *Logger) internalFields() *logrus.Entry {
+	entry := l.withFields()
+	return entry.WithContext(context.WithValue(entry.Context, ctxInternalKey{}, true))
+}
+
+// context возвращает контекст, к которому привязан логгер
+func (l *Logger) context() context.Context {
+	if l.ctx != nil {
+		return l.ctx
+	}
+	return context.Background()
+}
+
+// clone возвращает копию логгера для создания дочернего
+func (l *Logger) clone() *Logger {
+	l = l.orNop()
+	child := *l
+	return &child
+}
+
+// level возвращает эффективный уровень логгера
+func (l *Logger) level() Level {
+	level, ok := l.core.poolLevel(l.pool)
+	if !ok {
+		level, ok = l.core.serviceLevel(l.serviceName)
+	}
+	if !ok {
+		level = Level(l.core.level.Load())
+	}
+	if level < DebugLevel {
+		if d := l.core.errorDebug.Load(); d != nil && d.boosted(l.serviceName) {
+			level = DebugLevel
 		}
-		fields["file"] = fmt.Sprintf("%s:%d", filepath.Base(file), line)
 	}
+	if l.floor > level {
+		return l.floor
+	}
+	return level
+}
 
-	return l.logger.WithFields(fields)
+// enabled проверяет, будет ли записано сообщение указанного уровня
+func (l *Logger) enabled(level Level) bool {
+	l = l.orNop()
+	return !l.core.nop && level <= l.level()
 }
 
 // WithService создает новый логгер с указанным именем сервиса
 func (l *Logger) WithService(serviceName string) *Logger {
-	return &Logger{
-		logger:      l.logger,
-		serviceName: serviceName,
-	}
+	child := l.clone()
+	child.serviceName = child.checkServiceName(serviceName)
+	return child
 }
 
 // WithGroup создает новый логгер с дополнительной группой
 func (l *Logger) WithGroup(group string) *Logger {
+	l = l.orNop()
+	group = l.checkServiceName(group)
 	serviceName := l.serviceName
 	if serviceName != "" {
 		serviceName = fmt.Sprintf("%s.%s", serviceName, group)
@@ -163,49 +826,119 @@ func (l *Logger) WithGroup(group string) *Logger {
 		serviceName = group
 	}
 
-	return &Logger{
-		logger:      l.logger,
-		serviceName: serviceName,
+	child := l.clone()
+	child.serviceName = serviceName
+	return child
+}
+
+// With создает дочерний логгер с постоянными полями. В отличие от WithFields
+// поля сохраняются в логгере и добавляются ко всем его записям и записям его
+// потомков, а file и func указывают на место каждого вызова
+func (l *Logger) With(fields map[string]interface{}) *Logger {
+	return l.with(fields)
+}
+
+// with создает дочерний логгер с дополнительными постоянными полями
+func (l *Logger) with(fields logrus.Fields) *Logger {
+	l = l.orNop()
+	merged := make(logrus.Fields, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
 	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	child := l.clone()
+	child.fields = merged
+	return child
+}
+
+// Trace логирует сообщение на уровне Trace
+func (l *Logger) Trace(args ...interface{}) {
+	if !l.enabled(TraceLevel) {
+		return
+	}
+	l.withFields().Trace(args...)
+}
+
+// Tracef логирует форматированное сообщение на уровне Trace
+func (l *Logger) Tracef(format string, args ...interface{}) {
+	if !l.enabled(TraceLevel) {
+		return
+	}
+	l.withFields().Tracef(format, args...)
+}
+
+// Traceln логирует сообщение на уровне Trace, разделяя аргументы пробелами
+func (l *Logger) Traceln(args ...interface{}) {
+	if !l.enabled(TraceLevel) {
+		return
+	}
+	l.withFields().Traceln(args...)
 }
 
 // Debug логирует сообщение на уровне Debug
 func (l *Logger) Debug(args ...interface{}) {
+	if !l.enabled(DebugLevel) {
+		return
+	}
 	l.withFields().Debug(args...)
 }
 
 // Debugf логирует форматированное сообщение на уровне Debug
 func (l *Logger) Debugf(format string, args ...interface{}) {
+	if !l.enabled(DebugLevel) {
+		return
+	}
 	l.withFields().Debugf(format, args...)
 }
 
 // Info логирует сообщение на уровне Info
 func (l *Logger) Info(args ...interface{}) {
+	if !l.enabled(InfoLevel) {
+		return
+	}
 	l.withFields().Info(args...)
 }
 
 // Infof логирует форматированное сообщение на уровне Info
 func (l *Logger) Infof(format string, args ...interface{}) {
+	if !l.enabled(InfoLevel) {
+		return
+	}
 	l.withFields().Infof(format, args...)
 }
 
 // Warn логирует сообщение на уровне Warn
 func (l *Logger) Warn(args ...interface{}) {
+	if !l.enabled(WarnLevel) {
+		return
+	}
 	l.withFields().Warn(args...)
 }
 
 // Warnf логирует форматированное сообщение на уровне Warn
 func (l *Logger) Warnf(format string, args ...interface{}) {
+	if !l.enabled(WarnLevel) {
+		return
+	}
 	l.withFields().Warnf(format, args...)
 }
 
 // Error логирует сообщение на уровне Error
 func (l *Logger) Error(args ...interface{}) {
+	if !l.enabled(ErrorLevel) {
+		return
+	}
 	l.withFields().Error(args...)
 }
 
 // Errorf логирует форматированное сообщение на уровне Error
 func (l *Logger) Errorf(format string, args ...interface{}) {
+	if !l.enabled(ErrorLevel) {
+		return
+	}
 	l.withFields().Errorf(format, args...)
 }
 
@@ -246,10 +979,90 @@ func (l *Logger) WithError(err error) *logrus.Entry {
 
 // SetLevel устанавливает уровень логирования
 func (l *Logger) SetLevel(level Level) {
-	l.logger.SetLevel(level)
+	// Уровень заглушки общий для всех nil логгеров и не меняется
+	if l == nil {
+		nilReceiver()
+		return
+	}
+	l.core.level.Store(uint32(level))
 }
 
 // GetLevel возвращает текущий уровень логирования
 func (l *Logger) GetLevel() Level {
-	return l.logger.GetLevel()
+	l = l.orNop()
+	return Level(l.core.level.Load())
+}
+
+// logFiles возвращает открытые файлы логов
+func (c *core) logFiles() []logFile {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.files
+}
+
+// Sync сбрасывает буферы файлов логов на диск
+func (l *Logger) Sync() error {
+	l = l.orNop()
+	var errs []error
+	for _, file := range l.core.logFiles() {
+		if err := file.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Reopen закрывает и заново открывает файлы логов по настроенному пути.
+// Нужен для внешней ротации (logrotate без copytruncate): после переименования
+// файла запись продолжается в новый файл
+func (l *Logger) Reopen() error {
+	l = l.orNop()
+	var errs []error
+	for _, file := range l.core.logFiles() {
+		if err := file.Reopen(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to reopen log file: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close сбрасывает и закрывает файлы логов. Повторные вызовы ничего не делают,
+// поэтому его можно вызывать через defer вместе с явным закрытием
+func (l *Logger) Close() error {
+	l = l.orNop()
+	var err error
+	l.core.closeOnce.Do(func() {
+		if l.core.stopReload != nil {
+			l.core.stopReload()
+		}
+		if l.core.stopHeartbeat != nil {
+			l.core.stopHeartbeat()
+		}
+		if l.core.stopDropped != nil {
+			l.core.stopDropped()
+		}
+		if l.core.stopResources != nil {
+			l.core.stopResources()
+		}
+
+		err = errors.Join(l.Sync(), l.core.closeHooks(), closeFiles(l.core.logFiles()))
+	})
+	return err
+}
+
+// entryLogger возвращает логгер, создавший запись
+func entryLogger(entry *logrus.Entry) *Logger {
+	if entry.Context == nil {
+		return nil
+	}
+	return FromContext(entry.Context)
+}
+
+// internalEntry сообщает, что запись служебная
+func internalEntry(entry *logrus.Entry) bool {
+	if entry.Context == nil {
+		return false
+	}
+	internal, _ := entry.Context.Value(ctxInternalKey{}).(bool)
+	return internal
 }
@@ -1,13 +1,79 @@
 package logger
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// newBufferLogger создает логгер, пишущий JSON в буфер
+func newBufferLogger(t *testing.T) (*Logger, *bytes.Buffer) {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	logger, err := New(Config{
+		Level:        TraceLevel,
+		Writers:      []io.Writer{buf},
+		ReportCaller: true,
+	})
+	require.NoError(t, err)
+
+	return logger, buf
+}
+
+// lockedBuffer буфер, безопасный для записи из других горутин
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// setTestSink заменяет назначения вывода логгера одним writer'ом
+func setTestSink(logger *Logger, w io.Writer, formatter logrus.Formatter) {
+	logger.logger.SetFormatter(&dispatcher{sinks: []*sink{{writer: w, formatter: formatter}}})
+}
+
+// testSinks возвращает назначения вывода логгера
+func testSinks(logger *Logger) []*sink {
+	return logger.logger.Formatter.(*dispatcher).sinks
+}
+
+// decodeEntries разбирает JSON-записи из буфера
+func decodeEntries(t *testing.T, buf fmt.Stringer) []map[string]interface{} {
+	t.Helper()
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		entry := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
 func TestNew(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -88,6 +154,24 @@ func TestLogger_WithFields(t *testing.T) {
 	assert.NotNil(t, entry)
 }
 
+func TestLogger_With(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	reqLogger := logger.With(map[string]interface{}{"request_id": "r-1", "user_id": "anonymous"})
+	userLogger := reqLogger.With(map[string]interface{}{"user_id": "u-42"})
+	reqLogger.Info("request started")
+	userLogger.Info("profile loaded")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "r-1", entries[0]["request_id"])
+	assert.Equal(t, "anonymous", entries[0]["user_id"])
+	assert.Equal(t, "r-1", entries[1]["request_id"])
+	assert.Equal(t, "u-42", entries[1]["user_id"])
+	assert.Contains(t, entries[1]["file"], "logger_test.go")
+	assert.NotContains(t, logger.fields, "request_id")
+}
+
 func TestLogger_WithError(t *testing.T) {
 	config := Config{
 		Level:  DebugLevel,
@@ -156,6 +240,28 @@ func TestLogger_Levels(t *testing.T) {
 	assert.Equal(t, InfoLevel, logger.GetLevel())
 }
 
+func TestLogger_Trace(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	logger.Trace("trace", "message")
+	logger.Tracef("trace %d", 2)
+	logger.Traceln("trace", "line")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "tracemessage", entries[0]["msg"])
+	assert.Equal(t, "trace 2", entries[1]["msg"])
+	assert.Equal(t, "trace line", entries[2]["msg"])
+	for _, entry := range entries {
+		assert.Equal(t, "trace", entry["level"])
+		assert.Equal(t, "logger_test.go", strings.Split(entry["file"].(string), ":")[0])
+	}
+
+	logger.SetLevel(DebugLevel)
+	logger.Trace("hidden")
+	assert.Len(t, decodeEntries(t, buf), 3)
+}
+
 func TestLogger_FileOutput(t *testing.T) {
 	tempFile := t.TempDir() + "/test.log"
 
@@ -196,4 +302,200 @@ func TestLogger_BothOutput(t *testing.T) {
 	content, err := os.ReadFile(tempFile)
 	require.NoError(t, err)
 	assert.Contains(t, string(content), "test message")
+
+}
+
+func TestLogger_Formats(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      Config
+		wantConsole logrus.Formatter
+		wantFile    logrus.Formatter
+	}{
+		{
+			name:        "defaults",
+			config:      Config{},
+			wantConsole: &logrus.TextFormatter{},
+			wantFile:    &logrus.JSONFormatter{},
+		},
+		{
+			name:        "json everywhere",
+			config:      Config{Format: JSONFormat},
+			wantConsole: &logrus.JSONFormatter{},
+			wantFile:    &logrus.JSONFormatter{},
+		},
+		{
+			name:        "text everywhere",
+			config:      Config{Format: TextFormat},
+			wantConsole: &logrus.TextFormatter{},
+			wantFile:    &logrus.TextFormatter{},
+		},
+		{
+			name:        "per output overrides",
+			config:      Config{Format: TextFormat, ConsoleFormat: JSONFormat},
+			wantConsole: &logrus.JSONFormatter{},
+			wantFile:    &logrus.TextFormatter{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.Level = InfoLevel
+			config.Output = BothOutput
+			config.FilePath = t.TempDir() + "/test.log"
+
+			logger, err := New(config)
+			require.NoError(t, err)
+			defer logger.Close()
+
+			sinks := testSinks(logger)
+			require.Len(t, sinks, 2)
+			assert.Equal(t, os.Stdout, sinks[0].writer)
+			assert.IsType(t, tt.wantConsole, sinks[0].formatter)
+			assert.IsType(t, tt.wantFile, sinks[1].formatter)
+		})
+	}
+}
+
+func TestLogger_FileOutput_Text(t *testing.T) {
+	tempFile := t.TempDir() + "/test.log"
+
+	logger, err := New(Config{
+		Level:    InfoLevel,
+		Output:   FileOutput,
+		FilePath: tempFile,
+		Format:   TextFormat,
+	})
+	require.NoError(t, err)
+
+	logger.Info("test message")
+
+	content, err := os.ReadFile(tempFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `msg="test message"`)
+}
+
+func TestNew_InvalidFormat(t *testing.T) {
+	_, err := New(Config{
+		Level:  InfoLevel,
+		Output: ConsoleOutput,
+		Format: "xml",
+	})
+	assert.ErrorContains(t, err, "unsupported format: xml")
+
+	_, err = New(Config{
+		Level:      InfoLevel,
+		Output:     FileOutput,
+		FilePath:   t.TempDir() + "/test.log",
+		FileFormat: "yaml",
+	})
+	assert.ErrorContains(t, err, "unsupported format: yaml")
+}
+
+func TestLogger_FileOutput_CreatesDirectory(t *testing.T) {
+	tempFile := t.TempDir() + "/nested/logs/test.log"
+
+	logger, err := New(Config{
+		Level:    InfoLevel,
+		Output:   FileOutput,
+		FilePath: tempFile,
+		DirMode:  0700,
+	})
+	require.NoError(t, err)
+
+	logger.Info("test message")
+
+	dirInfo, err := os.Stat(strings.TrimSuffix(tempFile, "/test.log"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), dirInfo.Mode().Perm())
+
+	content, err := os.ReadFile(tempFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "test message")
+}
+
+func TestLogger_FileOutput_DisableDirCreation(t *testing.T) {
+	_, err := New(Config{
+		Level:              InfoLevel,
+		Output:             FileOutput,
+		FilePath:           t.TempDir() + "/missing/test.log",
+		DisableDirCreation: true,
+	})
+	assert.Error(t, err)
+}
+
+func TestLogger_Close(t *testing.T) {
+	tempFile := t.TempDir() + "/test.log"
+
+	logger, err := New(Config{
+		Level:     InfoLevel,
+		Output:    BothOutput,
+		FilePath:  tempFile,
+		MaxSizeMB: 1,
+	})
+	require.NoError(t, err)
+
+	logger.Info("test message")
+	require.NoError(t, logger.Sync())
+	require.NoError(t, logger.Close())
+	require.NoError(t, logger.Close())
+
+	content, err := os.ReadFile(tempFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "test message")
+}
+
+func TestLogger_Close_ConsoleOutput(t *testing.T) {
+	logger, err := New(Config{
+		Level:  InfoLevel,
+		Output: ConsoleOutput,
+	})
+	require.NoError(t, err)
+
+	assert.NoError(t, logger.Sync())
+	assert.NoError(t, logger.Close())
+}
+
+func TestLogger_Writers(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	logger, err := New(Config{
+		Level:   InfoLevel,
+		Writers: []io.Writer{buf},
+	})
+	require.NoError(t, err)
+
+	logger.Info("test message")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "test message", entries[0]["msg"])
+}
+
+func TestLogger_Writers_WithOutput(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tempFile := t.TempDir() + "/test.log"
+
+	logger, err := New(Config{
+		Level:    InfoLevel,
+		Output:   FileOutput,
+		FilePath: tempFile,
+		Format:   TextFormat,
+		Writers:  []io.Writer{buf},
+	})
+	require.NoError(t, err)
+	defer logger.Close()
+
+	logger.Info("test message")
+
+	assert.Contains(t, buf.String(), `msg="test message"`)
+	content, err := os.ReadFile(tempFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `msg="test message"`)
+}
+
+func TestNew_NoOutput(t *testing.T) {
+	_, err := New(Config{Level: InfoLevel})
+	assert.Error(t, err)
 }
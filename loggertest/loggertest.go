@@ -0,0 +1,157 @@
+// Package loggertest помогает проверять логирование в тестах сервисов:
+// логгер пишет записи в память, а не в stdout, и их можно проверить без
+// разбора вывода.
+//
+//	rec := loggertest.New(t)
+//	svc := orders.NewService(repo, rec.Logger)
+//	svc.Cancel(ctx, id)
+//	rec.AssertLogged(logger.WarnLevel, "order cancelled")
+package loggertest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ex-rate/logger"
+)
+
+// Entry записанная запись
+type Entry struct {
+	Level   logger.Level
+	Message string
+	Time    time.Time
+	Fields  map[string]interface{} // поля записи, в том числе service
+}
+
+// Recorder логгер, который хранит записи в памяти
+type Recorder struct {
+	*logger.Logger
+
+	t       testing.TB
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// New создает логгер уровня Trace, записи которого сохраняются в памяти.
+// Логгер закрывается по завершении теста
+func New(t testing.TB) *Recorder {
+	t.Helper()
+	return NewWithConfig(t, logger.Config{Level: logger.TraceLevel})
+}
+
+// NewWithConfig создает логгер с конфигурацией config, записи которого
+// дополнительно сохраняются в памяти. Вывод config, если он задан, сохраняется
+func NewWithConfig(t testing.TB, config logger.Config) *Recorder {
+	t.Helper()
+
+	r := &Recorder{t: t}
+	config.Destinations = append(config.Destinations, logger.Destination{
+		Writer: recordWriter{r},
+		Format: logger.JSONFormat,
+	})
+	l, err := logger.New(config)
+	if err != nil {
+		t.Fatalf("loggertest: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	r.Logger = l
+	return r
+}
+
+// recordWriter разбирает записи JSON и сохраняет их в Recorder.
+// Диспетчер логгера пишет каждую запись одним вызовом Write
+type recordWriter struct {
+	r *Recorder
+}
+
+// Write сохраняет одну запись
+func (w recordWriter) Write(p []byte) (int, error) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(p, &data); err != nil {
+		return 0, fmt.Errorf("loggertest: decode entry: %w", err)
+	}
+
+	entry := Entry{Fields: data}
+	if level, ok := data["level"].(string); ok {
+		entry.Level, _ = logger.ParseLevel(level)
+	}
+	entry.Message, _ = data["msg"].(string)
+	if ts, ok := data["time"].(string); ok {
+		entry.Time, _ = time.Parse(time.RFC3339, ts)
+	}
+	delete(data, "level")
+	delete(data, "msg")
+	delete(data, "time")
+
+	w.r.mu.Lock()
+	w.r.entries = append(w.r.entries, entry)
+	w.r.mu.Unlock()
+	return len(p), nil
+}
+
+// Entries возвращает копию записанных записей в порядке записи
+func (r *Recorder) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Entry(nil), r.entries...)
+}
+
+// Filter возвращает записи уровня level, сообщение которых содержит substr
+func (r *Recorder) Filter(level logger.Level, substr string) []Entry {
+	var matched []Entry
+	for _, entry := range r.Entries() {
+		if entry.Level == level && strings.Contains(entry.Message, substr) {
+			matched = append(matched, entry)
+		}
+	}
+	return matched
+}
+
+// Reset удаляет записанные записи
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = nil
+}
+
+// AssertLogged проверяет, что записана запись уровня level, сообщение
+// которой содержит substr, и возвращает первую такую запись
+func (r *Recorder) AssertLogged(level logger.Level, substr string) (Entry, bool) {
+	r.t.Helper()
+
+	matched := r.Filter(level, substr)
+	if len(matched) == 0 {
+		r.t.Errorf("loggertest: no %s entry containing %q, logged:\n%s", level, substr, r.dump())
+		return Entry{}, false
+	}
+	return matched[0], true
+}
+
+// AssertNotLogged проверяет, что записи уровня level с substr в сообщении нет
+func (r *Recorder) AssertNotLogged(level logger.Level, substr string) bool {
+	r.t.Helper()
+
+	if matched := r.Filter(level, substr); len(matched) > 0 {
+		r.t.Errorf("loggertest: unexpected %s entry containing %q: %q", level, substr, matched[0].Message)
+		return false
+	}
+	return true
+}
+
+// dump описывает записанные записи для сообщения об ошибке
+func (r *Recorder) dump() string {
+	entries := r.Entries()
+	if len(entries) == 0 {
+		return "  (no entries)"
+	}
+	var b strings.Builder
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "  %s: %s\n", entry.Level, entry.Message)
+	}
+	return b.String()
+}
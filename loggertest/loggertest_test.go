@@ -0,0 +1,70 @@
+package loggertest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ex-rate/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeT перехватывает ошибки проверок
+type fakeT struct {
+	testing.TB
+	errors []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestRecorder(t *testing.T) {
+	rec := New(t)
+
+	rec.WithService("orders").WithField("order_id", 42).Warn("order cancelled by user")
+	rec.Debug("cache miss")
+
+	entries := rec.Entries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, logger.WarnLevel, entries[0].Level)
+	assert.Equal(t, "order cancelled by user", entries[0].Message)
+	assert.Equal(t, "orders", entries[0].Fields["service"])
+	assert.Equal(t, float64(42), entries[0].Fields["order_id"])
+	assert.False(t, entries[0].Time.IsZero())
+	assert.NotContains(t, entries[0].Fields, "msg")
+
+	entry, ok := rec.AssertLogged(logger.WarnLevel, "cancelled")
+	assert.True(t, ok)
+	assert.Equal(t, float64(42), entry.Fields["order_id"])
+	assert.True(t, rec.AssertNotLogged(logger.ErrorLevel, "cancelled"))
+
+	rec.Reset()
+	assert.Empty(t, rec.Entries())
+}
+
+func TestRecorder_FailedAssertions(t *testing.T) {
+	ft := &fakeT{TB: t}
+	rec := New(t)
+	rec.t = ft
+
+	rec.Info("payment captured")
+	_, ok := rec.AssertLogged(logger.ErrorLevel, "payment")
+	assert.False(t, ok)
+	assert.False(t, rec.AssertNotLogged(logger.InfoLevel, "payment"))
+
+	require.Len(t, ft.errors, 2)
+	assert.Contains(t, ft.errors[0], "info: payment captured")
+	assert.Contains(t, ft.errors[1], "unexpected info entry")
+}
+
+func TestNewWithConfig(t *testing.T) {
+	rec := NewWithConfig(t, logger.Config{Level: logger.WarnLevel})
+
+	rec.Info("hidden")
+	rec.Error("shown")
+	require.Len(t, rec.Entries(), 1)
+	rec.AssertLogged(logger.ErrorLevel, "shown")
+}
@@ -0,0 +1,19 @@
+module github.com/ex-rate/logger/logrbridge
+
+go 1.24.5
+
+require (
+	github.com/ex-rate/logger v0.0.0
+	github.com/go-logr/logr v1.4.2
+	github.com/stretchr/testify v1.11.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/sirupsen/logrus v1.9.3 // indirect
+	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/ex-rate/logger => ../
@@ -0,0 +1,140 @@
+// Package logrbridge позволяет передать logger.Logger библиотекам, которые
+// принимают logr.Logger: controller-runtime, client-go и другим библиотекам
+// Kubernetes.
+//
+// Уровни детализации logr переводятся в уровни логгера: V(0) - Info,
+// V(1) - Debug, V(2) и выше - Trace. Имена из WithName становятся группами
+// сервиса (WithGroup), значения из WithValues - постоянными полями.
+// Пакет вынесен в отдельный модуль, чтобы основной пакет не зависел от logr
+package logrbridge
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"time"
+
+	"github.com/ex-rate/logger"
+	"github.com/go-logr/logr"
+)
+
+// errorKey поле ошибки, как у WithError
+const errorKey = "error"
+
+// New возвращает logr.Logger, который пишет через l
+func New(l *logger.Logger) logr.Logger {
+	return logr.New(NewLogSink(l))
+}
+
+// NewLogSink возвращает logr.LogSink, который пишет через l
+func NewLogSink(l *logger.Logger) logr.LogSink {
+	return &sink{logger: l}
+}
+
+// sink реализует logr.LogSink и logr.CallDepthLogSink
+type sink struct {
+	logger    *logger.Logger
+	callDepth int // кадры между методом sink и вызывающим кодом
+}
+
+// vLevel переводит уровень детализации logr в уровень slog: записи идут
+// через SlogHandler, который учитывает место вызова из записи
+func vLevel(level int) slog.Level {
+	switch {
+	case level <= 0:
+		return slog.LevelInfo
+	case level == 1:
+		return slog.LevelDebug
+	default:
+		return slog.LevelDebug - 1
+	}
+}
+
+// Init сохраняет глубину вызова logr
+func (s *sink) Init(info logr.RuntimeInfo) {
+	s.callDepth = info.CallDepth
+}
+
+// Enabled проверяет эффективный уровень логгера
+func (s *sink) Enabled(level int) bool {
+	return s.logger.SlogHandler().Enabled(context.Background(), vLevel(level))
+}
+
+// Info пишет запись уровня детализации level
+func (s *sink) Info(level int, msg string, keysAndValues ...any) {
+	s.log(vLevel(level), msg, keysAndValues)
+}
+
+// Error пишет запись уровня Error с полем error
+func (s *sink) Error(err error, msg string, keysAndValues ...any) {
+	if err != nil {
+		keysAndValues = append([]any{errorKey, err}, keysAndValues...)
+	}
+	s.log(slog.LevelError, msg, keysAndValues)
+}
+
+// WithValues возвращает sink с постоянными полями
+func (s *sink) WithValues(keysAndValues ...any) logr.LogSink {
+	return &sink{logger: s.logger.With(fields(keysAndValues)), callDepth: s.callDepth}
+}
+
+// WithName возвращает sink для вложенной группы сервиса
+func (s *sink) WithName(name string) logr.LogSink {
+	return &sink{logger: s.logger.WithGroup(name), callDepth: s.callDepth}
+}
+
+// WithCallDepth возвращает sink, пропускающий еще depth кадров вызова
+func (s *sink) WithCallDepth(depth int) logr.LogSink {
+	return &sink{logger: s.logger, callDepth: s.callDepth + depth}
+}
+
+// log передает запись обработчику slog логгера с местом вызова в коде
+// пользователя: кадры log, Info или Error и callDepth кадров logr
+func (s *sink) log(level slog.Level, msg string, keysAndValues []any) {
+	handler := s.logger.SlogHandler()
+	ctx := context.Background()
+	if !handler.Enabled(ctx, level) {
+		return
+	}
+
+	var pcs [1]uintptr
+	runtime.Callers(3+s.callDepth, pcs[:])
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.AddAttrs(attrs(keysAndValues)...)
+	_ = handler.Handle(ctx, r)
+}
+
+// attrs переводит пары ключ-значение logr в атрибуты
+func attrs(keysAndValues []any) []slog.Attr {
+	result := make([]slog.Attr, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		result = append(result, slog.Any(key(keysAndValues[i]), value(keysAndValues, i+1)))
+	}
+	return result
+}
+
+// fields переводит пары ключ-значение logr в поля
+func fields(keysAndValues []any) map[string]any {
+	f := make(map[string]any, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		f[key(keysAndValues[i])] = value(keysAndValues, i+1)
+	}
+	return f
+}
+
+// key возвращает ключ поля. Ключи не строкового типа приводятся к строке
+func key(k any) string {
+	if s, ok := k.(string); ok {
+		return s
+	}
+	return fmt.Sprint(k)
+}
+
+// value возвращает значение пары. Для непарного последнего ключа - (MISSING)
+func value(keysAndValues []any, i int) any {
+	if i < len(keysAndValues) {
+		return keysAndValues[i]
+	}
+	return "(MISSING)"
+}
@@ -0,0 +1,63 @@
+package logrbridge
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ex-rate/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decode разбирает JSON-записи из буфера
+func decode(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := logger.New(logger.Config{Level: logger.DebugLevel, Writers: []io.Writer{&buf}, ReportCaller: true})
+	require.NoError(t, err)
+
+	log := New(l.WithService("operator")).WithName("reconciler").WithValues("controller", "pods", "dangling")
+
+	log.Info("reconciling", "namespace", "default")
+	log.V(1).Info("cache hit")
+	log.V(2).Info("hidden trace")
+	log.Error(errors.New("conflict"), "update failed", 42, "x")
+
+	assert.True(t, log.V(1).Enabled())
+	assert.False(t, log.V(2).Enabled())
+
+	entries := decode(t, &buf)
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, "info", entries[0]["level"])
+	assert.Equal(t, "reconciling", entries[0]["msg"])
+	assert.Equal(t, "operator.reconciler", entries[0]["service"])
+	assert.Equal(t, "pods", entries[0]["controller"])
+	assert.Equal(t, "(MISSING)", entries[0]["dangling"])
+	assert.Equal(t, "default", entries[0]["namespace"])
+	assert.Contains(t, entries[0]["file"], "logrbridge_test.go")
+
+	assert.Equal(t, "debug", entries[1]["level"])
+
+	assert.Equal(t, "error", entries[2]["level"])
+	assert.Equal(t, "conflict", entries[2]["error"])
+	assert.Equal(t, "x", entries[2]["42"])
+	assert.Contains(t, entries[2]["file"], "logrbridge_test.go")
+}
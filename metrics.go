@@ -0,0 +1,117 @@
+package logger
+
+import "github.com/sirupsen/logrus"
+
+// MetricTagField признак записи, по которой нужно посчитать метрики: metric=true
+const MetricTagField = "metric"
+
+// MetricsRecorder приемник метрик, построенных по записям логов.
+// Реализуется адаптером к Prometheus (CounterVec, HistogramVec) или другой системе
+type MetricsRecorder interface {
+	IncCounter(name string, labels map[string]string)
+	ObserveHistogram(name string, value float64, labels map[string]string)
+}
+
+// MetricsOptions настройки построения метрик по записям
+type MetricsOptions struct {
+	// CountEntries считает все записи в log_entries_total{service, level}
+	CountEntries bool
+	// HistogramFields числовые поля помеченных записей, значения которых
+	// попадают в одноименные гистограммы. По умолчанию duration_ms
+	HistogramFields []string
+}
+
+// EnableMetrics включает построение метрик по записям логов. Для записей с полем
+// metric=true увеличивается log_events_total{service, level, event}, где event -
+// сообщение записи, а числовые поля из HistogramFields попадают в гистограммы
+// с лейблами {service, event}. Метрики считаются до семплирования
+func (l *Logger) EnableMetrics(recorder MetricsRecorder, opts MetricsOptions) {
+	if l == nil {
+		nilReceiver()
+		return
+	}
+	if len(opts.HistogramFields) == 0 {
+		opts.HistogramFields = []string{"duration_ms"}
+	}
+	l.logger.AddHook(&metricsHook{recorder: recorder, opts: opts})
+}
+
+// metricsHook hook logrus, передающий метрики в MetricsRecorder
+type metricsHook struct {
+	recorder MetricsRecorder
+	opts     MetricsOptions
+}
+
+// Levels возвращает уровни, для которых вызывается hook
+func (h *metricsHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire строит метрики по записи
+func (h *metricsHook) Fire(entry *logrus.Entry) error {
+	if l := entryLogger(entry); l != nil && !l.enabled(Level(entry.Level)) {
+		return nil
+	}
+
+	service, _ := entry.Data["service"].(string)
+
+	if h.opts.CountEntries {
+		h.recorder.IncCounter("log_entries_total", map[string]string{
+			"service": service,
+			"level":   entry.Level.String(),
+		})
+	}
+
+	if tagged, _ := entry.Data[MetricTagField].(bool); !tagged {
+		return nil
+	}
+
+	h.recorder.IncCounter("log_events_total", map[string]string{
+		"service": service,
+		"level":   entry.Level.String(),
+		"event":   entry.Message,
+	})
+
+	for _, field := range h.opts.HistogramFields {
+		value, ok := toFloat(entry.Data[field])
+		if !ok {
+			continue
+		}
+		h.recorder.ObserveHistogram(field, value, map[string]string{
+			"service": service,
+			"event":   entry.Message,
+		})
+	}
+	return nil
+}
+
+// toFloat приводит числовое значение поля к float64
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
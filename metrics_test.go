@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryMetrics приемник метрик в памяти
+type memoryMetrics struct {
+	mu         sync.Mutex
+	counters   map[string]int
+	histograms map[string][]float64
+}
+
+func newMemoryMetrics() *memoryMetrics {
+	return &memoryMetrics{
+		counters:   make(map[string]int),
+		histograms: make(map[string][]float64),
+	}
+}
+
+// metricKey строит ключ метрики вида name{k=v,...}
+func metricKey(name string, labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(pairs)
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
+
+func (m *memoryMetrics) IncCounter(name string, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counters[metricKey(name, labels)]++
+}
+
+func (m *memoryMetrics) ObserveHistogram(name string, value float64, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := metricKey(name, labels)
+	m.histograms[key] = append(m.histograms[key], value)
+}
+
+func TestLogger_EnableMetrics(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+	logger.SetLevel(InfoLevel)
+
+	metrics := newMemoryMetrics()
+	logger.EnableMetrics(metrics, MetricsOptions{CountEntries: true})
+
+	api := logger.WithService("api")
+	api.WithFields(map[string]interface{}{
+		"metric":      true,
+		"duration_ms": int64(120),
+	}).Info("request handled")
+	api.WithFields(map[string]interface{}{
+		"metric":      true,
+		"duration_ms": 80.5,
+	}).Info("request handled")
+	api.Warn("slow dependency")
+	api.WithField("metric", true).Debug("below level")
+
+	assert.Equal(t, map[string]int{
+		"log_entries_total{level=info,service=api}":                      2,
+		"log_entries_total{level=warning,service=api}":                   1,
+		"log_events_total{event=request handled,level=info,service=api}": 2,
+	}, metrics.counters)
+	assert.Equal(t, map[string][]float64{
+		"duration_ms{event=request handled,service=api}": {120, 80.5},
+	}, metrics.histograms)
+}
+
+func TestLogger_EnableMetrics_IgnoresSampling(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	logger.SetSamplingRate(0)
+
+	metrics := newMemoryMetrics()
+	logger.EnableMetrics(metrics, MetricsOptions{})
+
+	logger.WithField("metric", true).Info("event")
+
+	assert.Empty(t, buf.String())
+	assert.Equal(t, 1, metrics.counters["log_events_total{event=event,level=info,service=}"])
+}
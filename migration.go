@@ -0,0 +1,229 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync/atomic"
+)
+
+// Migration режим двойной записи при переходе на новое назначение логов:
+// каждая запись пишется и в основной вывод (файл, если он настроен, иначе
+// консоль), и в New. Доля CompareRate записей сравнивается по полям
+type Migration struct {
+	New         Destination
+	CompareRate float64 // доля сравниваемых записей, 0-1 (0 - без сравнения)
+	// OnMismatch вызывается при расхождении выводов. Вызывается под блокировкой
+	// логгера, поэтому не должен писать в тот же логгер
+	OnMismatch func(MigrationMismatch)
+}
+
+// MigrationMismatch расхождение выводов старого и нового назначений
+type MigrationMismatch struct {
+	Old, New []byte
+	Missing  []string // поля, которых нет в новом выводе
+	Extra    []string // поля, которых нет в старом выводе
+	Changed  []string // поля с разными значениями
+}
+
+// MigrationStats счетчики сравнения выводов
+type MigrationStats struct {
+	Compared   uint64
+	Mismatched uint64
+}
+
+// migration действующий режим двойной записи
+type migration struct {
+	old, new   *sink
+	rate       float64
+	onMismatch func(MigrationMismatch)
+
+	compared   atomic.Uint64
+	mismatched atomic.Uint64
+}
+
+// newMigration добавляет к назначениям новое назначение миграции
+func newMigration(config Config, sinks []*sink) (*migration, []*sink, error) {
+	m := config.Migration
+
+	old := primarySink(sinks)
+	if old == nil {
+		return nil, nil, errors.New("migration requires console or file output")
+	}
+
+	newSinks, _, err := setupOutput(Config{Format: config.Format, Destinations: []Destination{m.New}})
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid migration destination: %w", err)
+	}
+
+	newSinks[0].name = "migration"
+	return &migration{
+		old:        old,
+		new:        newSinks[0],
+		rate:       m.CompareRate,
+		onMismatch: m.OnMismatch,
+	}, append(sinks, newSinks[0]), nil
+}
+
+// primarySink возвращает основное назначение: файл, если он есть, иначе stdout
+func primarySink(sinks []*sink) *sink {
+	var console *sink
+	for _, s := range sinks {
+		if s.name == "file" {
+			return s
+		}
+		if s.name == "stdout" && console == nil {
+			console = s
+		}
+	}
+	return console
+}
+
+// compare сравнивает выводы одной записи в старом и новом назначениях
+func (m *migration) compare(oldData, newData []byte) {
+	if m.rate <= 0 || (m.rate < 1 && rand.Float64() >= m.rate) {
+		return
+	}
+	m.compared.Add(1)
+
+	mismatch := MigrationMismatch{Old: oldData, New: newData}
+	oldFields := parseOutput(oldData)
+	newFields := parseOutput(newData)
+	for k, oldValue := range oldFields {
+		newValue, ok := newFields[k]
+		switch {
+		case !ok:
+			mismatch.Missing = append(mismatch.Missing, k)
+		case oldValue != newValue && oldValue != compositeValue && newValue != compositeValue:
+			mismatch.Changed = append(mismatch.Changed, k)
+		}
+	}
+	for k := range newFields {
+		if _, ok := oldFields[k]; !ok {
+			mismatch.Extra = append(mismatch.Extra, k)
+		}
+	}
+
+	if len(mismatch.Missing)+len(mismatch.Extra)+len(mismatch.Changed) == 0 {
+		return
+	}
+	m.mismatched.Add(1)
+	if m.onMismatch != nil {
+		sort.Strings(mismatch.Missing)
+		sort.Strings(mismatch.Extra)
+		sort.Strings(mismatch.Changed)
+		m.onMismatch(mismatch)
+	}
+}
+
+// MigrationStats возвращает счетчики сравнения выводов в режиме миграции
+func (l *Logger) MigrationStats() MigrationStats {
+	l = l.orNop()
+	l.core.mu.RLock()
+	m := l.core.migration
+	l.core.mu.RUnlock()
+	if m == nil {
+		return MigrationStats{}
+	}
+	return MigrationStats{Compared: m.compared.Load(), Mismatched: m.mismatched.Load()}
+}
+
+// compositeValue значение вложенного объекта или массива: текстовый формат
+// выводит их иначе, чем JSON, поэтому сравнивается только наличие поля
+const compositeValue = "\x00composite"
+
+// parseOutput разбирает строку вывода JSON или текстового формата в значения полей
+func parseOutput(data []byte) map[string]string {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("{")) {
+		return parseJSONOutput(data)
+	}
+	return parseTextOutput(data)
+}
+
+// parseJSONOutput разбирает запись JSON-формата
+func parseJSONOutput(data []byte) map[string]string {
+	var raw map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return nil
+	}
+
+	fields := make(map[string]string, len(raw))
+	for k, v := range raw {
+		switch v := v.(type) {
+		case string:
+			fields[k] = v
+		case json.Number:
+			fields[k] = v.String()
+		case bool:
+			fields[k] = strconv.FormatBool(v)
+		case nil:
+			fields[k] = "<nil>"
+		default:
+			fields[k] = compositeValue
+		}
+	}
+	return fields
+}
+
+// parseTextOutput разбирает запись текстового формата logrus: key=value
+// через пробел, значения с пробелами и спецсимволами в кавычках
+func parseTextOutput(data []byte) map[string]string {
+	fields := make(map[string]string)
+	s := string(data)
+	for len(s) > 0 {
+		if s[0] == ' ' {
+			s = s[1:]
+			continue
+		}
+
+		eq := 0
+		for eq < len(s) && s[eq] != '=' && s[eq] != ' ' {
+			eq++
+		}
+		key := s[:eq]
+		if eq == len(s) || s[eq] != '=' {
+			fields[key] = ""
+			s = s[eq:]
+			continue
+		}
+		s = s[eq+1:]
+
+		var value string
+		if len(s) > 0 && s[0] == '"' {
+			end := quotedEnd(s)
+			unquoted, err := strconv.Unquote(s[:end])
+			if err != nil {
+				unquoted = s[:end]
+			}
+			value, s = unquoted, s[end:]
+		} else {
+			end := 0
+			for end < len(s) && s[end] != ' ' {
+				end++
+			}
+			value, s = s[:end], s[end:]
+		}
+		fields[key] = value
+	}
+	return fields
+}
+
+// quotedEnd возвращает позицию за закрывающей кавычкой строки s
+func quotedEnd(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(s)
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigration_DualWriteMatches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	target := &bytes.Buffer{}
+	var mismatches []MigrationMismatch
+
+	logger, err := New(Config{
+		Level:      TraceLevel,
+		Output:     FileOutput,
+		FilePath:   path,
+		FileFormat: TextFormat,
+		Migration: &Migration{
+			New:         Destination{Writer: target, Format: JSONFormat},
+			CompareRate: 1,
+			OnMismatch:  func(m MigrationMismatch) { mismatches = append(mismatches, m) },
+		},
+	})
+	require.NoError(t, err)
+
+	logger.WithFields(map[string]interface{}{
+		"user_id": 42,
+		"path":    "/api/v1/users list",
+		"ok":      true,
+		"tags":    []string{"a", "b"},
+	}).Info("request handled")
+	require.NoError(t, logger.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `msg="request handled"`)
+	assert.Len(t, decodeEntries(t, target), 1)
+
+	assert.Empty(t, mismatches)
+	assert.Equal(t, MigrationStats{Compared: 1}, logger.MigrationStats())
+}
+
+func TestMigration_ReportsMismatch(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+	old := &sink{writer: &bytes.Buffer{}}
+	target := &sink{writer: &bytes.Buffer{}}
+	var got MigrationMismatch
+	m := &migration{old: old, new: target, rate: 1, onMismatch: func(mm MigrationMismatch) { got = mm }}
+	logger.core.migration = m
+
+	m.compare(
+		[]byte(`level=info msg="user created" user_id=7 region=eu`+"\n"),
+		[]byte(`{"level":"info","msg":"user created","user_id":8,"tenant":"acme"}`+"\n"),
+	)
+
+	assert.Equal(t, []string{"region"}, got.Missing)
+	assert.Equal(t, []string{"tenant"}, got.Extra)
+	assert.Equal(t, []string{"user_id"}, got.Changed)
+	assert.Equal(t, MigrationStats{Compared: 1, Mismatched: 1}, logger.MigrationStats())
+}
+
+func TestMigration_CompareRateZero(t *testing.T) {
+	m := &migration{}
+	m.compare([]byte(`msg=a`), []byte(`{"msg":"b"}`))
+	assert.Zero(t, m.compared.Load())
+}
+
+func TestMigration_Errors(t *testing.T) {
+	_, err := New(Config{
+		Destinations: []Destination{{Writer: &bytes.Buffer{}}},
+		Migration:    &Migration{New: Destination{Writer: &bytes.Buffer{}}},
+	})
+	assert.ErrorContains(t, err, "requires console or file output")
+
+	_, err = New(Config{Output: ConsoleOutput, Migration: &Migration{New: Destination{Writer: &bytes.Buffer{}}, CompareRate: 2}})
+	assert.Error(t, err)
+
+	_, err = New(Config{Output: ConsoleOutput, Migration: &Migration{}})
+	assert.Error(t, err)
+}
+
+func TestParseTextOutput(t *testing.T) {
+	fields := parseTextOutput([]byte(`time="2024-01-15T10:00:00Z" level=info msg="say \"hi\"" empty= flag`))
+	assert.Equal(t, map[string]string{
+		"time":  "2024-01-15T10:00:00Z",
+		"level": "info",
+		"msg":   `say "hi"`,
+		"empty": "",
+		"flag":  "",
+	}, fields)
+}
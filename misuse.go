@@ -0,0 +1,170 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultMisuseMaxFields     = 32
+	defaultMisuseMaxCollection = 1000
+
+	// misuseField поле предупреждения о неправильном использовании: такие
+	// записи сами не проверяются
+	misuseField = "misuse"
+)
+
+// Виды неправильного использования логгера
+const (
+	MisuseTooManyFields    = "too_many_fields"
+	MisuseNonStringMapKeys = "non_string_map_keys"
+	MisuseLargeCollection  = "large_collection"
+)
+
+// misuseHook в режиме Development ищет в записях признаки неправильного
+// использования логгера и один раз на вид, поле и сообщение пишет Warn с
+// подсказкой, как исправить вызов
+type misuseHook struct {
+	maxFields     int
+	maxCollection int
+	warned        sync.Map
+}
+
+// newMisuseHook создает проверку записей с ограничениями из конфигурации
+func newMisuseHook(config Config) *misuseHook {
+	h := &misuseHook{maxFields: config.MisuseMaxFields, maxCollection: config.MisuseMaxCollection}
+	if h.maxFields == 0 {
+		h.maxFields = defaultMisuseMaxFields
+	}
+	if h.maxCollection == 0 {
+		h.maxCollection = defaultMisuseMaxCollection
+	}
+	return h
+}
+
+// Levels возвращает уровни, для которых вызывается hook
+func (h *misuseHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// misuse найденная проблема записи
+type misuse struct {
+	kind   string
+	field  string
+	detail string
+	hint   string
+}
+
+// Fire проверяет запись и предупреждает о найденных проблемах
+func (h *misuseHook) Fire(entry *logrus.Entry) error {
+	l := entryLogger(entry)
+	if l == nil || !l.enabled(Level(entry.Level)) {
+		return nil
+	}
+	if _, ok := entry.Data[misuseField]; ok {
+		return nil
+	}
+	for _, m := range h.check(entry) {
+		key := m.kind + "\x00" + m.field + "\x00" + entry.Message
+		if _, warned := h.warned.LoadOrStore(key, struct{}{}); warned {
+			continue
+		}
+		fields := map[string]interface{}{
+			misuseField: m.kind,
+			"entry_msg": entry.Message,
+			"detail":    m.detail,
+			"hint":      m.hint,
+		}
+		if m.field != "" {
+			fields["field"] = m.field
+		}
+		l.withFields().WithFields(fields).Warn("logger misuse")
+	}
+	return nil
+}
+
+// check возвращает проблемы записи
+func (h *misuseHook) check(entry *logrus.Entry) []misuse {
+	var found []misuse
+	// service добавляется к каждой записи и не считается
+	if n := len(entry.Data) - 1; n > h.maxFields {
+		found = append(found, misuse{
+			kind:   MisuseTooManyFields,
+			detail: fmt.Sprintf("entry has %d fields, limit %d", n, h.maxFields),
+			hint:   "group related fields into one struct field or move constant fields to With",
+		})
+	}
+	for key, value := range entry.Data {
+		v := reflect.ValueOf(value)
+		switch v.Kind() {
+		case reflect.Map:
+			if k := v.Type().Key().Kind(); k != reflect.String {
+				found = append(found, misuse{
+					kind:   MisuseNonStringMapKeys,
+					field:  key,
+					detail: fmt.Sprintf("map with %s keys", v.Type().Key()),
+					hint:   "convert keys to strings: formats encode non-string keys differently or fail",
+				})
+			}
+		case reflect.Slice, reflect.Array:
+			// []byte пишется строкой и ограничивается MaxFieldSize
+			if v.Type().Elem().Kind() == reflect.Uint8 {
+				continue
+			}
+		default:
+			continue
+		}
+		if n := v.Len(); n > h.maxCollection {
+			found = append(found, misuse{
+				kind:   MisuseLargeCollection,
+				field:  key,
+				detail: fmt.Sprintf("%s with %d elements, limit %d", v.Kind(), n, h.maxCollection),
+				hint:   "log the element count or a sample instead of the whole collection",
+			})
+		}
+	}
+	return found
+}
+
+var (
+	// nilFallback логгер-заглушка для вызовов методов у nil *Logger
+	nilFallback     *Logger
+	nilFallbackOnce sync.Once
+	// nilWarned места вызова, о которых уже предупредили
+	nilWarned sync.Map
+	// nilWarnings включает предупреждения о nil логгере. У nil логгера нет
+	// своей конфигурации, поэтому их включает любой логгер с Development
+	nilWarnings atomic.Bool
+)
+
+// nilReceiver возвращает заглушку NewNop вместо nil *Logger, чтобы вызов не
+// паниковал. В режиме разработки один раз на место вызова пишет
+// предупреждение в stderr. Собственного вывода у nil логгера нет,
+// настройки и регистрации на нем игнорируются
+func nilReceiver() *Logger {
+	if nilWarnings.Load() {
+		site := "unknown location"
+		if frame, ok := callerFrame(); ok {
+			site = fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if _, warned := nilWarned.LoadOrStore(site, struct{}{}); !warned {
+			fmt.Fprintf(os.Stderr, "logger: method called on nil *Logger at %s, the call is ignored: create the logger with logger.New or use logger.NewNop\n", site)
+		}
+	}
+	nilFallbackOnce.Do(func() { nilFallback = NewNop() })
+	return nilFallback
+}
+
+// orNop возвращает l или заглушку для nil *Logger. Методы получают
+// заглушку здесь или через enabled, withFields, clone и with
+func (l *Logger) orNop() *Logger {
+	if l == nil {
+		return nilReceiver()
+	}
+	return l
+}
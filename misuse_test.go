@@ -0,0 +1,280 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_MisuseWarnings(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(Config{
+		Level:               DebugLevel,
+		Writers:             []io.Writer{buf},
+		Development:         true,
+		MisuseMaxFields:     3,
+		MisuseMaxCollection: 2,
+	})
+	require.NoError(t, err)
+
+	fields := map[string]interface{}{"a": 1, "b": 2, "c": 3, "d": 4}
+	logger.WithFields(fields).Info("too wide")
+	logger.WithFields(fields).Info("too wide")
+	logger.WithField("by_id", map[int]string{1: "x"}).Info("int keys")
+	logger.WithField("ids", []int{1, 2, 3}).Info("many ids")
+	logger.WithField("payload", []byte("abc")).Info("bytes")
+	logger.WithField("ids", []int{1, 2}).Info("few ids")
+
+	var warnings []map[string]interface{}
+	for _, entry := range decodeEntries(t, buf) {
+		if entry["msg"] == "logger misuse" {
+			warnings = append(warnings, entry)
+		}
+	}
+	require.Len(t, warnings, 3)
+	assert.Equal(t, MisuseTooManyFields, warnings[0][misuseField])
+	assert.Equal(t, "too wide", warnings[0]["entry_msg"])
+	assert.Equal(t, "entry has 4 fields, limit 3", warnings[0]["detail"])
+	assert.Equal(t, "warning", warnings[0]["level"])
+
+	assert.Equal(t, MisuseNonStringMapKeys, warnings[1][misuseField])
+	assert.Equal(t, "by_id", warnings[1]["field"])
+	assert.Equal(t, "map with int keys", warnings[1]["detail"])
+
+	assert.Equal(t, MisuseLargeCollection, warnings[2][misuseField])
+	assert.Equal(t, "slice with 3 elements, limit 2", warnings[2]["detail"])
+	assert.NotEmpty(t, warnings[2]["hint"])
+}
+
+func TestLogger_MisuseOnlyInDevelopment(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	logger.WithField("by_id", map[int]string{1: "x"}).Info("int keys")
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "int keys", entries[0]["msg"])
+}
+
+// captureNilWarnings включает или выключает предупреждения о nil логгере
+// и возвращает текст, который fn пишет в stderr
+func captureNilWarnings(t *testing.T, enabled bool, fn func()) string {
+	t.Helper()
+	nilWarned.Range(func(key, _ interface{}) bool {
+		nilWarned.Delete(key)
+		return true
+	})
+	prev := nilWarnings.Load()
+	nilWarnings.Store(enabled)
+	defer nilWarnings.Store(prev)
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+	fn()
+	os.Stderr = stderr
+	require.NoError(t, w.Close())
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(out)
+}
+
+func TestLogger_NilSafe(t *testing.T) {
+	var logger *Logger
+	call := func() { logger.Info("lost") }
+	out := captureNilWarnings(t, true, func() {
+		assert.NotPanics(t, func() {
+			call()
+			call()
+			logger.WithField("k", "v").Error("lost")
+			logger.WithService("billing").WithContext(context.Background()).Warn("lost")
+			logger.InfoFields("lost", String("k", "v"))
+			logger.SetLevel(DebugLevel)
+			assert.Equal(t, PanicLevel, logger.GetLevel())
+			assert.NoError(t, logger.Sync())
+			assert.NoError(t, logger.Close())
+		})
+	})
+
+	// Одно предупреждение на место вызова: call вызывается дважды
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	assert.Len(t, lines, 8)
+	assert.Contains(t, out, "logger: method called on nil *Logger at ")
+	assert.Contains(t, out, "use logger.NewNop")
+}
+
+func TestLogger_NilWarningOnlyInDevelopment(t *testing.T) {
+	var logger *Logger
+	out := captureNilWarnings(t, false, func() {
+		logger.Info("lost")
+		logger.WithField("k", "v").Error("lost")
+	})
+	assert.Empty(t, out)
+
+	prev := nilWarnings.Load()
+	nilWarnings.Store(false)
+	t.Cleanup(func() { nilWarnings.Store(prev) })
+	_, err := New(Config{Level: InfoLevel, Output: ConsoleOutput, Development: true})
+	require.NoError(t, err)
+	assert.True(t, nilWarnings.Load())
+}
+
+func TestConfig_ValidateMisuseLimits(t *testing.T) {
+	err := Config{Level: InfoLevel, Output: ConsoleOutput, MisuseMaxFields: -1}.Validate()
+	assert.ErrorContains(t, err, "misuse limits must not be negative")
+}
+
+// nilFlags провайдер флагов без значений
+type nilFlags struct{}
+
+func (nilFlags) StringFlag(_ context.Context, _ string, d string) string  { return d }
+func (nilFlags) FloatFlag(_ context.Context, _ string, d float64) float64 { return d }
+func (nilFlags) BoolFlag(_ context.Context, _ string, d bool) bool        { return d }
+
+func TestLogger_NilSafeMethods(t *testing.T) {
+	var l *Logger
+	ctx := context.Background()
+	serve := func(h http.Handler, method, target, body string) {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, target, strings.NewReader(body)))
+	}
+	ok := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+
+	calls := map[string]func(){
+		"AddContextExtractor": func() { l.AddContextExtractor(ContextValueExtractor("k", "f")) },
+		"AddHook":             func() { l.AddHook(nil) },
+		"AdminHandler":        func() { serve(l.AdminHandler(), http.MethodGet, "/level", "") },
+		"ApplyConfig":         func() { assert.NoError(t, l.ApplyConfig(Config{Level: DebugLevel})) },
+		"ApplyFlags":          func() { l.ApplyFlags(ctx, nilFlags{}) },
+		"AssertTrue":          func() { l.AssertTrue(false, "invariant", nil) },
+		"AuditExec":           func() { _ = l.AuditExec(exec.Command("true"), ExecAuditOptions{}) },
+		"CaptureCommand":      func() { l.CaptureCommand(exec.Command("true"), CaptureOptions{})() },
+		"Close":               func() { assert.NoError(t, l.Close()) },
+		"ConsumerMiddleware": func() {
+			_ = l.ConsumerMiddleware(func(context.Context, Message) error { return nil })(ctx, Message{})
+		},
+		"Debug":             func() { l.Debug("x") },
+		"DebugBundle":       func() { assert.NoError(t, l.DebugBundle(io.Discard)) },
+		"DebugCtx":          func() { l.DebugCtx(ctx, "x") },
+		"DebugFields":       func() { l.DebugFields("x") },
+		"Debugf":            func() { l.Debugf("%d", 1) },
+		"Deprecated":        func() { l.Deprecated("nil-logger-test", "use New") },
+		"EnableMetrics":     func() { l.EnableMetrics(nil, MetricsOptions{}) },
+		"EnableSLOAlerts":   func() { assert.NoError(t, l.EnableSLOAlerts(nil, nil)) },
+		"Error":             func() { l.Error("x") },
+		"ErrorCtx":          func() { l.ErrorCtx(ctx, "x") },
+		"ErrorFields":       func() { l.ErrorFields("x") },
+		"Errorf":            func() { l.Errorf("%d", 1) },
+		"ForPool":           func() { l.ForPool("db", DebugLevel).Info("x") },
+		"GetLevel":          func() { l.GetLevel() },
+		"HTTPMiddleware":    func() { serve(l.HTTPMiddleware(AccessLogOptions{})(ok), http.MethodGet, "/", "") },
+		"Info":              func() { l.Info("x") },
+		"InfoCtx":           func() { l.InfoCtx(ctx, "x") },
+		"InfoFields":        func() { l.InfoFields("x") },
+		"Infof":             func() { l.Infof("%d", 1) },
+		"IngestHandler":     func() { serve(l.IngestHandler(IngestOptions{}), http.MethodPost, "/", `{"msg":"x"}`) },
+		"Log":               func() { l.Log(InfoLevel, "x") },
+		"MigrationStats":    func() { l.MigrationStats() },
+		"OnCancel":          func() { l.OnCancel(ctx, "op")() },
+		"OpenConnection":    func() { l.OpenConnection(ConnectionInfo{}).Close(0, "", nil) },
+		"Panic":             func() { assert.Panics(t, func() { l.Panic("x") }) },
+		"Panicf":            func() { assert.Panics(t, func() { l.Panicf("%d", 1) }) },
+		"PoolLevels":        func() { l.PoolLevels() },
+		"Progress":          func() { l.Progress("import", 10).Add(1) },
+		"Redaction":         func() { l.Redaction() },
+		"Reopen":            func() { assert.NoError(t, l.Reopen()) },
+		"ReopenOnSignal":    func() { l.ReopenOnSignal()() },
+		"ResetPoolLevel":    func() { l.ResetPoolLevel("db") },
+		"ResetServiceLevel": func() { l.ResetServiceLevel("billing") },
+		"SamplingRate":      func() { l.SamplingRate() },
+		"ServeAggregator": func() {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			ln.Close()
+			_ = l.ServeAggregator(ln, AggregatorOptions{})
+		},
+		"ServiceLevels":           func() { l.ServiceLevels() },
+		"SetLevel":                func() { l.SetLevel(DebugLevel) },
+		"SetPoolLevel":            func() { l.SetPoolLevel("db", DebugLevel) },
+		"SetRedaction":            func() { l.SetRedaction(true) },
+		"SetSamplingRate":         func() { l.SetSamplingRate(0.5) },
+		"SetServiceLevel":         func() { l.SetServiceLevel("billing", DebugLevel) },
+		"SetTargeting":            func() { l.SetTargeting(Targeting{}) },
+		"SetTraceSampler":         func() { l.SetTraceSampler(nil) },
+		"SinkLatencies":           func() { l.SinkLatencies() },
+		"SlogHandler":             func() { slog.New(l.SlogHandler()).Info("x") },
+		"Snapshot":                func() { _ = l.Snapshot(io.Discard) },
+		"StartDroppedSummary":     func() { l.StartDroppedSummary(time.Hour)() },
+		"StartHeartbeat":          func() { l.StartHeartbeat(time.Hour)() },
+		"StartResourceAnnotation": func() { stop, _ := l.StartResourceAnnotation(time.Hour); stop() },
+		"StartSummary":            func() { l.StartSummary("job").Finish() },
+		"StdLogger":               func() { l.StdLogger(InfoLevel).Print("x") },
+		"Sync":                    func() { assert.NoError(t, l.Sync()) },
+		"TargetUser":              func() { l.TargetUser("u-1") },
+		"Targeting":               func() { l.Targeting() },
+		"ToggleVerbosityOnSignal": func() { l.ToggleVerbosityOnSignal()() },
+		"Trace":                   func() { l.Trace("x") },
+		"TraceCtx":                func() { l.TraceCtx(ctx, "x") },
+		"TraceFields":             func() { l.TraceFields("x") },
+		"Tracef":                  func() { l.Tracef("%d", 1) },
+		"Traceln":                 func() { l.Traceln("x") },
+		"UnderLegalHold":          func() { l.UnderLegalHold("billing", time.Now()) },
+		"UntargetUser":            func() { l.UntargetUser("u-1") },
+		"Warn":                    func() { l.Warn("x") },
+		"WarnCtx":                 func() { l.WarnCtx(ctx, "x") },
+		"WarnFields":              func() { l.WarnFields("x") },
+		"Warnf":                   func() { l.Warnf("%d", 1) },
+		"WatchConfig":             func() { l.WatchConfig("missing.yaml", time.Hour)() },
+		"WatchFlags": func() {
+			cancelled, cancel := context.WithCancel(ctx)
+			cancel()
+			l.WatchFlags(cancelled, nilFlags{}, time.Hour)
+		},
+		"With":          func() { l.With(map[string]interface{}{"k": "v"}).Info("x") },
+		"WithContext":   func() { l.WithContext(ctx).Info("x") },
+		"WithDiff":      func() { l.WithDiff("order", 1, 2).Info("x") },
+		"WithError":     func() { l.WithError(io.EOF).Info("x") },
+		"WithField":     func() { l.WithField("k", "v").Info("x") },
+		"WithFields":    func() { l.WithFields(map[string]interface{}{"k": "v"}).Info("x") },
+		"WithGroup":     func() { l.WithGroup("db").Info("x") },
+		"WithPriority":  func() { l.WithPriority(PriorityCritical).Info("x") },
+		"WithRetention": func() { l.WithRetention(RetentionShort).Info("x") },
+		"WithService":   func() { l.WithService("billing").Info("x") },
+		"WithWorker":    func() { l.WithWorker(1).Info("x") },
+		"WorkerGroup":   func() { l.WorkerGroup(ctx) },
+		"Writer":        func() { w := l.Writer(); _, _ = w.Write([]byte("x\n")); w.Close() },
+		"WriterLevel":   func() { w := l.WriterLevel(InfoLevel); _, _ = w.Write([]byte("x\n")); w.Close() },
+	}
+
+	// Fatal завершает процесс, как и у NewNop
+	skipped := map[string]bool{"Fatal": true, "Fatalf": true}
+	typ := reflect.TypeOf(l)
+	for i := 0; i < typ.NumMethod(); i++ {
+		name := typ.Method(i).Name
+		if !skipped[name] {
+			assert.Contains(t, calls, name, "exported method without nil logger case")
+		}
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			assert.NotPanics(t, call)
+		})
+	}
+
+	// Вызовы nil логгера не меняют общую заглушку
+	assert.Equal(t, PanicLevel, l.GetLevel())
+	assert.False(t, l.Redaction())
+	assert.Empty(t, l.ServiceLevels())
+}
@@ -0,0 +1,16 @@
+package logger
+
+import "io"
+
+// NewNop создает логгер, который ничего не пишет: для модульных тестов и
+// библиотек с необязательным логированием. Проверка уровня отбрасывает
+// записи до сбора полей, SetLevel их не включает. Fatal и Panic по-прежнему
+// завершают программу и вызывают панику
+func NewNop() *Logger {
+	l, err := New(Config{Level: PanicLevel, Writers: []io.Writer{io.Discard}})
+	if err != nil {
+		panic("logger: nop logger: " + err.Error())
+	}
+	l.core.nop = true
+	return l
+}
@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewNop(t *testing.T) {
+	logger := NewNop()
+	logger.SetLevel(TraceLevel)
+
+	child := logger.WithService("orders").WithContext(context.Background())
+	child.Info("discarded")
+	child.WithField("order_id", 1).Error("discarded")
+	child.InfoFields("discarded", Int("n", 1))
+	assert.Panics(t, func() { child.Panic("still panics") })
+
+	assert.Zero(t, logger.core.entries[ErrorLevel].Load())
+	assert.Zero(t, logger.core.entries[PanicLevel].Load())
+	assert.NoError(t, logger.Close())
+}
+
+func TestNewNop_NoAlloc(t *testing.T) {
+	logger := NewNop()
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.Error("discarded")
+		logger.ErrorFields("discarded", String("k", "v"))
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkNop_Info(b *testing.B) {
+	logger := NewNop()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.Info("discarded")
+	}
+}
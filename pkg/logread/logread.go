@@ -0,0 +1,406 @@
+// Package logread читает файлы, записанные логгером: JSON и текстовый
+// формат logfmt, в том числе ротированные и сжатые копии, и отдает записи
+// структурами Entry с фильтром по времени, уровню и полям. На нем строятся
+// утилиты разбора, воспроизведения и обезличивания логов.
+//
+//	r, err := logread.OpenRotated("/var/log/app/app.log", logread.Filter{
+//	    Since:  time.Now().Add(-time.Hour),
+//	    Level:  "warn",
+//	    Fields: map[string]string{"service": "billing"},
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer r.Close()
+//	for r.Next() {
+//	    e := r.Entry()
+//	    fmt.Println(e.Time, e.Level, e.Message, e.Fields["order_id"])
+//	}
+//	return r.Err()
+package logread
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ex-rate/logger"
+)
+
+// maxLineBytes предел длины строки лога
+const maxLineBytes = 16 << 20
+
+// Ключи служебных полей: logrus пишет time, level и msg, режим Serverless -
+// timestamp и message
+var (
+	timeKeys    = []string{"time", "timestamp"}
+	levelKeys   = []string{"level"}
+	messageKeys = []string{"msg", "message"}
+)
+
+// Entry запись лога
+type Entry struct {
+	Time    time.Time
+	Level   logger.Level
+	Message string
+	// Fields остальные поля записи. Числа из JSON - json.Number, значения
+	// текстового формата - строки
+	Fields map[string]interface{}
+
+	Path string // файл, из которого прочитана запись; для NewReader пустой
+	Line int    // номер строки в файле, с единицы
+}
+
+// Filter условия отбора записей. Пустые условия не ограничивают выборку
+type Filter struct {
+	Since time.Time // записи не раньше Since
+	Until time.Time // записи раньше Until
+	// Level записи этого уровня и серьезнее, например warn
+	Level string
+	// Fields значения полей: запись подходит, если каждое поле есть и его
+	// значение в текстовом виде совпадает
+	Fields map[string]string
+}
+
+// matcher разобранный Filter
+type matcher struct {
+	Filter
+	level    logger.Level
+	hasLevel bool
+}
+
+// compile проверяет фильтр
+func (f Filter) compile() (matcher, error) {
+	m := matcher{Filter: f}
+	if f.Level != "" {
+		level, err := logger.ParseLevel(f.Level)
+		if err != nil {
+			return matcher{}, err
+		}
+		m.level, m.hasLevel = level, true
+	}
+	return m, nil
+}
+
+// match проверяет запись
+func (m matcher) match(e Entry) bool {
+	if !m.Since.IsZero() && e.Time.Before(m.Since) {
+		return false
+	}
+	if !m.Until.IsZero() && !e.Time.Before(m.Until) {
+		return false
+	}
+	if m.hasLevel && e.Level > m.level {
+		return false
+	}
+	for key, want := range m.Fields {
+		v, ok := e.Fields[key]
+		if !ok || fmt.Sprint(v) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// source источник строк: файл или переданный поток
+type source struct {
+	path string
+	open func() (io.ReadCloser, error)
+}
+
+// Reader читает записи из нескольких источников по очереди. Строки, которые
+// не удалось разобрать, пропускаются и учитываются в Skipped
+type Reader struct {
+	sources []source
+	filter  matcher
+
+	current io.ReadCloser
+	scanner *bufio.Scanner
+	path    string
+	line    int
+
+	entry   Entry
+	err     error
+	skipped int
+}
+
+// Open читает файлы paths по порядку. Сжатые файлы распаковываются кодеком
+// по расширению (logger.CodecForFile). Если задан Filter.Since, файлы,
+// измененные раньше него, не открываются
+func Open(paths []string, filter Filter) (*Reader, error) {
+	m, err := filter.compile()
+	if err != nil {
+		return nil, err
+	}
+	r := &Reader{filter: m}
+	for _, path := range paths {
+		path := path
+		r.sources = append(r.sources, source{path: path, open: func() (io.ReadCloser, error) {
+			return openFile(path, m.Since)
+		}})
+	}
+	return r, nil
+}
+
+// OpenRotated читает ротированные копии файла лога path от старых к новым
+// и затем сам path
+func OpenRotated(path string, filter Filter) (*Reader, error) {
+	backups, err := logger.BackupFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	return Open(append(backups, path), filter)
+}
+
+// NewReader читает записи из r, например из stdin. r не закрывается
+func NewReader(r io.Reader, filter Filter) (*Reader, error) {
+	m, err := filter.compile()
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{filter: m, sources: []source{{open: func() (io.ReadCloser, error) {
+		return io.NopCloser(r), nil
+	}}}}, nil
+}
+
+// errSkipFile файл заведомо не содержит подходящих записей
+var errSkipFile = errors.New("file is older than filter")
+
+// openFile открывает файл лога, распаковывая сжатые файлы
+func openFile(path string, since time.Time) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !since.IsZero() {
+		// Файл не меняется после последней записи в него
+		if info, err := file.Stat(); err == nil && info.ModTime().Before(since) {
+			file.Close()
+			return nil, errSkipFile
+		}
+	}
+	codec, ok := logger.CodecForFile(path)
+	if !ok {
+		return file, nil
+	}
+	r, err := codec.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &codecFile{ReadCloser: r, file: file}, nil
+}
+
+// codecFile распаковываемый файл: закрывает и распаковщик, и файл
+type codecFile struct {
+	io.ReadCloser
+	file *os.File
+}
+
+func (f *codecFile) Close() error {
+	return errors.Join(f.ReadCloser.Close(), f.file.Close())
+}
+
+// Next переходит к следующей подходящей записи. Возвращает false, когда
+// записи закончились или чтение прервалось ошибкой (Err)
+func (r *Reader) Next() bool {
+	for r.err == nil {
+		if r.scanner == nil {
+			if len(r.sources) == 0 {
+				return false
+			}
+			src := r.sources[0]
+			r.sources = r.sources[1:]
+			rc, err := src.open()
+			if errors.Is(err, errSkipFile) {
+				continue
+			}
+			if err != nil {
+				r.err = err
+				return false
+			}
+			r.current, r.path, r.line = rc, src.path, 0
+			r.scanner = bufio.NewScanner(rc)
+			r.scanner.Buffer(make([]byte, 64<<10), maxLineBytes)
+		}
+
+		if !r.scanner.Scan() {
+			err := r.scanner.Err()
+			r.closeCurrent()
+			if err != nil {
+				r.err = r.wrap(err)
+				return false
+			}
+			continue
+		}
+		r.line++
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		entry, err := ParseLine(line)
+		if err != nil {
+			r.skipped++
+			continue
+		}
+		if !r.filter.match(entry) {
+			continue
+		}
+		entry.Path, entry.Line = r.path, r.line
+		r.entry = entry
+		return true
+	}
+	return false
+}
+
+// wrap добавляет к ошибке имя файла
+func (r *Reader) wrap(err error) error {
+	if r.path == "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", r.path, err)
+}
+
+// closeCurrent закрывает прочитанный источник
+func (r *Reader) closeCurrent() {
+	if r.current != nil {
+		if err := r.current.Close(); err != nil && r.err == nil {
+			r.err = r.wrap(err)
+		}
+	}
+	r.current, r.scanner = nil, nil
+}
+
+// Entry возвращает текущую запись
+func (r *Reader) Entry() Entry {
+	return r.entry
+}
+
+// Err возвращает ошибку чтения
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// Skipped возвращает число строк, которые не удалось разобрать как запись
+func (r *Reader) Skipped() int {
+	return r.skipped
+}
+
+// Close закрывает открытый файл
+func (r *Reader) Close() error {
+	r.sources = nil
+	if r.current == nil {
+		return nil
+	}
+	err := r.current.Close()
+	r.current, r.scanner = nil, nil
+	return err
+}
+
+// ParseLine разбирает строку лога в формате JSON или logfmt. Запись должна
+// содержать уровень
+func ParseLine(line []byte) (Entry, error) {
+	line = bytes.TrimSpace(line)
+	if len(line) > 0 && line[0] == '{' {
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.UseNumber()
+		var fields map[string]interface{}
+		if err := dec.Decode(&fields); err != nil {
+			return Entry{}, err
+		}
+		return newEntry(fields)
+	}
+	fields, err := parseLogfmt(line)
+	if err != nil {
+		return Entry{}, err
+	}
+	return newEntry(fields)
+}
+
+// newEntry выделяет из полей время, уровень и сообщение
+func newEntry(fields map[string]interface{}) (Entry, error) {
+	var e Entry
+	level, ok := takeString(fields, levelKeys)
+	if !ok {
+		return Entry{}, errors.New("entry has no level")
+	}
+	parsed, err := logger.ParseLevel(level)
+	if err != nil {
+		return Entry{}, err
+	}
+	e.Level = parsed
+	if ts, ok := takeString(fields, timeKeys); ok {
+		if e.Time, err = time.Parse(time.RFC3339Nano, ts); err != nil {
+			return Entry{}, fmt.Errorf("invalid entry time: %w", err)
+		}
+	}
+	e.Message, _ = takeString(fields, messageKeys)
+	e.Fields = fields
+	return e, nil
+}
+
+// takeString удаляет из полей первое найденное строковое поле из keys
+func takeString(fields map[string]interface{}, keys []string) (string, bool) {
+	for _, key := range keys {
+		if s, ok := fields[key].(string); ok {
+			delete(fields, key)
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// parseLogfmt разбирает пары key=value текстового формата logrus.
+// Значения в кавычках раскрываются как строки Go
+func parseLogfmt(line []byte) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	s := string(line)
+	for s != "" {
+		eq := 0
+		for eq < len(s) && s[eq] != '=' && s[eq] != ' ' {
+			eq++
+		}
+		if eq == 0 || eq == len(s) || s[eq] != '=' {
+			return nil, fmt.Errorf("logfmt: expected key=value at %q", s)
+		}
+		key := s[:eq]
+		s = s[eq+1:]
+
+		var value string
+		if s != "" && s[0] == '"' {
+			end := 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return nil, fmt.Errorf("logfmt: unterminated value of %s", key)
+			}
+			unquoted, err := strconv.Unquote(s[:end+1])
+			if err != nil {
+				return nil, fmt.Errorf("logfmt: value of %s: %w", key, err)
+			}
+			value, s = unquoted, s[end+1:]
+		} else {
+			end := 0
+			for end < len(s) && s[end] != ' ' {
+				end++
+			}
+			value, s = s[:end], s[end:]
+		}
+		fields[key] = value
+
+		for s != "" && s[0] == ' ' {
+			s = s[1:]
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,160 @@
+package logread
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ex-rate/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeLog пишет записи логгером в формате format и возвращает вывод
+func writeLog(t *testing.T, format string, write func(l *logger.Logger)) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	l, err := logger.New(logger.Config{Level: logger.DebugLevel, Format: format, Writers: []io.Writer{&buf}})
+	require.NoError(t, err)
+	write(l)
+	require.NoError(t, l.Close())
+	return buf.Bytes()
+}
+
+// readAll читает все записи
+func readAll(t *testing.T, r *Reader) []Entry {
+	t.Helper()
+	defer r.Close()
+	var entries []Entry
+	for r.Next() {
+		entries = append(entries, r.Entry())
+	}
+	require.NoError(t, r.Err())
+	return entries
+}
+
+func TestOpenRotated(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	// Старая копия сжата и записана в текстовом формате, новая - в JSON
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	_, err := zw.Write(writeLog(t, logger.TextFormat, func(l *logger.Logger) {
+		l.WithField("note", `quoted "value" here`).Info("first")
+	}))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app-2024-01-15T10-00-00.000.log.gz"), gz.Bytes(), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app-2024-01-15T11-00-00.000.log"), writeLog(t, logger.JSONFormat, func(l *logger.Logger) {
+		l.WithField("order_id", 42).Warn("second")
+	}), 0o644))
+	current := writeLog(t, logger.JSONFormat, func(l *logger.Logger) {
+		l.Debug("third")
+	})
+	require.NoError(t, os.WriteFile(path, append([]byte("not a log line\n\n"), current...), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.log"), current, 0o644))
+
+	r, err := OpenRotated(path, Filter{})
+	require.NoError(t, err)
+	entries := readAll(t, r)
+	require.Len(t, entries, 3)
+	assert.Equal(t, 1, r.Skipped())
+
+	assert.Equal(t, "first", entries[0].Message)
+	assert.Equal(t, logger.InfoLevel, entries[0].Level)
+	assert.Equal(t, `quoted "value" here`, entries[0].Fields["note"])
+	assert.WithinDuration(t, time.Now(), entries[0].Time, time.Minute)
+	assert.Equal(t, filepath.Join(dir, "app-2024-01-15T10-00-00.000.log.gz"), entries[0].Path)
+
+	assert.Equal(t, "second", entries[1].Message)
+	assert.Equal(t, logger.WarnLevel, entries[1].Level)
+	assert.Equal(t, json.Number("42"), entries[1].Fields["order_id"])
+	assert.NotContains(t, entries[1].Fields, "msg")
+
+	assert.Equal(t, "third", entries[2].Message)
+	assert.Equal(t, path, entries[2].Path)
+	assert.Equal(t, 3, entries[2].Line)
+}
+
+func TestReader_Filter(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	lines := strings.Join([]string{
+		`{"time":"2024-01-15T10:00:00Z","level":"info","msg":"a","service":"billing"}`,
+		`{"time":"2024-01-15T10:05:00Z","level":"error","msg":"b","service":"billing","code":503}`,
+		`time="2024-01-15T10:10:00Z" level=warning msg=c service=orders code=503`,
+		`{"time":"2024-01-15T10:15:00Z","level":"error","msg":"d","service":"billing","code":500}`,
+	}, "\n")
+
+	cases := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"all", Filter{}, []string{"a", "b", "c", "d"}},
+		{"range", Filter{Since: base.Add(5 * time.Minute), Until: base.Add(15 * time.Minute)}, []string{"b", "c"}},
+		{"level", Filter{Level: "warn"}, []string{"b", "c", "d"}},
+		{"fields", Filter{Fields: map[string]string{"code": "503"}}, []string{"b", "c"}},
+		{"combined", Filter{Level: "error", Fields: map[string]string{"service": "billing"}, Since: base.Add(time.Minute)}, []string{"b", "d"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader(strings.NewReader(lines), tc.filter)
+			require.NoError(t, err)
+			var got []string
+			for _, e := range readAll(t, r) {
+				got = append(got, e.Message)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	_, err := NewReader(strings.NewReader(lines), Filter{Level: "loud"})
+	assert.ErrorContains(t, err, "unknown log level")
+}
+
+func TestOpen_SkipsOldFiles(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "old.log")
+	require.NoError(t, os.WriteFile(old, []byte(`{"time":"2030-01-01T00:00:00Z","level":"info","msg":"future"}`+"\n"), 0o644))
+	stamp := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(old, stamp, stamp))
+
+	r, err := Open([]string{old, filepath.Join(dir, "missing.log")}, Filter{Since: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+	assert.False(t, r.Next())
+	assert.ErrorIs(t, r.Err(), os.ErrNotExist)
+	require.NoError(t, r.Close())
+}
+
+func TestParseLine(t *testing.T) {
+	e, err := ParseLine([]byte(`{"timestamp":"2024-01-15T10:00:00.123+03:00","level":"error","message":"from lambda","aws_request_id":"r-1"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "from lambda", e.Message)
+	assert.Equal(t, logger.ErrorLevel, e.Level)
+	assert.Equal(t, time.Date(2024, 1, 15, 7, 0, 0, 123e6, time.UTC), e.Time.UTC())
+	assert.Equal(t, map[string]interface{}{"aws_request_id": "r-1"}, e.Fields)
+
+	e, err = ParseLine([]byte(`level=debug msg="line\nbreak" empty= path=/tmp/a`))
+	require.NoError(t, err)
+	assert.Equal(t, "line\nbreak", e.Message)
+	assert.True(t, e.Time.IsZero())
+	assert.Equal(t, map[string]interface{}{"empty": "", "path": "/tmp/a"}, e.Fields)
+
+	for _, line := range []string{
+		`{"msg":"no level"}`,
+		`{"level":"info","time":"yesterday"}`,
+		`{"level":"loud"}`,
+		`level=info msg="unterminated`,
+		`plain text`,
+	} {
+		_, err := ParseLine([]byte(line))
+		assert.Error(t, err, line)
+	}
+}
@@ -0,0 +1,399 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// PluginProtocolVersion версия протокола назначений-плагинов
+	PluginProtocolVersion = 1
+
+	defaultPluginStartTimeout = 5 * time.Second
+	defaultPluginSyncTimeout  = 5 * time.Second
+
+	// maxPluginFrame предел размера сообщения протокола
+	maxPluginFrame = 16 << 20
+	// pluginRestartDelay пауза перед перезапуском упавшего плагина
+	pluginRestartDelay = time.Second
+)
+
+// errPluginDown плагин завершился и еще не перезапущен
+var errPluginDown = errors.New("plugin is not running")
+
+// PluginConfig назначение вывода во внешнем процессе. Логгер запускает
+// Command и обменивается с ним сообщениями протокола плагинов через stdin
+// и stdout; stderr плагина выводится в stderr процесса. Плагин на Go
+// пишется с пакетом github.com/ex-rate/logger/sinkplugin
+type PluginConfig struct {
+	Name    string            `yaml:"name"` // имя в ошибках и задержках назначений, по умолчанию имя команды
+	Command string            `yaml:"command"`
+	Args    []string          `yaml:"args"`
+	Env     map[string]string `yaml:"env"` // переменные окружения в дополнение к окружению процесса
+	// Options произвольные настройки, которые плагин получает при запуске
+	Options map[string]interface{} `yaml:"options"`
+
+	Level  string `yaml:"level"`  // минимальный уровень записей, по умолчанию все записи
+	Filter string `yaml:"filter"` // выражение над записью, по умолчанию все записи
+
+	StartTimeout time.Duration `yaml:"start_timeout"` // ожидание готовности плагина, по умолчанию 5s
+	SyncTimeout  time.Duration `yaml:"sync_timeout"`  // ожидание ответа на Sync, по умолчанию 5s
+}
+
+// name возвращает имя назначения
+func (c PluginConfig) name() string {
+	return "plugin:" + firstNonEmpty(c.Name, filepath.Base(c.Command))
+}
+
+// validate проверяет настройки плагина
+func (c PluginConfig) validate() error {
+	var errs []error
+	if c.Command == "" {
+		errs = append(errs, errors.New("plugin command is required"))
+	}
+	if c.Level != "" {
+		if _, err := ParseLevel(c.Level); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if _, err := compileFilter(c.Filter); err != nil {
+		errs = append(errs, err)
+	}
+	if c.StartTimeout < 0 || c.SyncTimeout < 0 {
+		errs = append(errs, errors.New("plugin timeouts must not be negative"))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("%s: %w", c.name(), err)
+	}
+	return nil
+}
+
+// pluginMessage сообщение протокола плагинов
+type pluginMessage struct {
+	Type string `json:"type"` // hello, entry, sync от логгера; ready, ack, error от плагина
+
+	Protocol int                    `json:"protocol,omitempty"` // hello
+	Name     string                 `json:"name,omitempty"`     // hello
+	Options  map[string]interface{} `json:"options,omitempty"`  // hello
+	Entry    json.RawMessage        `json:"entry,omitempty"`    // entry: запись в JSON
+	Error    string                 `json:"error,omitempty"`    // ready, ack, error
+}
+
+// writePluginFrame пишет сообщение: длина в 4 байтах big-endian и JSON
+func writePluginFrame(w io.Writer, msg pluginMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	frame := make([]byte, 4, 4+len(data))
+	binary.BigEndian.PutUint32(frame, uint32(len(data)))
+	_, err = w.Write(append(frame, data...))
+	return err
+}
+
+// readPluginFrame читает сообщение
+func readPluginFrame(r io.Reader) (pluginMessage, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return pluginMessage{}, err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > maxPluginFrame {
+		return pluginMessage{}, fmt.Errorf("plugin message too large: %d bytes", n)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return pluginMessage{}, err
+	}
+	var msg pluginMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return pluginMessage{}, fmt.Errorf("invalid plugin message: %w", err)
+	}
+	return msg, nil
+}
+
+// openPluginSink запускает плагин и создает назначение, которое пишет в
+// него записи в JSON
+func openPluginSink(config PluginConfig) (*pluginConn, *sink, error) {
+	if err := config.validate(); err != nil {
+		return nil, nil, err
+	}
+	formatter, err := newFormatter(JSONFormat, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	accept, _ := minLevel(config.Level)
+	filter, _ := compileFilter(config.Filter)
+	conn := newPluginConn(config)
+	if err := conn.start(); err != nil {
+		return nil, nil, err
+	}
+	return conn, &sink{name: config.name(), writer: conn, formatter: formatter, accept: accept, filter: filter}, nil
+}
+
+// pluginProcess запущенный процесс плагина
+type pluginProcess struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	acks   chan pluginMessage // ready и ack
+	exited chan struct{}      // закрывается, когда stdout плагина закрыт
+}
+
+// pluginConn назначение во внешнем процессе. Упавший плагин
+// перезапускается при следующей записи, но не чаще раза в секунду.
+// Асинхронные ошибки плагина возвращаются следующим вызовом Write
+type pluginConn struct {
+	config PluginConfig
+
+	mu        sync.Mutex
+	proc      *pluginProcess
+	restartAt time.Time
+	closed    bool
+
+	// Ошибки пишет и горутина чтения, пока Sync держит mu
+	errMu sync.Mutex
+	err   error
+}
+
+// newPluginConn заполняет значения по умолчанию
+func newPluginConn(c PluginConfig) *pluginConn {
+	if c.StartTimeout == 0 {
+		c.StartTimeout = defaultPluginStartTimeout
+	}
+	if c.SyncTimeout == 0 {
+		c.SyncTimeout = defaultPluginSyncTimeout
+	}
+	return &pluginConn{config: c}
+}
+
+// start запускает плагин
+func (c *pluginConn) start() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.startLocked()
+}
+
+// startLocked запускает процесс плагина и ждет ответа на hello
+func (c *pluginConn) startLocked() error {
+	cmd := exec.Command(c.config.Command, c.config.Args...)
+	cmd.Env = os.Environ()
+	for k, v := range c.config.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("%s: %w", c.config.name(), err)
+	}
+
+	proc := &pluginProcess{cmd: cmd, stdin: stdin, acks: make(chan pluginMessage, 1), exited: make(chan struct{})}
+	go c.read(proc, bufio.NewReader(stdout))
+
+	fail := func(err error) error {
+		c.stop(proc, 0)
+		c.restartAt = time.Now().Add(pluginRestartDelay)
+		return fmt.Errorf("%s: %w", c.config.name(), err)
+	}
+	hello := pluginMessage{Type: "hello", Protocol: PluginProtocolVersion, Name: c.config.Name, Options: c.config.Options}
+	if err := writePluginFrame(stdin, hello); err != nil {
+		return fail(err)
+	}
+	timer := time.NewTimer(c.config.StartTimeout)
+	defer timer.Stop()
+	select {
+	case msg := <-proc.acks:
+		if msg.Type != "ready" {
+			return fail(fmt.Errorf("unexpected %q message on start", msg.Type))
+		}
+		if msg.Error != "" {
+			return fail(errors.New(msg.Error))
+		}
+	case <-proc.exited:
+		// Плагин мог ответить ошибкой и сразу завершиться
+		select {
+		case msg := <-proc.acks:
+			if msg.Error != "" {
+				return fail(errors.New(msg.Error))
+			}
+		default:
+		}
+		return fail(errors.New("plugin exited on start"))
+	case <-timer.C:
+		return fail(errors.New("plugin did not start in time"))
+	}
+	c.proc = proc
+	return nil
+}
+
+// read разбирает сообщения плагина до закрытия его stdout
+func (c *pluginConn) read(proc *pluginProcess, r io.Reader) {
+	defer close(proc.exited)
+	for {
+		msg, err := readPluginFrame(r)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, os.ErrClosed) {
+				c.setErr(fmt.Errorf("%s: %w", c.config.name(), err))
+			}
+			return
+		}
+		switch msg.Type {
+		case "error":
+			c.setErr(fmt.Errorf("%s: %s", c.config.name(), msg.Error))
+		default:
+			select {
+			case proc.acks <- msg:
+			default:
+				// Ответ, которого никто не ждет, например после таймаута Sync
+			}
+		}
+	}
+}
+
+// setErr запоминает асинхронную ошибку плагина
+func (c *pluginConn) setErr(err error) {
+	c.errMu.Lock()
+	c.err = errors.Join(c.err, err)
+	c.errMu.Unlock()
+}
+
+// running возвращает запущенный процесс, перезапуская упавший плагин
+func (c *pluginConn) running() (*pluginProcess, error) {
+	if c.closed {
+		return nil, errors.New("plugin output is closed")
+	}
+	if c.proc != nil {
+		select {
+		case <-c.proc.exited:
+			c.downLocked()
+			return nil, fmt.Errorf("%s: %w", c.config.name(), errPluginDown)
+		default:
+			return c.proc, nil
+		}
+	}
+	if time.Now().Before(c.restartAt) {
+		return nil, fmt.Errorf("%s: %w", c.config.name(), errPluginDown)
+	}
+	if err := c.startLocked(); err != nil {
+		return nil, err
+	}
+	return c.proc, nil
+}
+
+// Write отправляет запись в JSON плагину
+func (c *pluginConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	proc, err := c.running()
+	if err != nil {
+		return 0, errors.Join(c.takeErr(), err)
+	}
+	entry := p
+	if n := len(entry); n > 0 && entry[n-1] == '\n' {
+		entry = entry[:n-1]
+	}
+	if err := writePluginFrame(proc.stdin, pluginMessage{Type: "entry", Entry: entry}); err != nil {
+		// Запись в stdin падает раньше, чем горутина чтения замечает
+		// завершение плагина
+		c.downLocked()
+		return 0, errors.Join(c.takeErr(), fmt.Errorf("%s: %w", c.config.name(), err))
+	}
+	return len(p), c.takeErr()
+}
+
+// downLocked останавливает упавший плагин и откладывает перезапуск
+func (c *pluginConn) downLocked() {
+	c.stop(c.proc, 0)
+	c.proc = nil
+	c.restartAt = time.Now().Add(pluginRestartDelay)
+}
+
+// takeErr возвращает накопленную ошибку плагина и сбрасывает ее
+func (c *pluginConn) takeErr() error {
+	c.errMu.Lock()
+	defer c.errMu.Unlock()
+	err := c.err
+	c.err = nil
+	return err
+}
+
+// Sync просит плагин сбросить записи и ждет ответа
+func (c *pluginConn) Sync() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || c.proc == nil {
+		return c.takeErr()
+	}
+	proc := c.proc
+	if err := writePluginFrame(proc.stdin, pluginMessage{Type: "sync"}); err != nil {
+		return errors.Join(c.takeErr(), fmt.Errorf("%s: %w", c.config.name(), err))
+	}
+	timer := time.NewTimer(c.config.SyncTimeout)
+	defer timer.Stop()
+	var err error
+	select {
+	case msg := <-proc.acks:
+		if msg.Error != "" {
+			err = fmt.Errorf("%s: %s", c.config.name(), msg.Error)
+		}
+	case <-proc.exited:
+		err = fmt.Errorf("%s: %w", c.config.name(), errPluginDown)
+	case <-timer.C:
+		err = fmt.Errorf("%s: sync timed out", c.config.name())
+	}
+	return errors.Join(c.takeErr(), err)
+}
+
+// Reopen ничего не делает: файлы плагина - его забота
+func (c *pluginConn) Reopen() error {
+	return nil
+}
+
+// Close закрывает stdin плагина и ждет его завершения. Плагин, не
+// завершившийся за SyncTimeout, останавливается принудительно
+func (c *pluginConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	var err error
+	if c.proc != nil {
+		err = c.stop(c.proc, c.config.SyncTimeout)
+		c.proc = nil
+	}
+	return errors.Join(c.takeErr(), err)
+}
+
+// stop закрывает stdin процесса, ждет его завершения не дольше wait и
+// затем завершает его принудительно
+func (c *pluginConn) stop(proc *pluginProcess, wait time.Duration) error {
+	proc.stdin.Close()
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-proc.exited:
+	case <-timer.C:
+		_ = proc.cmd.Process.Kill()
+	}
+	if err := proc.cmd.Wait(); err != nil {
+		return fmt.Errorf("%s: %w", c.config.name(), err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHelperPlugin не тест: так запускается плагин из тестового бинарника.
+// LOGGER_PLUGIN_HELPER задает поведение: echo отвечает на sync числом
+// полученных записей и падает на записи crash, fail не запускается,
+// silent не отвечает на hello
+func TestHelperPlugin(t *testing.T) {
+	mode := os.Getenv("LOGGER_PLUGIN_HELPER")
+	if mode == "" {
+		t.Skip("helper process")
+	}
+	in := bufio.NewReader(os.Stdin)
+	if _, err := readPluginFrame(in); err != nil {
+		os.Exit(2)
+	}
+	switch mode {
+	case "fail":
+		_ = writePluginFrame(os.Stdout, pluginMessage{Type: "ready", Error: "backend unavailable"})
+		os.Exit(1)
+	case "silent":
+		time.Sleep(time.Minute)
+		os.Exit(0)
+	}
+	_ = writePluginFrame(os.Stdout, pluginMessage{Type: "ready"})
+	entries := 0
+	for {
+		msg, err := readPluginFrame(in)
+		if err != nil {
+			os.Exit(0)
+		}
+		switch msg.Type {
+		case "entry":
+			if bytes.Contains(msg.Entry, []byte("crash")) {
+				os.Exit(3)
+			}
+			entries++
+		case "sync":
+			ack := pluginMessage{Type: "ack"}
+			if entries != 2 {
+				ack.Error = "unexpected entries"
+			}
+			_ = writePluginFrame(os.Stdout, ack)
+		}
+	}
+}
+
+// helperPlugin настройки запуска TestHelperPlugin в режиме mode
+func helperPlugin(mode string) PluginConfig {
+	return PluginConfig{
+		Name:         mode,
+		Command:      os.Args[0],
+		Args:         []string{"-test.run=^TestHelperPlugin$"},
+		Env:          map[string]string{"LOGGER_PLUGIN_HELPER": mode},
+		StartTimeout: time.Second,
+	}
+}
+
+func TestPluginConn(t *testing.T) {
+	conn := newPluginConn(helperPlugin("echo"))
+	require.NoError(t, conn.start())
+
+	for _, entry := range []string{`{"msg":"a"}`, `{"msg":"b"}` + "\n"} {
+		_, err := conn.Write([]byte(entry))
+		require.NoError(t, err)
+	}
+	require.NoError(t, conn.Sync())
+
+	// Упавший плагин перезапускается не сразу
+	_, err := conn.Write([]byte(`{"msg":"crash"}`))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		_, err := conn.Write([]byte(`{"msg":"c"}`))
+		return err != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	_, err = conn.Write([]byte(`{"msg":"c"}`))
+	assert.ErrorIs(t, err, errPluginDown)
+
+	conn.mu.Lock()
+	conn.restartAt = time.Time{}
+	conn.mu.Unlock()
+	_, err = conn.Write([]byte(`{"msg":"d"}`))
+	require.NoError(t, err)
+	assert.ErrorContains(t, conn.Sync(), "plugin:echo: unexpected entries")
+
+	require.NoError(t, conn.Close())
+	_, err = conn.Write([]byte(`{"msg":"e"}`))
+	assert.ErrorContains(t, err, "plugin output is closed")
+}
+
+func TestPluginConn_StartErrors(t *testing.T) {
+	err := newPluginConn(helperPlugin("fail")).start()
+	assert.ErrorContains(t, err, "plugin:fail: backend unavailable")
+
+	err = newPluginConn(helperPlugin("silent")).start()
+	assert.ErrorContains(t, err, "plugin:silent: plugin did not start in time")
+
+	err = newPluginConn(PluginConfig{Command: "/nonexistent/plugin"}).start()
+	assert.ErrorContains(t, err, "plugin:plugin:")
+}
+
+func TestLogger_Plugins(t *testing.T) {
+	config := helperPlugin("echo")
+	config.Level = "warn"
+	log, err := New(Config{Level: DebugLevel, Plugins: []PluginConfig{config}})
+	require.NoError(t, err)
+
+	log.Info("below plugin level")
+	log.Warn("first")
+	log.Error("second")
+	require.NoError(t, log.Sync())
+	require.NoError(t, log.Close())
+}
+
+func TestPluginConfig_Validate(t *testing.T) {
+	err := Config{Level: InfoLevel, Plugins: []PluginConfig{{Name: "vault", Level: "loud", StartTimeout: -1}}}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "plugin:vault: plugin command is required")
+	assert.Contains(t, err.Error(), "unknown log level")
+	assert.Contains(t, err.Error(), "plugin timeouts must not be negative")
+}
@@ -0,0 +1,105 @@
+package logger
+
+import "github.com/sirupsen/logrus"
+
+// poolLevels уровни пулов: заданные при регистрации через ForPool и
+// переопределенные конфигурацией или во время работы
+type poolLevels struct {
+	registered map[string]Level
+	overrides  map[string]Level
+}
+
+// ForPool возвращает логгер именованного компонента, например пула
+// воркеров. Уровень компонента не зависит от уровней сервисов: по умолчанию
+// это level, его можно переопределить в PoolLevels, через SetPoolLevel или
+// PUT /pools/{name}/level в AdminHandler. Записи получают поле pool
+func (l *Logger) ForPool(name string, level Level) *Logger {
+	if l == nil {
+		return nilReceiver()
+	}
+	l.core.updatePoolLevels(func(p *poolLevels) {
+		p.registered[name] = level
+	})
+	child := l.with(logrus.Fields{"pool": name})
+	child.pool = name
+	return child
+}
+
+// SetPoolLevel переопределяет уровень компонента
+func (l *Logger) SetPoolLevel(name string, level Level) {
+	if l == nil {
+		nilReceiver()
+		return
+	}
+	l.core.updatePoolLevels(func(p *poolLevels) {
+		p.overrides[name] = level
+	})
+}
+
+// ResetPoolLevel возвращает компоненту уровень, заданный в ForPool
+func (l *Logger) ResetPoolLevel(name string) {
+	if l == nil {
+		nilReceiver()
+		return
+	}
+	l.core.updatePoolLevels(func(p *poolLevels) {
+		delete(p.overrides, name)
+	})
+}
+
+// PoolLevels возвращает действующие уровни компонентов
+func (l *Logger) PoolLevels() map[string]Level {
+	l = l.orNop()
+	levels := make(map[string]Level)
+	if p := l.core.pools.Load(); p != nil {
+		for k, v := range p.registered {
+			levels[k] = v
+		}
+		for k, v := range p.overrides {
+			levels[k] = v
+		}
+	}
+	return levels
+}
+
+// setPoolLevels задает уровни компонентов из конфигурации. Уровни,
+// зарегистрированные через ForPool, сохраняются
+func (c *core) setPoolLevels(levels map[string]Level) {
+	c.updatePoolLevels(func(p *poolLevels) {
+		p.overrides = make(map[string]Level, len(levels))
+		for name, level := range levels {
+			p.overrides[name] = level
+		}
+	})
+}
+
+// updatePoolLevels меняет копию уровней компонентов и атомарно подменяет ее
+func (c *core) updatePoolLevels(update func(*poolLevels)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	next := &poolLevels{registered: make(map[string]Level), overrides: make(map[string]Level)}
+	if current := c.pools.Load(); current != nil {
+		for k, v := range current.registered {
+			next.registered[k] = v
+		}
+		for k, v := range current.overrides {
+			next.overrides[k] = v
+		}
+	}
+	update(next)
+	c.pools.Store(next)
+}
+
+// poolLevel возвращает уровень компонента
+func (c *core) poolLevel(name string) (Level, bool) {
+	p := c.pools.Load()
+	if p == nil || name == "" {
+		return 0, false
+	}
+	if level, ok := p.overrides[name]; ok {
+		return level, true
+	}
+	level, ok := p.registered[name]
+	return level, ok
+}
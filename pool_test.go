@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_ForPool(t *testing.T) {
+	log, buf := newBufferLogger(t)
+	log.SetLevel(InfoLevel)
+	log.SetServiceLevel("ingest", DebugLevel)
+
+	svc := log.WithService("ingest")
+	workers := svc.ForPool("ingest-workers", WarnLevel)
+
+	workers.Info("batch processed")
+	workers.Warn("batch retried")
+	svc.Debug("service debug")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "batch retried", entries[0]["msg"])
+	assert.Equal(t, "ingest-workers", entries[0]["pool"])
+	assert.Equal(t, "ingest", entries[0]["service"])
+	assert.Equal(t, "service debug", entries[1]["msg"])
+
+	// Уровень меняется во время работы для всех логгеров компонента
+	buf.Reset()
+	log.SetPoolLevel("ingest-workers", DebugLevel)
+	workers.Debug("item parsed")
+	assert.Equal(t, map[string]Level{"ingest-workers": DebugLevel}, log.PoolLevels())
+
+	log.ResetPoolLevel("ingest-workers")
+	workers.Info("batch processed")
+	entries = decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "item parsed", entries[0]["msg"])
+	assert.Equal(t, map[string]Level{"ingest-workers": WarnLevel}, log.PoolLevels())
+}
+
+func TestLogger_ForPool_Config(t *testing.T) {
+	buf := &lockedBuffer{}
+	log, err := New(Config{
+		Level:      InfoLevel,
+		Writers:    []io.Writer{buf},
+		PoolLevels: map[string]Level{"ingest-workers": ErrorLevel},
+	})
+	require.NoError(t, err)
+	defer log.Close()
+
+	workers := log.ForPool("ingest-workers", DebugLevel)
+	workers.Warn("batch retried")
+	assert.Empty(t, buf.String())
+	assert.Equal(t, ErrorLevel, log.PoolLevels()["ingest-workers"])
+
+	// Перезагрузка конфигурации заменяет переопределения, но не уровни из кода
+	require.NoError(t, log.ApplyConfig(Config{Level: InfoLevel, Writers: []io.Writer{buf}}))
+	workers.Debug("item parsed")
+	assert.Contains(t, buf.String(), "item parsed")
+
+	err = Config{Output: ConsoleOutput, PoolLevels: map[string]Level{"": InfoLevel}}.Validate()
+	assert.ErrorContains(t, err, "invalid pool level")
+}
+
+func TestLogger_AdminHandler_Pools(t *testing.T) {
+	log, _ := newBufferLogger(t)
+	log.ForPool("ingest-workers", WarnLevel)
+	handler := log.AdminHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/pools/ingest-workers/level", strings.NewReader(`{"level":"debug"}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"ingest-workers":"debug"}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/pools/ingest-workers/level", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/pools/levels", nil))
+	var levels map[string]Level
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &levels))
+	assert.Equal(t, map[string]Level{"ingest-workers": WarnLevel}, levels)
+}
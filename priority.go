@@ -0,0 +1,55 @@
+package logger
+
+import "github.com/sirupsen/logrus"
+
+// PriorityField поле записи с классом приоритета
+const PriorityField = "priority"
+
+// Priority класс приоритета записи. В отличие от уровня он определяет, можно
+// ли потерять запись: семплирование и отложенная пакетная запись учитывают
+// приоритет, а порог уровня логгера - нет
+type Priority string
+
+const (
+	PriorityCritical   Priority = "critical"    // не семплируется, пишется в файл без задержки
+	PriorityNormal     Priority = "normal"      // семплируются записи Info и ниже, по умолчанию
+	PriorityBestEffort Priority = "best_effort" // семплируется на любом уровне
+)
+
+// WithPriority создает дочерний логгер, записи которого помечены классом
+// приоритета
+func (l *Logger) WithPriority(priority Priority) *Logger {
+	return l.with(map[string]interface{}{PriorityField: string(priority)})
+}
+
+// entryPriority возвращает класс приоритета записи, по умолчанию normal
+func entryPriority(data map[string]interface{}) Priority {
+	switch v := data[PriorityField].(type) {
+	case string:
+		if v != "" {
+			return Priority(v)
+		}
+	case Priority:
+		if v != "" {
+			return v
+		}
+	}
+	return PriorityNormal
+}
+
+// sampledEntry решает, сохранять ли запись, с учетом ее приоритета
+func (c *core) sampledEntry(entry *logrus.Entry) bool {
+	switch entryPriority(entry.Data) {
+	case PriorityCritical:
+		return true
+	case PriorityBestEffort:
+		return c.sample()
+	}
+	return c.sampled(Level(entry.Level))
+}
+
+// urgent сообщает, что запись нужно сбросить из пачки сразу: записи об
+// ошибках и критичные записи не должны теряться при падении процесса
+func urgent(entry *logrus.Entry) bool {
+	return Level(entry.Level) <= ErrorLevel || entryPriority(entry.Data) == PriorityCritical
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_PrioritySampling(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	logger.SetSamplingRate(0)
+
+	logger.WithPriority(PriorityCritical).Info("payment captured")
+	logger.Info("cache warmed")
+	logger.WithPriority(PriorityBestEffort).Error("verbose failure")
+	logger.Error("failure")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "payment captured", entries[0]["msg"])
+	assert.Equal(t, "critical", entries[0][PriorityField])
+	assert.Equal(t, "failure", entries[1]["msg"])
+}
+
+func TestLogger_PriorityFlushesBatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "batch.log")
+	logger, err := New(Config{
+		Level:             InfoLevel,
+		Output:            FileOutput,
+		FilePath:          path,
+		FileBatchEntries:  10,
+		FileBatchInterval: time.Hour,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { logger.Close() })
+
+	logger.Info("queued")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Empty(t, data)
+
+	logger.WithPriority(PriorityCritical).Info("order placed")
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "queued")
+	assert.Contains(t, string(data), "order placed")
+}
+
+func TestEntryPriority(t *testing.T) {
+	assert.Equal(t, PriorityNormal, entryPriority(nil))
+	assert.Equal(t, PriorityCritical, entryPriority(map[string]interface{}{PriorityField: "critical"}))
+	assert.Equal(t, PriorityBestEffort, entryPriority(map[string]interface{}{PriorityField: PriorityBestEffort}))
+}
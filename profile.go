@@ -0,0 +1,166 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ProfileEnv переменная окружения с именем профиля конфигурации
+	ProfileEnv = "LOG_PROFILE"
+	// DefaultProfile профиль с общими настройками, поверх которого
+	// накладывается выбранный
+	DefaultProfile = "default"
+)
+
+// selectProfile возвращает из файла с профилями YAML выбранного профиля,
+// глубоко слитого с профилем default: вложенные объекты сливаются по
+// ключам, остальные значения, в том числе списки, заменяются значением
+// профиля. Файл без ключа profiles возвращается как есть
+func selectProfile(data []byte, profile string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return data, nil
+	}
+	root := doc.Content[0]
+	profiles := mappingValue(root, "profiles")
+	if profiles == nil {
+		return data, nil
+	}
+	if len(root.Content) > 2 {
+		return nil, fmt.Errorf("config with profiles must not have other top-level keys, move them to the %s profile", DefaultProfile)
+	}
+	profiles = resolveAliases(profiles)
+	if profiles.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: profiles must be a mapping", profiles.Line)
+	}
+
+	merged := emptyAsMapping(mappingValue(profiles, DefaultProfile))
+	if profile != "" && profile != DefaultProfile {
+		selected := mappingValue(profiles, profile)
+		if selected == nil {
+			return nil, fmt.Errorf("unknown config profile %q (available: %s)", profile, strings.Join(profileNames(profiles), ", "))
+		}
+		merged = mergeNodes(merged, emptyAsMapping(selected))
+	}
+	if merged == nil {
+		return nil, fmt.Errorf("no config profile selected: set %s (available: %s)", ProfileEnv, strings.Join(profileNames(profiles), ", "))
+	}
+	if merged.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: profile must be a mapping", merged.Line)
+	}
+	return yaml.Marshal(merged)
+}
+
+// mappingValue возвращает значение ключа объекта YAML
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// emptyAsMapping заменяет пустой профиль (dev: без значения) пустым объектом
+func emptyAsMapping(node *yaml.Node) *yaml.Node {
+	if node != nil && node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	return node
+}
+
+// profileNames возвращает имена профилей по алфавиту
+func profileNames(profiles *yaml.Node) []string {
+	var names []string
+	for i := 0; i+1 < len(profiles.Content); i += 2 {
+		names = append(names, profiles.Content[i].Value)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// mergeNodes возвращает base, поверх которого наложен override. Объекты
+// сливаются по ключам, остальные значения заменяются
+func mergeNodes(base, override *yaml.Node) *yaml.Node {
+	if base == nil || base.Kind != yaml.MappingNode || override.Kind != yaml.MappingNode {
+		return override
+	}
+	merged := *base
+	merged.Content = append([]*yaml.Node(nil), base.Content...)
+	for i := 0; i+1 < len(override.Content); i += 2 {
+		key, value := override.Content[i], override.Content[i+1]
+		replaced := false
+		for j := 0; j+1 < len(merged.Content); j += 2 {
+			if merged.Content[j].Value == key.Value {
+				merged.Content[j+1] = mergeNodes(merged.Content[j+1], value)
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged.Content = append(merged.Content, key, value)
+		}
+	}
+	return &merged
+}
+
+// resolveAliases возвращает копию узла, в которой ссылки *name заменены
+// копиями узлов &name, а ключи слияния << раскрыты: после слияния
+// профилей якорь может оказаться в другом профиле, чем ссылка на него, а
+// значения default не должны перекрывать значения из <<
+func resolveAliases(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.AliasNode {
+		return resolveAliases(node.Alias)
+	}
+	resolved := *node
+	resolved.Anchor = ""
+	resolved.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		resolved.Content[i] = resolveAliases(child)
+	}
+	if resolved.Kind == yaml.MappingNode {
+		resolved.Content = expandMergeKeys(resolved.Content)
+	}
+	return &resolved
+}
+
+// expandMergeKeys заменяет ключи << объекта ключами объектов, на которые
+// они ссылаются. Явные ключи объекта важнее раскрытых
+func expandMergeKeys(content []*yaml.Node) []*yaml.Node {
+	var explicit, merged []*yaml.Node
+	for i := 0; i+1 < len(content); i += 2 {
+		key, value := content[i], content[i+1]
+		if key.Tag != "!!merge" {
+			explicit = append(explicit, key, value)
+			continue
+		}
+		sources := []*yaml.Node{value}
+		if value.Kind == yaml.SequenceNode {
+			sources = value.Content
+		}
+		for _, source := range sources {
+			merged = append(merged, source.Content...)
+		}
+	}
+	if merged == nil {
+		return content
+	}
+	seen := make(map[string]bool)
+	for i := 0; i < len(explicit); i += 2 {
+		seen[explicit[i].Value] = true
+	}
+	for i := 0; i+1 < len(merged); i += 2 {
+		if !seen[merged[i].Value] {
+			seen[merged[i].Value] = true
+			explicit = append(explicit, merged[i], merged[i+1])
+		}
+	}
+	return explicit
+}
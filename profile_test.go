@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const profilesConfig = `
+profiles:
+  default:
+    level: info
+    output: console
+    format: json
+    service_levels:
+      billing: warn
+      orders: info
+    drop: ['entry.msg == "healthcheck"']
+    heartbeat_interval: 1m
+  dev:
+    level: debug
+    format: text
+    service_levels:
+      billing: debug
+  staging: &staging
+    console_level: info
+    drop: []
+  prod:
+    <<: *staging
+    level: warn
+    heartbeat_interval: 30s
+  empty:
+`
+
+func TestLoadConfigProfile(t *testing.T) {
+	path := writeConfig(t, "logger.yaml", profilesConfig)
+
+	config, err := LoadConfigProfile(path, "")
+	require.NoError(t, err)
+	assert.Equal(t, InfoLevel, config.Level)
+	assert.Equal(t, JSONFormat, config.Format)
+	assert.Equal(t, time.Minute, config.HeartbeatInterval)
+
+	// Вложенные объекты сливаются по ключам, значения заменяются
+	config, err = LoadConfigProfile(path, "dev")
+	require.NoError(t, err)
+	assert.Equal(t, DebugLevel, config.Level)
+	assert.Equal(t, TextFormat, config.Format)
+	assert.Equal(t, ConsoleOutput, config.Output)
+	assert.Equal(t, map[string]Level{"billing": DebugLevel, "orders": InfoLevel}, config.ServiceLevels)
+	assert.Equal(t, []string{`entry.msg == "healthcheck"`}, config.Drop)
+
+	// Значения из << важнее default, списки заменяются целиком
+	config, err = LoadConfigProfile(path, "prod")
+	require.NoError(t, err)
+	assert.Equal(t, WarnLevel, config.Level)
+	assert.Equal(t, "info", config.ConsoleLevel)
+	assert.Empty(t, config.Drop)
+	assert.Equal(t, 30*time.Second, config.HeartbeatInterval)
+
+	config, err = LoadConfigProfile(path, "empty")
+	require.NoError(t, err)
+	assert.Equal(t, InfoLevel, config.Level)
+
+	_, err = LoadConfigProfile(path, "qa")
+	assert.ErrorContains(t, err, `unknown config profile "qa" (available: default, dev, empty, prod, staging)`)
+}
+
+func TestLoadConfig_ProfileEnv(t *testing.T) {
+	path := writeConfig(t, "logger.yaml", profilesConfig)
+	t.Setenv(ProfileEnv, "dev")
+	config, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, DebugLevel, config.Level)
+
+	// Файл без профилей читается как раньше
+	path = writeConfig(t, "plain.yaml", "level: error\noutput: console\n")
+	config, err = LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, ErrorLevel, config.Level)
+}
+
+func TestLoadConfigProfile_Errors(t *testing.T) {
+	path := writeConfig(t, "logger.yaml", "level: info\nprofiles:\n  dev:\n    level: debug\n")
+	_, err := LoadConfigProfile(path, "dev")
+	assert.ErrorContains(t, err, "config with profiles must not have other top-level keys")
+
+	path = writeConfig(t, "logger.yaml", "profiles:\n  dev:\n    level: debug\n")
+	_, err = LoadConfigProfile(path, "")
+	assert.ErrorContains(t, err, "no config profile selected: set LOG_PROFILE (available: dev)")
+
+	// Неизвестные ключи профиля по-прежнему ошибка
+	path = writeConfig(t, "logger.yaml", "profiles:\n  default:\n    level: info\n  dev:\n    levle: debug\n")
+	_, err = LoadConfigProfile(path, "dev")
+	assert.ErrorContains(t, err, "field levle not found")
+}
@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultProgressInterval минимальный интервал между записями о прогрессе
+const defaultProgressInterval = 10 * time.Second
+
+// Progress отчет о ходе длительной операции. Записи progress пишутся не чаще
+// интервала и содержат процент выполнения, скорость и оставшееся время
+type Progress struct {
+	logger    *Logger
+	operation string
+	total     int64
+	interval  time.Duration
+	now       func() time.Time
+
+	mu         sync.Mutex
+	done       int64
+	start      time.Time
+	lastReport time.Time
+	finished   bool
+}
+
+// Progress создает отчет о ходе операции из total шагов.
+// При неизвестном объеме total равен 0: процент и ETA тогда не считаются
+func (l *Logger) Progress(operation string, total int64) *Progress {
+	now := time.Now()
+	return &Progress{
+		logger:     l,
+		operation:  operation,
+		total:      total,
+		interval:   defaultProgressInterval,
+		now:        time.Now,
+		start:      now,
+		lastReport: now,
+	}
+}
+
+// Every задает минимальный интервал между записями о прогрессе
+func (p *Progress) Every(interval time.Duration) *Progress {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.interval = interval
+	return p
+}
+
+// Add отмечает выполнение n шагов и пишет запись, если с прошлой прошло
+// не меньше интервала. По достижении total пишется итоговая запись
+func (p *Progress) Add(n int64) {
+	p.mu.Lock()
+	if p.finished {
+		p.mu.Unlock()
+		return
+	}
+	p.done += n
+	now := p.now()
+	completed := p.total > 0 && p.done >= p.total
+	if !completed && now.Sub(p.lastReport) < p.interval {
+		p.mu.Unlock()
+		return
+	}
+	p.lastReport = now
+	p.finished = completed
+	fields := p.fields(now)
+	p.mu.Unlock()
+
+	p.log(fields, completed)
+}
+
+// Done пишет итоговую запись, если она еще не была записана
+func (p *Progress) Done() {
+	p.mu.Lock()
+	if p.finished {
+		p.mu.Unlock()
+		return
+	}
+	p.finished = true
+	fields := p.fields(p.now())
+	p.mu.Unlock()
+
+	p.log(fields, true)
+}
+
+// fields собирает поля записи о прогрессе
+func (p *Progress) fields(now time.Time) logrus.Fields {
+	elapsed := now.Sub(p.start)
+	fields := logrus.Fields{
+		"operation":  p.operation,
+		"done":       p.done,
+		"elapsed_ms": elapsed.Milliseconds(),
+	}
+
+	var rate float64
+	if elapsed > 0 {
+		rate = float64(p.done) / elapsed.Seconds()
+		fields["rate_per_s"] = rate
+	}
+
+	if p.total > 0 {
+		fields["total"] = p.total
+		fields["percent"] = float64(p.done) * 100 / float64(p.total)
+		if rate > 0 && p.done < p.total {
+			fields["eta_s"] = float64(p.total-p.done) / rate
+		}
+	}
+	return fields
+}
+
+// log пишет запись о прогрессе или о завершении операции
+func (p *Progress) log(fields logrus.Fields, completed bool) {
+	entry := p.logger.withFields().WithFields(fields)
+	if completed {
+		entry.Info("operation completed")
+		return
+	}
+	entry.Info("operation progress")
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestProgress создает отчет о прогрессе с управляемыми часами
+func newTestProgress(logger *Logger, total int64, clock *fakeClock) *Progress {
+	p := logger.Progress("reindex", total).Every(time.Second)
+	p.now = clock.Now
+	p.start = clock.now
+	p.lastReport = clock.now
+	return p
+}
+
+func TestProgress_Throttled(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	clock := &fakeClock{now: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)}
+	p := newTestProgress(logger, 100, clock)
+
+	p.Add(10)
+	clock.now = clock.now.Add(500 * time.Millisecond)
+	p.Add(10)
+	assert.Empty(t, buf.String())
+
+	clock.now = clock.now.Add(1500 * time.Millisecond)
+	p.Add(20)
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	entry := entries[0]
+	assert.Equal(t, "operation progress", entry["msg"])
+	assert.Equal(t, "reindex", entry["operation"])
+	assert.Equal(t, 40.0, entry["done"])
+	assert.Equal(t, 100.0, entry["total"])
+	assert.Equal(t, 40.0, entry["percent"])
+	assert.Equal(t, 20.0, entry["rate_per_s"])
+	assert.Equal(t, 3.0, entry["eta_s"])
+	assert.Equal(t, 2000.0, entry["elapsed_ms"])
+}
+
+func TestProgress_CompletedOnce(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	clock := &fakeClock{now: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)}
+	p := newTestProgress(logger, 10, clock)
+
+	clock.now = clock.now.Add(100 * time.Millisecond)
+	p.Add(10)
+	p.Add(1)
+	p.Done()
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "operation completed", entries[0]["msg"])
+	assert.Equal(t, 100.0, entries[0]["percent"])
+	assert.NotContains(t, entries[0], "eta_s")
+}
+
+func TestProgress_UnknownTotal(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	clock := &fakeClock{now: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)}
+	p := newTestProgress(logger, 0, clock)
+
+	clock.now = clock.now.Add(2 * time.Second)
+	p.Add(50)
+	p.Done()
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, 25.0, entries[0]["rate_per_s"])
+	assert.NotContains(t, entries[0], "percent")
+	assert.NotContains(t, entries[0], "total")
+	assert.Equal(t, "operation completed", entries[1]["msg"])
+}
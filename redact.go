@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// redactedValue значение, которым заменяются скрытые поля
+const redactedValue = "[REDACTED]"
+
+// defaultRedactKeys ключи полей, скрываемые по умолчанию
+var defaultRedactKeys = []string{"password", "token", "secret", "authorization", "api_key"}
+
+// newRedactKeys создает множество ключей для скрытия без учета регистра
+func newRedactKeys(keys []string) map[string]struct{} {
+	if len(keys) == 0 {
+		keys = defaultRedactKeys
+	}
+
+	set := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		set[strings.ToLower(key)] = struct{}{}
+	}
+	return set
+}
+
+// setRedactKeys заменяет ключи для скрытия
+func (c *core) setRedactKeys(keys []string) {
+	set := newRedactKeys(keys)
+	c.redactKeys.Store(&set)
+}
+
+// redactKeySet возвращает действующие ключи для скрытия
+func (c *core) redactKeySet() map[string]struct{} {
+	if keys := c.redactKeys.Load(); keys != nil {
+		return *keys
+	}
+	return nil
+}
+
+// SetRedaction включает или выключает скрытие чувствительных полей
+func (l *Logger) SetRedaction(enabled bool) {
+	if l == nil {
+		nilReceiver()
+		return
+	}
+	l.core.redact.Store(enabled)
+}
+
+// Redaction сообщает, включено ли скрытие чувствительных полей
+func (l *Logger) Redaction() bool {
+	l = l.orNop()
+	return l.core.redact.Load()
+}
+
+// redactEntry возвращает копию записи со скрытыми значениями чувствительных полей
+func (c *core) redactEntry(entry *logrus.Entry) *logrus.Entry {
+	if !c.redact.Load() {
+		return entry
+	}
+
+	keys := c.redactKeySet()
+	return transformFields(entry, func(key string, value interface{}) (interface{}, bool) {
+		if _, ok := keys[strings.ToLower(key)]; ok {
+			return redactedValue, true
+		}
+		return value, false
+	})
+}
@@ -0,0 +1,41 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_Redaction(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	logger.SetRedaction(true)
+
+	logger.WithFields(map[string]interface{}{
+		"Password": "hunter2",
+		"user":     "alice",
+	}).Info("login")
+
+	logger.SetRedaction(false)
+	logger.WithField("token", "abc").Info("plain")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, redactedValue, entries[0]["Password"])
+	assert.Equal(t, "alice", entries[0]["user"])
+	assert.Equal(t, "abc", entries[1]["token"])
+}
+
+func TestCore_RedactEntry_KeepsOriginal(t *testing.T) {
+	c := &core{}
+	c.setRedactKeys([]string{"card"})
+	c.redact.Store(true)
+
+	entry := &logrus.Entry{Data: logrus.Fields{"card": "4111", "password": "x"}}
+	redacted := c.redactEntry(entry)
+
+	assert.Equal(t, redactedValue, redacted.Data["card"])
+	assert.Equal(t, "x", redacted.Data["password"])
+	assert.Equal(t, "4111", entry.Data["card"])
+}
@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultReloadInterval период проверки файла конфигурации на изменения
+const defaultReloadInterval = 2 * time.Second
+
+// NewWithReload создает логгер по файлу конфигурации и следит за файлом:
+// изменения уровня, форматов и назначений вывода применяются без перезапуска.
+// Наблюдение останавливается при Close
+func NewWithReload(path string) (*Logger, error) {
+	l, err := NewFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+	l.core.stopReload = l.WatchConfig(path, defaultReloadInterval)
+	return l, nil
+}
+
+// WatchConfig периодически проверяет файл конфигурации и при его изменении
+// применяет новую конфигурацию. Если файл не читается или конфигурация
+// некорректна, пишется предупреждение и остается прежняя конфигурация.
+// Возвращает функцию остановки, которая дожидается завершения горутины
+func (l *Logger) WatchConfig(path string, interval time.Duration) (stop func()) {
+	if l == nil {
+		nilReceiver()
+		return func() {}
+	}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	last, _ := configVersion(path)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				version, err := configVersion(path)
+				if err != nil || version == last {
+					continue
+				}
+				last = version
+				l.reload(path)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+}
+
+// configVersion возвращает время изменения и размер файла конфигурации
+func configVersion(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d/%d", info.ModTime().UnixNano(), info.Size()), nil
+}
+
+// reload читает файл конфигурации и применяет его
+func (l *Logger) reload(path string) {
+	config, err := LoadConfig(path)
+	if err == nil {
+		err = l.ApplyConfig(config)
+	}
+	if err != nil {
+		l.withFields().WithField("path", path).WithError(err).Warn("config reload failed")
+		return
+	}
+	l.withFields().WithField("path", path).Info("config reloaded")
+}
+
+// ApplyConfig применяет уровни, скрытие полей, ограничения размера полей,
+// таргетинг, форматы и назначения вывода к работающему логгеру. Новые
+// назначения открываются до переключения, поэтому при ошибке логгер продолжает
+// писать по прежней конфигурации. Правила таргетинга заменяются, только если
+// они изменились в конфигурации: пользователи, добавленные TargetUser,
+// сохраняются. Development и ограничения MisuseMax* не меняются без
+// перезапуска: конфигурация с другими значениями не применяется. Heartbeat и
+// семплирование меняются соответствующими методами
+func (l *Logger) ApplyConfig(config Config) error {
+	if l == nil {
+		nilReceiver()
+		return nil
+	}
+	if config.Serverless {
+		config = serverlessConfig(config)
+	}
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	old := l.core.config.Load()
+	if fixed := fixedFieldChanges(*old, config); len(fixed) > 0 {
+		return fmt.Errorf("fields cannot change at runtime: %s", strings.Join(fixed, ", "))
+	}
+
+	d, files, err := newDispatcher(config)
+	if err != nil {
+		return err
+	}
+
+	// logrus вызывает форматтер под той же блокировкой, поэтому после
+	// переключения в старые назначения больше ничего не пишется
+	l.logger.SetFormatter(d)
+
+	c := l.core
+	c.mu.Lock()
+	oldFiles := c.files
+	c.files = files
+	c.migration = d.migration
+	c.sinks = d.sinks
+	c.mu.Unlock()
+
+	c.config.Store(&config)
+	c.level.Store(uint32(config.Level))
+	c.setServiceLevels(config.ServiceLevels)
+	c.setPoolLevels(config.PoolLevels)
+	c.redact.Store(config.Redact)
+	c.setRedactKeys(config.RedactKeys)
+	c.fieldLimits.Store(newFieldLimits(config))
+	c.errorDebug.Store(newErrorDebug(config))
+	c.reportCaller.Store(config.ReportCaller)
+	c.callerFormat.Store(newCallerFormat(config))
+	c.legalHolds.Store(&config.LegalHolds)
+	c.setDefaults(config)
+	c.serviceNames.Store(newServiceNamePolicy(config))
+	if !sameTargeting(old.Targeting, config.Targeting) {
+		l.SetTargeting(config.Targeting)
+	}
+
+	for _, file := range oldFiles {
+		file.Sync()
+	}
+	return closeFiles(oldFiles)
+}
+
+// fixedFieldChanges возвращает измененные поля конфигурации, которые
+// применяются только при создании логгера
+func fixedFieldChanges(old, config Config) []string {
+	var changed []string
+	if old.Development != config.Development {
+		changed = append(changed, "development")
+	}
+	if old.MisuseMaxFields != config.MisuseMaxFields {
+		changed = append(changed, "misuse_max_fields")
+	}
+	if old.MisuseMaxCollection != config.MisuseMaxCollection {
+		changed = append(changed, "misuse_max_collection")
+	}
+	return changed
+}
+
+// sameTargeting сравнивает правила таргетинга
+func sameTargeting(a, b Targeting) bool {
+	return a.Level == b.Level && a.Percent == b.Percent && slices.Equal(a.UserIDs, b.UserIDs)
+}
@@ -0,0 +1,151 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_ApplyConfig(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.log")
+	second := filepath.Join(dir, "second.log")
+
+	logger, err := New(Config{Level: InfoLevel, Output: FileOutput, FilePath: first, FileFormat: JSONFormat})
+	require.NoError(t, err)
+	t.Cleanup(func() { logger.Close() })
+
+	logger.Debug("hidden")
+	logger.Info("before")
+
+	require.NoError(t, logger.ApplyConfig(Config{
+		Level:      DebugLevel,
+		Output:     FileOutput,
+		FilePath:   second,
+		FileFormat: TextFormat,
+	}))
+	assert.Equal(t, DebugLevel, logger.GetLevel())
+
+	logger.Debug("after")
+	require.NoError(t, logger.Close())
+
+	data, err := os.ReadFile(first)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"msg":"before"`)
+	assert.NotContains(t, string(data), "hidden")
+	assert.NotContains(t, string(data), "after")
+
+	data, err = os.ReadFile(second)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `msg=after`)
+}
+
+func TestLogger_ApplyConfigInvalidKeepsOutput(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	err := logger.ApplyConfig(Config{Level: DebugLevel, Output: FileOutput})
+	require.Error(t, err)
+	assert.Equal(t, TraceLevel, logger.GetLevel())
+
+	logger.Info("still written")
+	assert.Contains(t, buf.String(), "still written")
+}
+
+func TestLogger_ApplyConfigRuntimeFields(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(Config{Level: InfoLevel, Writers: []io.Writer{buf}, Redact: true})
+	require.NoError(t, err)
+	logger.TargetUser("u-1")
+
+	require.NoError(t, logger.ApplyConfig(Config{
+		Level:             InfoLevel,
+		Writers:           []io.Writer{buf},
+		Redact:            true,
+		RedactKeys:        []string{"card"},
+		MaxFieldSize:      16,
+		FieldPreviewBytes: 4,
+	}))
+	// Таргетинг в конфигурации не изменился: пользователь из TargetUser остается
+	assert.Equal(t, []string{"u-1"}, logger.Targeting().UserIDs)
+
+	logger.WithFields(map[string]interface{}{
+		"card":     "4111",
+		"password": "hunter2",
+		"payload":  strings.Repeat("a", 32),
+	}).Info("applied")
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, redactedValue, entries[0]["card"])
+	assert.Equal(t, "hunter2", entries[0]["password"])
+	assert.Equal(t, "aaaa", entries[0]["payload"].(map[string]interface{})["preview"])
+
+	require.NoError(t, logger.ApplyConfig(Config{
+		Level:     InfoLevel,
+		Writers:   []io.Writer{buf},
+		Targeting: Targeting{Level: TraceLevel, UserIDs: []string{"u-2"}},
+	}))
+	assert.Equal(t, Targeting{Level: TraceLevel, UserIDs: []string{"u-2"}}, logger.Targeting())
+
+	err = logger.ApplyConfig(Config{Level: InfoLevel, Writers: []io.Writer{buf}, Development: true, MisuseMaxFields: 8})
+	assert.EqualError(t, err, "fields cannot change at runtime: development, misuse_max_fields")
+}
+
+func TestLogger_WatchConfig(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	configPath := filepath.Join(dir, "logger.yaml")
+	writeYAML := func(level string) {
+		content := "level: " + level + "\noutput: file\nfile_path: " + logPath + "\n"
+		require.NoError(t, os.WriteFile(configPath, []byte(content), 0600))
+	}
+	writeYAML("info")
+
+	logger, err := NewFromFile(configPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { logger.Close() })
+
+	stop := logger.WatchConfig(configPath, 10*time.Millisecond)
+	defer stop()
+
+	// Время изменения файла может совпасть с первым, поэтому меняется и размер
+	writeYAML("debug  ")
+	require.Eventually(t, func() bool {
+		return logger.GetLevel() == DebugLevel
+	}, time.Second, 10*time.Millisecond)
+
+	writeYAML("loud")
+	require.Eventually(t, func() bool {
+		data, _ := os.ReadFile(logPath)
+		return containsAll(string(data), "config reloaded", "config reload failed")
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, DebugLevel, logger.GetLevel())
+}
+
+func TestNewWithReload(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "logger.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("level: warn\noutput: file\nfile_path: "+filepath.Join(dir, "app.log")+"\n"), 0600))
+
+	logger, err := NewWithReload(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, WarnLevel, logger.GetLevel())
+	assert.NotNil(t, logger.core.stopReload)
+	require.NoError(t, logger.Close())
+}
+
+// containsAll проверяет, что строка содержит все подстроки
+func containsAll(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if !strings.Contains(s, sub) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,273 @@
+package logger
+
+import (
+	"bufio"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// cgroupRoot корень файловой системы, от которого читаются /proc и
+// /sys/fs/cgroup. В тестах подменяется
+var cgroupRoot = "/"
+
+// cgroupUnlimited значения лимита памяти cgroup v1 не меньше этого означают
+// отсутствие лимита
+const cgroupUnlimited = 1 << 62
+
+// errNoCgroup сведения cgroup недоступны: не Linux или cgroup не смонтирована
+var errNoCgroup = errors.New("cgroup information is not available")
+
+// StartResourceAnnotation читает лимиты и потребление ресурсов cgroup
+// процесса сразу и затем раз в interval и добавляет их к записям Warn и
+// серьезнее: cgroup_cpu_limit (ядра), cgroup_cpu_throttled и
+// cgroup_cpu_throttled_ms, cgroup_memory_limit_bytes,
+// cgroup_memory_usage_bytes, cgroup_memory_usage_ratio и cgroup_oom_kills.
+// Поддерживаются cgroup v1 и v2. Без лимита соответствующее поле не пишется.
+// Возвращает функцию остановки или ошибку, если cgroup недоступна
+func (l *Logger) StartResourceAnnotation(interval time.Duration) (stop func(), err error) {
+	if l == nil {
+		nilReceiver()
+		return func() {}, nil
+	}
+	fields, err := readCgroup(cgroupRoot)
+	if err != nil {
+		return nil, err
+	}
+	l.core.resources.Store(&fields)
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				// Временная ошибка чтения оставляет прошлые значения
+				if fields, err := readCgroup(cgroupRoot); err == nil {
+					l.core.resources.Store(&fields)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+			l.core.resources.Store(nil)
+		})
+	}, nil
+}
+
+// withResources добавляет к записи Warn и серьезнее сведения о ресурсах,
+// которых в ней еще нет
+func (c *core) withResources(entry *logrus.Entry) *logrus.Entry {
+	resources := c.resources.Load()
+	if resources == nil || entry.Level > logrus.WarnLevel {
+		return entry
+	}
+
+	data := make(logrus.Fields, len(entry.Data)+len(*resources))
+	for k, v := range *resources {
+		data[k] = v
+	}
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+
+	annotated := *entry
+	annotated.Data = data
+	return &annotated
+}
+
+// readCgroup читает лимиты и потребление ресурсов cgroup процесса
+func readCgroup(root string) (logrus.Fields, error) {
+	paths, err := cgroupPaths(filepath.Join(root, "proc/self/cgroup"))
+	if err != nil {
+		return nil, errNoCgroup
+	}
+	mount := filepath.Join(root, "sys/fs/cgroup")
+	fields := make(logrus.Fields)
+
+	if path, ok := paths[""]; ok {
+		dir := cgroupDir(mount, path)
+		if _, err := os.Stat(filepath.Join(dir, "cgroup.controllers")); err == nil {
+			readCgroupV2(dir, fields)
+			return fields, nil
+		}
+	}
+
+	readCgroupV1(cgroupDir(filepath.Join(mount, "cpu"), paths["cpu"]), cgroupDir(filepath.Join(mount, "memory"), paths["memory"]), fields)
+	if len(fields) == 0 {
+		return nil, errNoCgroup
+	}
+	return fields, nil
+}
+
+// cgroupPaths разбирает /proc/self/cgroup: путь группы по контроллеру,
+// для cgroup v2 - по пустому имени
+func cgroupPaths(name string) (map[string]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	paths := make(map[string]string)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		parts := strings.SplitN(sc.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		for _, controller := range strings.Split(parts[1], ",") {
+			paths[controller] = parts[2]
+		}
+	}
+	return paths, sc.Err()
+}
+
+// cgroupDir возвращает каталог группы. В контейнере со своим пространством
+// имен cgroup группа смонтирована в корень, а путь в /proc/self/cgroup
+// указывает на группу хоста
+func cgroupDir(mount, path string) string {
+	dir := filepath.Join(mount, path)
+	if _, err := os.Stat(dir); err != nil {
+		return mount
+	}
+	return dir
+}
+
+// readCgroupV2 читает cpu.max, cpu.stat, memory.max, memory.current и memory.events
+func readCgroupV2(dir string, fields logrus.Fields) {
+	if quota, period, ok := cpuMax(filepath.Join(dir, "cpu.max")); ok {
+		fields["cgroup_cpu_limit"] = roundRatio(quota / period)
+	}
+	stat := readKeyValues(filepath.Join(dir, "cpu.stat"))
+	if v, ok := stat["nr_throttled"]; ok {
+		fields["cgroup_cpu_throttled"] = v
+	}
+	if v, ok := stat["throttled_usec"]; ok {
+		fields["cgroup_cpu_throttled_ms"] = v / 1000
+	}
+	limit, hasLimit := readUint(filepath.Join(dir, "memory.max"))
+	usage, hasUsage := readUint(filepath.Join(dir, "memory.current"))
+	addMemory(fields, limit, hasLimit, usage, hasUsage)
+	if v, ok := readKeyValues(filepath.Join(dir, "memory.events"))["oom_kill"]; ok {
+		fields["cgroup_oom_kills"] = v
+	}
+}
+
+// readCgroupV1 читает лимиты и потребление из контроллеров cpu и memory cgroup v1
+func readCgroupV1(cpuDir, memoryDir string, fields logrus.Fields) {
+	quota, hasQuota := readInt(filepath.Join(cpuDir, "cpu.cfs_quota_us"))
+	period, hasPeriod := readInt(filepath.Join(cpuDir, "cpu.cfs_period_us"))
+	if hasQuota && hasPeriod && quota > 0 && period > 0 {
+		fields["cgroup_cpu_limit"] = roundRatio(float64(quota) / float64(period))
+	}
+	stat := readKeyValues(filepath.Join(cpuDir, "cpu.stat"))
+	if v, ok := stat["nr_throttled"]; ok {
+		fields["cgroup_cpu_throttled"] = v
+	}
+	if v, ok := stat["throttled_time"]; ok {
+		fields["cgroup_cpu_throttled_ms"] = v / 1000000
+	}
+	limit, hasLimit := readUint(filepath.Join(memoryDir, "memory.limit_in_bytes"))
+	usage, hasUsage := readUint(filepath.Join(memoryDir, "memory.usage_in_bytes"))
+	addMemory(fields, limit, hasLimit && limit < cgroupUnlimited, usage, hasUsage)
+	if v, ok := readKeyValues(filepath.Join(memoryDir, "memory.oom_control"))["oom_kill"]; ok {
+		fields["cgroup_oom_kills"] = v
+	}
+}
+
+// addMemory добавляет лимит, потребление памяти и их отношение
+func addMemory(fields logrus.Fields, limit uint64, hasLimit bool, usage uint64, hasUsage bool) {
+	if hasLimit {
+		fields["cgroup_memory_limit_bytes"] = limit
+	}
+	if hasUsage {
+		fields["cgroup_memory_usage_bytes"] = usage
+	}
+	if hasLimit && hasUsage && limit > 0 {
+		fields["cgroup_memory_usage_ratio"] = roundRatio(float64(usage) / float64(limit))
+	}
+}
+
+// cpuMax разбирает cpu.max вида "квота период"; квота max - без лимита
+func cpuMax(name string) (quota, period float64, ok bool) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return 0, 0, false
+	}
+	parts := strings.Fields(string(data))
+	if len(parts) != 2 || parts[0] == "max" {
+		return 0, 0, false
+	}
+	q, err1 := strconv.ParseFloat(parts[0], 64)
+	p, err2 := strconv.ParseFloat(parts[1], 64)
+	if err1 != nil || err2 != nil || p <= 0 {
+		return 0, 0, false
+	}
+	return q, p, true
+}
+
+// readUint читает число из файла; max и ошибки означают отсутствие значения
+func readUint(name string) (uint64, bool) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	return v, err == nil
+}
+
+// readInt читает число со знаком из файла
+func readInt(name string) (int64, bool) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	return v, err == nil
+}
+
+// readKeyValues читает файл строк "ключ значение"
+func readKeyValues(name string) map[string]uint64 {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil
+	}
+	values := make(map[string]uint64)
+	for _, line := range strings.Split(string(data), "\n") {
+		parts := strings.Fields(line)
+		if len(parts) != 2 {
+			continue
+		}
+		if v, err := strconv.ParseUint(parts[1], 10, 64); err == nil {
+			values[parts[0]] = v
+		}
+	}
+	return values
+}
+
+// roundRatio округляет отношение до сотых
+func roundRatio(v float64) float64 {
+	return math.Round(v*100) / 100
+}
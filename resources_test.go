@@ -0,0 +1,125 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTree создает файлы с содержимым в каталоге root
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+}
+
+func TestReadCgroup_V2(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"proc/self/cgroup": "0::/kubepods/pod1/app\n",
+		"sys/fs/cgroup/kubepods/pod1/app/cgroup.controllers": "cpu memory\n",
+		"sys/fs/cgroup/kubepods/pod1/app/cpu.max":            "150000 100000\n",
+		"sys/fs/cgroup/kubepods/pod1/app/cpu.stat":           "usage_usec 100\nnr_throttled 12\nthrottled_usec 3400000\n",
+		"sys/fs/cgroup/kubepods/pod1/app/memory.max":         "536870912\n",
+		"sys/fs/cgroup/kubepods/pod1/app/memory.current":     "402653184\n",
+		"sys/fs/cgroup/kubepods/pod1/app/memory.events":      "low 0\nhigh 0\nmax 4\noom 1\noom_kill 1\n",
+	})
+
+	fields, err := readCgroup(root)
+	require.NoError(t, err)
+	assert.Equal(t, logrus.Fields{
+		"cgroup_cpu_limit":          1.5,
+		"cgroup_cpu_throttled":      uint64(12),
+		"cgroup_cpu_throttled_ms":   uint64(3400),
+		"cgroup_memory_limit_bytes": uint64(536870912),
+		"cgroup_memory_usage_bytes": uint64(402653184),
+		"cgroup_memory_usage_ratio": 0.75,
+		"cgroup_oom_kills":          uint64(1),
+	}, fields)
+}
+
+func TestReadCgroup_V2Unlimited(t *testing.T) {
+	// В контейнере группа смонтирована в корень /sys/fs/cgroup
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"proc/self/cgroup":                 "0::/system.slice/docker-1.scope\n",
+		"sys/fs/cgroup/cgroup.controllers": "cpu memory\n",
+		"sys/fs/cgroup/cpu.max":            "max 100000\n",
+		"sys/fs/cgroup/memory.max":         "max\n",
+		"sys/fs/cgroup/memory.current":     "1024\n",
+	})
+
+	fields, err := readCgroup(root)
+	require.NoError(t, err)
+	assert.Equal(t, logrus.Fields{"cgroup_memory_usage_bytes": uint64(1024)}, fields)
+}
+
+func TestReadCgroup_V1(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"proc/self/cgroup":                           "5:memory:/docker/abc\n4:cpu,cpuacct:/docker/abc\n",
+		"sys/fs/cgroup/cpu/cpu.cfs_quota_us":         "50000\n",
+		"sys/fs/cgroup/cpu/cpu.cfs_period_us":        "100000\n",
+		"sys/fs/cgroup/cpu/cpu.stat":                 "nr_periods 10\nnr_throttled 3\nthrottled_time 25000000\n",
+		"sys/fs/cgroup/memory/memory.limit_in_bytes": "9223372036854771712\n",
+		"sys/fs/cgroup/memory/memory.usage_in_bytes": "2048\n",
+		"sys/fs/cgroup/memory/memory.oom_control":    "oom_kill_disable 0\nunder_oom 0\noom_kill 2\n",
+	})
+
+	fields, err := readCgroup(root)
+	require.NoError(t, err)
+	assert.Equal(t, logrus.Fields{
+		"cgroup_cpu_limit":          0.5,
+		"cgroup_cpu_throttled":      uint64(3),
+		"cgroup_cpu_throttled_ms":   uint64(25),
+		"cgroup_memory_usage_bytes": uint64(2048),
+		"cgroup_oom_kills":          uint64(2),
+	}, fields)
+}
+
+func TestReadCgroup_Unavailable(t *testing.T) {
+	_, err := readCgroup(t.TempDir())
+	assert.ErrorIs(t, err, errNoCgroup)
+}
+
+func TestLogger_StartResourceAnnotation(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"proc/self/cgroup":                 "0::/\n",
+		"sys/fs/cgroup/cgroup.controllers": "memory\n",
+		"sys/fs/cgroup/memory.max":         "1000\n",
+		"sys/fs/cgroup/memory.current":     "900\n",
+	})
+	old := cgroupRoot
+	cgroupRoot = root
+	defer func() { cgroupRoot = old }()
+
+	log, buf := newBufferLogger(t)
+	stop, err := log.StartResourceAnnotation(time.Hour)
+	require.NoError(t, err)
+
+	log.Info("request handled")
+	log.WithField("cgroup_memory_limit_bytes", "override").Error("out of memory")
+	stop()
+	log.Warn("after stop")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 3)
+	assert.NotContains(t, entries[0], "cgroup_memory_usage_bytes")
+	assert.EqualValues(t, 900, entries[1]["cgroup_memory_usage_bytes"])
+	assert.EqualValues(t, 0.9, entries[1]["cgroup_memory_usage_ratio"])
+	assert.Equal(t, "override", entries[1]["cgroup_memory_limit_bytes"])
+	assert.NotContains(t, entries[2], "cgroup_memory_usage_bytes")
+
+	cgroupRoot = t.TempDir()
+	_, err = log.StartResourceAnnotation(time.Hour)
+	assert.ErrorIs(t, err, errNoCgroup)
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"os"
+	"sort"
+	"time"
+)
+
+// rotatedFile ротированный файл логов на диске
+type rotatedFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// rotatedFiles возвращает все ротированные файлы writer'а от старых к новым
+func (w *rotatingWriter) rotatedFiles() ([]rotatedFile, error) {
+	paths, err := listBackups(w.current)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.templated() {
+		periods, err := listPeriodFiles(w.path, w.current)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, periods...)
+	}
+
+	files := make([]rotatedFile, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		files = append(files, rotatedFile{path: path, size: info.Size(), modTime: info.ModTime()})
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	return files, nil
+}
+
+// applyRetention удаляет ротированные файлы старше MaxAge и самые старые
+// файлы, пока общий объем логов превышает MaxTotalSizeMB. Файлы под запретом
+// удаления остаются и учитываются в общем объеме
+func (w *rotatingWriter) applyRetention() error {
+	if w.maxAge <= 0 && w.maxTotal <= 0 {
+		return nil
+	}
+
+	files, err := w.rotatedFiles()
+	if err != nil {
+		return err
+	}
+	held := heldFiles(files, w.holds)
+
+	if w.maxAge > 0 {
+		cutoff := w.now().Add(-w.maxAge)
+		kept := files[:0]
+		for _, file := range files {
+			if held[file.path] || !file.modTime.Before(cutoff) {
+				kept = append(kept, file)
+				continue
+			}
+			if err := os.Remove(file.path); err != nil {
+				return err
+			}
+		}
+		files = kept
+	}
+
+	if w.maxTotal > 0 {
+		// Текущий файл не удаляется, но учитывается в общем объеме
+		total := w.size
+		for _, file := range files {
+			total += file.size
+		}
+		for _, file := range files {
+			if total <= w.maxTotal {
+				break
+			}
+			if held[file.path] {
+				continue
+			}
+			if err := os.Remove(file.path); err != nil {
+				return err
+			}
+			total -= file.size
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeRotated создает ротированный файл с указанным временем изменения
+func writeRotated(t *testing.T, path string, size int, modTime time.Time) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(path, make([]byte, size), 0640))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestRotatingWriter_MaxAge(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	now := time.Now()
+
+	old := backupName(path, now.AddDate(0, 0, -10))
+	fresh := backupName(path, now.AddDate(0, 0, -1))
+	writeRotated(t, old, 10, now.AddDate(0, 0, -10))
+	writeRotated(t, fresh, 10, now.AddDate(0, 0, -1))
+
+	w, err := newRotatingWriter(Config{FilePath: path, MaxSizeMB: 1, MaxAge: 7})
+	require.NoError(t, err)
+	t.Cleanup(func() { w.file.Close() })
+
+	assert.NoFileExists(t, old)
+	assert.FileExists(t, fresh)
+}
+
+func TestRotatingWriter_MaxTotalSize(t *testing.T) {
+	dir := t.TempDir()
+	clock := &fakeClock{now: time.Now()}
+
+	w := &rotatingWriter{
+		path:     filepath.Join(dir, "app-2006-01-02.log"),
+		policy:   RotateDaily,
+		maxTotal: 25,
+		now:      clock.Now,
+		rename:   os.Rename,
+	}
+
+	oldest := filepath.Join(dir, clock.now.AddDate(0, 0, -3).Format("app-2006-01-02.log"))
+	older := filepath.Join(dir, clock.now.AddDate(0, 0, -2).Format("app-2006-01-02.log"))
+	newer := filepath.Join(dir, clock.now.AddDate(0, 0, -1).Format("app-2006-01-02.log"))
+	writeRotated(t, oldest, 10, clock.now.AddDate(0, 0, -3))
+	writeRotated(t, older, 10, clock.now.AddDate(0, 0, -2))
+	writeRotated(t, newer, 10, clock.now.AddDate(0, 0, -1))
+
+	require.NoError(t, w.open(clock.now))
+	t.Cleanup(func() { w.file.Close() })
+	_, err := w.Write([]byte("12345"))
+	require.NoError(t, err)
+
+	require.NoError(t, w.applyRetention())
+
+	assert.NoFileExists(t, oldest)
+	assert.FileExists(t, older)
+	assert.FileExists(t, newer)
+}
@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RetentionField поле записи с классом хранения
+const RetentionField = "retention"
+
+// RetentionClass класс хранения записи. Файловые назначения из
+// Config.RetentionFiles хранят записи класса по своим правилам, а внешние
+// конвейеры могут учитывать поле retention
+type RetentionClass string
+
+const (
+	RetentionShort     RetentionClass = "short"      // отладочные и шумные записи
+	RetentionStandard  RetentionClass = "standard"   // обычные записи, класс по умолчанию
+	RetentionLegalHold RetentionClass = "legal_hold" // записи, которые нельзя удалять
+)
+
+// RetentionFile файл для записей одного класса хранения. Ротация (MaxSizeMB,
+// Rotation, Compress) общая с основным файлом, а лимиты хранения свои:
+// 0 - без ограничения, поэтому для legal_hold их обычно не задают
+type RetentionFile struct {
+	FilePath       string `yaml:"file_path"`
+	MaxBackups     int    `yaml:"max_backups"`
+	MaxAge         int    `yaml:"max_age"` // дни
+	MaxTotalSizeMB int    `yaml:"max_total_size_mb"`
+}
+
+// WithRetention создает дочерний логгер, записи которого помечены классом
+// хранения
+func (l *Logger) WithRetention(class RetentionClass) *Logger {
+	return l.with(map[string]interface{}{RetentionField: string(class)})
+}
+
+// validRetentionClass проверяет, известен ли класс хранения
+func validRetentionClass(class RetentionClass) bool {
+	switch class {
+	case RetentionShort, RetentionStandard, RetentionLegalHold:
+		return true
+	}
+	return false
+}
+
+// entryRetention возвращает класс хранения записи, по умолчанию standard
+func entryRetention(data map[string]interface{}) RetentionClass {
+	switch v := data[RetentionField].(type) {
+	case string:
+		if v != "" {
+			return RetentionClass(v)
+		}
+	case RetentionClass:
+		if v != "" {
+			return v
+		}
+	}
+	return RetentionStandard
+}
+
+// retentionSinks открывает файлы классов хранения в порядке названий классов
+func retentionSinks(config Config) ([]*sink, []logFile, error) {
+	classes := make([]string, 0, len(config.RetentionFiles))
+	for class := range config.RetentionFiles {
+		classes = append(classes, string(class))
+	}
+	sort.Strings(classes)
+
+	var sinks []*sink
+	var files []logFile
+	for _, name := range classes {
+		class := RetentionClass(name)
+		rf := config.RetentionFiles[class]
+
+		classConfig := config
+		classConfig.FilePath = rf.FilePath
+		classConfig.MaxBackups = rf.MaxBackups
+		classConfig.MaxAge = rf.MaxAge
+		classConfig.MaxTotalSizeMB = rf.MaxTotalSizeMB
+
+		file, s, err := openFileSink(classConfig)
+		if err != nil {
+			closeFiles(files)
+			return nil, nil, fmt.Errorf("retention class %s: %w", class, err)
+		}
+		s.name = fmt.Sprintf("file[%s]", class)
+		s.retention = func(c RetentionClass) bool { return c == class }
+		sinks = append(sinks, s)
+		files = append(files, file)
+	}
+	return sinks, files, nil
+}
+
+// excludeRetention возвращает фильтр основного файла: записи классов со
+// своими файлами в него не пишутся
+func excludeRetention(files map[RetentionClass]RetentionFile) func(RetentionClass) bool {
+	if len(files) == 0 {
+		return nil
+	}
+	return func(c RetentionClass) bool {
+		_, own := files[c]
+		return !own
+	}
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntryRetention(t *testing.T) {
+	assert.Equal(t, RetentionStandard, entryRetention(nil))
+	assert.Equal(t, RetentionStandard, entryRetention(map[string]interface{}{RetentionField: ""}))
+	assert.Equal(t, RetentionShort, entryRetention(map[string]interface{}{RetentionField: "short"}))
+	assert.Equal(t, RetentionLegalHold, entryRetention(map[string]interface{}{RetentionField: RetentionLegalHold}))
+}
+
+func TestLogger_RetentionFiles(t *testing.T) {
+	dir := t.TempDir()
+	mainPath := filepath.Join(dir, "app.log")
+	holdPath := filepath.Join(dir, "legal", "hold.log")
+	shortPath := filepath.Join(dir, "short.log")
+
+	logger, err := New(Config{
+		Level:    InfoLevel,
+		Output:   FileOutput,
+		FilePath: mainPath,
+		RetentionFiles: map[RetentionClass]RetentionFile{
+			RetentionLegalHold: {FilePath: holdPath},
+			RetentionShort:     {FilePath: shortPath, MaxAge: 1},
+		},
+	})
+	require.NoError(t, err)
+
+	logger.Info("regular")
+	logger.WithRetention(RetentionStandard).Info("explicit standard")
+	logger.WithRetention(RetentionLegalHold).WithService("billing").Info("invoice issued")
+	logger.WithRetention(RetentionShort).Info("cache warmed")
+	require.NoError(t, logger.Close())
+
+	read := func(path string) []map[string]interface{} {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return decodeEntries(t, bytes.NewBuffer(data))
+	}
+
+	main := read(mainPath)
+	require.Len(t, main, 2)
+	assert.Equal(t, "regular", main[0]["msg"])
+	assert.Equal(t, "standard", main[1][RetentionField])
+
+	hold := read(holdPath)
+	require.Len(t, hold, 1)
+	assert.Equal(t, "invoice issued", hold[0]["msg"])
+	assert.Equal(t, "legal_hold", hold[0][RetentionField])
+
+	short := read(shortPath)
+	require.Len(t, short, 1)
+	assert.Equal(t, "cache warmed", short[0]["msg"])
+
+	names := make([]string, 0)
+	for _, s := range logger.SinkLatencies() {
+		names = append(names, s.Sink)
+	}
+	assert.Equal(t, []string{"file", "file[legal_hold]", "file[short]"}, names)
+}
+
+func TestConfig_ValidateRetentionFiles(t *testing.T) {
+	err := Config{Output: ConsoleOutput, RetentionFiles: map[RetentionClass]RetentionFile{
+		"forever":      {FilePath: filepath.Join(t.TempDir(), "x.log")},
+		RetentionShort: {MaxAge: -1},
+	}}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported retention class: forever")
+	assert.Contains(t, err.Error(), "retention class short: file path is required")
+	assert.Contains(t, err.Error(), "retention class short: limits must not be negative")
+}
@@ -0,0 +1,192 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// defaultRingFileSizeMB размер кольцевого файла по умолчанию
+const defaultRingFileSizeMB = 16
+
+// Заголовок кольцевого файла: сигнатура, размер области данных, позиция
+// следующей записи и признак того, что запись уже шла по кругу
+const (
+	ringMagic      = "LOGRING1"
+	ringHeaderSize = 64
+)
+
+// ringFile назначение-бортовой самописец: файл фиксированного размера,
+// отображенный в память, в котором новые записи затирают самые старые.
+// Запись - копирование в память без системных вызовов, данные сбрасывает на
+// диск ядро, поэтому они переживают аварийное завершение процесса
+type ringFile struct {
+	mu     sync.Mutex
+	file   *os.File
+	mem    []byte // весь файл: заголовок и данные
+	data   []byte // область данных
+	head   int
+	wraps  bool
+	closed bool
+}
+
+// openRingFile открывает кольцевой файл. Существующий файл того же размера
+// продолжается с сохраненной позиции, иначе файл создается заново
+func openRingFile(config Config) (*ringFile, error) {
+	sizeMB := config.RingFileSizeMB
+	if sizeMB <= 0 {
+		sizeMB = defaultRingFileSizeMB
+	}
+	dataSize := sizeMB << 20
+
+	if !config.DisableDirCreation {
+		mode := config.DirMode
+		if mode == 0 {
+			mode = defaultDirMode
+		}
+		if err := os.MkdirAll(filepath.Dir(config.RingFilePath), mode); err != nil {
+			return nil, fmt.Errorf("failed to create ring file directory: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(config.RingFilePath, os.O_CREATE|os.O_RDWR, 0640)
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Truncate(int64(ringHeaderSize + dataSize)); err != nil {
+		file.Close()
+		return nil, err
+	}
+	mem, err := mapRingFile(file, ringHeaderSize+dataSize)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	r := &ringFile{file: file, mem: mem, data: mem[ringHeaderSize:]}
+	head, wraps, err := parseRingHeader(mem)
+	if err == nil && head <= dataSize {
+		r.head, r.wraps = head, wraps
+	} else {
+		clear(mem)
+		copy(mem, ringMagic)
+		binary.LittleEndian.PutUint64(mem[8:], uint64(dataSize))
+	}
+	return r, nil
+}
+
+// parseRingHeader проверяет заголовок и возвращает позицию записи
+func parseRingHeader(mem []byte) (head int, wraps bool, err error) {
+	if len(mem) < ringHeaderSize || string(mem[:len(ringMagic)]) != ringMagic {
+		return 0, false, errors.New("not a ring file")
+	}
+	if size := binary.LittleEndian.Uint64(mem[8:]); size != uint64(len(mem)-ringHeaderSize) {
+		return 0, false, fmt.Errorf("ring file size mismatch: header %d, file %d", size, len(mem)-ringHeaderSize)
+	}
+	return int(binary.LittleEndian.Uint64(mem[16:])), binary.LittleEndian.Uint64(mem[24:]) != 0, nil
+}
+
+// Write копирует запись в кольцо, затирая самые старые данные
+func (r *ringFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return 0, os.ErrClosed
+	}
+
+	n := len(p)
+	if len(p) > len(r.data) {
+		p = p[len(p)-len(r.data):]
+	}
+	copied := copy(r.data[r.head:], p)
+	if copied < len(p) {
+		copy(r.data, p[copied:])
+	}
+	if r.head+len(p) >= len(r.data) {
+		r.wraps = true
+	}
+	r.head = (r.head + len(p)) % len(r.data)
+
+	binary.LittleEndian.PutUint64(r.mem[16:], uint64(r.head))
+	if r.wraps {
+		binary.LittleEndian.PutUint64(r.mem[24:], 1)
+	}
+	return n, nil
+}
+
+// snapshot пишет содержимое кольца от старых записей к новым
+func (r *ringFile) snapshot(w io.Writer) error {
+	r.mu.Lock()
+	data := ringContents(r.data, r.head, r.wraps)
+	r.mu.Unlock()
+	_, err := w.Write(data)
+	return err
+}
+
+// ringContents возвращает копию записей кольца по порядку. Первая запись
+// после перехода по кругу затерта частично и отбрасывается
+func ringContents(data []byte, head int, wraps bool) []byte {
+	if !wraps {
+		return bytes.Clone(data[:head])
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, data[head:]...)
+	out = append(out, data[:head]...)
+	if i := bytes.IndexByte(out, '\n'); i >= 0 {
+		out = out[i+1:]
+	}
+	// Хвост незаписанной области заполнен нулями
+	return bytes.TrimLeft(out, "\x00")
+}
+
+// Sync сбрасывает кольцо на диск
+func (r *ringFile) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	return syncRingFile(r.mem)
+}
+
+// Reopen ничего не делает: кольцевой файл не ротируется
+func (r *ringFile) Reopen() error {
+	return nil
+}
+
+// Close сбрасывает кольцо на диск и закрывает файл
+func (r *ringFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	err := errors.Join(syncRingFile(r.mem), unmapRingFile(r.mem), r.file.Close())
+	r.mem, r.data = nil, nil
+	return err
+}
+
+// ReadRingFile пишет в w записи кольцевого файла от старых к новым. Работает
+// и с файлом упавшего процесса
+func ReadRingFile(path string, w io.Writer) error {
+	mem, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	head, wraps, err := parseRingHeader(mem)
+	if err != nil {
+		return err
+	}
+	data := mem[ringHeaderSize:]
+	if head > len(data) {
+		return errors.New("ring file is corrupted")
+	}
+	_, err = w.Write(ringContents(data, head, wraps))
+	return err
+}
@@ -0,0 +1,26 @@
+//go:build !unix
+
+package logger
+
+import (
+	"errors"
+	"os"
+)
+
+// errRingUnsupported кольцевой файл требует mmap
+var errRingUnsupported = errors.New("ring file is not supported on this platform")
+
+// mapRingFile не поддерживается без mmap
+func mapRingFile(file *os.File, size int) ([]byte, error) {
+	return nil, errRingUnsupported
+}
+
+// syncRingFile не поддерживается без mmap
+func syncRingFile(mem []byte) error {
+	return errRingUnsupported
+}
+
+// unmapRingFile не поддерживается без mmap
+func unmapRingFile(mem []byte) error {
+	return errRingUnsupported
+}
@@ -0,0 +1,112 @@
+//go:build unix
+
+package logger
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingContents(t *testing.T) {
+	assert.Equal(t, "a\nb\n", string(ringContents([]byte("a\nb\n\x00\x00"), 4, false)))
+
+	// Записи "aa\n", "bb\n", "cc\n" в кольце из 7 байт: "cc\n" перешла через
+	// конец и затерла "aa", остаток затертой записи отбрасывается
+	assert.Equal(t, "bb\ncc\n", string(ringContents([]byte("c\n\nbb\nc"), 2, true)))
+}
+
+func TestRingFile_Wrap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring.log")
+	ring, err := openRingFile(Config{RingFilePath: path, RingFileSizeMB: 1})
+	require.NoError(t, err)
+
+	line := strings.Repeat("x", 1000) + "\n"
+	for i := 0; i < 3000; i++ {
+		_, err := ring.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.True(t, ring.wraps)
+
+	var buf bytes.Buffer
+	require.NoError(t, ring.snapshot(&buf))
+	assert.LessOrEqual(t, buf.Len(), 1<<20)
+	assert.Greater(t, buf.Len(), 1<<20-2*len(line))
+	for _, l := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		assert.Equal(t, strings.TrimSuffix(line, "\n"), l)
+	}
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(ringHeaderSize+1<<20), info.Size())
+
+	// Запись больше кольца: сохраняется ее конец
+	big := strings.Repeat("y", 2<<20)
+	_, err = ring.Write([]byte(big))
+	require.NoError(t, err)
+	require.NoError(t, ring.Close())
+	_, err = ring.Write([]byte("closed\n"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
+
+func TestLogger_RingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flight", "ring.log")
+	var console bytes.Buffer
+	logger, err := New(Config{
+		Level:         DebugLevel,
+		Destinations:  []Destination{{Writer: &console, Level: "info"}},
+		RingFilePath:  path,
+		RingFileLevel: "debug",
+	})
+	require.NoError(t, err)
+
+	logger.Debug("detail before crash")
+	logger.Info("request")
+	logger.Trace("hidden")
+	require.NoError(t, logger.Close())
+	assert.NotContains(t, console.String(), "detail before crash")
+
+	// Файл продолжается после перезапуска
+	logger, err = New(Config{Level: InfoLevel, Writers: []io.Writer{io.Discard}, RingFilePath: path})
+	require.NoError(t, err)
+	logger.Info("after restart")
+	require.NoError(t, logger.Close())
+
+	var buf bytes.Buffer
+	require.NoError(t, ReadRingFile(path, &buf))
+	entries := decodeEntries(t, &buf)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "detail before crash", entries[0]["msg"])
+	assert.Equal(t, "request", entries[1]["msg"])
+	assert.Equal(t, "after restart", entries[2]["msg"])
+
+	assert.Error(t, ReadRingFile(filepath.Join(t.TempDir(), "missing"), &buf))
+	notRing := filepath.Join(t.TempDir(), "plain.log")
+	require.NoError(t, os.WriteFile(notRing, []byte("plain"), 0o600))
+	assert.ErrorContains(t, ReadRingFile(notRing, &buf), "not a ring file")
+}
+
+func TestLogger_DebugBundle_Ring(t *testing.T) {
+	logger, err := New(Config{
+		Level:        InfoLevel,
+		Writers:      []io.Writer{io.Discard},
+		RingFilePath: filepath.Join(t.TempDir(), "flight.ring"),
+	})
+	require.NoError(t, err)
+	defer logger.Close()
+	logger.Info("before bundle")
+
+	var bundle bytes.Buffer
+	require.NoError(t, logger.DebugBundle(&bundle))
+	files := readBundle(t, &bundle)
+	entries := decodeEntries(t, bytes.NewBufferString(files["ring.ndjson"]))
+	require.Len(t, entries, 1)
+	assert.Equal(t, "before bundle", entries[0]["msg"])
+	assert.NotContains(t, files["manifest.json"], "skipped")
+}
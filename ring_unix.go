@@ -0,0 +1,24 @@
+//go:build unix
+
+package logger
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// mapRingFile отображает файл в память для записи
+func mapRingFile(file *os.File, size int) ([]byte, error) {
+	return unix.Mmap(int(file.Fd()), 0, size, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+}
+
+// syncRingFile сбрасывает отображенный файл на диск
+func syncRingFile(mem []byte) error {
+	return unix.Msync(mem, unix.MS_SYNC)
+}
+
+// unmapRingFile снимает отображение файла
+func unmapRingFile(mem []byte) error {
+	return unix.Munmap(mem)
+}
@@ -0,0 +1,505 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat формат времени в имени ротированного файла
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotationPolicy определяет ротацию файла по расписанию
+type RotationPolicy string
+
+const (
+	NoRotation   RotationPolicy = ""
+	RotateHourly RotationPolicy = "hourly"
+	RotateDaily  RotationPolicy = "daily"
+)
+
+// periodStart возвращает начало периода ротации, в который попадает t
+func (p RotationPolicy) periodStart(t time.Time) time.Time {
+	switch p {
+	case RotateHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case RotateDaily:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+	return time.Time{}
+}
+
+// next возвращает начало следующего периода ротации
+func (p RotationPolicy) next(start time.Time) time.Time {
+	switch p {
+	case RotateHourly:
+		return start.Add(time.Hour)
+	case RotateDaily:
+		return start.AddDate(0, 0, 1)
+	}
+	return time.Time{}
+}
+
+// rotatingWriter пишет в файл и ротирует его по размеру и расписанию.
+// При ротации по расписанию путь может быть шаблоном времени: app-2006-01-02.log
+type rotatingWriter struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	codec      Codec // кодек сжатия ротированных файлов, nil - без сжатия
+	policy     RotationPolicy
+	maxAge     time.Duration
+	maxTotal   int64
+	holds      []LegalHold // запреты удаления, файлы под ними не удаляются
+	now        func() time.Time
+	rename     func(oldpath, newpath string) error
+
+	file       *os.File
+	current    string // путь открытого файла
+	size       int64
+	periodEnd  time.Time
+	lastRotate time.Time
+
+	// Ротированные файлы сжимаются в фоне по одному, очистка старых файлов
+	// идет после сжатия. Ошибка фоновой работы возвращается следующей записью
+	compressing sync.WaitGroup
+	compressMu  sync.Mutex
+	err         error
+}
+
+// newRotatingWriter создает writer с ротацией
+func newRotatingWriter(config Config) (*rotatingWriter, error) {
+	w := &rotatingWriter{
+		path:       config.FilePath,
+		maxSize:    int64(config.MaxSizeMB) * 1024 * 1024,
+		maxBackups: config.MaxBackups,
+		policy:     config.Rotation,
+		maxAge:     time.Duration(config.MaxAge) * 24 * time.Hour,
+		maxTotal:   int64(config.MaxTotalSizeMB) * 1024 * 1024,
+		holds:      config.LegalHolds,
+		now:        time.Now,
+		rename:     os.Rename,
+	}
+	if config.Compress {
+		codec, err := lookupCodec(firstNonEmpty(config.CompressCodec, DefaultCodec))
+		if err != nil {
+			return nil, err
+		}
+		w.codec = codec
+	}
+	now := w.now()
+	if err := w.open(now); err != nil {
+		return nil, err
+	}
+
+	// Файл без шаблона мог остаться с прошлого периода
+	if w.policy != NoRotation && !w.templated() && w.size > 0 {
+		info, err := w.file.Stat()
+		if err != nil {
+			w.file.Close()
+			return nil, err
+		}
+		if info.ModTime().Before(w.policy.periodStart(now)) {
+			if err := w.rotate(now, false); err != nil {
+				w.file.Close()
+				return nil, err
+			}
+		}
+	}
+
+	// Старые файлы могли накопиться, пока сервис не работал
+	w.compressMu.Lock()
+	err := w.applyRetention()
+	w.compressMu.Unlock()
+	if err != nil {
+		w.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// templated сообщает, содержит ли имя файла шаблон времени
+func (w *rotatingWriter) templated() bool {
+	if w.policy == NoRotation {
+		return false
+	}
+	layout := filepath.Base(w.path)
+	return time.Unix(0, 0).UTC().Format(layout) != time.Unix(1e9, 0).UTC().Format(layout)
+}
+
+// open открывает файл текущего периода и запоминает его размер
+func (w *rotatingWriter) open(now time.Time) error {
+	path := w.path
+	if w.policy != NoRotation {
+		start := w.policy.periodStart(now)
+		w.periodEnd = w.policy.next(start)
+		if w.templated() {
+			path = filepath.Join(filepath.Dir(w.path), start.Format(filepath.Base(w.path)))
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	w.file = file
+	w.current = path
+	w.size = info.Size()
+	return nil
+}
+
+// Write записывает данные, ротируя файл при смене периода или превышении размера
+func (w *rotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// Прежний файл не открылся после неудачной ротации: пробуем снова
+	if w.file == nil {
+		if err := w.reopenCurrent(nil); err != nil {
+			return 0, fmt.Errorf("failed to reopen log file: %w", err)
+		}
+	}
+
+	now := w.now()
+	var rotateErr error
+	switch {
+	case w.policy != NoRotation && !now.Before(w.periodEnd):
+		rotateErr = w.rotate(now, w.templated())
+	case w.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxSize:
+		rotateErr = w.rotate(now, false)
+	}
+	if rotateErr != nil {
+		rotateErr = fmt.Errorf("failed to rotate log file: %w", rotateErr)
+		// После неудачной ротации запись продолжается в прежний файл
+		if w.file == nil {
+			return 0, rotateErr
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	if err == nil {
+		err = w.takeErrLocked()
+	}
+	return n, errors.Join(rotateErr, err)
+}
+
+// takeErrLocked возвращает ошибку фонового сжатия и сбрасывает ее
+func (w *rotatingWriter) takeErrLocked() error {
+	err := w.err
+	w.err = nil
+	return err
+}
+
+// Sync сбрасывает текущий файл на диск
+func (w *rotatingWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+// Close закрывает текущий файл и дожидается сжатия ротированных файлов
+func (w *rotatingWriter) Close() error {
+	w.mu.Lock()
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+	}
+	w.mu.Unlock()
+
+	w.compressing.Wait()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return errors.Join(err, w.takeErrLocked())
+}
+
+// Reopen закрывает и заново открывает файл текущего периода,
+// например после переименования файла внешним logrotate
+func (w *rotatingWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file != nil {
+		if err := w.file.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
+			return err
+		}
+	}
+	return w.open(w.now())
+}
+
+// rotate закрывает текущий файл и открывает новый. Если имя файла
+// меняется вместе с периодом, старый файл остается под своим именем,
+// иначе он переименовывается с временем ротации в имени
+func (w *rotatingWriter) rotate(now time.Time, rename bool) error {
+	if err := w.file.Close(); err != nil {
+		return w.reopenCurrent(err)
+	}
+
+	old := w.current
+	if !rename {
+		old = w.nextBackupName(now)
+		if err := w.rename(w.current, old); err != nil {
+			return w.reopenCurrent(err)
+		}
+	}
+
+	if err := w.open(now); err != nil {
+		return w.reopenCurrent(err)
+	}
+
+	if w.codec != nil {
+		// Сжатие большого файла не должно задерживать запись
+		w.compressing.Add(1)
+		go w.compress(old)
+		return nil
+	}
+
+	return w.prune()
+}
+
+// reopenCurrent после неудачной ротации заново открывает прежний путь, чтобы
+// следующие записи не падали на закрытом файле. Если открыть не удалось,
+// w.file становится nil, и Write повторит ротацию
+func (w *rotatingWriter) reopenCurrent(err error) error {
+	w.file = nil
+	file, openErr := os.OpenFile(w.current, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if openErr != nil {
+		return errors.Join(err, openErr)
+	}
+	info, statErr := file.Stat()
+	if statErr != nil {
+		file.Close()
+		return errors.Join(err, statErr)
+	}
+	w.file = file
+	w.size = info.Size()
+	return err
+}
+
+// compress сжимает ротированный файл и затем удаляет старые файлы,
+// учитывая уже сжатый
+func (w *rotatingWriter) compress(path string) {
+	defer w.compressing.Done()
+	w.compressMu.Lock()
+	defer w.compressMu.Unlock()
+
+	err := compressFile(path, w.codec)
+	// Файл мог удалить prune после сжатия предыдущего
+	if errors.Is(err, os.ErrNotExist) {
+		err = nil
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to compress rotated log file: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err == nil {
+		if err = w.prune(); err != nil {
+			err = fmt.Errorf("failed to prune rotated log files: %w", err)
+		}
+	}
+	if err != nil {
+		w.err = errors.Join(w.err, err)
+	}
+}
+
+// nextBackupName возвращает свободное имя для ротированного файла
+func (w *rotatingWriter) nextBackupName(now time.Time) string {
+	// Ротации в пределах одной миллисекунды не должны затирать друг друга
+	// и должны сохранять порядок имен
+	now = now.Truncate(time.Millisecond)
+	if !now.After(w.lastRotate) {
+		now = w.lastRotate.Add(time.Millisecond)
+	}
+	backup := backupName(w.current, now)
+	for fileExists(backup) || compressedExists(backup) {
+		now = now.Add(time.Millisecond)
+		backup = backupName(w.current, now)
+	}
+	w.lastRotate = now
+	return backup
+}
+
+// prune удаляет самые старые ротированные файлы сверх MaxBackups.
+// Файлы под запретом удаления не удаляются и не учитываются в MaxBackups
+func (w *rotatingWriter) prune() error {
+	if err := w.applyRetention(); err != nil {
+		return err
+	}
+	if w.maxBackups <= 0 {
+		return nil
+	}
+
+	backups, err := listBackups(w.current)
+	if err != nil {
+		return err
+	}
+	if backups, err = w.releasable(backups); err != nil {
+		return err
+	}
+	if err := removeOldest(backups, w.maxBackups); err != nil {
+		return err
+	}
+
+	if !w.templated() {
+		return nil
+	}
+
+	periods, err := listPeriodFiles(w.path, w.current)
+	if err != nil {
+		return err
+	}
+	if periods, err = w.releasable(periods); err != nil {
+		return err
+	}
+	return removeOldest(periods, w.maxBackups)
+}
+
+// removeOldest удаляет первые файлы списка, оставляя keep последних
+func removeOldest(files []string, keep int) error {
+	for len(files) > keep {
+		if err := os.Remove(files[0]); err != nil {
+			return err
+		}
+		files = files[1:]
+	}
+	return nil
+}
+
+// backupName строит имя ротированного файла: app.log -> app-<время>.log
+func backupName(path string, t time.Time) string {
+	ext := filepath.Ext(path)
+	prefix := strings.TrimSuffix(path, ext)
+	return fmt.Sprintf("%s-%s%s", prefix, t.Format(backupTimeFormat), ext)
+}
+
+// fileExists проверяет существование файла
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// BackupFiles возвращает ротированные копии файла лога path, в том числе
+// сжатые, от старых к новым. Сам path в список не входит
+func BackupFiles(path string) ([]string, error) {
+	return listBackups(path)
+}
+
+// listBackups возвращает ротированные файлы от старых к новым
+func listBackups(path string) ([]string, error) {
+	ext := filepath.Ext(path)
+	prefix := strings.TrimSuffix(filepath.Base(path), ext) + "-"
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(trimCodecExt(name), ext)
+		if _, err := time.Parse(backupTimeFormat, strings.TrimPrefix(stamp, prefix)); err != nil {
+			continue
+		}
+		backups = append(backups, filepath.Join(filepath.Dir(path), name))
+	}
+
+	// Время в имени файла сортируется лексикографически
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// listPeriodFiles возвращает файлы прошлых периодов, подходящие под шаблон,
+// от старых к новым. Текущий файл в список не входит
+func listPeriodFiles(template, current string) ([]string, error) {
+	layout := filepath.Base(template)
+	dir := filepath.Dir(template)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	type period struct {
+		path  string
+		start time.Time
+	}
+	var periods []period
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() || path == current {
+			continue
+		}
+		start, err := time.Parse(layout, trimCodecExt(entry.Name()))
+		if err != nil {
+			continue
+		}
+		periods = append(periods, period{path: path, start: start})
+	}
+
+	sort.Slice(periods, func(i, j int) bool {
+		return periods[i].start.Before(periods[j].start)
+	})
+
+	files := make([]string, len(periods))
+	for i, p := range periods {
+		files[i] = p.path
+	}
+	return files, nil
+}
+
+// compressFile сжимает файл кодеком и удаляет исходный
+func compressFile(path string, codec Codec) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+codec.Ext(), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+
+	cw, err := codec.NewWriter(dst)
+	if err != nil {
+		dst.Close()
+		return err
+	}
+	if _, err := io.Copy(cw, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := cw.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
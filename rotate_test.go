@@ -0,0 +1,257 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRotatingWriter создает writer с ротацией по размеру в байтах
+func newTestRotatingWriter(t *testing.T, maxSize int64, maxBackups int, compress bool) (*rotatingWriter, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	w, err := newRotatingWriter(Config{
+		FilePath:   path,
+		MaxBackups: maxBackups,
+		Compress:   compress,
+	})
+	require.NoError(t, err)
+	w.maxSize = maxSize
+	t.Cleanup(func() { w.Close() })
+
+	return w, path
+}
+
+func TestRotatingWriter_Rotate(t *testing.T) {
+	w, path := newTestRotatingWriter(t, 10, 0, false)
+
+	for i := 0; i < 3; i++ {
+		_, err := w.Write([]byte("12345678\n"))
+		require.NoError(t, err)
+	}
+
+	backups, err := listBackups(path)
+	require.NoError(t, err)
+	assert.Len(t, backups, 2)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "12345678\n", string(content))
+}
+
+func TestRotatingWriter_RenameFails(t *testing.T) {
+	w, path := newTestRotatingWriter(t, 10, 0, false)
+	w.rename = func(string, string) error { return os.ErrPermission }
+
+	_, err := w.Write([]byte("12345678\n"))
+	require.NoError(t, err)
+	n, err := w.Write([]byte("abcdefgh\n"))
+	assert.ErrorIs(t, err, os.ErrPermission)
+	assert.Equal(t, 9, n, "the entry is written to the current file")
+
+	// Когда переименование снова работает, ротация продолжается
+	w.rename = os.Rename
+	_, err = w.Write([]byte("ijklmnop\n"))
+	require.NoError(t, err)
+
+	backups, err := listBackups(path)
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	backup, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Equal(t, "12345678\nabcdefgh\n", string(backup))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "ijklmnop\n", string(content))
+}
+
+func TestRotatingWriter_MaxBackups(t *testing.T) {
+	w, path := newTestRotatingWriter(t, 5, 2, false)
+
+	for _, line := range []string{"one\n", "two\n", "three\n", "four\n", "five\n"} {
+		_, err := w.Write([]byte(line))
+		require.NoError(t, err)
+	}
+
+	backups, err := listBackups(path)
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+
+	content, err := os.ReadFile(backups[1])
+	require.NoError(t, err)
+	assert.Equal(t, "four\n", string(content))
+}
+
+func TestRotatingWriter_Compress(t *testing.T) {
+	w, path := newTestRotatingWriter(t, 5, 0, true)
+
+	_, err := w.Write([]byte("first\n"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("second\n"))
+	require.NoError(t, err)
+	// Сжатие идет в фоне, Close его дожидается
+	require.NoError(t, w.Close())
+
+	backups, err := listBackups(path)
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.True(t, strings.HasSuffix(backups[0], ".gz"))
+
+	file, err := os.Open(backups[0])
+	require.NoError(t, err)
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	content, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, "first\n", string(content))
+}
+
+func TestRotatingWriter_CompressThenPrune(t *testing.T) {
+	w, path := newTestRotatingWriter(t, 5, 2, true)
+
+	for _, line := range []string{"one\n", "two\n", "three\n", "four\n", "five\n"} {
+		_, err := w.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	// Очистка идет после сжатия: остаются два последних сжатых файла
+	backups, err := listBackups(path)
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	for _, backup := range backups {
+		assert.True(t, strings.HasSuffix(backup, ".gz"), backup)
+	}
+
+	file, err := os.Open(backups[1])
+	require.NoError(t, err)
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	content, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, "four\n", string(content))
+}
+
+func TestNew_FileRotation(t *testing.T) {
+	tempFile := filepath.Join(t.TempDir(), "test.log")
+
+	logger, err := New(Config{
+		Level:     InfoLevel,
+		Output:    FileOutput,
+		FilePath:  tempFile,
+		MaxSizeMB: 1,
+	})
+	require.NoError(t, err)
+	assert.IsType(t, &rotatingWriter{}, testSinks(logger)[0].writer)
+
+	logger.Info("test message")
+
+	content, err := os.ReadFile(tempFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "test message")
+}
+
+// fakeClock управляемые часы для тестов ротации по расписанию
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestRotatingWriter_DailyTemplate(t *testing.T) {
+	dir := t.TempDir()
+	clock := &fakeClock{now: time.Date(2024, 1, 15, 23, 59, 0, 0, time.Local)}
+
+	w := &rotatingWriter{
+		path:       filepath.Join(dir, "app-2006-01-02.log"),
+		policy:     RotateDaily,
+		maxBackups: 1,
+		now:        clock.Now,
+		rename:     os.Rename,
+	}
+	require.NoError(t, w.open(clock.now))
+	t.Cleanup(func() { w.file.Close() })
+
+	_, err := w.Write([]byte("monday\n"))
+	require.NoError(t, err)
+
+	clock.now = clock.now.Add(2 * time.Minute)
+	_, err = w.Write([]byte("tuesday\n"))
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(dir, "app-2024-01-15.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "monday\n", string(content))
+
+	content, err = os.ReadFile(filepath.Join(dir, "app-2024-01-16.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "tuesday\n", string(content))
+
+	clock.now = clock.now.AddDate(0, 0, 1)
+	_, err = w.Write([]byte("wednesday\n"))
+	require.NoError(t, err)
+
+	// MaxBackups: 1 - файл за понедельник удален
+	assert.NoFileExists(t, filepath.Join(dir, "app-2024-01-15.log"))
+	assert.FileExists(t, filepath.Join(dir, "app-2024-01-16.log"))
+	assert.FileExists(t, filepath.Join(dir, "app-2024-01-17.log"))
+}
+
+func TestRotatingWriter_HourlyWithoutTemplate(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 15, 10, 30, 0, 0, time.Local)}
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	w := &rotatingWriter{path: path, policy: RotateHourly, now: clock.Now, rename: os.Rename}
+	require.NoError(t, w.open(clock.now))
+	t.Cleanup(func() { w.file.Close() })
+
+	_, err := w.Write([]byte("10:30\n"))
+	require.NoError(t, err)
+
+	clock.now = clock.now.Add(time.Hour)
+	_, err = w.Write([]byte("11:30\n"))
+	require.NoError(t, err)
+
+	backups, err := listBackups(path)
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+
+	content, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Equal(t, "10:30\n", string(content))
+
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "11:30\n", string(content))
+}
+
+func TestNew_DailyRotation(t *testing.T) {
+	dir := t.TempDir()
+
+	logger, err := New(Config{
+		Level:    InfoLevel,
+		Output:   FileOutput,
+		FilePath: filepath.Join(dir, "app-2006-01-02.log"),
+		Rotation: RotateDaily,
+	})
+	require.NoError(t, err)
+
+	logger.Info("test message")
+
+	content, err := os.ReadFile(filepath.Join(dir, "app-"+time.Now().Format("2006-01-02")+".log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "test message")
+}
@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// SetSamplingRate задает долю сохраняемых записей уровня Info и ниже (от 0 до 1).
+// Записи уровня Warn и выше сохраняются всегда
+func (l *Logger) SetSamplingRate(rate float64) {
+	if l == nil {
+		nilReceiver()
+		return
+	}
+	l.core.setSampleRate(rate)
+}
+
+// SamplingRate возвращает текущую долю сохраняемых записей
+func (l *Logger) SamplingRate() float64 {
+	l = l.orNop()
+	return l.core.samplingRate()
+}
+
+// setSampleRate сохраняет долю записей, ограничивая ее диапазоном [0, 1]
+func (c *core) setSampleRate(rate float64) {
+	rate = math.Max(0, math.Min(1, rate))
+	c.sampleRate.Store(math.Float64bits(rate))
+}
+
+// samplingRate возвращает долю сохраняемых записей
+func (c *core) samplingRate() float64 {
+	return math.Float64frombits(c.sampleRate.Load())
+}
+
+// sampled решает, сохранять ли запись указанного уровня
+func (c *core) sampled(level Level) bool {
+	if level <= WarnLevel {
+		return true
+	}
+	return c.sample()
+}
+
+// sample случайно сохраняет долю записей по текущей доле семплирования
+func (c *core) sample() bool {
+	rate := c.samplingRate()
+	return rate >= 1 || rand.Float64() < rate
+}
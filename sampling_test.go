@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger_SamplingRate(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	logger.SetSamplingRate(0)
+
+	logger.Info("dropped")
+	logger.WithField("key", "value").Debug("dropped entry")
+	logger.Warn("kept")
+	logger.Error("kept")
+
+	assert.Len(t, decodeEntries(t, buf), 2)
+
+	logger.SetSamplingRate(5)
+	assert.Equal(t, float64(1), logger.SamplingRate())
+}
+
+func TestCore_Sampled(t *testing.T) {
+	c := &core{}
+	c.setSampleRate(0.3)
+
+	kept := 0
+	for i := 0; i < 1000; i++ {
+		if c.sampled(InfoLevel) {
+			kept++
+		}
+	}
+	assert.InDelta(t, 300, kept, 80)
+	assert.True(t, c.sampled(ErrorLevel))
+}
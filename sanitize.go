@@ -0,0 +1,200 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Ограничения схемы клиентских записей по умолчанию
+const (
+	defaultSchemaMaxFields       = 32
+	defaultSchemaMaxFieldBytes   = 1024
+	defaultSchemaMaxMessageBytes = 4096
+)
+
+// FieldType тип значения поля в схеме клиентских записей
+type FieldType string
+
+const (
+	AnyField    FieldType = "any"
+	StringField FieldType = "string"
+	NumberField FieldType = "number"
+	BoolField   FieldType = "bool"
+)
+
+// EntrySchema схема записей, принимаемых от клиентов и других хостов.
+// Неизвестные поля, поля неверного типа и слишком большие значения
+// отбрасываются, их имена попадают в поле dropped_fields
+type EntrySchema struct {
+	// Fields допустимые поля и их типы. Если не задано, допустимы любые поля
+	Fields map[string]FieldType
+
+	MaxFields       int // полей в записи, больше - запись отклоняется (по умолчанию 32)
+	MaxFieldBytes   int // размер значения поля в JSON (по умолчанию 1024)
+	MaxMessageBytes int // сообщение длиннее обрезается (по умолчанию 4096)
+}
+
+// fieldKeyPattern допустимые имена полей
+var fieldKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_.\-]{1,64}$`)
+
+// levelAliases названия уровней, которые используют клиентские библиотеки
+var levelAliases = map[string]string{
+	"log":         "info",
+	"information": "info",
+	"verbose":     "debug",
+	"err":         "error",
+	"critical":    "error",
+}
+
+// normalizeLevel приводит название уровня клиента к уровню логгера.
+// Уровни fatal и panic от клиентов не принимаются
+func normalizeLevel(name string) (Level, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := levelAliases[name]; ok {
+		name = alias
+	}
+	level, err := ParseLevel(name)
+	if err != nil {
+		return 0, err
+	}
+	if level < ErrorLevel {
+		return 0, fmt.Errorf("level %s is not allowed", level)
+	}
+	return level, nil
+}
+
+// Sanitize проверяет запись по схеме и возвращает очищенную копию и уровень.
+// Запись без сообщения, с недопустимым уровнем или слишком большим числом
+// полей отклоняется
+func (s EntrySchema) Sanitize(entry ClientEntry) (ClientEntry, Level, error) {
+	maxFields := s.MaxFields
+	if maxFields <= 0 {
+		maxFields = defaultSchemaMaxFields
+	}
+	maxFieldBytes := s.MaxFieldBytes
+	if maxFieldBytes <= 0 {
+		maxFieldBytes = defaultSchemaMaxFieldBytes
+	}
+	maxMessageBytes := s.MaxMessageBytes
+	if maxMessageBytes <= 0 {
+		maxMessageBytes = defaultSchemaMaxMessageBytes
+	}
+
+	level, err := normalizeLevel(entry.Level)
+	if err != nil {
+		return ClientEntry{}, 0, err
+	}
+	message := stripControl(entry.Message)
+	if strings.TrimSpace(message) == "" {
+		return ClientEntry{}, 0, errors.New("message is required")
+	}
+	if len(entry.Fields) > maxFields {
+		return ClientEntry{}, 0, errors.New("too many fields")
+	}
+	if len(message) > maxMessageBytes {
+		message = truncateUTF8(message, maxMessageBytes)
+	}
+
+	fields := make(map[string]interface{}, len(entry.Fields))
+	var dropped []string
+	for k, v := range entry.Fields {
+		value, ok := s.sanitizeField(k, v, maxFieldBytes)
+		if !ok {
+			dropped = append(dropped, stripControl(k))
+			continue
+		}
+		fields[k] = value
+	}
+	if len(dropped) > 0 {
+		sort.Strings(dropped)
+		fields["dropped_fields"] = dropped
+	}
+
+	return ClientEntry{
+		Level:     level.String(),
+		Message:   message,
+		Timestamp: entry.Timestamp,
+		Fields:    fields,
+	}, level, nil
+}
+
+// sanitizeField проверяет одно поле и возвращает очищенное значение
+func (s EntrySchema) sanitizeField(key string, value interface{}, maxBytes int) (interface{}, bool) {
+	if !fieldKeyPattern.MatchString(key) {
+		return nil, false
+	}
+	if _, reserved := ingestReservedFields[key]; reserved {
+		return nil, false
+	}
+	if s.Fields != nil {
+		typ, ok := s.Fields[key]
+		if !ok || !typ.matches(value) {
+			return nil, false
+		}
+	}
+
+	if str, ok := value.(string); ok {
+		value = stripControl(str)
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil || len(encoded) > maxBytes {
+		return nil, false
+	}
+	return value, true
+}
+
+// matches проверяет тип значения, полученного из JSON
+func (t FieldType) matches(value interface{}) bool {
+	switch t {
+	case StringField:
+		_, ok := value.(string)
+		return ok
+	case NumberField:
+		switch value.(type) {
+		case float64, json.Number:
+			return true
+		}
+		return false
+	case BoolField:
+		_, ok := value.(bool)
+		return ok
+	case AnyField:
+		return true
+	}
+	return false
+}
+
+// stripControl удаляет управляющие символы, которыми можно подделать
+// строки текстового лога
+func stripControl(s string) string {
+	if strings.IndexFunc(s, unicode.IsControl) < 0 {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// truncateUTF8 обрезает строку до n байт, не разрывая символы
+func truncateUTF8(s string, n int) string {
+	if n >= len(s) {
+		return s
+	}
+	for n > 0 && !isRuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// isRuneStart сообщает, начинается ли с байта b символ UTF-8
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
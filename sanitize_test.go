@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntrySchema_Sanitize(t *testing.T) {
+	schema := EntrySchema{
+		Fields: map[string]FieldType{
+			"component": StringField,
+			"duration":  NumberField,
+			"retry":     BoolField,
+			"context":   AnyField,
+			"note":      StringField,
+		},
+		MaxFieldBytes:   32,
+		MaxMessageBytes: 12,
+	}
+
+	entry, level, err := schema.Sanitize(ClientEntry{
+		Level:   " Warning ",
+		Message: "line\nbreak and more",
+		Fields: map[string]interface{}{
+			"component": "cart\r\nlevel=error",
+			"duration":  12.5,
+			"retry":     "yes",
+			"context":   map[string]interface{}{"page": "/checkout"},
+			"note":      strings.Repeat("x", 100),
+			"unknown":   1.0,
+			"service":   "spoofed",
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, WarnLevel, level)
+	assert.Equal(t, "warning", entry.Level)
+	assert.Equal(t, "linebreak an", entry.Message)
+	assert.Equal(t, map[string]interface{}{
+		"component":      "cartlevel=error",
+		"duration":       12.5,
+		"context":        map[string]interface{}{"page": "/checkout"},
+		"dropped_fields": []string{"note", "retry", "service", "unknown"},
+	}, entry.Fields)
+}
+
+func TestEntrySchema_SanitizeWithoutFieldList(t *testing.T) {
+	entry, _, err := EntrySchema{}.Sanitize(ClientEntry{
+		Level:   "log",
+		Message: "hello",
+		Fields: map[string]interface{}{
+			"any.key-1": []interface{}{1.0, 2.0},
+			"bad key":   "x",
+			"client_ip": "1.1.1.1",
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "info", entry.Level)
+	assert.Equal(t, []interface{}{1.0, 2.0}, entry.Fields["any.key-1"])
+	assert.Equal(t, []string{"bad key", "client_ip"}, entry.Fields["dropped_fields"])
+}
+
+func TestEntrySchema_SanitizeRejects(t *testing.T) {
+	tests := map[string]ClientEntry{
+		"unknown level": {Level: "loud", Message: "m"},
+		"fatal level":   {Level: "fatal", Message: "m"},
+		"empty message": {Level: "info", Message: " \n "},
+		"too many fields": {Level: "info", Message: "m", Fields: map[string]interface{}{
+			"a": 1.0, "b": 2.0, "c": 3.0,
+		}},
+	}
+	for name, entry := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := EntrySchema{MaxFields: 2}.Sanitize(entry)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNormalizeLevel(t *testing.T) {
+	for name, want := range map[string]Level{
+		"LOG": InfoLevel, "verbose": DebugLevel, "err": ErrorLevel, "critical": ErrorLevel, "trace": TraceLevel,
+	} {
+		level, err := normalizeLevel(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, level, name)
+	}
+}
+
+func TestTruncateUTF8(t *testing.T) {
+	assert.Equal(t, "при", truncateUTF8("привет", 7))
+	assert.Equal(t, "abc", truncateUTF8("abc", 10))
+}
@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RequestIDFunc извлекает идентификатор запроса AWS из контекста Lambda,
+// например через lambdacontext.FromContext(ctx).AwsRequestID
+type RequestIDFunc func(ctx context.Context) string
+
+// serverlessConfig приводит конфигурацию к режиму AWS Lambda:
+// файловые выводы отключаются, логи пишутся только в stdout
+func serverlessConfig(config Config) Config {
+	config.Output = ConsoleOutput
+	config.FilePath = ""
+	config.RingFilePath = ""
+	config.RetentionFiles = nil
+	config.FileBatchEntries = 0
+	config.FileBatchInterval = 0
+	config.Format = "json"
+	return config
+}
+
+// cloudWatchFormatter возвращает JSON-формат, который CloudWatch Logs
+// разбирает как структурированную запись
+func cloudWatchFormatter() logrus.Formatter {
+	return &logrus.JSONFormatter{
+		FieldMap: logrus.FieldMap{
+			logrus.FieldKeyTime: "timestamp",
+			logrus.FieldKeyMsg:  "message",
+		},
+	}
+}
+
+// LambdaHandler оборачивает обработчик Lambda: добавляет в контекст логгер
+// с идентификатором запроса AWS и сбрасывает вывод до возврата из обработчика
+func LambdaHandler[In, Out any](l *Logger, requestID RequestIDFunc, handler func(ctx context.Context, in In) (Out, error)) func(ctx context.Context, in In) (Out, error) {
+	return func(ctx context.Context, in In) (Out, error) {
+		reqLogger := l
+		if requestID != nil {
+			if id := requestID(ctx); id != "" {
+				reqLogger = l.with(logrus.Fields{"aws_request_id": id})
+			}
+		}
+		// stdout в Lambda не буферизуется, файловые выводы сбрасываем явно
+		defer reqLogger.Sync()
+
+		return handler(NewContext(ctx, reqLogger), in)
+	}
+}
@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_Serverless(t *testing.T) {
+	tempFile := t.TempDir() + "/ignored.log"
+
+	logger, err := New(Config{
+		Level:      InfoLevel,
+		Output:     FileOutput,
+		FilePath:   tempFile,
+		Serverless: true,
+	})
+	require.NoError(t, err)
+	assert.NoFileExists(t, tempFile)
+	sinks := testSinks(logger)
+	require.Len(t, sinks, 1)
+	assert.Equal(t, cloudWatchFormatter(), sinks[0].formatter)
+}
+
+func TestLambdaHandler(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	setTestSink(logger, buf, cloudWatchFormatter())
+
+	handler := LambdaHandler(logger, func(ctx context.Context) string {
+		return "req-123"
+	}, func(ctx context.Context, in string) (int, error) {
+		FromContext(ctx).Info("handling " + in)
+		return len(in), nil
+	})
+
+	out, err := handler(context.Background(), "event")
+	require.NoError(t, err)
+	assert.Equal(t, 5, out)
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "handling event", entries[0]["message"])
+	assert.Equal(t, "req-123", entries[0]["aws_request_id"])
+	assert.Contains(t, entries[0], "timestamp")
+}
+
+func TestNew_ServerlessIgnoresFileOutputs(t *testing.T) {
+	// Файловая система Lambda доступна только для чтения
+	readOnly := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(readOnly, nil, 0o600))
+
+	config := Config{
+		Level:             InfoLevel,
+		Output:            FileOutput,
+		FilePath:          filepath.Join(readOnly, "app.log"),
+		Serverless:        true,
+		RingFilePath:      filepath.Join(readOnly, "ring.log"),
+		RetentionFiles:    map[RetentionClass]RetentionFile{RetentionLegalHold: {FilePath: filepath.Join(readOnly, "audit.log")}},
+		FileBatchEntries:  10,
+		FileBatchInterval: time.Second,
+	}
+	require.NoError(t, config.Validate())
+
+	logger, err := New(config)
+	require.NoError(t, err)
+	assert.Len(t, testSinks(logger), 1)
+	applied := logger.core.config.Load()
+	assert.Empty(t, applied.RingFilePath)
+	assert.Empty(t, applied.RetentionFiles)
+	assert.Zero(t, applied.FileBatchEntries)
+}
@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseServiceLevels разбирает уровни сервисов из строки вида
+// "orders=debug, payments=warn, *=info"
+func ParseServiceLevels(s string) (map[string]Level, error) {
+	levels := make(map[string]Level)
+	for _, item := range splitList(s) {
+		service, name, ok := strings.Cut(item, "=")
+		service = strings.TrimSpace(service)
+		if !ok || service == "" {
+			return nil, fmt.Errorf("invalid service level: %q", item)
+		}
+		level, err := ParseLevel(strings.TrimSpace(name))
+		if err != nil {
+			return nil, fmt.Errorf("service %s: %w", service, err)
+		}
+		levels[service] = level
+	}
+	return levels, nil
+}
+
+// SetServiceLevel задает собственный уровень логгеров сервиса service
+// (WithService) и его групп (WithGroup), независимый от общего уровня
+func (l *Logger) SetServiceLevel(service string, level Level) {
+	if l == nil {
+		nilReceiver()
+		return
+	}
+	l.core.updateServiceLevels(func(levels map[string]Level) {
+		levels[service] = level
+	})
+}
+
+// ResetServiceLevel возвращает логгеры сервиса к общему уровню
+func (l *Logger) ResetServiceLevel(service string) {
+	if l == nil {
+		nilReceiver()
+		return
+	}
+	l.core.updateServiceLevels(func(levels map[string]Level) {
+		delete(levels, service)
+	})
+}
+
+// ServiceLevels возвращает собственные уровни сервисов
+func (l *Logger) ServiceLevels() map[string]Level {
+	l = l.orNop()
+	levels := make(map[string]Level)
+	if current := l.core.serviceLevels.Load(); current != nil {
+		for k, v := range *current {
+			levels[k] = v
+		}
+	}
+	return levels
+}
+
+// setServiceLevels задает уровни сервисов из конфигурации. Уровень "*"
+// заменяет общий уровень
+func (c *core) setServiceLevels(levels map[string]Level) {
+	overrides := make(map[string]Level, len(levels))
+	for service, level := range levels {
+		if service == "*" {
+			c.level.Store(uint32(level))
+			continue
+		}
+		overrides[service] = level
+	}
+	c.serviceLevels.Store(&overrides)
+}
+
+// updateServiceLevels меняет копию уровней сервисов и атомарно подменяет ее
+func (c *core) updateServiceLevels(update func(map[string]Level)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	levels := make(map[string]Level)
+	if current := c.serviceLevels.Load(); current != nil {
+		for k, v := range *current {
+			levels[k] = v
+		}
+	}
+	update(levels)
+	c.serviceLevels.Store(&levels)
+}
+
+// serviceLevel возвращает собственный уровень сервиса или ближайшего
+// родительского сервиса для групп вида orders.db
+func (c *core) serviceLevel(service string) (Level, bool) {
+	levels := c.serviceLevels.Load()
+	if levels == nil || len(*levels) == 0 || service == "" {
+		return 0, false
+	}
+	for {
+		if level, ok := (*levels)[service]; ok {
+			return level, true
+		}
+		i := strings.LastIndexByte(service, '.')
+		if i < 0 {
+			return 0, false
+		}
+		service = service[:i]
+	}
+}
@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseServiceLevels(t *testing.T) {
+	levels, err := ParseServiceLevels("orders=debug, payments = warn,*=info")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Level{"orders": DebugLevel, "payments": WarnLevel, "*": InfoLevel}, levels)
+
+	_, err = ParseServiceLevels("orders")
+	assert.Error(t, err)
+	_, err = ParseServiceLevels("orders=loud")
+	assert.Error(t, err)
+}
+
+func TestLogger_ServiceLevels(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(Config{
+		Level:   ErrorLevel,
+		Writers: []io.Writer{buf},
+		ServiceLevels: map[string]Level{
+			"orders":   DebugLevel,
+			"payments": ErrorLevel,
+			"*":        WarnLevel,
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, WarnLevel, logger.GetLevel())
+
+	orders := logger.WithService("orders")
+	payments := logger.WithService("payments")
+
+	orders.Debug("orders debug")
+	orders.WithGroup("db").Debug("orders db debug")
+	orders.WithField("k", "v").Debug("orders entry debug")
+	payments.Warn("payments warn")
+	logger.WithService("users").Warn("users warn")
+	logger.WithService("users").Info("users info")
+
+	var msgs []interface{}
+	for _, e := range decodeEntries(t, buf) {
+		msgs = append(msgs, e["msg"])
+	}
+	assert.Equal(t, []interface{}{"orders debug", "orders db debug", "orders entry debug", "users warn"}, msgs)
+
+	// Общий уровень не влияет на сервисы с собственным уровнем
+	logger.SetLevel(TraceLevel)
+	assert.False(t, payments.enabled(InfoLevel))
+	assert.True(t, logger.WithService("users").enabled(TraceLevel))
+}
+
+func TestLogger_SetServiceLevel(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+	logger.SetLevel(InfoLevel)
+	orders := logger.WithService("orders")
+
+	assert.False(t, orders.enabled(DebugLevel))
+	logger.SetServiceLevel("orders", DebugLevel)
+	assert.True(t, orders.enabled(DebugLevel))
+	assert.Equal(t, map[string]Level{"orders": DebugLevel}, logger.ServiceLevels())
+
+	logger.ResetServiceLevel("orders")
+	assert.False(t, orders.enabled(DebugLevel))
+	assert.Empty(t, logger.ServiceLevels())
+}
+
+func TestLogger_ServiceLevelWithContextFloor(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+	logger.SetServiceLevel("orders", WarnLevel)
+
+	child := logger.WithService("orders").WithContext(ContextWithDebug(t.Context()))
+	assert.True(t, child.enabled(DebugLevel))
+}
+
+func TestConfigFromEnv_ServiceLevels(t *testing.T) {
+	t.Setenv("LOG_SERVICE_LEVELS", "orders=debug,*=warn")
+
+	config, err := ConfigFromEnv("LOG")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Level{"orders": DebugLevel, "*": WarnLevel}, config.ServiceLevels)
+}
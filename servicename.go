@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// defaultMaxServiceNameLength максимальная длина имени сервиса по умолчанию
+const defaultMaxServiceNameLength = 64
+
+// ServiceNameMode строгость проверки имен сервисов и групп
+type ServiceNameMode string
+
+const (
+	ServiceNamesAsIs      ServiceNameMode = ""          // имена не меняются
+	ServiceNamesNormalize ServiceNameMode = "normalize" // имена молча приводятся к единому виду
+	ServiceNamesStrict    ServiceNameMode = "strict"    // приводятся, о неверных именах пишется предупреждение
+)
+
+// serviceNamePolicy приводит имена сервисов и групп к единому виду:
+// строчные латинские буквы, цифры, '_', '-' и '.', не длиннее maxLength
+type serviceNamePolicy struct {
+	mode      ServiceNameMode
+	maxLength int
+	warned    sync.Map // неверные имена, о которых уже предупредили
+}
+
+// newServiceNamePolicy создает политику имен по конфигурации, nil - имена не меняются
+func newServiceNamePolicy(config Config) *serviceNamePolicy {
+	if config.ServiceNameMode == ServiceNamesAsIs {
+		return nil
+	}
+	p := &serviceNamePolicy{mode: config.ServiceNameMode, maxLength: config.MaxServiceNameLength}
+	if p.maxLength == 0 {
+		p.maxLength = defaultMaxServiceNameLength
+	}
+	return p
+}
+
+// validServiceNameMode проверяет, известен ли режим проверки имен
+func validServiceNameMode(mode ServiceNameMode) bool {
+	switch mode {
+	case ServiceNamesAsIs, ServiceNamesNormalize, ServiceNamesStrict:
+		return true
+	}
+	return false
+}
+
+// normalizeServiceName приводит имя к единому виду: буквы в нижнем регистре,
+// остальные недопустимые символы заменяются на '-', имя обрезается до maxLength
+func normalizeServiceName(name string, maxLength int) string {
+	var b strings.Builder
+	b.Grow(len(name))
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+			b.WriteRune(r)
+			dash = r == '-' || r == '.'
+		case !dash && b.Len() > 0:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	normalized := strings.Trim(b.String(), "-.")
+	if maxLength > 0 && len(normalized) > maxLength {
+		normalized = strings.TrimRight(normalized[:maxLength], "-.")
+	}
+	return normalized
+}
+
+// checkServiceName приводит имя сервиса или группы по политике логгера. В режиме
+// strict о каждом неверном имени один раз пишется предупреждение, а в режиме
+// Development вызывается panic
+func (l *Logger) checkServiceName(name string) string {
+	p := l.core.serviceNames.Load()
+	if p == nil {
+		return name
+	}
+	normalized := normalizeServiceName(name, p.maxLength)
+	if normalized == name || p.mode != ServiceNamesStrict {
+		return normalized
+	}
+
+	if _, warned := p.warned.LoadOrStore(name, struct{}{}); !warned {
+		l.withFields().WithFields(map[string]interface{}{
+			"invalid_name":    name,
+			"normalized_name": normalized,
+		}).Warn("invalid service name")
+	}
+	if l.core.development {
+		panic(fmt.Sprintf("invalid service name %q, use %q", name, normalized))
+	}
+	return normalized
+}
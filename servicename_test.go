@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeServiceName(t *testing.T) {
+	tests := []struct {
+		name, want string
+		max        int
+	}{
+		{name: "orders", want: "orders"},
+		{name: " Order Service ", want: "order-service"},
+		{name: "Payments/API v2", want: "payments-api-v2"},
+		{name: "billing.Invoices", want: "billing.invoices"},
+		{name: "Заказы--cart!!", want: "cart"},
+		{name: "very-long-service-name", max: 10, want: "very-long"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, normalizeServiceName(tt.name, tt.max), tt.name)
+	}
+}
+
+func TestLogger_ServiceNameNormalize(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(Config{Level: InfoLevel, Writers: []io.Writer{buf}, ServiceNameMode: ServiceNamesNormalize})
+	require.NoError(t, err)
+
+	logger.WithService("Order Service").WithGroup("HTTP API").Info("served")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "order-service.http-api", entries[0]["service"])
+}
+
+func TestLogger_ServiceNameStrict(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(Config{Level: InfoLevel, Writers: []io.Writer{buf}, ServiceNameMode: ServiceNamesStrict})
+	require.NoError(t, err)
+
+	logger.WithService("Orders").Info("first")
+	logger.WithService("Orders").Info("second")
+	logger.WithService("orders").Info("valid")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 4, "one warning per invalid name")
+	assert.Equal(t, "invalid service name", entries[0]["msg"])
+	assert.Equal(t, "Orders", entries[0]["invalid_name"])
+	assert.Equal(t, "orders", entries[0]["normalized_name"])
+	for _, entry := range entries[1:] {
+		assert.Equal(t, "orders", entry["service"])
+	}
+
+	dev, err := New(Config{Level: InfoLevel, Writers: []io.Writer{buf}, ServiceNameMode: ServiceNamesStrict, Development: true})
+	require.NoError(t, err)
+	assert.Panics(t, func() { dev.WithService("Billing") })
+}
+
+func TestConfig_ValidateServiceNameMode(t *testing.T) {
+	config := Config{Level: InfoLevel, ServiceNameMode: "lenient"}
+	assert.ErrorContains(t, config.Validate(), "unsupported service name mode")
+}
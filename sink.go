@@ -0,0 +1,218 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// sink назначение вывода логов со своим форматом и фильтром уровней
+type sink struct {
+	name      string
+	writer    io.Writer
+	formatter logrus.Formatter
+	backend   Backend                   // бэкенд вместо writer и formatter
+	accept    func(Level) bool          // nil - все уровни
+	retention func(RetentionClass) bool // фильтр классов хранения, nil - все классы
+	filter    *expr                     // выражение над записью, nil - все записи
+	binary    bool                      // запись не в текстовом виде, контрольная сумма не добавляется
+	latency   sinkLatency
+}
+
+// accepts проверяет, пишется ли запись уровня level в назначение
+func (s *sink) accepts(level Level) bool {
+	return s.accept == nil || s.accept(level)
+}
+
+// acceptsEntry проверяет уровень, класс хранения и фильтр записи
+func (s *sink) acceptsEntry(entry *logrus.Entry) bool {
+	if !s.accepts(Level(entry.Level)) {
+		return false
+	}
+	if s.retention != nil && !s.retention(entryRetention(entry.Data)) {
+		return false
+	}
+	return s.filter == nil || s.filter.match(entry)
+}
+
+// minLevel возвращает фильтр записей уровня level и серьезнее.
+// Пустое название уровня означает все записи
+func minLevel(name string) (func(Level) bool, error) {
+	if name == "" {
+		return nil, nil
+	}
+	min, err := ParseLevel(name)
+	if err != nil {
+		return nil, err
+	}
+	return func(level Level) bool { return level <= min }, nil
+}
+
+// dispatcher форматтер logrus, который сам пишет запись во все назначения.
+// Он отбрасывает записи ниже эффективного уровня создавшего их логгера
+// (в том числе полученные через WithField и др.), применяет семплирование
+// и скрытие полей и защищает вызывающий код от паник при кодировании значений.
+// logrus вызывает форматтер под своей блокировкой, поэтому записи в назначения
+// не перемешиваются
+type dispatcher struct {
+	sinks     []*sink
+	migration *migration    // сравнение выводов в режиме миграции
+	slowSink  time.Duration // порог p99 задержки записи для предупреждения slow sink
+	checksum  bool          // добавлять контрольную сумму в каждую запись
+
+	drop       []*expr     // записи, подходящие под любое выражение, отбрасываются
+	levelRules []levelRule // правила, меняющие уровень записи
+}
+
+// Format пишет запись во все назначения и возвращает пустой результат для logrus
+func (d *dispatcher) Format(entry *logrus.Entry) ([]byte, error) {
+	l := entryLogger(entry)
+	if l != nil {
+		internal := internalEntry(entry)
+		if !internal && !l.enabled(Level(entry.Level)) {
+			return nil, nil
+		}
+		if entry.Level <= logrus.ErrorLevel {
+			l.triggerErrorDebug()
+		}
+		// Итоги задачи учитывают записи до семплирования
+		if l.summary != nil && !internal {
+			l.summary.record(entry)
+		}
+		if !internal && !l.core.sampledEntry(entry) {
+			if counter := l.core.dropped.Load(); counter != nil {
+				counter.record(entry)
+			}
+			return nil, nil
+		}
+		entry = l.core.withDefaults(entry)
+		entry = l.core.withResources(entry)
+		if matchAny(d.drop, entry) {
+			return nil, nil
+		}
+		if len(d.levelRules) > 0 {
+			entry = applyLevelRules(d.levelRules, entry)
+			if !internal && !l.enabled(Level(entry.Level)) {
+				return nil, nil
+			}
+		}
+		if l.core.reportCaller.Load() {
+			entry = withCaller(entry, l.core.callerFormat.Load())
+		}
+		entry = l.core.holdEntry(entry)
+		entry = l.core.redactEntry(entry)
+		entry = l.core.summarizeEntry(entry)
+		l.core.entries[Level(entry.Level)].Add(1)
+	}
+
+	entry = withoutCycles(entry)
+
+	// Форматтеры logrus пишут в entry.Buffer, если он задан: без сброса
+	// результаты разных назначений склеились бы в одном буфере
+	unbuffered := *entry
+	unbuffered.Buffer = nil
+
+	var errs []error
+	var oldData, newData []byte
+	for _, s := range d.sinks {
+		// Каждое назначение получает свою копию записи: форматтер, подменивший
+		// уровень, сообщение или поля, не влияет на остальные назначения.
+		// Поля меняются только через копию map, как в конвейере выше
+		view := unbuffered
+		entry := &view
+		if !s.acceptsEntry(entry) {
+			continue
+		}
+		if s.backend != nil {
+			start := time.Now()
+			if err := s.backend.Write(entryView(entry)); err != nil {
+				errs = append(errs, err)
+			}
+			if entry.Level <= logrus.FatalLevel {
+				if err := s.backend.Sync(); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			d.observe(l, s, start)
+			continue
+		}
+		data, err := safeFormat(s.formatter, entry)
+		if err == nil && d.checksum && !s.binary {
+			data, err = stampChecksum(data)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		start := time.Now()
+		if err := writeEntry(s.writer, entry, data); err != nil {
+			errs = append(errs, err)
+		}
+		// Записи об ошибках и критичные записи не должны теряться в пачке
+		// при падении процесса
+		if urgent(entry) {
+			if f, ok := s.writer.(flusher); ok {
+				if err := f.flush(); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+		// После Panic и Fatal процесс обычно завершается: асинхронные
+		// назначения отправляют такую запись и свою очередь сразу
+		if entry.Level <= logrus.FatalLevel {
+			if syncer, ok := s.writer.(interface{ Sync() error }); ok {
+				if err := syncer.Sync(); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+		d.observe(l, s, start)
+		if d.migration != nil {
+			switch s {
+			case d.migration.old:
+				oldData = data
+			case d.migration.new:
+				newData = data
+			}
+		}
+	}
+	if oldData != nil && newData != nil {
+		d.migration.compare(oldData, newData)
+	}
+	return nil, errors.Join(errs...)
+}
+
+// writeEntry пишет отформатированную запись. Сетевой вывод только ставит
+// запись в очередь: конвейер работает под блокировкой logrus, и ожидание
+// медленного сервера задержало бы все записи, включая записи с дедлайном
+func writeEntry(w io.Writer, entry *logrus.Entry, data []byte) error {
+	if cw, ok := w.(contextWriter); ok {
+		ctx := entry.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		return cw.enqueue(ctx, data)
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// observe учитывает задержку записи в назначение и предупреждает о медленном назначении
+func (d *dispatcher) observe(l *Logger, s *sink, start time.Time) {
+	s.latency.observe(time.Since(start))
+	if d.slowSink > 0 && l != nil {
+		if p99, slow := s.latency.checkSlow(d.slowSink, start); slow {
+			go l.warnSlowSink(s.name, p99, d.slowSink)
+		}
+	}
+}
+
+// isTerminal проверяет, подключен ли файл к терминалу
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,160 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatcher_PerSinkFormat(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+
+	text := &bytes.Buffer{}
+	jsonBuf := &bytes.Buffer{}
+	logger.logger.SetFormatter(&dispatcher{sinks: []*sink{
+		{writer: text, formatter: &logrus.TextFormatter{DisableColors: true}},
+		{writer: jsonBuf, formatter: &logrus.JSONFormatter{}},
+	}})
+
+	logger.WithField("key", "value").Info("test message")
+
+	assert.Contains(t, text.String(), `msg="test message"`)
+	assert.Contains(t, text.String(), "key=value")
+
+	entry := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(jsonBuf.Bytes(), &entry))
+	assert.Equal(t, "test message", entry["msg"])
+	assert.Equal(t, "value", entry["key"])
+}
+
+func TestDispatcher_DroppedEntryNotWritten(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	logger.SetLevel(WarnLevel)
+
+	logger.WithField("key", "value").Info("dropped")
+	logger.WithField("key", "value").Warn("kept")
+
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+}
+
+func TestDispatcher_SinkMinLevel(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+
+	all := &bytes.Buffer{}
+	warn := &bytes.Buffer{}
+	accept, err := minLevel("warn")
+	require.NoError(t, err)
+	logger.logger.SetFormatter(&dispatcher{sinks: []*sink{
+		{writer: all, formatter: &logrus.JSONFormatter{}},
+		{writer: warn, formatter: &logrus.JSONFormatter{}, accept: accept},
+	}})
+
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Warn("warn")
+	logger.Error("error")
+
+	assert.Len(t, decodeEntries(t, all), 4)
+	entries := decodeEntries(t, warn)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "warn", entries[0]["msg"])
+	assert.Equal(t, "error", entries[1]["msg"])
+}
+
+func TestMinLevel(t *testing.T) {
+	accept, err := minLevel("")
+	require.NoError(t, err)
+	assert.Nil(t, accept)
+
+	_, err = minLevel("loud")
+	assert.Error(t, err)
+}
+
+func TestNew_Destinations(t *testing.T) {
+	all := &bytes.Buffer{}
+	errs := &bytes.Buffer{}
+	logger, err := New(Config{
+		Level: TraceLevel,
+		Destinations: []Destination{
+			{Writer: all},
+			{Writer: errs, Format: TextFormat, Level: "error"},
+		},
+	})
+	require.NoError(t, err)
+
+	logger.Info("started")
+	logger.Error("failed")
+
+	assert.Len(t, decodeEntries(t, all), 2)
+	assert.NotContains(t, errs.String(), "started")
+	assert.Contains(t, errs.String(), `msg=failed`)
+}
+
+func TestNew_InvalidDestination(t *testing.T) {
+	_, err := New(Config{Destinations: []Destination{{Writer: &bytes.Buffer{}, Level: "loud"}}})
+	assert.Error(t, err)
+
+	_, err = New(Config{Destinations: []Destination{{}}})
+	assert.Error(t, err)
+
+	_, err = New(Config{Output: ConsoleOutput, ConsoleLevel: "loud"})
+	assert.Error(t, err)
+}
+
+func TestNew_FileLevel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	logger, err := New(Config{
+		Level:     TraceLevel,
+		Output:    FileOutput,
+		FilePath:  path,
+		FileLevel: "warn",
+	})
+	require.NoError(t, err)
+
+	logger.Info("skipped")
+	logger.Warn("written")
+	require.NoError(t, logger.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "skipped")
+	assert.Contains(t, string(data), "written")
+}
+
+func TestNew_SplitStdErr(t *testing.T) {
+	logger, err := New(Config{Level: TraceLevel, Output: ConsoleOutput, SplitStdErr: true})
+	require.NoError(t, err)
+
+	sinks := testSinks(logger)
+	require.Len(t, sinks, 2)
+	stdout, stderr := sinks[0], sinks[1]
+	assert.Equal(t, os.Stdout, stdout.writer)
+	assert.Equal(t, os.Stderr, stderr.writer)
+
+	for _, level := range []Level{InfoLevel, DebugLevel, TraceLevel} {
+		assert.True(t, stdout.accepts(level), level)
+		assert.False(t, stderr.accepts(level), level)
+	}
+	for _, level := range []Level{PanicLevel, FatalLevel, ErrorLevel, WarnLevel} {
+		assert.False(t, stdout.accepts(level), level)
+		assert.True(t, stderr.accepts(level), level)
+	}
+}
+
+func TestNew_SplitStdErrWithConsoleLevel(t *testing.T) {
+	logger, err := New(Config{Level: TraceLevel, Output: ConsoleOutput, SplitStdErr: true, ConsoleLevel: "info"})
+	require.NoError(t, err)
+
+	sinks := testSinks(logger)
+	require.Len(t, sinks, 2)
+	assert.True(t, sinks[0].accepts(InfoLevel))
+	assert.False(t, sinks[0].accepts(DebugLevel))
+	assert.True(t, sinks[1].accepts(ErrorLevel))
+}
@@ -0,0 +1,172 @@
+// Package sinkplugin помогает написать назначение вывода для логгера в
+// отдельной программе, не форкая пакет: логгер запускает ее из
+// Config.Plugins и передает записи по протоколу плагинов.
+//
+//	func main() {
+//	    err := sinkplugin.Serve(func(c sinkplugin.Config) (sinkplugin.Sink, error) {
+//	        return newVaultSink(c.Options["address"].(string))
+//	    })
+//	    if err != nil {
+//	        fmt.Fprintln(os.Stderr, err)
+//	        os.Exit(1)
+//	    }
+//	}
+//
+// Протокол: сообщения в stdin и stdout плагина - длина в 4 байтах
+// big-endian и JSON {"type": ...}. Логгер отправляет hello с версией
+// протокола, именем и options, плагин отвечает ready (с error, если
+// запуститься не удалось). Затем логгер отправляет entry с записью в JSON
+// без ответа и sync, на который плагин отвечает ack. Ошибки записи плагин
+// сообщает сообщением error. Закрытый stdin означает остановку: плагин
+// сбрасывает записи и завершается. stdout занят протоколом, свои
+// сообщения плагин пишет в stderr
+package sinkplugin
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ex-rate/logger"
+	"github.com/ex-rate/logger/pkg/logread"
+)
+
+// maxFrame предел размера сообщения протокола
+const maxFrame = 16 << 20
+
+// Config параметры запуска плагина из PluginConfig логгера
+type Config struct {
+	Name    string
+	Options map[string]interface{}
+}
+
+// Sink назначение, которое реализует плагин. Методы вызываются из одной
+// горутины
+type Sink interface {
+	Write(entry logread.Entry) error
+	Sync() error
+	Close() error
+}
+
+// message сообщение протокола плагинов
+type message struct {
+	Type     string                 `json:"type"`
+	Protocol int                    `json:"protocol,omitempty"`
+	Name     string                 `json:"name,omitempty"`
+	Options  map[string]interface{} `json:"options,omitempty"`
+	Entry    json.RawMessage        `json:"entry,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+}
+
+// Serve обслуживает логгер через stdin и stdout: создает назначение через
+// open и передает ему записи, пока логгер не закроет stdin
+func Serve(open func(Config) (Sink, error)) error {
+	return serve(os.Stdin, os.Stdout, open)
+}
+
+// serve обслуживает логгер через r и w
+func serve(r io.Reader, w io.Writer, open func(Config) (Sink, error)) error {
+	in := bufio.NewReader(r)
+	out := bufio.NewWriter(w)
+	reply := func(msg message) error {
+		if err := writeFrame(out, msg); err != nil {
+			return err
+		}
+		return out.Flush()
+	}
+
+	hello, err := readFrame(in)
+	if err != nil {
+		return fmt.Errorf("read hello: %w", err)
+	}
+	if hello.Type != "hello" {
+		return fmt.Errorf("unexpected %q message, expected hello", hello.Type)
+	}
+	if hello.Protocol != logger.PluginProtocolVersion {
+		err := fmt.Errorf("unsupported plugin protocol %d, plugin supports %d", hello.Protocol, logger.PluginProtocolVersion)
+		_ = reply(message{Type: "ready", Error: err.Error()})
+		return err
+	}
+	sink, err := open(Config{Name: hello.Name, Options: hello.Options})
+	if err != nil {
+		_ = reply(message{Type: "ready", Error: err.Error()})
+		return err
+	}
+	if err := reply(message{Type: "ready"}); err != nil {
+		sink.Close()
+		return err
+	}
+
+	for {
+		msg, err := readFrame(in)
+		if errors.Is(err, io.EOF) {
+			return sink.Close()
+		}
+		if err != nil {
+			sink.Close()
+			return err
+		}
+		switch msg.Type {
+		case "entry":
+			entry, err := logread.ParseLine(msg.Entry)
+			if err == nil {
+				err = sink.Write(entry)
+			}
+			if err != nil {
+				if err := reply(message{Type: "error", Error: err.Error()}); err != nil {
+					sink.Close()
+					return err
+				}
+			}
+		case "sync":
+			ack := message{Type: "ack"}
+			if err := sink.Sync(); err != nil {
+				ack.Error = err.Error()
+			}
+			if err := reply(ack); err != nil {
+				sink.Close()
+				return err
+			}
+		}
+	}
+}
+
+// writeFrame пишет сообщение: длина в 4 байтах big-endian и JSON
+func writeFrame(w io.Writer, msg message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(data)))
+	if _, err := w.Write(size[:]); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// readFrame читает сообщение
+func readFrame(r io.Reader) (message, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return message{}, err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > maxFrame {
+		return message{}, fmt.Errorf("plugin message too large: %d bytes", n)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return message{}, err
+	}
+	var msg message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return message{}, fmt.Errorf("invalid plugin message: %w", err)
+	}
+	return msg, nil
+}
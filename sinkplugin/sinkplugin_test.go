@@ -0,0 +1,98 @@
+package sinkplugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ex-rate/logger"
+	"github.com/ex-rate/logger/pkg/logread"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fileSink пишет сообщения записей в файл строками "уровень сообщение"
+type fileSink struct {
+	file *os.File
+}
+
+func (s *fileSink) Write(e logread.Entry) error {
+	if e.Message == "reject me" {
+		return errors.New("rejected by backend")
+	}
+	_, err := fmt.Fprintf(s.file, "%s %s order_id=%v\n", e.Level, e.Message, e.Fields["order_id"])
+	return err
+}
+
+func (s *fileSink) Sync() error  { return s.file.Sync() }
+func (s *fileSink) Close() error { return s.file.Close() }
+
+// TestHelperPlugin не тест: так запускается плагин из тестового бинарника
+func TestHelperPlugin(t *testing.T) {
+	if os.Getenv("SINKPLUGIN_HELPER") != "1" {
+		t.Skip("helper process")
+	}
+	err := Serve(func(c Config) (Sink, error) {
+		path, _ := c.Options["path"].(string)
+		if path == "" {
+			return nil, errors.New("path option is required")
+		}
+		file, err := os.Create(path)
+		if err != nil {
+			return nil, err
+		}
+		return &fileSink{file: file}, nil
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// helperPlugin настройки логгера для запуска TestHelperPlugin
+func helperPlugin(options map[string]interface{}) logger.PluginConfig {
+	return logger.PluginConfig{
+		Name:    "file",
+		Command: os.Args[0],
+		Args:    []string{"-test.run=^TestHelperPlugin$"},
+		Env:     map[string]string{"SINKPLUGIN_HELPER": "1"},
+		Options: options,
+		Level:   "info",
+	}
+}
+
+func TestServe(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plugin.log")
+	log, err := logger.New(logger.Config{
+		Level:   logger.DebugLevel,
+		Plugins: []logger.PluginConfig{helperPlugin(map[string]interface{}{"path": path})},
+	})
+	require.NoError(t, err)
+
+	log.WithField("order_id", 42).Warn("payment delayed")
+	log.Debug("below plugin level")
+	log.Info("reject me")
+	// Ошибка записи приходит от плагина асинхронно, Sync ее дожидается
+	assert.ErrorContains(t, log.Sync(), "plugin:file: rejected by backend")
+	log.Error("order failed")
+	require.NoError(t, log.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"warning payment delayed order_id=42",
+		"error order failed order_id=<nil>",
+	}, strings.Split(strings.TrimSpace(string(data)), "\n"))
+}
+
+func TestServe_OpenError(t *testing.T) {
+	_, err := logger.New(logger.Config{
+		Level:   logger.InfoLevel,
+		Plugins: []logger.PluginConfig{helperPlugin(nil)},
+	})
+	assert.ErrorContains(t, err, "plugin:file: path option is required")
+}
@@ -0,0 +1,24 @@
+package logger
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// addClockSkew добавляет к записи с временем клиента время получения сервером
+// и расхождение часов. Если расхождение по модулю больше maxSkew, запись
+// помечается полем clock_skew_exceeded, чтобы ошибки порядка записей
+// можно было найти. При maxSkew = 0 ничего не добавляется
+func addClockSkew(fields logrus.Fields, clientTime, received time.Time, maxSkew time.Duration) {
+	if maxSkew <= 0 || clientTime.IsZero() {
+		return
+	}
+
+	skew := received.Sub(clientTime)
+	fields["received_at"] = received.UTC().Format(time.RFC3339Nano)
+	fields["clock_skew_ms"] = skew.Milliseconds()
+	if skew > maxSkew || skew < -maxSkew {
+		fields["clock_skew_exceeded"] = true
+	}
+}
@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddClockSkew(t *testing.T) {
+	received := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		client   time.Time
+		maxSkew  time.Duration
+		skewMs   int64
+		exceeded bool
+		skipped  bool
+	}{
+		{name: "within threshold", client: received.Add(-2 * time.Second), maxSkew: 5 * time.Second, skewMs: 2000},
+		{name: "client behind", client: received.Add(-time.Minute), maxSkew: 5 * time.Second, skewMs: 60000, exceeded: true},
+		{name: "client ahead", client: received.Add(10 * time.Second), maxSkew: 5 * time.Second, skewMs: -10000, exceeded: true},
+		{name: "disabled", client: received.Add(-time.Hour), skipped: true},
+		{name: "no client time", maxSkew: time.Second, skipped: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := logrus.Fields{}
+			addClockSkew(fields, tt.client, received, tt.maxSkew)
+
+			if tt.skipped {
+				assert.Empty(t, fields)
+				return
+			}
+			assert.Equal(t, "2024-01-15T10:00:00Z", fields["received_at"])
+			assert.Equal(t, tt.skewMs, fields["clock_skew_ms"])
+			if tt.exceeded {
+				assert.Equal(t, true, fields["clock_skew_exceeded"])
+			} else {
+				assert.NotContains(t, fields, "clock_skew_exceeded")
+			}
+		})
+	}
+}
+
+func TestLogger_IngestHandlerClockSkew(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	handler := logger.IngestHandler(IngestOptions{MaxClockSkew: time.Minute})
+
+	rec := postBatch(handler, `[
+		{"level": "info", "message": "old clock", "timestamp": "2001-01-01T00:00:00Z",
+		 "fields": {"clock_skew_exceeded": false}},
+		{"level": "info", "message": "no clock"}
+	]`)
+	assert.Equal(t, 202, rec.Code)
+
+	entries := decodeEntries(t, buf)
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, "2001-01-01T00:00:00Z", entries[0]["client_time"])
+		assert.Contains(t, entries[0], "received_at")
+		assert.Greater(t, entries[0]["clock_skew_ms"], float64(0))
+		assert.Equal(t, true, entries[0]["clock_skew_exceeded"])
+
+		assert.NotContains(t, entries[1], "received_at")
+		assert.NotContains(t, entries[1], "clock_skew_ms")
+	}
+}
@@ -0,0 +1,204 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// sloBuckets число интервалов скользящего окна SLO
+const sloBuckets = 60
+
+// SLORule правило SLO по записям: доля записей уровня Error и выше среди
+// записей уровня Info и выше за окно Window. Скорость расхода бюджета ошибок -
+// эта доля, деленная на 1-Objective; при BurnRate и выше срабатывает алерт
+type SLORule struct {
+	Name       string        `yaml:"name"`
+	Service    string        `yaml:"service"`     // сервис, пусто - каждый сервис отдельно
+	Objective  float64       `yaml:"objective"`   // доля записей без ошибок, например 0.999
+	Window     time.Duration `yaml:"window"`      // окно оценки
+	BurnRate   float64       `yaml:"burn_rate"`   // порог скорости расхода бюджета
+	MinEntries int           `yaml:"min_entries"` // минимум записей в окне для оценки
+}
+
+// validate проверяет правило
+func (r SLORule) validate() error {
+	var errs []error
+	if r.Objective <= 0 || r.Objective >= 1 {
+		errs = append(errs, fmt.Errorf("objective must be between 0 and 1: %v", r.Objective))
+	}
+	if r.Window <= 0 {
+		errs = append(errs, errors.New("window must be positive"))
+	}
+	if r.BurnRate <= 0 {
+		errs = append(errs, errors.New("burn rate must be positive"))
+	}
+	if r.MinEntries < 0 {
+		errs = append(errs, errors.New("min entries must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// Alert срабатывание или снятие алерта SLO
+type Alert struct {
+	Rule       string
+	Service    string
+	Resolved   bool // скорость расхода бюджета опустилась ниже порога
+	BurnRate   float64
+	ErrorRatio float64
+	Errors     int
+	Entries    int
+	Window     time.Duration
+	Time       time.Time
+}
+
+// AlertSink получатель алертов: pager, чат, webhook. Alert вызывается
+// синхронно из записи лога и не должен блокироваться
+type AlertSink interface {
+	Alert(alert Alert)
+}
+
+// AlertFunc функция-получатель алертов
+type AlertFunc func(alert Alert)
+
+// Alert вызывает функцию
+func (f AlertFunc) Alert(alert Alert) {
+	f(alert)
+}
+
+// EnableSLOAlerts включает алерты по правилам SLO. Записи, прошедшие уровень
+// логгера, считаются до семплирования. Алерт отправляется один раз
+// при превышении порога и еще раз с Resolved, когда скорость расхода бюджета
+// опускается ниже порога
+func (l *Logger) EnableSLOAlerts(sink AlertSink, rules []SLORule) error {
+	if l == nil {
+		nilReceiver()
+		return nil
+	}
+	for i, rule := range rules {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("slo rule %d: %w", i, err)
+		}
+	}
+	l.logger.AddHook(newSLOHook(sink, rules, time.Now))
+	return nil
+}
+
+// sloKey окно правила для сервиса
+type sloKey struct {
+	rule    int
+	service string
+}
+
+// sloWindow скользящее окно записей и ошибок по интервалам
+type sloWindow struct {
+	width   time.Duration
+	index   [sloBuckets]int64
+	entries [sloBuckets]int
+	errors  [sloBuckets]int
+	firing  bool
+}
+
+// record учитывает запись в интервале времени now
+func (w *sloWindow) record(now time.Time, failed bool) {
+	idx := now.UnixNano() / int64(w.width)
+	slot := idx % sloBuckets
+	if w.index[slot] != idx {
+		w.index[slot] = idx
+		w.entries[slot] = 0
+		w.errors[slot] = 0
+	}
+	w.entries[slot]++
+	if failed {
+		w.errors[slot]++
+	}
+}
+
+// totals возвращает число записей и ошибок в окне, заканчивающемся в now
+func (w *sloWindow) totals(now time.Time) (entries, failures int) {
+	idx := now.UnixNano() / int64(w.width)
+	for slot := range w.index {
+		if idx-w.index[slot] < sloBuckets {
+			entries += w.entries[slot]
+			failures += w.errors[slot]
+		}
+	}
+	return entries, failures
+}
+
+// sloHook hook logrus, считающий SLO по записям
+type sloHook struct {
+	sink  AlertSink
+	rules []SLORule
+	now   func() time.Time
+
+	mu      sync.Mutex
+	windows map[sloKey]*sloWindow
+}
+
+func newSLOHook(sink AlertSink, rules []SLORule, now func() time.Time) *sloHook {
+	return &sloHook{
+		sink:    sink,
+		rules:   append([]SLORule(nil), rules...),
+		now:     now,
+		windows: make(map[sloKey]*sloWindow),
+	}
+}
+
+// Levels возвращает уровни, для которых вызывается hook
+func (h *sloHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel, logrus.WarnLevel, logrus.InfoLevel}
+}
+
+// Fire учитывает запись в окнах правил и отправляет алерты
+func (h *sloHook) Fire(entry *logrus.Entry) error {
+	service, _ := entry.Data["service"].(string)
+	failed := entry.Level <= logrus.ErrorLevel
+	now := h.now()
+
+	var alerts []Alert
+	h.mu.Lock()
+	for i, rule := range h.rules {
+		if rule.Service != "" && rule.Service != service {
+			continue
+		}
+		key := sloKey{rule: i, service: service}
+		w := h.windows[key]
+		if w == nil {
+			w = &sloWindow{width: max(rule.Window/sloBuckets, 1)}
+			h.windows[key] = w
+		}
+		w.record(now, failed)
+
+		entries, errs := w.totals(now)
+		if entries == 0 || entries < rule.MinEntries {
+			continue
+		}
+		ratio := float64(errs) / float64(entries)
+		burn := ratio / (1 - rule.Objective)
+		if burn >= rule.BurnRate == w.firing {
+			continue
+		}
+		w.firing = !w.firing
+		alerts = append(alerts, Alert{
+			Rule:       rule.Name,
+			Service:    service,
+			Resolved:   !w.firing,
+			BurnRate:   burn,
+			ErrorRatio: ratio,
+			Errors:     errs,
+			Entries:    entries,
+			Window:     rule.Window,
+			Time:       now,
+		})
+	}
+	h.mu.Unlock()
+
+	for _, alert := range alerts {
+		h.sink.Alert(alert)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type alertRecorder struct {
+	alerts []Alert
+}
+
+func (r *alertRecorder) Alert(alert Alert) {
+	r.alerts = append(r.alerts, alert)
+}
+
+func fireSLO(t *testing.T, h *sloHook, service string, level logrus.Level, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		require.NoError(t, h.Fire(&logrus.Entry{Level: level, Data: logrus.Fields{"service": service}}))
+	}
+}
+
+func TestSLOHook_FiresAndResolves(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+	alerts := &alertRecorder{}
+	h := newSLOHook(alerts, []SLORule{{
+		Name:       "api-availability",
+		Service:    "api",
+		Objective:  0.99,
+		Window:     time.Minute,
+		BurnRate:   10,
+		MinEntries: 10,
+	}}, clock.Now)
+
+	// 5% ошибок - скорость расхода 5, ниже порога
+	fireSLO(t, h, "api", logrus.InfoLevel, 95)
+	fireSLO(t, h, "api", logrus.ErrorLevel, 5)
+	assert.Empty(t, alerts.alerts)
+
+	// 15 ошибок из 110 - скорость расхода выше 13
+	fireSLO(t, h, "api", logrus.ErrorLevel, 10)
+	require.Len(t, alerts.alerts, 1)
+	alert := alerts.alerts[0]
+	assert.Equal(t, "api-availability", alert.Rule)
+	assert.Equal(t, "api", alert.Service)
+	assert.False(t, alert.Resolved)
+	assert.Equal(t, 11, alert.Errors)
+	assert.Equal(t, 106, alert.Entries)
+	assert.InDelta(t, 10.38, alert.BurnRate, 0.01)
+
+	// Повторное превышение не шлет алерт
+	fireSLO(t, h, "api", logrus.ErrorLevel, 5)
+	require.Len(t, alerts.alerts, 1)
+
+	// Ошибки выходят из окна
+	clock.now = clock.now.Add(2 * time.Minute)
+	fireSLO(t, h, "api", logrus.InfoLevel, 20)
+	require.Len(t, alerts.alerts, 2)
+	assert.True(t, alerts.alerts[1].Resolved)
+	assert.Zero(t, alerts.alerts[1].Errors)
+}
+
+func TestSLOHook_PerServiceWindows(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+	alerts := &alertRecorder{}
+	h := newSLOHook(alerts, []SLORule{{Objective: 0.9, Window: time.Minute, BurnRate: 2, MinEntries: 4}}, clock.Now)
+
+	fireSLO(t, h, "billing", logrus.ErrorLevel, 1)
+	fireSLO(t, h, "orders", logrus.InfoLevel, 4)
+	fireSLO(t, h, "billing", logrus.InfoLevel, 2)
+	assert.Empty(t, alerts.alerts)
+
+	// В общем окне было бы 1 ошибка из 8, у billing - 1 из 4
+	fireSLO(t, h, "billing", logrus.WarnLevel, 1)
+	require.Len(t, alerts.alerts, 1)
+	assert.Equal(t, "billing", alerts.alerts[0].Service)
+}
+
+func TestLogger_SLOAlertsFromConfig(t *testing.T) {
+	alerts := &alertRecorder{}
+	logger, err := New(Config{
+		Level:     InfoLevel,
+		Writers:   []io.Writer{io.Discard},
+		AlertSink: alerts,
+		SLORules:  []SLORule{{Name: "checkout", Objective: 0.5, Window: time.Minute, BurnRate: 1.5, MinEntries: 4}},
+	})
+	require.NoError(t, err)
+
+	checkout := logger.WithService("checkout")
+	checkout.Info("ok")
+	checkout.Debug("below level")
+	checkout.Error("failed")
+	checkout.Error("failed")
+	checkout.Error("failed")
+
+	require.Len(t, alerts.alerts, 1)
+	assert.Equal(t, "checkout", alerts.alerts[0].Service)
+	assert.Equal(t, 4, alerts.alerts[0].Entries)
+	assert.Equal(t, 3, alerts.alerts[0].Errors)
+}
+
+func TestConfig_ValidateSLORules(t *testing.T) {
+	config := Config{
+		Writers:  []io.Writer{io.Discard},
+		SLORules: []SLORule{{Objective: 1, BurnRate: 0}},
+	}
+
+	err := config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "slo rule 0: objective must be between 0 and 1")
+	assert.Contains(t, err.Error(), "window must be positive")
+	assert.Contains(t, err.Error(), "alert sink is required")
+
+	logger, _ := newBufferLogger(t)
+	assert.Error(t, logger.EnableSLOAlerts(AlertFunc(func(Alert) {}), []SLORule{{Objective: 0.99}}))
+}
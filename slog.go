@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"github.com/sirupsen/logrus"
+)
+
+// slogHandler реализует slog.Handler поверх логгера
+type slogHandler struct {
+	logger *Logger
+	prefix string        // группы из WithGroup через точку
+	attrs  logrus.Fields // атрибуты из WithAttrs
+}
+
+// SlogHandler возвращает slog.Handler, который пишет записи log/slog через
+// этот логгер: с его уровнем, полями, редактированием и назначениями.
+// Атрибуты групп пишутся плоскими полями с именем через точку (http.method)
+//
+//	slog.SetDefault(slog.New(log.SlogHandler()))
+func (l *Logger) SlogHandler() slog.Handler {
+	l = l.orNop()
+	return &slogHandler{logger: l}
+}
+
+// fromSlogLevel переводит уровень slog в уровень логгера. Уровни ниже Debug
+// пишутся как Trace, промежуточные уровни округляются вниз
+func fromSlogLevel(level slog.Level) Level {
+	switch {
+	case level < slog.LevelDebug:
+		return TraceLevel
+	case level < slog.LevelInfo:
+		return DebugLevel
+	case level < slog.LevelWarn:
+		return InfoLevel
+	case level < slog.LevelError:
+		return WarnLevel
+	default:
+		return ErrorLevel
+	}
+}
+
+// Enabled проверяет уровень логгера с учетом детализации запроса из контекста
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	l := fromSlogLevel(level)
+	if h.logger.enabled(l) {
+		return true
+	}
+	return ctx != nil && l <= h.logger.contextFloor(ctx)
+}
+
+// Handle пишет запись slog
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	logger := h.logger
+	if ctx != nil && ctx != context.Background() {
+		logger = logger.WithContext(ctx)
+	}
+	level := fromSlogLevel(r.Level)
+	if !logger.enabled(level) {
+		return nil
+	}
+
+	fields := make(logrus.Fields, len(h.attrs)+r.NumAttrs()+3)
+	for k, v := range h.attrs {
+		fields[k] = v
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addSlogAttr(fields, h.prefix, a)
+		return true
+	})
+
+	entry := logger.withFields().WithFields(fields)
+	// Место вызова берется из записи, а не из стека обработчика
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		entry = entry.WithContext(withCallerFrame(entry.Context, frame))
+	}
+	if !r.Time.IsZero() {
+		entry = entry.WithTime(r.Time)
+	}
+	entry.Log(logrus.Level(level), r.Message)
+	return nil
+}
+
+// WithAttrs возвращает обработчик с постоянными атрибутами
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	merged := make(logrus.Fields, len(h.attrs)+len(attrs))
+	for k, v := range h.attrs {
+		merged[k] = v
+	}
+	for _, a := range attrs {
+		addSlogAttr(merged, h.prefix, a)
+	}
+	return &slogHandler{logger: h.logger, prefix: h.prefix, attrs: merged}
+}
+
+// WithGroup возвращает обработчик, который добавляет имя группы к ключам
+// последующих атрибутов
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{logger: h.logger, prefix: h.prefix + name + ".", attrs: h.attrs}
+}
+
+// addSlogAttr добавляет атрибут в поля, раскрывая группы
+func addSlogAttr(fields logrus.Fields, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		group := a.Value.Group()
+		if len(group) == 0 {
+			return
+		}
+		// Группа без имени раскрывается на текущем уровне
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range group {
+			addSlogAttr(fields, prefix, ga)
+		}
+		return
+	}
+	fields[prefix+a.Key] = a.Value.Any()
+}
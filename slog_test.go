@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromSlogLevel(t *testing.T) {
+	assert.Equal(t, TraceLevel, fromSlogLevel(slog.LevelDebug-1))
+	assert.Equal(t, DebugLevel, fromSlogLevel(slog.LevelDebug))
+	assert.Equal(t, InfoLevel, fromSlogLevel(slog.LevelInfo))
+	assert.Equal(t, InfoLevel, fromSlogLevel(slog.LevelInfo+2))
+	assert.Equal(t, WarnLevel, fromSlogLevel(slog.LevelWarn))
+	assert.Equal(t, ErrorLevel, fromSlogLevel(slog.LevelError))
+	assert.Equal(t, ErrorLevel, fromSlogLevel(slog.LevelError+4))
+}
+
+func TestLogger_SlogHandler(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	logger.SetLevel(InfoLevel)
+
+	slogger := slog.New(logger.WithService("api").SlogHandler()).
+		With("component", "router").
+		WithGroup("http")
+
+	slogger.Debug("hidden")
+	slogger.Warn("slow request",
+		"method", "GET",
+		slog.Group("client", "ip", "203.0.113.7"),
+		slog.Group("empty"),
+		slog.Duration("latency", 1500*time.Millisecond),
+		slog.Any("skip", nil),
+	)
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	entry := entries[0]
+	assert.Equal(t, "warning", entry["level"])
+	assert.Equal(t, "slow request", entry["msg"])
+	assert.Equal(t, "api", entry["service"])
+	assert.Equal(t, "router", entry["component"])
+	assert.Equal(t, "GET", entry["http.method"])
+	assert.Equal(t, "203.0.113.7", entry["http.client.ip"])
+	assert.Equal(t, float64(1500*time.Millisecond), entry["http.latency"])
+	assert.NotContains(t, entry, "http.empty")
+	assert.Contains(t, entry["file"], "slog_test.go")
+}
+
+func TestLogger_SlogHandlerContext(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	logger.SetLevel(InfoLevel)
+	slogger := slog.New(logger.SlogHandler())
+
+	ctx := ContextWithRequestID(ContextWithDebug(context.Background()), "req-1")
+	slogger.DebugContext(ctx, "request detail")
+	slogger.DebugContext(context.Background(), "hidden")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "request detail", entries[0]["msg"])
+	assert.Equal(t, "req-1", entries[0]["request_id"])
+}
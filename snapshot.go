@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrNoSnapshot возвращается Snapshot, если у логгера нет кольцевого файла
+var ErrNoSnapshot = errors.New("logger has no ring file to snapshot")
+
+// Snapshot пишет в w записи кольцевого файла-самописца от старых к новым,
+// не останавливая запись логов. Так после инцидента можно снять последние
+// минуты подробного лога с работающего процесса: в файл, в ответ HTTP
+// (GET /snapshot в AdminHandler) или в тикет поддержки
+func (l *Logger) Snapshot(w io.Writer) error {
+	l = l.orNop()
+	l.core.mu.RLock()
+	sinks := l.core.sinks
+	l.core.mu.RUnlock()
+
+	for _, s := range sinks {
+		if ring, ok := s.writer.(*ringFile); ok {
+			return ring.snapshot(w)
+		}
+	}
+	return ErrNoSnapshot
+}
@@ -0,0 +1,51 @@
+//go:build unix
+
+package logger
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_Snapshot(t *testing.T) {
+	logger, err := New(Config{
+		Level:        DebugLevel,
+		Writers:      []io.Writer{io.Discard},
+		RingFilePath: filepath.Join(t.TempDir(), "ring.log"),
+	})
+	require.NoError(t, err)
+	defer logger.Close()
+
+	logger.Debug("cache miss")
+	logger.Info("request served")
+
+	var buf bytes.Buffer
+	require.NoError(t, logger.Snapshot(&buf))
+	entries := decodeEntries(t, &buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "cache miss", entries[0]["msg"])
+	assert.Equal(t, "request served", entries[1]["msg"])
+
+	rec := httptest.NewRecorder()
+	logger.AdminHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/snapshot", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+	assert.Equal(t, buf.String(), rec.Body.String())
+}
+
+func TestLogger_SnapshotWithoutRing(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+
+	assert.ErrorIs(t, logger.Snapshot(io.Discard), ErrNoSnapshot)
+
+	rec := httptest.NewRecorder()
+	logger.AdminHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/snapshot", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
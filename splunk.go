@@ -0,0 +1,404 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultSplunkSourceType sourcetype по умолчанию: Splunk разбирает
+	// событие как JSON
+	defaultSplunkSourceType = "_json"
+
+	defaultSplunkBatchSize     = 500
+	defaultSplunkFlushInterval = time.Second
+	defaultSplunkQueueSize     = 10000
+	defaultSplunkMaxRetries    = 5
+	defaultSplunkRetryBackoff  = 200 * time.Millisecond
+	defaultSplunkTimeout       = 10 * time.Second
+
+	// splunkMaxBatchBytes предел размера пачки до сжатия: max_content_length
+	// HEC в старых версиях Splunk
+	splunkMaxBatchBytes = 1000000
+
+	// maxSplunkRetryBackoff предел паузы между повторами
+	maxSplunkRetryBackoff = 30 * time.Second
+
+	// splunkEventPath путь приема событий HEC
+	splunkEventPath = "/services/collector/event"
+)
+
+// SplunkConfig настройки вывода в Splunk HTTP Event Collector
+type SplunkConfig struct {
+	Address string `yaml:"address"` // адрес HEC, например https://splunk.internal:8088
+	Token   string `yaml:"token"`   // токен HEC, заголовок Authorization: Splunk <token>
+
+	// Index, Source и SourceType метаданные событий. Без Index и Source
+	// используются настройки токена, SourceType по умолчанию _json
+	Index      string `yaml:"index"`
+	Source     string `yaml:"source"`
+	SourceType string `yaml:"sourcetype"`
+	Host       string `yaml:"host"` // по умолчанию имя хоста
+	// Compression сжатие тела запроса: gzip (по умолчанию) или none
+	Compression string `yaml:"compression"`
+
+	BatchSize     int           `yaml:"batch_size"`     // событий в одном запросе, по умолчанию 500
+	FlushInterval time.Duration `yaml:"flush_interval"` // по умолчанию 1s
+	// QueueSize предел записей в памяти, ожидающих отправки. Записи сверх
+	// него отбрасываются. По умолчанию 10000
+	QueueSize    int           `yaml:"queue_size"`
+	MaxRetries   int           `yaml:"max_retries"`   // повторов запроса при 429, 5xx и сетевых ошибках, по умолчанию 5
+	RetryBackoff time.Duration `yaml:"retry_backoff"` // первая пауза перед повтором, дальше удваивается; по умолчанию 200ms
+	Timeout      time.Duration `yaml:"timeout"`       // таймаут запроса, по умолчанию 10s
+}
+
+// validate проверяет настройки Splunk
+func (c SplunkConfig) validate() error {
+	var errs []error
+	if c.Address == "" {
+		errs = append(errs, errors.New("splunk address is required"))
+	} else if u, err := url.Parse(c.Address); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("invalid splunk address: %s", c.Address))
+	}
+	if c.Token == "" {
+		errs = append(errs, errors.New("splunk token is required"))
+	}
+	switch c.Compression {
+	case "", "gzip", "none":
+	default:
+		errs = append(errs, fmt.Errorf("unsupported splunk compression: %s", c.Compression))
+	}
+	if c.BatchSize < 0 || c.QueueSize < 0 || c.MaxRetries < 0 || c.FlushInterval < 0 || c.RetryBackoff < 0 || c.Timeout < 0 {
+		errs = append(errs, errors.New("splunk limits must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// openSplunkSink создает назначение с фоновой отправкой в HEC
+func openSplunkSink(config Config) (*splunkConn, *sink, error) {
+	c := config.Splunk
+	if err := c.validate(); err != nil {
+		return nil, nil, err
+	}
+	inner, err := newFormatter(JSONFormat, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	hostname, _ := os.Hostname()
+	formatter := &splunkFormatter{inner: inner, meta: splunkEvent{
+		Host:       firstNonEmpty(c.Host, hostname),
+		Source:     c.Source,
+		SourceType: firstNonEmpty(c.SourceType, defaultSplunkSourceType),
+		Index:      c.Index,
+	}}
+	conn := newSplunkConn(c)
+	return conn, &sink{name: "splunk", writer: conn, formatter: formatter, binary: true}, nil
+}
+
+// splunkEvent событие HEC: метаданные и запись в JSON
+type splunkEvent struct {
+	Time       json.Number     `json:"time,omitempty"`
+	Host       string          `json:"host,omitempty"`
+	Source     string          `json:"source,omitempty"`
+	SourceType string          `json:"sourcetype,omitempty"`
+	Index      string          `json:"index,omitempty"`
+	Event      json.RawMessage `json:"event"`
+}
+
+// splunkFormatter заворачивает запись в JSON в событие HEC
+type splunkFormatter struct {
+	inner logrus.Formatter
+	meta  splunkEvent
+}
+
+// Format возвращает событие со временем записи в секундах с миллисекундами
+func (f *splunkFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	doc, err := f.inner.Format(entry)
+	if err != nil {
+		return nil, err
+	}
+	event := f.meta
+	ms := entry.Time.UnixMilli()
+	event.Time = json.Number(fmt.Sprintf("%d.%03d", ms/1000, ms%1000))
+	event.Event = bytes.TrimRight(doc, "\n")
+	out, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// splunkConn копит события в ограниченной очереди и отправляет их пачками
+// в HEC из фоновой горутины. Ошибки отправки возвращаются следующим
+// вызовом Write, как у вывода в Elasticsearch
+type splunkConn struct {
+	config   SplunkConfig
+	eventURL string
+	client   *http.Client
+
+	queue chan []byte
+	flush chan chan struct{}
+	done  chan struct{}
+
+	mu      sync.Mutex
+	err     error
+	dropped int
+	closed  bool
+}
+
+// newSplunkConn заполняет значения по умолчанию и запускает отправку
+func newSplunkConn(c SplunkConfig) *splunkConn {
+	if c.Compression == "" {
+		c.Compression = "gzip"
+	}
+	if c.BatchSize == 0 {
+		c.BatchSize = defaultSplunkBatchSize
+	}
+	if c.FlushInterval == 0 {
+		c.FlushInterval = defaultSplunkFlushInterval
+	}
+	if c.QueueSize == 0 {
+		c.QueueSize = defaultSplunkQueueSize
+	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = defaultSplunkMaxRetries
+	}
+	if c.RetryBackoff == 0 {
+		c.RetryBackoff = defaultSplunkRetryBackoff
+	}
+	if c.Timeout == 0 {
+		c.Timeout = defaultSplunkTimeout
+	}
+	conn := &splunkConn{
+		config:   c,
+		eventURL: strings.TrimRight(c.Address, "/") + splunkEventPath,
+		client:   &http.Client{Timeout: c.Timeout},
+		queue:    make(chan []byte, c.QueueSize),
+		flush:    make(chan chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go conn.run()
+	return conn
+}
+
+// Write ставит событие в очередь. При заполненной очереди событие
+// отбрасывается, число отброшенных событий сообщается ошибкой
+func (c *splunkConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return 0, errors.New("splunk output is closed")
+	}
+	select {
+	case c.queue <- append([]byte(nil), p...):
+	default:
+		c.dropped++
+	}
+	return len(p), c.takeErrLocked()
+}
+
+// takeErrLocked возвращает накопленную ошибку отправки и сбрасывает ее
+func (c *splunkConn) takeErrLocked() error {
+	err := c.err
+	if c.dropped > 0 {
+		err = errors.Join(err, fmt.Errorf("splunk queue is full: %d entries dropped", c.dropped))
+		c.dropped = 0
+	}
+	c.err = nil
+	return err
+}
+
+// setErr запоминает ошибку фоновой отправки
+func (c *splunkConn) setErr(err error) {
+	c.mu.Lock()
+	c.err = errors.Join(c.err, err)
+	c.mu.Unlock()
+}
+
+// run собирает пачки из очереди и отправляет их по числу событий, по
+// размеру, по таймеру и по запросу Sync
+func (c *splunkConn) run() {
+	defer close(c.done)
+	ticker := time.NewTicker(c.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([][]byte, 0, c.config.BatchSize)
+	size := 0
+	send := func() {
+		if len(batch) > 0 {
+			c.send(batch)
+			batch = batch[:0]
+			size = 0
+		}
+	}
+	add := func(event []byte) {
+		if size+len(event) > splunkMaxBatchBytes {
+			send()
+		}
+		batch = append(batch, event)
+		size += len(event)
+		if len(batch) >= c.config.BatchSize {
+			send()
+		}
+	}
+	for {
+		select {
+		case event, ok := <-c.queue:
+			if !ok {
+				send()
+				return
+			}
+			add(event)
+		case <-ticker.C:
+			send()
+		case ack := <-c.flush:
+			for drained := false; !drained; {
+				select {
+				case event := <-c.queue:
+					add(event)
+				default:
+					drained = true
+				}
+			}
+			send()
+			close(ack)
+		}
+	}
+}
+
+// send отправляет пачку, повторяя ее при 429, 5xx и сетевых ошибках с
+// экспоненциальной паузой
+func (c *splunkConn) send(events [][]byte) {
+	body, err := c.encode(events)
+	if err != nil {
+		c.setErr(fmt.Errorf("splunk hec: %w", err))
+		return
+	}
+	backoff := c.config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := c.post(body, len(events))
+		if retry == nil {
+			if err != nil {
+				c.setErr(err)
+			}
+			return
+		}
+		if attempt == c.config.MaxRetries {
+			c.setErr(fmt.Errorf("splunk hec: %d entries dropped after %d retries: %w", len(events), attempt, retry))
+			return
+		}
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxSplunkRetryBackoff)
+	}
+}
+
+// encode склеивает события и сжимает их, если сжатие включено
+func (c *splunkConn) encode(events [][]byte) ([]byte, error) {
+	data := bytes.Join(events, nil)
+	if c.config.Compression != "gzip" {
+		return data, nil
+	}
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	if _, err := gz.Write(data); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// splunkResponse ответ HEC. InvalidEventNumber - номер первого события,
+// которое HEC не смог разобрать; события до него приняты
+type splunkResponse struct {
+	Text               string `json:"text"`
+	Code               int    `json:"code"`
+	InvalidEventNumber *int   `json:"invalid-event-number"`
+}
+
+// post отправляет пачку из count событий одним запросом. retry - причина
+// повторить запрос, err - ошибка, которую повторять бесполезно
+func (c *splunkConn) post(body []byte, count int) (retry, err error) {
+	req, err := http.NewRequest(http.MethodPost, c.eventURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Splunk "+c.config.Token)
+	if c.config.Compression == "gzip" {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err, nil
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return fmt.Errorf("unexpected status: %s", resp.Status), nil
+	}
+	if resp.StatusCode < 300 {
+		return nil, nil
+	}
+
+	var result splunkResponse
+	if json.Unmarshal(data, &result) != nil || result.Text == "" {
+		return nil, fmt.Errorf("splunk hec: unexpected status %s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+	rejected := count
+	if n := result.InvalidEventNumber; n != nil && *n >= 0 && *n < count {
+		rejected = count - *n
+	}
+	return nil, fmt.Errorf("splunk hec: %d entries rejected: %s (code %d)", rejected, result.Text, result.Code)
+}
+
+// Sync отправляет накопленные события и возвращает ошибки отправки
+func (c *splunkConn) Sync() error {
+	ack := make(chan struct{})
+	select {
+	case c.flush <- ack:
+		<-ack
+	case <-c.done:
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.takeErrLocked()
+}
+
+// Reopen ничего не делает: каждый запрос открывает соединение заново
+// при необходимости
+func (c *splunkConn) Reopen() error {
+	return nil
+}
+
+// Close отправляет события из очереди и останавливает отправку
+func (c *splunkConn) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	close(c.queue)
+	c.mu.Unlock()
+
+	<-c.done
+	c.client.CloseIdleConnections()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.takeErrLocked()
+}
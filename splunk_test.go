@@ -0,0 +1,215 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hecServer тестовый HEC: запоминает события и отвечает статусом из respond
+type hecServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests int
+	events   []splunkEvent
+	auth     string
+	encoding string
+	respond  func(request int) (int, string)
+}
+
+func newHECServer(t *testing.T, respond func(request int) (int, string)) *hecServer {
+	s := &hecServer{respond: respond}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *hecServer) handle(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != splunkEventPath {
+		http.NotFound(w, r)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	s.auth = r.Header.Get("Authorization")
+	s.encoding = r.Header.Get("Content-Encoding")
+	if s.respond != nil {
+		if status, body := s.respond(s.requests); status != http.StatusOK {
+			w.WriteHeader(status)
+			fmt.Fprint(w, body)
+			return
+		}
+	}
+
+	var body io.Reader = r.Body
+	if s.encoding == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, `{"text":"Invalid data format","code":6}`, http.StatusBadRequest)
+			return
+		}
+		body = gz
+	}
+	dec := json.NewDecoder(body)
+	for dec.More() {
+		var event splunkEvent
+		if err := dec.Decode(&event); err != nil {
+			http.Error(w, `{"text":"Invalid data format","code":6}`, http.StatusBadRequest)
+			return
+		}
+		s.events = append(s.events, event)
+	}
+	fmt.Fprint(w, `{"text":"Success","code":0}`)
+}
+
+func (s *hecServer) received() []splunkEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]splunkEvent(nil), s.events...)
+}
+
+func TestSplunkOutput(t *testing.T) {
+	server := newHECServer(t, nil)
+
+	log, err := New(Config{
+		Level:  InfoLevel,
+		Output: SplunkOutput,
+		Splunk: SplunkConfig{
+			Address: server.URL,
+			Token:   "secret",
+			Index:   "orders",
+			Source:  "api-server",
+			Host:    "web-1",
+		},
+	})
+	require.NoError(t, err)
+
+	log.WithField("order_id", 42).Info("order created")
+	log.Warn("payment delayed")
+	require.NoError(t, log.Sync())
+
+	events := server.received()
+	require.Len(t, events, 2)
+	assert.Equal(t, "web-1", events[0].Host)
+	assert.Equal(t, "api-server", events[0].Source)
+	assert.Equal(t, "_json", events[0].SourceType)
+	assert.Equal(t, "orders", events[0].Index)
+	assert.Regexp(t, `^\d+\.\d{3}$`, events[0].Time.String())
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(events[0].Event, &doc))
+	assert.Equal(t, "order created", doc["msg"])
+	assert.EqualValues(t, 42, doc["order_id"])
+	assert.Equal(t, "Splunk secret", server.auth)
+	assert.Equal(t, "gzip", server.encoding)
+	require.NoError(t, log.Close())
+}
+
+func TestSplunkOutput_FatalFlushesQueue(t *testing.T) {
+	server := newHECServer(t, nil)
+	code := stubExit(t)
+
+	log, err := New(Config{
+		Level:  InfoLevel,
+		Output: SplunkOutput,
+		Splunk: SplunkConfig{Address: server.URL, Token: "secret", FlushInterval: time.Hour},
+	})
+	require.NoError(t, err)
+
+	log.Info("queued")
+	log.Fatal("shutting down")
+
+	assert.Equal(t, 1, *code)
+	events := server.received()
+	require.Len(t, events, 2)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(events[1].Event, &doc))
+	assert.Equal(t, "shutting down", doc["msg"])
+}
+
+func TestSplunkFormatter_Time(t *testing.T) {
+	inner, err := newFormatter(JSONFormat, false)
+	require.NoError(t, err)
+	f := &splunkFormatter{inner: inner, meta: splunkEvent{Host: "web-1"}}
+	entry := logrus.NewEntry(logrus.New())
+	entry.Message = "order created"
+	entry.Time = time.Unix(1700000000, 5_000_000)
+	data, err := f.Format(entry)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"time":1700000000.005,"host":"web-1"`)
+	assert.True(t, bytes.HasSuffix(data, []byte("}\n")))
+}
+
+func TestSplunkConn_Retry(t *testing.T) {
+	server := newHECServer(t, func(request int) (int, string) {
+		if request == 1 {
+			return http.StatusServiceUnavailable, `{"text":"Server is busy","code":9}`
+		}
+		return http.StatusOK, ""
+	})
+	conn := newSplunkConn(SplunkConfig{Address: server.URL, Token: "t", Compression: "none", RetryBackoff: time.Millisecond})
+
+	for i := 0; i < 3; i++ {
+		_, err := conn.Write([]byte(fmt.Sprintf("{\"event\":{\"n\":%d}}\n", i)))
+		require.NoError(t, err)
+	}
+	require.NoError(t, conn.Sync())
+	assert.Len(t, server.received(), 3)
+	assert.Empty(t, server.encoding)
+	require.NoError(t, conn.Close())
+	assert.Equal(t, 2, server.requests)
+}
+
+func TestSplunkConn_Rejected(t *testing.T) {
+	server := newHECServer(t, func(request int) (int, string) {
+		return http.StatusBadRequest, `{"text":"Incorrect index","code":7,"invalid-event-number":1}`
+	})
+	conn := newSplunkConn(SplunkConfig{Address: server.URL, Token: "t", RetryBackoff: time.Millisecond})
+
+	for i := 0; i < 3; i++ {
+		_, err := conn.Write([]byte("{\"event\":{}}\n"))
+		require.NoError(t, err)
+	}
+	// Отклоненные запросы не повторяются
+	assert.ErrorContains(t, conn.Sync(), "splunk hec: 2 entries rejected: Incorrect index (code 7)")
+	require.NoError(t, conn.Close())
+	assert.Equal(t, 1, server.requests)
+}
+
+func TestSplunkConn_RetriesExhausted(t *testing.T) {
+	server := newHECServer(t, func(int) (int, string) { return http.StatusInternalServerError, "" })
+	conn := newSplunkConn(SplunkConfig{Address: server.URL, Token: "t", MaxRetries: 2, RetryBackoff: time.Millisecond})
+
+	_, err := conn.Write([]byte("{\"event\":{}}\n"))
+	require.NoError(t, err)
+	assert.ErrorContains(t, conn.Close(), "splunk hec: 1 entries dropped after 2 retries")
+	assert.Equal(t, 3, server.requests)
+}
+
+func TestSplunkConfig_Validate(t *testing.T) {
+	err := Config{
+		Output: SplunkOutput,
+		Splunk: SplunkConfig{Address: "splunk.internal:8088", Compression: "zstd", BatchSize: -1},
+	}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid splunk address: splunk.internal:8088")
+	assert.Contains(t, err.Error(), "splunk token is required")
+	assert.Contains(t, err.Error(), "unsupported splunk compression: zstd")
+	assert.Contains(t, err.Error(), "splunk limits must not be negative")
+
+	err = Config{Output: SplunkOutput}.Validate()
+	assert.ErrorContains(t, err, "splunk address is required")
+}
@@ -0,0 +1,156 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+)
+
+// defaultSpoolSize размер очереди сетевого вывода по умолчанию
+const defaultSpoolSize = 1024
+
+// errSpoolFull запись отброшена: очередь сетевого вывода заполнена,
+// а контекст записи истек
+var errSpoolFull = errors.New("network output spool is full, entry dropped")
+
+// contextWriter назначение с очередью: конвейер ставит в нее запись и не
+// ждет отправки, пока держит блокировку logrus
+type contextWriter interface {
+	enqueue(ctx context.Context, p []byte) error
+}
+
+// spoolItem запись в очереди и канал для результата. Запись без канала
+// никто не ждет, ее ошибка возвращается следующей записью
+type spoolItem struct {
+	data   []byte
+	result chan error
+}
+
+// spoolConn сетевое соединение с очередью: записи отправляет фоновая
+// горутина. Write ждет отправки, enqueue только ставит запись в очередь:
+// запись с контекстом ждет места в очереди не дольше дедлайна
+type spoolConn struct {
+	logFile
+
+	queue chan spoolItem
+	done  chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+
+	errMu sync.Mutex
+	err   error
+}
+
+// newSpoolConn оборачивает соединение в очередь из size записей
+func newSpoolConn(conn logFile, size int) *spoolConn {
+	if size <= 0 {
+		size = defaultSpoolSize
+	}
+	c := &spoolConn{
+		logFile: conn,
+		queue:   make(chan spoolItem, size),
+		done:    make(chan struct{}),
+	}
+	go c.run()
+	return c
+}
+
+// run отправляет записи из очереди. Элемент без данных - метка Sync: он
+// получает ошибку предыдущих записей
+func (c *spoolConn) run() {
+	defer close(c.done)
+	for item := range c.queue {
+		if item.data == nil {
+			item.result <- c.takeErr()
+			continue
+		}
+		_, err := c.logFile.Write(item.data)
+		if item.result != nil {
+			item.result <- err
+		} else if err != nil {
+			c.errMu.Lock()
+			c.err = err
+			c.errMu.Unlock()
+		}
+	}
+}
+
+// takeErr возвращает и сбрасывает ошибку отправки из очереди
+func (c *spoolConn) takeErr() error {
+	c.errMu.Lock()
+	defer c.errMu.Unlock()
+	err := c.err
+	c.err = nil
+	return err
+}
+
+// Write отправляет запись и ждет результата
+func (c *spoolConn) Write(p []byte) (int, error) {
+	item := spoolItem{data: p, result: make(chan error, 1)}
+	if err := c.put(context.Background(), item); err != nil {
+		return 0, err
+	}
+	if err := <-item.result; err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// enqueue ставит копию записи в очередь и не ждет отправки. Место в
+// очереди запись с контекстом ждет до дедлайна, после чего отбрасывается.
+// Возвращает ошибку отправки предыдущих записей
+func (c *spoolConn) enqueue(ctx context.Context, p []byte) error {
+	// Запись отправляется после возврата, когда p уже переиспользован
+	item := spoolItem{data: append([]byte(nil), p...)}
+	if err := c.put(ctx, item); err != nil {
+		return err
+	}
+	return c.takeErr()
+}
+
+// Sync ждет отправки записей, стоящих в очереди, и возвращает их ошибку
+func (c *spoolConn) Sync() error {
+	item := spoolItem{result: make(chan error, 1)}
+	if err := c.put(context.Background(), item); err != nil {
+		return err
+	}
+	return errors.Join(<-item.result, c.logFile.Sync())
+}
+
+// put ставит элемент в очередь, ожидая места не дольше ctx
+func (c *spoolConn) put(ctx context.Context, item spoolItem) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return net.ErrClosed
+	}
+	// Свободное место в очереди занимается и при истекшем контексте
+	select {
+	case c.queue <- item:
+		return nil
+	default:
+	}
+	select {
+	case c.queue <- item:
+		return nil
+	case <-ctx.Done():
+		return errSpoolFull
+	}
+}
+
+// Close отправляет записи из очереди и закрывает соединение
+func (c *spoolConn) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	close(c.queue)
+	c.mu.Unlock()
+
+	<-c.done
+	return c.logFile.Close()
+}
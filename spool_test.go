@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stalledConn сетевое соединение, запись в которое ждет release
+type stalledConn struct {
+	lockedBuffer
+	release chan struct{}
+	closed  bool
+}
+
+func newStalledConn() *stalledConn {
+	return &stalledConn{release: make(chan struct{})}
+}
+
+func (c *stalledConn) Write(p []byte) (int, error) {
+	<-c.release
+	return c.lockedBuffer.Write(p)
+}
+
+func (c *stalledConn) Sync() error   { return nil }
+func (c *stalledConn) Reopen() error { return nil }
+func (c *stalledConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+// failingConn сетевое соединение, запись в которое всегда падает
+type failingConn struct{ stalledConn }
+
+func (c *failingConn) Write(p []byte) (int, error) {
+	return 0, errors.New("connection refused")
+}
+
+func TestSpoolConn_Enqueue(t *testing.T) {
+	conn := newStalledConn()
+	spool := newSpoolConn(conn, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	require.NoError(t, spool.enqueue(ctx, []byte("first\n")))
+	assert.Less(t, time.Since(start), time.Second)
+
+	// Первая запись занимает отправку, вторая - единственное место в
+	// очереди, третьей места нет
+	require.Eventually(t, func() bool { return len(spool.queue) == 0 }, time.Second, time.Millisecond)
+	require.NoError(t, spool.enqueue(ctx, []byte("second\n")))
+	assert.ErrorIs(t, spool.enqueue(ctx, []byte("third\n")), errSpoolFull)
+
+	close(conn.release)
+	require.NoError(t, spool.Close())
+	assert.Equal(t, "first\nsecond\n", conn.String())
+	assert.True(t, conn.closed)
+
+	_, err := spool.Write([]byte("late\n"))
+	assert.Error(t, err)
+}
+
+func TestSpoolConn_EnqueueError(t *testing.T) {
+	spool := newSpoolConn(&failingConn{}, 0)
+	defer spool.Close()
+
+	require.NoError(t, spool.enqueue(context.Background(), []byte("first\n")))
+	assert.ErrorContains(t, spool.Sync(), "connection refused")
+	assert.NoError(t, spool.Sync())
+
+	// Ошибка отправки из очереди возвращается следующей записью
+	require.NoError(t, spool.enqueue(context.Background(), []byte("second\n")))
+	assert.Eventually(t, func() bool {
+		return spool.enqueue(context.Background(), []byte("third\n")) != nil
+	}, time.Second, time.Millisecond)
+}
+
+func TestSpoolConn_Write(t *testing.T) {
+	conn := newStalledConn()
+	close(conn.release)
+	spool := newSpoolConn(conn, 0)
+	defer spool.Close()
+
+	n, err := spool.Write([]byte("entry\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 6, n)
+	assert.Equal(t, "entry\n", conn.String())
+}
+
+func TestLogger_InfoCtx_Deadline(t *testing.T) {
+	log, _ := newBufferLogger(t)
+	conn := newStalledConn()
+	spool := newSpoolConn(conn, 0)
+	setTestSink(log, spool, &logrus.JSONFormatter{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	log.InfoCtx(ctx, "request handled")
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Empty(t, conn.String())
+
+	close(conn.release)
+	require.NoError(t, spool.Close())
+	entries := decodeEntries(t, &conn.lockedBuffer)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "request handled", entries[0]["msg"])
+}
+
+func TestLogger_InfoCtx_SlowBackgroundWrite(t *testing.T) {
+	log, _ := newBufferLogger(t)
+	conn := newStalledConn()
+	spool := newSpoolConn(conn, 0)
+	setTestSink(log, spool, &logrus.JSONFormatter{})
+
+	// Запись без контекста не держит блокировку logrus, пока сервер не ответит
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		log.Info("background")
+	}()
+	<-written
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	log.InfoCtx(ctx, "request handled")
+	assert.Less(t, time.Since(start), time.Second)
+
+	close(conn.release)
+	require.NoError(t, spool.Close())
+	entries := decodeEntries(t, &conn.lockedBuffer)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "background", entries[0]["msg"])
+	assert.Equal(t, "request handled", entries[1]["msg"])
+}
+
+func TestConfig_Validate_SpoolSize(t *testing.T) {
+	err := Config{Output: ConsoleOutput, SpoolSize: -1}.Validate()
+	assert.ErrorContains(t, err, "spool size must not be negative")
+}
@@ -0,0 +1,34 @@
+package logger
+
+import (
+	"log"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// stdLogWriter пишет каждое сообщение *log.Logger одной записью. log.Logger
+// вызывает Write один раз на сообщение, поэтому многострочные сообщения
+// (например, стек паники из http.Server) не разбиваются
+type stdLogWriter struct {
+	logger *Logger
+	level  Level
+}
+
+// StdLogger возвращает *log.Logger стандартной библиотеки, сообщения которого
+// пишутся записями уровня level, например для http.Server.ErrorLog:
+//
+//	srv := &http.Server{ErrorLog: log.WithService("http").StdLogger(logger.WarnLevel)}
+func (l *Logger) StdLogger(level Level) *log.Logger {
+	return log.New(&stdLogWriter{logger: l, level: level}, "", 0)
+}
+
+// Write пишет сообщение без завершающего перевода строки
+func (w *stdLogWriter) Write(p []byte) (int, error) {
+	if !w.logger.enabled(w.level) {
+		return len(p), nil
+	}
+	msg := strings.TrimSuffix(string(p), "\n")
+	w.logger.withFields().Log(logrus.Level(w.level), msg)
+	return len(p), nil
+}
@@ -0,0 +1,26 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_StdLogger(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	logger.SetLevel(InfoLevel)
+
+	std := logger.WithService("http").StdLogger(WarnLevel)
+	std.Printf("http: TLS handshake error from %s: EOF", "10.0.0.1:5000")
+	std.Print("panic serving 10.0.0.1: boom\ngoroutine 1 [running]:")
+
+	logger.StdLogger(DebugLevel).Print("hidden")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "warning", entries[0]["level"])
+	assert.Equal(t, "http: TLS handshake error from 10.0.0.1:5000: EOF", entries[0]["msg"])
+	assert.Equal(t, "http", entries[0]["service"])
+	assert.Equal(t, "panic serving 10.0.0.1: boom\ngoroutine 1 [running]:", entries[1]["msg"])
+}
@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultPreviewBytes сколько первых байт большого поля попадает в описание
+const defaultPreviewBytes = 64
+
+// FieldSummary краткое описание поля, которое превысило допустимый размер
+type FieldSummary struct {
+	Type    string `json:"type"`
+	Length  int    `json:"length"`
+	SHA256  string `json:"sha256"`
+	Preview string `json:"preview"`
+}
+
+// String форматирует описание для текстового вывода
+func (s FieldSummary) String() string {
+	return fmt.Sprintf("<%s len=%d sha256=%s preview=%q>", s.Type, s.Length, s.SHA256, s.Preview)
+}
+
+// fieldLimits ограничения размера полей записи
+type fieldLimits struct {
+	maxSize      int // 0 - без ограничения
+	previewBytes int
+}
+
+// newFieldLimits возвращает ограничения размера полей из конфигурации
+func newFieldLimits(config Config) *fieldLimits {
+	limits := &fieldLimits{maxSize: config.MaxFieldSize, previewBytes: config.FieldPreviewBytes}
+	if limits.previewBytes == 0 {
+		limits.previewBytes = defaultPreviewBytes
+	}
+	return limits
+}
+
+// summarizeEntry заменяет слишком большие поля записи их описанием
+func (c *core) summarizeEntry(entry *logrus.Entry) *logrus.Entry {
+	limits := c.fieldLimits.Load()
+	if limits == nil || limits.maxSize <= 0 {
+		return entry
+	}
+
+	return transformFields(entry, func(key string, value interface{}) (interface{}, bool) {
+		return limits.summarizeValue(value)
+	})
+}
+
+// summarizeValue возвращает описание значения, если его размер превышает лимит
+func (limits *fieldLimits) summarizeValue(v interface{}) (interface{}, bool) {
+	var raw []byte
+	switch typed := v.(type) {
+	case string:
+		if len(typed) <= limits.maxSize {
+			return v, false
+		}
+		raw = []byte(typed)
+	case []byte:
+		raw = typed
+	case error:
+		text, ok := safeText(typed.Error)
+		if !ok || len(text) <= limits.maxSize {
+			return v, false
+		}
+		raw = []byte(text)
+	case fmt.Stringer:
+		text, ok := safeText(typed.String)
+		if !ok || len(text) <= limits.maxSize {
+			return v, false
+		}
+		raw = []byte(text)
+	default:
+		if !isComposite(v) {
+			return v, false
+		}
+		var err error
+		// Ошибки кодирования обработает safeFormat
+		if raw, err = safeMarshal(v); err != nil {
+			return v, false
+		}
+	}
+
+	if len(raw) <= limits.maxSize {
+		return v, false
+	}
+
+	sum := sha256.Sum256(raw)
+	preview := raw[:min(len(raw), limits.previewBytes)]
+
+	return FieldSummary{
+		Type:    fmt.Sprintf("%T", v),
+		Length:  len(raw),
+		SHA256:  hex.EncodeToString(sum[:]),
+		Preview: strings.ToValidUTF8(string(preview), ""),
+	}, true
+}
+
+// safeText возвращает текст ошибки или Stringer. При панике значение
+// остается как есть, ее обработает safeFormat
+func safeText(text func() string) (s string, ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	return text(), true
+}
+
+// isComposite проверяет, может ли значение занимать много места
+func isComposite(v interface{}) bool {
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Pointer, reflect.Interface:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_LargeFieldSummary(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	logger.core.fieldLimits.Store(&fieldLimits{maxSize: 32, previewBytes: 8})
+
+	payload := strings.Repeat("a", 100)
+	logger.WithFields(map[string]interface{}{
+		"payload": payload,
+		"items":   make([]int, 50),
+		"small":   "ok",
+		"count":   12345,
+	}).Info("message")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+
+	sum := sha256.Sum256([]byte(payload))
+	assert.Equal(t, map[string]interface{}{
+		"type":    "string",
+		"length":  float64(100),
+		"sha256":  hex.EncodeToString(sum[:]),
+		"preview": "aaaaaaaa",
+	}, entries[0]["payload"])
+
+	items := entries[0]["items"].(map[string]interface{})
+	assert.Equal(t, "[]int", items["type"])
+	assert.Equal(t, "[0,0,0,0", items["preview"])
+
+	assert.Equal(t, "ok", entries[0]["small"])
+	assert.Equal(t, float64(12345), entries[0]["count"])
+}
+
+// longStringer значение с длинным текстовым представлением
+type longStringer struct{ n int }
+
+func (s longStringer) String() string { return strings.Repeat("s", s.n) }
+
+func TestLogger_LargeFieldSummary_ErrorAndStringer(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	logger.core.fieldLimits.Store(&fieldLimits{maxSize: 32, previewBytes: 8})
+
+	logger.WithError(errors.New(strings.Repeat("e", 100))).WithFields(map[string]interface{}{
+		"state":  longStringer{n: 100},
+		"cause":  errors.New("timeout"),
+		"broken": panicStringer{},
+	}).Error("failed")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+
+	errSummary := entries[0]["error"].(map[string]interface{})
+	assert.Equal(t, "*errors.errorString", errSummary["type"])
+	assert.Equal(t, float64(100), errSummary["length"])
+	assert.Equal(t, "eeeeeeee", errSummary["preview"])
+
+	state := entries[0]["state"].(map[string]interface{})
+	assert.Equal(t, "logger.longStringer", state["type"])
+	assert.Equal(t, float64(100), state["length"])
+	assert.Equal(t, "ssssssss", state["preview"])
+
+	assert.Equal(t, "timeout", entries[0]["cause"])
+	assert.Contains(t, entries[0], "broken")
+}
+
+func TestFieldSummary_String(t *testing.T) {
+	summary := FieldSummary{Type: "string", Length: 100, SHA256: "abc", Preview: "aaa"}
+	assert.Equal(t, `<string len=100 sha256=abc preview="aaa">`, summary.String())
+}
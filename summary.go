@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SummaryKeyField поле записи, по значению которого Summary считает записи
+const SummaryKeyField = "summary_key"
+
+// maxSummaryErrors сколько текстов ошибок сохраняет Summary
+const maxSummaryErrors = 100
+
+// Summary накапливает итоги пакетной задачи по записям ее логгера: число записей
+// по уровням и по ключам, тексты ошибок и длительность. Итог записывается одной
+// записью при вызове Finish
+type Summary struct {
+	logger *Logger
+	job    string
+	start  time.Time
+
+	mu          sync.Mutex
+	counts      map[string]int64
+	levels      map[string]int64
+	errors      []string
+	errorsTotal int64
+	finished    bool
+}
+
+// StartSummary начинает сбор итогов пакетной задачи. Записи логгера Summary.Logger
+// и его дочерних логгеров учитываются в итогах
+func (l *Logger) StartSummary(job string) *Summary {
+	return &Summary{
+		logger: l,
+		job:    job,
+		start:  time.Now(),
+		counts: make(map[string]int64),
+		levels: make(map[string]int64),
+	}
+}
+
+// Logger возвращает логгер задачи, записи которого попадают в итоги
+func (s *Summary) Logger() *Logger {
+	child := s.logger.with(logrus.Fields{"job": s.job})
+	child.summary = s
+	return child
+}
+
+// Count увеличивает счетчик key на n
+func (s *Summary) Count(key string, n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts[key] += n
+}
+
+// record учитывает запись в итогах. Записи с полем summary_key считаются по его
+// значению, записи уровня Error и серьезнее - как ошибки
+func (s *Summary) record(entry *logrus.Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.levels[entry.Level.String()]++
+	if key, ok := entry.Data[SummaryKeyField]; ok {
+		s.counts[fmt.Sprint(key)]++
+	}
+	if Level(entry.Level) > ErrorLevel {
+		return
+	}
+
+	s.errorsTotal++
+	if len(s.errors) >= maxSummaryErrors {
+		return
+	}
+	msg := entry.Message
+	if err, ok := entry.Data[logrus.ErrorKey].(error); ok {
+		msg += ": " + err.Error()
+	}
+	s.errors = append(s.errors, msg)
+}
+
+// Finish записывает итоговую запись batch summary: Info, если ошибок не было,
+// и Warn в противном случае. Повторные вызовы ничего не делают
+func (s *Summary) Finish() {
+	s.mu.Lock()
+	if s.finished {
+		s.mu.Unlock()
+		return
+	}
+	s.finished = true
+
+	fields := logrus.Fields{
+		"job":          s.job,
+		"duration_ms":  time.Since(s.start).Milliseconds(),
+		"counts":       copyCounts(s.counts),
+		"levels":       copyCounts(s.levels),
+		"errors_total": s.errorsTotal,
+	}
+	if len(s.errors) > 0 {
+		fields["errors"] = append([]string(nil), s.errors...)
+	}
+	failed := s.errorsTotal > 0
+	s.mu.Unlock()
+
+	entry := s.logger.withFields().WithFields(fields)
+	if failed {
+		entry.Warn("batch summary")
+	} else {
+		entry.Info("batch summary")
+	}
+}
+
+// copyCounts копирует счетчики, чтобы запись не зависела от дальнейших изменений
+func copyCounts(counts map[string]int64) map[string]int64 {
+	out := make(map[string]int64, len(counts))
+	for k, v := range counts {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummary_Finish(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	summary := logger.StartSummary("import")
+	log := summary.Logger()
+	log.WithField(SummaryKeyField, "imported").Info("row imported")
+	log.WithField(SummaryKeyField, "imported").Debug("row imported")
+	log.WithService("parser").WithField(SummaryKeyField, "skipped").Info("row skipped")
+	log.WithError(errors.New("bad row")).Error("row failed")
+	summary.Count("files", 2)
+	summary.Finish()
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 5)
+	assert.Equal(t, "import", entries[0]["job"])
+
+	result := entries[4]
+	assert.Equal(t, "batch summary", result["msg"])
+	assert.Equal(t, "warning", result["level"])
+	assert.Equal(t, "import", result["job"])
+	assert.Equal(t, map[string]interface{}{"imported": 2.0, "skipped": 1.0, "files": 2.0}, result["counts"])
+	assert.Equal(t, map[string]interface{}{"info": 2.0, "debug": 1.0, "error": 1.0}, result["levels"])
+	assert.Equal(t, 1.0, result["errors_total"])
+	assert.Equal(t, []interface{}{"row failed: bad row"}, result["errors"])
+	assert.Contains(t, result, "duration_ms")
+}
+
+func TestSummary_NoErrors(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	summary := logger.StartSummary("cleanup")
+	summary.Logger().Info("done")
+	summary.Finish()
+	summary.Finish()
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "info", entries[1]["level"])
+	assert.NotContains(t, entries[1], "errors")
+}
+
+func TestSummary_SkipsDisabledLevels(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	logger.SetLevel(InfoLevel)
+
+	summary := logger.StartSummary("import")
+	summary.Logger().WithField(SummaryKeyField, "row").Debug("dropped")
+	summary.Finish()
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Empty(t, entries[0]["counts"])
+}
+
+func TestSummary_ErrorsCapped(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	summary := logger.StartSummary("import")
+	log := summary.Logger()
+	for i := 0; i < maxSummaryErrors+5; i++ {
+		log.Error("row failed")
+	}
+	buf.Reset()
+	summary.Finish()
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, float64(maxSummaryErrors+5), entries[0]["errors_total"])
+	assert.Len(t, entries[0]["errors"], maxSummaryErrors)
+}
@@ -0,0 +1,245 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Протоколы syslog
+const (
+	SyslogRFC5424 = "rfc5424"
+	SyslogRFC3164 = "rfc3164"
+)
+
+// syslogDialTimeout таймаут подключения к серверу syslog
+const syslogDialTimeout = 5 * time.Second
+
+// localSyslogSockets сокеты локального syslog в порядке проверки
+var localSyslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// SyslogConfig настройки вывода в syslog
+type SyslogConfig struct {
+	// Network udp, tcp, unix или unixgram. Без Network и Address записи
+	// отправляются в локальный syslog (/dev/log)
+	Network  string `yaml:"network"`
+	Address  string `yaml:"address"`  // host:port или путь к сокету
+	Facility string `yaml:"facility"` // user (по умолчанию), daemon, local0..local7 и др.
+	Tag      string `yaml:"tag"`      // имя приложения, по умолчанию имя исполняемого файла
+	Protocol string `yaml:"protocol"` // rfc5424 (по умолчанию) или rfc3164
+}
+
+// syslogFacilities коды facility по RFC 5424
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5,
+	"lpr": 6, "news": 7, "uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslogSeverities уровни syslog для уровней логгера
+var syslogSeverities = map[logrus.Level]int{
+	logrus.PanicLevel: 1, // alert
+	logrus.FatalLevel: 2, // crit
+	logrus.ErrorLevel: 3, // err
+	logrus.WarnLevel:  4, // warning
+	logrus.InfoLevel:  6, // info
+	logrus.DebugLevel: 7, // debug
+	logrus.TraceLevel: 7,
+}
+
+// validate проверяет настройки syslog
+func (c SyslogConfig) validate() error {
+	var errs []error
+	switch c.Network {
+	case "":
+		if c.Address != "" {
+			errs = append(errs, errors.New("syslog network is required with address"))
+		}
+	case "udp", "udp4", "udp6", "tcp", "tcp4", "tcp6", "unix", "unixgram":
+		if c.Address == "" {
+			errs = append(errs, errors.New("syslog address is required"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unsupported syslog network: %s", c.Network))
+	}
+	if _, ok := syslogFacilities[firstNonEmpty(c.Facility, "user")]; !ok {
+		errs = append(errs, fmt.Errorf("unsupported syslog facility: %s", c.Facility))
+	}
+	switch c.Protocol {
+	case "", SyslogRFC5424, SyslogRFC3164:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported syslog protocol: %s", c.Protocol))
+	}
+	return errors.Join(errs...)
+}
+
+// openSyslogSink подключается к syslog и создает назначение
+func openSyslogSink(config Config) (*syslogConn, *sink, error) {
+	if err := config.Syslog.validate(); err != nil {
+		return nil, nil, err
+	}
+	inner, err := newFormatter(firstNonEmpty(config.Format, JSONFormat), false)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn := &syslogConn{network: config.Syslog.Network, address: config.Syslog.Address}
+	if err := conn.connect(); err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+
+	hostname, _ := os.Hostname()
+	formatter := &syslogFormatter{
+		inner:    inner,
+		facility: syslogFacilities[firstNonEmpty(config.Syslog.Facility, "user")],
+		tag:      firstNonEmpty(config.Syslog.Tag, filepath.Base(os.Args[0])),
+		hostname: firstNonEmpty(hostname, "-"),
+		pid:      os.Getpid(),
+		rfc3164:  config.Syslog.Protocol == SyslogRFC3164,
+	}
+	return conn, &sink{name: "syslog", writer: conn, formatter: formatter}, nil
+}
+
+// syslogFormatter оборачивает запись в сообщение syslog
+type syslogFormatter struct {
+	inner    logrus.Formatter
+	facility int
+	tag      string
+	hostname string
+	pid      int
+	rfc3164  bool
+}
+
+// Format форматирует запись вложенным форматтером и добавляет заголовок syslog
+func (f *syslogFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	msg, err := f.inner.Format(entry)
+	if err != nil {
+		return nil, err
+	}
+	msg = bytes.TrimRight(msg, "\n")
+
+	severity, ok := syslogSeverities[entry.Level]
+	if !ok {
+		severity = 7
+	}
+	pri := f.facility*8 + severity
+
+	var b bytes.Buffer
+	if f.rfc3164 {
+		fmt.Fprintf(&b, "<%d>%s %s %s[%d]: ", pri, entry.Time.Format(time.Stamp), f.hostname, f.tag, f.pid)
+	} else {
+		fmt.Fprintf(&b, "<%d>1 %s %s %s %d - - ", pri, entry.Time.Format(time.RFC3339Nano), f.hostname, f.tag, f.pid)
+	}
+	b.Write(msg)
+	return b.Bytes(), nil
+}
+
+// syslogConn соединение с syslog. В потоковых соединениях сообщения
+// разделяются префиксом длины (RFC 6587), при ошибке записи соединение
+// переоткрывается
+type syslogConn struct {
+	network string
+	address string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	stream bool
+	closed bool
+}
+
+// connect подключается к серверу или локальному syslog
+func (c *syslogConn) connect() error {
+	if c.network != "" {
+		conn, err := net.DialTimeout(c.network, c.address, syslogDialTimeout)
+		if err != nil {
+			return err
+		}
+		c.setConn(conn, c.network != "udp" && c.network != "udp4" && c.network != "udp6" && c.network != "unixgram")
+		return nil
+	}
+
+	var errs []error
+	for _, path := range localSyslogSockets {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := net.DialTimeout(network, path, syslogDialTimeout)
+			if err == nil {
+				c.setConn(conn, network == "unix")
+				return nil
+			}
+			errs = append(errs, err)
+		}
+	}
+	return fmt.Errorf("local syslog is not available: %w", errors.Join(errs...))
+}
+
+func (c *syslogConn) setConn(conn net.Conn, stream bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		conn.Close()
+		return
+	}
+	if c.conn != nil {
+		c.conn.Close()
+	}
+	c.conn, c.stream = conn, stream
+}
+
+// Write отправляет одно сообщение, переподключаясь при ошибке
+func (c *syslogConn) Write(p []byte) (int, error) {
+	if _, err := c.write(p); err == nil || errors.Is(err, net.ErrClosed) {
+		return len(p), err
+	}
+	if err := c.connect(); err != nil {
+		return 0, err
+	}
+	if _, err := c.write(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (c *syslogConn) write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return 0, net.ErrClosed
+	}
+	if c.stream {
+		frame := make([]byte, 0, len(p)+8)
+		frame = strconv.AppendInt(frame, int64(len(p)), 10)
+		frame = append(frame, ' ')
+		frame = append(frame, p...)
+		return c.conn.Write(frame)
+	}
+	return c.conn.Write(p)
+}
+
+// Sync ничего не делает: сообщения отправляются сразу
+func (c *syslogConn) Sync() error {
+	return nil
+}
+
+// Reopen переподключается к syslog
+func (c *syslogConn) Reopen() error {
+	return c.connect()
+}
+
+// Close закрывает соединение
+func (c *syslogConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	return c.conn.Close()
+}
@@ -0,0 +1,167 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readDatagram читает одно сообщение из сокета
+func readDatagram(t *testing.T, conn net.PacketConn) string {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, 64*1024)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	return string(buf[:n])
+}
+
+// syslogBody возвращает запись из сообщения RFC 5424
+func syslogBody(t *testing.T, msg string) map[string]interface{} {
+	t.Helper()
+	i := strings.Index(msg, " - - ")
+	require.GreaterOrEqual(t, i, 0, msg)
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(msg[i+5:]), &entry))
+	return entry
+}
+
+func TestSyslogOutput_UDP(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer server.Close()
+
+	log, err := New(Config{
+		Level:  DebugLevel,
+		Output: SyslogOutput,
+		Syslog: SyslogConfig{
+			Network:  "udp",
+			Address:  server.LocalAddr().String(),
+			Facility: "local0",
+			Tag:      "api",
+		},
+	})
+	require.NoError(t, err)
+	defer log.Close()
+
+	log.WithField("order_id", 42).Error("payment failed")
+	msg := readDatagram(t, server)
+
+	assert.Regexp(t, regexp.MustCompile(`^<131>1 \S+ \S+ api \d+ - - \{`), msg)
+	entry := syslogBody(t, msg)
+	assert.Equal(t, "payment failed", entry["msg"])
+	assert.EqualValues(t, 42, entry["order_id"])
+
+	log.Debug("details")
+	assert.True(t, strings.HasPrefix(readDatagram(t, server), "<135>1 "))
+}
+
+func TestSyslogOutput_TCPFraming(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	log, err := New(Config{
+		Level:  InfoLevel,
+		Output: SyslogOutput,
+		Syslog: SyslogConfig{Network: "tcp", Address: ln.Addr().String(), Tag: "api"},
+	})
+	require.NoError(t, err)
+	defer log.Close()
+
+	conn, err := ln.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	log.Info("first")
+	log.Warn("second")
+
+	r := bufio.NewReader(conn)
+	for _, want := range []struct {
+		pri, msg string
+	}{{"<14>", "first"}, {"<12>", "second"}} {
+		size, err := r.ReadString(' ')
+		require.NoError(t, err)
+		n, err := strconv.Atoi(strings.TrimSpace(size))
+		require.NoError(t, err)
+
+		frame := make([]byte, n)
+		_, err = io.ReadFull(r, frame)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(frame), want.pri+"1 "), string(frame))
+		assert.Equal(t, want.msg, syslogBody(t, string(frame))["msg"])
+	}
+}
+
+func TestSyslogOutput_UnixgramRFC3164(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	server, err := net.ListenPacket("unixgram", path)
+	require.NoError(t, err)
+	defer server.Close()
+
+	log, err := New(Config{
+		Level:  InfoLevel,
+		Output: SyslogOutput,
+		Format: TextFormat,
+		Syslog: SyslogConfig{
+			Network:  "unixgram",
+			Address:  path,
+			Facility: "daemon",
+			Tag:      "worker",
+			Protocol: SyslogRFC3164,
+		},
+	})
+	require.NoError(t, err)
+	defer log.Close()
+
+	log.Warn("queue is full")
+	msg := readDatagram(t, server)
+
+	assert.Regexp(t, regexp.MustCompile(`^<28>[A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2} \S+ worker\[\d+\]: `), msg)
+	assert.Contains(t, msg, "queue is full")
+	assert.False(t, strings.HasSuffix(msg, "\n"))
+}
+
+func TestSyslogOutput_Closed(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer server.Close()
+
+	conn := &syslogConn{network: "udp", address: server.LocalAddr().String()}
+	require.NoError(t, conn.connect())
+	require.NoError(t, conn.Close())
+
+	_, err = conn.Write([]byte("late"))
+	assert.ErrorIs(t, err, net.ErrClosed)
+	assert.NoError(t, conn.Close())
+}
+
+func TestSyslogConfig_Validate(t *testing.T) {
+	assert.NoError(t, SyslogConfig{}.validate())
+	assert.NoError(t, SyslogConfig{Network: "tcp", Address: "logs:514", Facility: "local7"}.validate())
+
+	err := Config{
+		Output: SyslogOutput,
+		Syslog: SyslogConfig{Network: "http", Facility: "local9", Protocol: "rfc1"},
+	}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported syslog network: http")
+	assert.Contains(t, err.Error(), "unsupported syslog facility: local9")
+	assert.Contains(t, err.Error(), "unsupported syslog protocol: rfc1")
+
+	err = Config{Output: SyslogOutput, Syslog: SyslogConfig{Network: "udp"}}.Validate()
+	assert.ErrorContains(t, err, "syslog address is required")
+	err = Config{Output: SyslogOutput, Syslog: SyslogConfig{Address: "logs:514"}}.Validate()
+	assert.ErrorContains(t, err, "syslog network is required")
+}
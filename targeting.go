@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"context"
+	"hash/fnv"
+	"sort"
+)
+
+// Targeting правила повышения детализации логов для отдельных пользователей
+type Targeting struct {
+	Level   Level    `yaml:"level" json:"level"`       // уровень для подходящих запросов (по умолчанию Debug)
+	Percent float64  `yaml:"percent" json:"percent"`   // доля пользователей по хешу user ID, 0-100
+	UserIDs []string `yaml:"user_ids" json:"user_ids"` // явный список пользователей
+}
+
+// targeting действующие правила таргетинга
+type targeting struct {
+	level   Level
+	percent float64
+	users   map[string]struct{}
+}
+
+// ctxUserIDKey ключ идентификатора пользователя в контексте
+type ctxUserIDKey struct{}
+
+// newTargeting создает правила из конфигурации
+func newTargeting(config Targeting) *targeting {
+	level := config.Level
+	if level == PanicLevel {
+		level = DebugLevel
+	}
+
+	t := &targeting{
+		level:   level,
+		percent: config.Percent,
+		users:   make(map[string]struct{}, len(config.UserIDs)),
+	}
+	for _, id := range config.UserIDs {
+		t.users[id] = struct{}{}
+	}
+	return t
+}
+
+// match проверяет, попадает ли пользователь под правила
+func (t *targeting) match(userID string) bool {
+	if _, ok := t.users[userID]; ok {
+		return true
+	}
+	if t.percent <= 0 {
+		return false
+	}
+
+	// Хеш дает стабильное решение для пользователя между запросами и инстансами
+	h := fnv.New32a()
+	h.Write([]byte(userID))
+	return float64(h.Sum32()%10000) < t.percent*100
+}
+
+// ContextWithUserID добавляет в контекст идентификатор пользователя
+func ContextWithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, ctxUserIDKey{}, userID)
+}
+
+// UserIDFromContext возвращает идентификатор пользователя из контекста
+func UserIDFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(ctxUserIDKey{}).(string)
+	return userID
+}
+
+// targetLevel возвращает уровень для пользователя из контекста, если он подходит под правила
+func (l *Logger) targetLevel(ctx context.Context) (Level, bool) {
+	userID := UserIDFromContext(ctx)
+	if userID == "" {
+		return 0, false
+	}
+
+	l.core.mu.RLock()
+	defer l.core.mu.RUnlock()
+
+	t := l.core.targeting
+	if !t.match(userID) {
+		return 0, false
+	}
+	return t.level, true
+}
+
+// SetTargeting заменяет правила таргетинга
+func (l *Logger) SetTargeting(config Targeting) {
+	if l == nil {
+		nilReceiver()
+		return
+	}
+	t := newTargeting(config)
+
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+	l.core.targeting = t
+}
+
+// Targeting возвращает действующие правила таргетинга
+func (l *Logger) Targeting() Targeting {
+	l = l.orNop()
+	l.core.mu.RLock()
+	defer l.core.mu.RUnlock()
+
+	t := l.core.targeting
+	users := make([]string, 0, len(t.users))
+	for id := range t.users {
+		users = append(users, id)
+	}
+	sort.Strings(users)
+
+	return Targeting{Level: t.level, Percent: t.percent, UserIDs: users}
+}
+
+// TargetUser добавляет пользователя в список повышенной детализации
+func (l *Logger) TargetUser(userID string) {
+	if l == nil {
+		nilReceiver()
+		return
+	}
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+
+	// Копируем правила, чтобы не менять их под читателями
+	t := *l.core.targeting
+	t.users = make(map[string]struct{}, len(l.core.targeting.users)+1)
+	for id := range l.core.targeting.users {
+		t.users[id] = struct{}{}
+	}
+	t.users[userID] = struct{}{}
+	l.core.targeting = &t
+}
+
+// UntargetUser удаляет пользователя из списка повышенной детализации
+func (l *Logger) UntargetUser(userID string) {
+	if l == nil {
+		nilReceiver()
+		return
+	}
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+
+	t := *l.core.targeting
+	t.users = make(map[string]struct{}, len(l.core.targeting.users))
+	for id := range l.core.targeting.users {
+		if id != userID {
+			t.users[id] = struct{}{}
+		}
+	}
+	l.core.targeting = &t
+}
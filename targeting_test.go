@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_Targeting_UserIDs(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	logger.SetLevel(InfoLevel)
+	logger.SetTargeting(Targeting{UserIDs: []string{"alice"}})
+
+	logger.WithContext(ContextWithUserID(context.Background(), "alice")).Debug("alice debug")
+	logger.WithContext(ContextWithUserID(context.Background(), "bob")).Debug("bob debug")
+	logger.WithContext(context.Background()).Debug("anonymous debug")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "alice debug", entries[0]["msg"])
+}
+
+func TestLogger_Targeting_Percent(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+	logger.SetLevel(InfoLevel)
+	logger.SetTargeting(Targeting{Level: TraceLevel, Percent: 25})
+
+	matched := 0
+	for i := 0; i < 1000; i++ {
+		ctx := ContextWithUserID(context.Background(), fmt.Sprintf("user-%d", i))
+		if logger.WithContext(ctx).enabled(TraceLevel) {
+			matched++
+		}
+	}
+	assert.InDelta(t, 250, matched, 60)
+
+	// Решение для пользователя стабильно
+	ctx := ContextWithUserID(context.Background(), "user-1")
+	assert.Equal(t, logger.WithContext(ctx).enabled(TraceLevel), logger.WithContext(ctx).enabled(TraceLevel))
+}
+
+func TestLogger_TargetUser(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+	logger.SetLevel(InfoLevel)
+
+	ctx := ContextWithUserID(context.Background(), "alice")
+	assert.False(t, logger.WithContext(ctx).enabled(DebugLevel))
+
+	logger.TargetUser("alice")
+	assert.True(t, logger.WithContext(ctx).enabled(DebugLevel))
+	assert.Equal(t, []string{"alice"}, logger.Targeting().UserIDs)
+
+	logger.UntargetUser("alice")
+	assert.False(t, logger.WithContext(ctx).enabled(DebugLevel))
+}
@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// TraceSampler сообщает, семплирован ли трейс запроса из контекста,
+// например trace.SpanContextFromContext(ctx).IsSampled() для OpenTelemetry
+type TraceSampler func(ctx context.Context) bool
+
+// ctxDebugKey ключ флага отладочного логирования в контексте
+type ctxDebugKey struct{}
+
+// SetTraceSampler включает режим, в котором логгеры, полученные через
+// WithContext для семплированных трейсов, пишут сообщения уровня Debug
+func (l *Logger) SetTraceSampler(sampler TraceSampler) {
+	if l == nil {
+		nilReceiver()
+		return
+	}
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+	l.core.sampler = sampler
+}
+
+// ContextWithDebug помечает контекст запроса для отладочного логирования
+func ContextWithDebug(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxDebugKey{}, true)
+}
+
+// DebugFromHeaders проверяет W3C-заголовки traceparent и baggage: отладка нужна,
+// если трейс семплирован или в baggage передан флаг debug=1
+func DebugFromHeaders(traceparent, baggage string) bool {
+	// traceparent: version-traceid-parentid-flags, бит 0x01 флагов - sampled
+	if parts := strings.Split(strings.TrimSpace(traceparent), "-"); len(parts) == 4 {
+		if flags, err := strconv.ParseUint(parts[3], 16, 8); err == nil && flags&0x01 != 0 {
+			return true
+		}
+	}
+
+	for _, member := range strings.Split(baggage, ",") {
+		// Свойства элемента baggage идут после ';'
+		member, _, _ = strings.Cut(member, ";")
+		key, value, ok := strings.Cut(member, "=")
+		if ok && strings.TrimSpace(key) == "debug" && strings.TrimSpace(value) == "1" {
+			return true
+		}
+	}
+	return false
+}
+
+// traceDebug проверяет, требует ли контекст отладочного логирования
+func (l *Logger) traceDebug(ctx context.Context) bool {
+	if debug, _ := ctx.Value(ctxDebugKey{}).(bool); debug {
+		return true
+	}
+
+	l.core.mu.RLock()
+	sampler := l.core.sampler
+	l.core.mu.RUnlock()
+
+	return sampler != nil && sampler(ctx)
+}
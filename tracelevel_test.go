@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_WithContext_TraceDebug(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	logger.SetLevel(InfoLevel)
+
+	logger.WithContext(context.Background()).Debug("regular request")
+	logger.WithContext(ContextWithDebug(context.Background())).Debug("debug request")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "debug request", entries[0]["msg"])
+	assert.Equal(t, InfoLevel, logger.GetLevel())
+}
+
+func TestLogger_WithContext_TraceSampler(t *testing.T) {
+	type sampledKey struct{}
+
+	logger, buf := newBufferLogger(t)
+	logger.SetLevel(InfoLevel)
+	logger.SetTraceSampler(func(ctx context.Context) bool {
+		sampled, _ := ctx.Value(sampledKey{}).(bool)
+		return sampled
+	})
+
+	sampled := logger.WithContext(context.WithValue(context.Background(), sampledKey{}, true))
+	sampled.Debug("sampled")
+	sampled.WithField("key", "value").Debug("sampled entry")
+	sampled.Trace("too verbose")
+
+	unsampled := logger.WithContext(context.Background())
+	unsampled.Debug("unsampled")
+	unsampled.WithField("key", "value").Debug("unsampled entry")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "sampled", entries[0]["msg"])
+	assert.Equal(t, "sampled entry", entries[1]["msg"])
+}
+
+func TestDebugFromHeaders(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceparent string
+		baggage     string
+		want        bool
+	}{
+		{
+			name:        "sampled trace",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			want:        true,
+		},
+		{
+			name:        "unsampled trace",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			want:        false,
+		},
+		{
+			name:    "debug baggage",
+			baggage: "userId=alice, debug=1;ttl=60",
+			want:    true,
+		},
+		{
+			name:    "debug disabled in baggage",
+			baggage: "debug=0",
+			want:    false,
+		},
+		{
+			name: "no headers",
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DebugFromHeaders(tt.traceparent, tt.baggage))
+		})
+	}
+}
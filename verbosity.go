@@ -0,0 +1,75 @@
+//go:build !windows
+
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ToggleVerbosityOnSignal включает управление детализацией сигналами: SIGUSR1
+// повышает уровень на одну ступень (Info -> Debug -> Trace), SIGUSR2 понижает
+// (не ниже Error, чтобы не скрыть ошибки). Каждое изменение записывается
+// независимо от уровня. Возвращает функцию, отключающую обработчик
+func (l *Logger) ToggleVerbosityOnSignal() (stop func()) {
+	if l == nil {
+		nilReceiver()
+		return func() {}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGUSR1, syscall.SIGUSR2)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-ch:
+				delta := 1
+				if sig == syscall.SIGUSR2 {
+					delta = -1
+				}
+				l.shiftLevel(delta, sig.String())
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+			wg.Wait()
+		})
+	}
+}
+
+// shiftLevel сдвигает уровень на delta ступеней в пределах Error..Trace
+// и записывает изменение
+func (l *Logger) shiftLevel(delta int, reason string) {
+	for {
+		old := l.core.level.Load()
+		next := int(old) + delta
+		if next < int(ErrorLevel) || next > int(TraceLevel) {
+			return
+		}
+		if !l.core.level.CompareAndSwap(old, uint32(next)) {
+			continue
+		}
+
+		l.internalFields().WithFields(logrus.Fields{
+			"old_level": Level(old).String(),
+			"new_level": Level(next).String(),
+			"signal":    reason,
+		}).Log(logrus.InfoLevel, "log level changed")
+		return
+	}
+}
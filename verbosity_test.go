@@ -0,0 +1,73 @@
+//go:build !windows
+
+package logger
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_ShiftLevel(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	logger.SetLevel(InfoLevel)
+
+	logger.shiftLevel(1, "test")
+	assert.Equal(t, DebugLevel, logger.GetLevel())
+	logger.shiftLevel(1, "test")
+	logger.shiftLevel(1, "test")
+	assert.Equal(t, TraceLevel, logger.GetLevel())
+
+	logger.SetLevel(WarnLevel)
+	logger.shiftLevel(-1, "test")
+	logger.shiftLevel(-1, "test")
+	assert.Equal(t, ErrorLevel, logger.GetLevel())
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "log level changed", entries[0]["msg"])
+	assert.Equal(t, "info", entries[0]["old_level"])
+	assert.Equal(t, "debug", entries[0]["new_level"])
+	assert.Equal(t, "warning", entries[2]["old_level"])
+	assert.Equal(t, "error", entries[2]["new_level"], "change is logged even above the new level")
+}
+
+func TestLogger_ShiftLevelPipeline(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(Config{
+		Level:         ErrorLevel,
+		Writers:       []io.Writer{buf},
+		DefaultFields: map[string]interface{}{"env": "prod"},
+	})
+	require.NoError(t, err)
+
+	logger.shiftLevel(1, "SIGUSR1")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "log level changed", entries[0]["msg"])
+	assert.Equal(t, "prod", entries[0]["env"])
+}
+
+func TestLogger_ToggleVerbosityOnSignal(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+	logger.SetLevel(InfoLevel)
+
+	stop := logger.ToggleVerbosityOnSignal()
+	defer stop()
+
+	process, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+
+	require.NoError(t, process.Signal(syscall.SIGUSR1))
+	assert.Eventually(t, func() bool { return logger.GetLevel() == DebugLevel }, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, process.Signal(syscall.SIGUSR2))
+	assert.Eventually(t, func() bool { return logger.GetLevel() == InfoLevel }, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package logger
+
+// ToggleVerbosityOnSignal ничего не делает: в Windows нет сигналов SIGUSR1 и
+// SIGUSR2. Метод есть на всех платформах, чтобы код собирался одинаково
+func (l *Logger) ToggleVerbosityOnSignal() (stop func()) {
+	return func() {}
+}
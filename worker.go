@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// WithWorker создает дочерний логгер с номером горутины-воркера
+func (l *Logger) WithWorker(worker int) *Logger {
+	return l.with(logrus.Fields{"worker": worker})
+}
+
+// WorkerGroup группа горутин по образцу errgroup.Group: каждая горутина получает
+// логгер со своим номером, а ошибка первой упавшей горутины отменяет контекст
+// группы и записывается в лог вместе с номером воркера
+type WorkerGroup struct {
+	logger *Logger
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	next   int
+	err    error
+	failed int
+}
+
+// WorkerGroup создает группу горутин и производный контекст, который
+// отменяется при первой ошибке или после Wait
+func (l *Logger) WorkerGroup(ctx context.Context) (*WorkerGroup, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &WorkerGroup{logger: l, cancel: cancel, failed: -1}, ctx
+}
+
+// Go запускает функцию в новой горутине с логгером, помеченным номером воркера
+func (g *WorkerGroup) Go(f func(log *Logger) error) {
+	g.mu.Lock()
+	worker := g.next
+	g.next++
+	g.mu.Unlock()
+
+	log := g.logger.WithWorker(worker)
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+
+		if err := f(log); err != nil {
+			g.fail(log, worker, err)
+		}
+	}()
+}
+
+// fail запоминает первую ошибку и отменяет группу
+func (g *WorkerGroup) fail(log *Logger, worker int, err error) {
+	g.mu.Lock()
+	first := g.err == nil
+	if first {
+		g.err = err
+		g.failed = worker
+	}
+	g.mu.Unlock()
+
+	if !first {
+		log.WithError(err).Debug("worker failed after group was canceled")
+		return
+	}
+
+	log.WithError(err).Error("worker failed, canceling group")
+	g.cancel(err)
+}
+
+// Wait дожидается завершения всех горутин и возвращает первую ошибку
+func (g *WorkerGroup) Wait() error {
+	g.wg.Wait()
+	g.cancel(context.Canceled)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.err
+}
+
+// FailedWorker возвращает номер воркера, ошибка которого отменила группу
+func (g *WorkerGroup) FailedWorker() (int, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.failed, g.failed >= 0
+}
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_WithWorker(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	logger.WithService("ingest").WithWorker(3).Info("started")
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, float64(3), entries[0]["worker"])
+	assert.Equal(t, "ingest", entries[0]["service"])
+}
+
+func TestWorkerGroup(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	logger.SetLevel(InfoLevel)
+
+	group, ctx := logger.WorkerGroup(context.Background())
+
+	failing := make(chan struct{})
+	group.Go(func(log *Logger) error {
+		<-ctx.Done()
+		log.Info("stopped")
+		return ctx.Err()
+	})
+	group.Go(func(log *Logger) error {
+		defer close(failing)
+		return assert.AnError
+	})
+
+	<-failing
+	err := group.Wait()
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.ErrorIs(t, context.Cause(ctx), assert.AnError)
+
+	worker, ok := group.FailedWorker()
+	assert.True(t, ok)
+	assert.Equal(t, 1, worker)
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	byMsg := make(map[string]map[string]interface{})
+	for _, entry := range entries {
+		byMsg[entry["msg"].(string)] = entry
+	}
+	assert.Equal(t, float64(1), byMsg["worker failed, canceling group"]["worker"])
+	assert.Equal(t, float64(0), byMsg["stopped"]["worker"])
+}
+
+func TestWorkerGroup_NoError(t *testing.T) {
+	logger, _ := newBufferLogger(t)
+
+	group, ctx := logger.WorkerGroup(context.Background())
+	for i := 0; i < 3; i++ {
+		group.Go(func(log *Logger) error { return nil })
+	}
+
+	assert.NoError(t, group.Wait())
+	assert.Error(t, ctx.Err())
+
+	_, ok := group.FailedWorker()
+	assert.False(t, ok)
+}
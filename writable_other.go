@@ -0,0 +1,23 @@
+//go:build !unix
+
+package logger
+
+import (
+	"fmt"
+	"os"
+)
+
+// checkAccess проверяет атрибут только для чтения. Права ACL без создания
+// файла не проверить, их ошибку вернет New
+func checkAccess(path string, dir bool) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	// В Windows атрибут только для чтения у каталога не запрещает создавать
+	// в нем файлы
+	if !dir && info.Mode().Perm()&0o200 == 0 {
+		return fmt.Errorf("%s is read-only", path)
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+//go:build unix
+
+package logger
+
+import "golang.org/x/sys/unix"
+
+// checkAccess проверяет право записи без создания файлов. В каталог нужен
+// еще и доступ на поиск, чтобы создать в нем файл
+func checkAccess(path string, dir bool) error {
+	mode := uint32(unix.W_OK)
+	if dir {
+		mode |= unix.X_OK
+	}
+	return unix.Access(path, mode)
+}
@@ -0,0 +1,168 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxWriterLineBytes размер незавершенной строки, после которого она
+// записывается, не дожидаясь перевода строки
+const maxWriterLineBytes = 64 << 10
+
+// syslogLevels уровни приоритетов syslog в префиксе <N> (sd-daemon).
+// emerg, alert и crit пишутся как Error: чужой вывод не должен завершать процесс
+var syslogLevels = [8]Level{ErrorLevel, ErrorLevel, ErrorLevel, ErrorLevel, WarnLevel, InfoLevel, InfoLevel, DebugLevel}
+
+// prefixLevels уровни для префиксов [ERROR] и WARN:
+var prefixLevels = map[string]Level{
+	"trace": TraceLevel, "debug": DebugLevel, "dbg": DebugLevel,
+	"info": InfoLevel, "notice": InfoLevel,
+	"warn": WarnLevel, "warning": WarnLevel,
+	"error": ErrorLevel, "err": ErrorLevel, "crit": ErrorLevel, "critical": ErrorLevel,
+	"alert": ErrorLevel, "emerg": ErrorLevel, "fatal": ErrorLevel, "panic": ErrorLevel,
+}
+
+// lineWriter пишет каждую строку вывода отдельной записью логгера
+type lineWriter struct {
+	logger *Logger
+	level  Level // уровень строк без распознанного префикса
+	parse  bool  // определять уровень по префиксу строки
+
+	// Для вывода подпроцессов: удаление ANSI-последовательностей, поиск
+	// уровня в начале строки и запись незавершенной строки по таймауту
+	capture bool
+	timeout time.Duration
+
+	mu     sync.Mutex
+	buf    []byte
+	timer  *time.Timer
+	closed bool
+}
+
+// Writer возвращает io.WriteCloser, который пишет каждую строку отдельной
+// записью. Уровень определяется по префиксу строки: <N> в формате sd-daemon,
+// [ERROR] или WARN:, префикс удаляется из сообщения. Строки без префикса
+// пишутся как Info. Close записывает последнюю незавершенную строку
+func (l *Logger) Writer() io.WriteCloser {
+	return &lineWriter{logger: l, level: InfoLevel, parse: true}
+}
+
+// WriterLevel возвращает io.WriteCloser, который пишет каждую строку
+// отдельной записью уровня level без разбора префиксов
+func (l *Logger) WriterLevel(level Level) io.WriteCloser {
+	return &lineWriter{logger: l, level: level}
+}
+
+// Write буферизует вывод и пишет завершенные строки
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.logLine(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	if len(w.buf) >= maxWriterLineBytes {
+		w.logLine(w.buf)
+		w.buf = nil
+	}
+	if len(w.buf) > 0 && w.timeout > 0 {
+		if w.timer == nil {
+			w.timer = time.AfterFunc(w.timeout, w.flushPartial)
+		} else {
+			w.timer.Reset(w.timeout)
+		}
+	}
+	return len(p), nil
+}
+
+// flushPartial пишет незавершенную строку, продолжение которой не пришло
+// за таймаут
+func (w *lineWriter) flushPartial() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed || len(w.buf) == 0 {
+		return
+	}
+	w.logLine(w.buf)
+	w.buf = nil
+}
+
+// Close записывает незавершенную строку. Повторный вызов ничего не делает
+func (w *lineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	if len(w.buf) > 0 {
+		w.logLine(w.buf)
+		w.buf = nil
+	}
+	return nil
+}
+
+// logLine пишет одну строку вывода
+func (w *lineWriter) logLine(line []byte) {
+	msg := strings.TrimRight(string(line), "\r")
+	if w.capture {
+		msg = stripANSI(msg)
+	}
+	level := w.level
+	if w.parse {
+		if parsed, rest, ok := parseLinePrefix(msg); ok {
+			level, msg = parsed, rest
+		} else if w.capture {
+			if parsed, ok := detectLevel(msg); ok {
+				level = parsed
+			}
+		}
+	}
+	if strings.TrimSpace(msg) == "" || !w.logger.enabled(level) {
+		return
+	}
+	w.logger.withFields().Log(logrus.Level(level), msg)
+}
+
+// parseLinePrefix определяет уровень по префиксу строки и возвращает строку
+// без префикса
+func parseLinePrefix(line string) (Level, string, bool) {
+	// <N>: приоритет syslog от 0 до 7
+	if len(line) >= 3 && line[0] == '<' && line[2] == '>' && line[1] >= '0' && line[1] <= '7' {
+		return syslogLevels[line[1]-'0'], strings.TrimLeft(line[3:], " "), true
+	}
+
+	// [ERROR] message
+	if strings.HasPrefix(line, "[") {
+		if end := strings.IndexByte(line, ']'); end > 0 {
+			if level, ok := prefixLevels[strings.ToLower(line[1:end])]; ok {
+				return level, strings.TrimLeft(line[end+1:], " "), true
+			}
+		}
+	}
+
+	// WARN: message
+	if end := strings.IndexByte(line, ':'); end > 0 {
+		if level, ok := prefixLevels[strings.ToLower(line[:end])]; ok {
+			return level, strings.TrimLeft(line[end+1:], " "), true
+		}
+	}
+	return 0, line, false
+}
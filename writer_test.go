@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLinePrefix(t *testing.T) {
+	tests := []struct {
+		line  string
+		level Level
+		msg   string
+		ok    bool
+	}{
+		{line: "<3>disk failure", level: ErrorLevel, msg: "disk failure", ok: true},
+		{line: "<4> low memory", level: WarnLevel, msg: "low memory", ok: true},
+		{line: "<0>emergency", level: ErrorLevel, msg: "emergency", ok: true},
+		{line: "<7>details", level: DebugLevel, msg: "details", ok: true},
+		{line: "[ERROR] connection refused", level: ErrorLevel, msg: "connection refused", ok: true},
+		{line: "[warning] retrying", level: WarnLevel, msg: "retrying", ok: true},
+		{line: "[FATAL] gave up", level: ErrorLevel, msg: "gave up", ok: true},
+		{line: "WARN: deprecated flag", level: WarnLevel, msg: "deprecated flag", ok: true},
+		{line: "debug:verbose", level: DebugLevel, msg: "verbose", ok: true},
+		{line: "<9>not syslog", msg: "<9>not syslog"},
+		{line: "[worker-1] started", msg: "[worker-1] started"},
+		{line: "note: plain text", msg: "note: plain text"},
+		{line: "plain line", msg: "plain line"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			level, msg, ok := parseLinePrefix(tt.line)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.msg, msg)
+			if tt.ok {
+				assert.Equal(t, tt.level, level)
+			}
+		})
+	}
+}
+
+func TestLogger_Writer(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	logger.SetLevel(InfoLevel)
+
+	w := logger.Writer()
+	_, err := w.Write([]byte("<3>disk failure\r\n[WARN] slow "))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("query\n\nDEBUG: hidden\nplain"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, w.Close())
+
+	_, err = w.Write([]byte("after close\n"))
+	assert.Error(t, err)
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "error", entries[0]["level"])
+	assert.Equal(t, "disk failure", entries[0]["msg"])
+	assert.Equal(t, "warning", entries[1]["level"])
+	assert.Equal(t, "slow query", entries[1]["msg"])
+	assert.Equal(t, "info", entries[2]["level"])
+	assert.Equal(t, "plain", entries[2]["msg"])
+}
+
+func TestLogger_WriterLongLine(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+
+	w := logger.Writer()
+	_, err := w.Write([]byte(strings.Repeat("x", maxWriterLineBytes)))
+	require.NoError(t, err)
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Len(t, entries[0]["msg"], maxWriterLineBytes)
+}
+
+func TestLogger_WriterLevel(t *testing.T) {
+	logger, buf := newBufferLogger(t)
+	logger.SetLevel(InfoLevel)
+
+	w := logger.WithService("legacy").WriterLevel(WarnLevel)
+	_, err := io.WriteString(w, "[ERROR] kept as is\nsecond line\n")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	hidden := logger.WriterLevel(DebugLevel)
+	_, err = io.WriteString(hidden, "debug output\n")
+	require.NoError(t, err)
+
+	entries := decodeEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "warning", entries[0]["level"])
+	assert.Equal(t, "[ERROR] kept as is", entries[0]["msg"])
+	assert.Equal(t, "legacy", entries[0]["service"])
+	assert.Equal(t, "second line", entries[1]["msg"])
+}
@@ -0,0 +1,58 @@
+//go:build linux
+
+package logger
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// maxIovecs ограничение числа буферов в одном вызове writev (IOV_MAX)
+const maxIovecs = 1024
+
+// writev пишет буферы в файл вызовами writev, дописывая остаток после
+// частичной записи
+func (w *fileWriter) writev(bufs [][]byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	rc, err := w.file.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	for len(bufs) > 0 {
+		chunk := bufs[:min(len(bufs), maxIovecs)]
+		var n int
+		var werr error
+		if err := rc.Write(func(fd uintptr) bool {
+			n, werr = unix.Writev(int(fd), chunk)
+			return werr != unix.EAGAIN
+		}); err != nil {
+			return err
+		}
+		if werr == unix.EINTR {
+			continue
+		}
+		if werr != nil {
+			return werr
+		}
+		bufs = advanceBuffers(bufs, n)
+	}
+	return nil
+}
+
+// advanceBuffers пропускает n записанных байт
+func advanceBuffers(bufs [][]byte, n int) [][]byte {
+	for len(bufs) > 0 && n >= len(bufs[0]) {
+		n -= len(bufs[0])
+		bufs = bufs[1:]
+	}
+	if len(bufs) > 0 && n > 0 {
+		// Не меняем исходный срез: буферы принадлежат пачке
+		rest := make([][]byte, len(bufs))
+		copy(rest, bufs)
+		rest[0] = rest[0][n:]
+		bufs = rest
+	}
+	return bufs
+}
@@ -0,0 +1,17 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdvanceBuffers(t *testing.T) {
+	bufs := [][]byte{[]byte("abc"), []byte("de"), []byte("f")}
+
+	assert.Equal(t, bufs, advanceBuffers(bufs, 0))
+	assert.Equal(t, [][]byte{[]byte("c"), []byte("de"), []byte("f")}, advanceBuffers(bufs, 2))
+	assert.Equal(t, [][]byte{[]byte("e"), []byte("f")}, advanceBuffers(bufs, 4))
+	assert.Empty(t, advanceBuffers(bufs, 6))
+	assert.Equal(t, "abc", string(bufs[0]), "source buffers must not change")
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package logger
+
+import "bytes"
+
+// writev пишет буферы в файл одним вызовом Write: writev используется только на Linux
+func (w *fileWriter) writev(bufs [][]byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := w.file.Write(bytes.Join(bufs, nil))
+	return err
+}
@@ -0,0 +1,173 @@
+// Package zapbackend подключает к логгеру бэкенд на zap. Достаточно импорта
+// ради побочного эффекта:
+//
+//	import _ "github.com/ex-rate/logger/zapbackend"
+//
+// после чего Config.Backend: "zap" выводит записи кодировщиком zap вместо
+// форматтеров logrus, не меняя вызовов в коде. Бэкенд вынесен в отдельный
+// модуль, чтобы основной пакет не зависел от zap
+package zapbackend
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/ex-rate/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Name имя бэкенда в Config.Backend
+const Name = "zap"
+
+func init() {
+	logger.RegisterBackend(Name, Open)
+}
+
+// levels уровни zap для уровней логгера. В zap нет Trace, такие записи
+// пишутся как debug
+var levels = map[logger.Level]zapcore.Level{
+	logger.TraceLevel: zapcore.DebugLevel,
+	logger.DebugLevel: zapcore.DebugLevel,
+	logger.InfoLevel:  zapcore.InfoLevel,
+	logger.WarnLevel:  zapcore.WarnLevel,
+	logger.ErrorLevel: zapcore.ErrorLevel,
+	logger.FatalLevel: zapcore.FatalLevel,
+	logger.PanicLevel: zapcore.PanicLevel,
+}
+
+// Backend пишет записи логгера в zapcore.Core. Уровень, семплирование и
+// скрытие полей уже применены логгером, поэтому core принимает все уровни
+type Backend struct {
+	core    zapcore.Core
+	closers []io.Closer
+}
+
+// New создает бэкенд поверх готового core, например со своим кодировщиком
+// или выводом:
+//
+//	logger.RegisterBackend("zap", func(logger.Config) (logger.Backend, error) {
+//		return zapbackend.New(core), nil
+//	})
+func New(core zapcore.Core) *Backend {
+	return &Backend{core: core}
+}
+
+// Open создает бэкенд по конфигурации логгера: Output console, file или
+// both, Format и FileFormat json или text. Ротация файла не поддерживается
+func Open(config logger.Config) (logger.Backend, error) {
+	var cores []zapcore.Core
+	var closers []io.Closer
+
+	console := func() error {
+		enc, err := encoder(config.Format)
+		if err != nil {
+			return err
+		}
+		// Sync для stdout в пайпе или терминале возвращает ошибку, сбрасывать
+		// там нечего
+		cores = append(cores, zapcore.NewCore(enc, zapcore.AddSync(struct{ io.Writer }{os.Stdout}), zapcore.DebugLevel))
+		return nil
+	}
+	file := func() error {
+		enc, err := encoder(firstNonEmpty(config.FileFormat, config.Format))
+		if err != nil {
+			return err
+		}
+		f, err := os.OpenFile(config.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		cores = append(cores, zapcore.NewCore(enc, zapcore.Lock(f), zapcore.DebugLevel))
+		closers = append(closers, f)
+		return nil
+	}
+
+	var err error
+	switch config.Output {
+	case "", logger.ConsoleOutput:
+		err = console()
+	case logger.FileOutput:
+		err = file()
+	case logger.BothOutput:
+		err = console()
+		if err == nil && config.FilePath != "" {
+			err = file()
+		}
+	default:
+		err = fmt.Errorf("unsupported output type for zap backend: %s", config.Output)
+	}
+	if err != nil {
+		for _, c := range closers {
+			_ = c.Close()
+		}
+		return nil, err
+	}
+	return &Backend{core: zapcore.NewTee(cores...), closers: closers}, nil
+}
+
+// encoder создает кодировщик zap с теми же ключами, что у форматтеров logrus
+func encoder(format string) (zapcore.Encoder, error) {
+	cfg := zap.NewProductionEncoderConfig()
+	cfg.TimeKey = "time"
+	cfg.MessageKey = "msg"
+	cfg.EncodeTime = zapcore.RFC3339TimeEncoder
+	cfg.CallerKey = zapcore.OmitKey
+	cfg.StacktraceKey = zapcore.OmitKey
+
+	switch format {
+	case "", logger.JSONFormat:
+		return zapcore.NewJSONEncoder(cfg), nil
+	case logger.TextFormat:
+		return zapcore.NewConsoleEncoder(cfg), nil
+	}
+	return nil, fmt.Errorf("unsupported format: %s", format)
+}
+
+// firstNonEmpty возвращает первую непустую строку
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Write пишет запись с полями в порядке ключей
+func (b *Backend) Write(entry logger.Entry) error {
+	keys := make([]string, 0, entry.Len())
+	entry.Range(func(key string, _ interface{}) bool {
+		keys = append(keys, key)
+		return true
+	})
+	sort.Strings(keys)
+
+	fields := make([]zapcore.Field, 0, len(keys))
+	for _, k := range keys {
+		v, _ := entry.Field(k)
+		fields = append(fields, zap.Any(k, v))
+	}
+	return b.core.Write(zapcore.Entry{
+		Level:   levels[entry.Level()],
+		Time:    entry.Time(),
+		Message: entry.Message(),
+	}, fields)
+}
+
+// Sync сбрасывает буферы core
+func (b *Backend) Sync() error {
+	return b.core.Sync()
+}
+
+// Close сбрасывает буферы и закрывает открытые бэкендом файлы
+func (b *Backend) Close() error {
+	errs := []error{b.core.Sync()}
+	for _, c := range b.closers {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,86 @@
+package zapbackend
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ex-rate/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// decode разбирает записи JSON по строкам
+func decode(t *testing.T, data []byte) []map[string]interface{} {
+	t.Helper()
+	var entries []map[string]interface{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestBackend_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	log, err := logger.New(logger.Config{
+		Level:    logger.DebugLevel,
+		Backend:  Name,
+		Output:   logger.FileOutput,
+		FilePath: path,
+		Redact:   true,
+	})
+	require.NoError(t, err)
+
+	orders := log.WithService("orders")
+	orders.WithField("password", "secret").WithError(assert.AnError).Error("charge failed")
+	orders.Debug("retrying")
+	orders.Trace("hidden")
+	require.NoError(t, log.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	entries := decode(t, data)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "error", entries[0]["level"])
+	assert.Equal(t, "charge failed", entries[0]["msg"])
+	assert.Equal(t, "orders", entries[0]["service"])
+	assert.Equal(t, "[REDACTED]", entries[0]["password"])
+	assert.Equal(t, assert.AnError.Error(), entries[0]["error"])
+	assert.NotEmpty(t, entries[0]["time"])
+	assert.Equal(t, "debug", entries[1]["level"])
+}
+
+func TestNew(t *testing.T) {
+	var out bytes.Buffer
+	enc, err := encoder(logger.JSONFormat)
+	require.NoError(t, err)
+	core := zapcore.NewCore(enc, zapcore.AddSync(&out), zapcore.DebugLevel)
+	logger.RegisterBackend("zap-custom", func(logger.Config) (logger.Backend, error) {
+		return New(core), nil
+	})
+
+	log, err := logger.New(logger.Config{Level: logger.InfoLevel, Backend: "zap-custom"})
+	require.NoError(t, err)
+	log.WithField("order", 42).Info("paid")
+	require.NoError(t, log.Close())
+
+	entries := decode(t, out.Bytes())
+	require.Len(t, entries, 1)
+	assert.Equal(t, "paid", entries[0]["msg"])
+	assert.Equal(t, float64(42), entries[0]["order"])
+}
+
+func TestOpen_UnsupportedOutput(t *testing.T) {
+	_, err := Open(logger.Config{Output: logger.SyslogOutput})
+	assert.ErrorContains(t, err, "unsupported output type for zap backend")
+
+	_, err = Open(logger.Config{Output: logger.ConsoleOutput, Format: "xml"})
+	assert.ErrorContains(t, err, "unsupported format")
+}